@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -12,8 +13,10 @@ import (
 	"github.com/compozy/gograph/engine/graph"
 	"github.com/compozy/gograph/engine/infra"
 	"github.com/compozy/gograph/engine/parser"
+	"github.com/compozy/gograph/engine/query"
 	"github.com/compozy/gograph/pkg/config"
 	"github.com/compozy/gograph/pkg/errors"
+	"github.com/compozy/gograph/pkg/git"
 	"github.com/compozy/gograph/pkg/logger"
 	"github.com/compozy/gograph/pkg/progress"
 	"github.com/mattn/go-isatty"
@@ -53,7 +56,13 @@ The resulting graph allows you to:
   gograph analyze /path/to/project --no-progress
   
   # Analyze with custom config file
-  gograph analyze /path/to/project -c custom-config.yaml`,
+  gograph analyze /path/to/project -c custom-config.yaml
+
+  # Backfill a snapshot of a release without touching the checkout
+  gograph analyze . --ref v1.2.0
+
+  # Write CSV files for an offline initial load with neo4j-admin
+  gograph analyze . --emit-bulk-csv ./import`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		projectPath := args[0]
@@ -79,14 +88,7 @@ The resulting graph allows you to:
 			}
 
 			// Initialize parser configuration from config
-			parserConfig := &parser.Config{
-				IgnoreDirs:      cfg.Analysis.IgnoreDirs,
-				IgnoreFiles:     cfg.Analysis.IgnoreFiles,
-				IncludeTests:    cfg.Analysis.IncludeTests,
-				IncludeVendor:   cfg.Analysis.IncludeVendor,
-				EnableSSA:       true,
-				EnableCallGraph: true,
-			}
+			parserConfig := parserConfigFromConfig(cfg)
 
 			// Initialize analyzer configuration with defaults
 			analyzerConfig := analyzer.DefaultAnalyzerConfig()
@@ -114,31 +116,121 @@ The resulting graph allows you to:
 				BatchSize:  1000,
 			}
 
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return fmt.Errorf("failed to get format flag: %w", err)
+			}
+			if format != findingsFormatText && format != findingsFormatSARIF {
+				return fmt.Errorf("unsupported format %q (use %s or %s)", format, findingsFormatText, findingsFormatSARIF)
+			}
+			outputPath, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return fmt.Errorf("failed to get output flag: %w", err)
+			}
+			// SARIF on stdout must not be interleaved with progress output
+			if format == findingsFormatSARIF && outputPath == "" {
+				noProgress = true
+			}
+
+			ref, err := cmd.Flags().GetString("ref")
+			if err != nil {
+				return fmt.Errorf("failed to get ref flag: %w", err)
+			}
+			bulkCSVDir, err := cmd.Flags().GetString("emit-bulk-csv")
+			if err != nil {
+				return fmt.Errorf("failed to get emit-bulk-csv flag: %w", err)
+			}
+			// SARIF findings may be read back from the stored graph
+			if bulkCSVDir != "" && format == findingsFormatSARIF {
+				return fmt.Errorf("--emit-bulk-csv cannot be combined with --format %s", findingsFormatSARIF)
+			}
+
+			// A git revision is analyzed from a temporary worktree, leaving the
+			// checkout untouched, and always recorded as an archived snapshot of
+			// its commit, leaving the latest graph as it is. The worktree is
+			// removed on exit, so files are stored under the project root.
+			projectRoot, err := filepath.Abs(projectPath)
+			if err != nil {
+				return fmt.Errorf("failed to resolve project path: %w", err)
+			}
+			analyzedPath := projectRoot
+			snapshotConfig := cfg
+			if ref != "" {
+				logger.Info("checking out revision", "ref", ref)
+				worktree, err := git.NewWorktree(context.Background(), projectPath, ref)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					if err := worktree.Remove(context.Background()); err != nil {
+						logger.Warn("failed to remove worktree", "path", worktree.Path, "error", err)
+					}
+				}()
+				if analyzedPath, err = worktree.ProjectDir(projectPath); err != nil {
+					return err
+				}
+				revisionConfig := *cfg
+				revisionConfig.Snapshots.Enabled = true
+				snapshotConfig = &revisionConfig
+			}
+
+			// Describe the snapshot the analysis is stored as. A bulk import
+			// loads a new database, so there are no earlier snapshots to keep.
+			var snapshot *core.Snapshot
+			if bulkCSVDir == "" {
+				snapshot = newAnalysisSnapshot(analyzedPath, snapshotConfig, ref != "")
+			}
+			if snapshot != nil {
+				snapshot.Root = projectRoot
+			}
+
 			// Start the analysis
+			var output *analysisOutput
 			if noProgress {
-				return runAnalysisWithoutProgress(projectPath, projectID, parserConfig, analyzerConfig, neo4jConfig)
+				output, err = runAnalysisWithoutProgress(
+					analyzedPath, projectRoot, projectID, parserConfig, analyzerConfig, neo4jConfig, cfg, snapshot, bulkCSVDir)
+			} else {
+				// Check if we're in TTY mode and suppress logging if so
+				isTTY := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+				if isTTY {
+					// Suppress all logging output to avoid conflicts with TUI
+					logger.Disable()
+					defer logger.Enable() // Re-enable after completion
+				}
+				output, err = runAnalysisWithProgress(
+					analyzedPath, projectRoot, projectID, parserConfig, analyzerConfig, neo4jConfig, cfg, snapshot, bulkCSVDir)
 			}
-
-			// Check if we're in TTY mode and suppress logging if so
-			isTTY := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
-			if isTTY {
-				// Suppress all logging output to avoid conflicts with TUI
-				logger.Disable()
-				defer logger.Enable() // Re-enable after completion
+			if err != nil {
+				return err
+			}
+			if output.bulkFiles != nil {
+				printBulkImportCommand(cmd, output.bulkFiles, neo4jConfig.Database)
+				return nil
+			}
+			if format != findingsFormatSARIF {
+				return nil
 			}
 
-			return runAnalysisWithProgress(projectPath, projectID, parserConfig, analyzerConfig, neo4jConfig)
+			// Findings of a backfilled revision are read from its snapshot
+			findingsProject := projectID
+			if snapshot != nil && snapshot.Backfill {
+				findingsProject = snapshot.Scope()
+			}
+			return writeAnalysisSARIF(cmd, analyzedPath, projectRoot, findingsProject, cfg, neo4jConfig, output, outputPath)
 		})
 	},
 }
 
 func runAnalysisWithoutProgress(
-	projectPath string,
+	projectPath, projectRoot string, // Directory parsed and root its files are stored under
 	projectID core.ID,
 	parserConfig *parser.Config,
 	analyzerConfig *analyzer.Config,
 	neo4jConfig *infra.Neo4jConfig,
-) error {
+	cfg *config.Config,
+	snapshot *core.Snapshot,
+	bulkCSVDir string,
+) (*analysisOutput, error) {
 	ctx := context.Background()
 	startTime := time.Now()
 
@@ -149,7 +241,7 @@ func runAnalysisWithoutProgress(
 	parserService := parser.NewService(parserConfig)
 	parseResult, err := parserService.ParseProject(ctx, projectPath, parserConfig)
 	if err != nil {
-		return fmt.Errorf("failed to parse project: %w", err)
+		return nil, fmt.Errorf("failed to parse project: %w", err)
 	}
 
 	// Count total files from packages
@@ -174,7 +266,7 @@ func runAnalysisWithoutProgress(
 	}
 	report, err := analyzerService.AnalyzeProject(ctx, analysisInput)
 	if err != nil {
-		return fmt.Errorf("failed to analyze project: %w", err)
+		return nil, fmt.Errorf("failed to analyze project: %w", err)
 	}
 	logger.Info("analysis completed",
 		"interfaces", len(report.InterfaceImplementations),
@@ -188,8 +280,10 @@ func runAnalysisWithoutProgress(
 	builder := graph.NewBuilder(nil) // Use default config
 	graphResult, err := builder.BuildFromAnalysis(ctx, projectID, parseResult, report)
 	if err != nil {
-		return fmt.Errorf("failed to build graph: %w", err)
+		return nil, fmt.Errorf("failed to build graph: %w", err)
 	}
+	graph.RelocateFiles(graphResult, projectPath, projectRoot)
+	graphResult.Snapshot = snapshot
 	logger.Info("graph built",
 		"nodes", len(graphResult.Nodes),
 		"relationships", len(graphResult.Relationships))
@@ -197,33 +291,60 @@ func runAnalysisWithoutProgress(
 	// -----
 	// Storage Phase
 	// -----
+	output := &analysisOutput{parseResult: parseResult, report: report}
+	if output.bulkFiles, err = storeAnalysisResult(ctx, graphResult, neo4jConfig, cfg, bulkCSVDir); err != nil {
+		return nil, err
+	}
+
+	logger.Info("✓ analysis completed successfully",
+		"duration", time.Since(startTime).Round(time.Millisecond),
+		"project_id", projectID)
+
+	return output, nil
+}
+
+// storeAnalysisResult stores the graph, or writes it as bulk import files
+// when a directory is given
+func storeAnalysisResult(
+	ctx context.Context,
+	graphResult *core.AnalysisResult,
+	neo4jConfig *infra.Neo4jConfig,
+	cfg *config.Config,
+	bulkCSVDir string,
+) (*infra.BulkCSVFiles, error) {
+	if bulkCSVDir != "" {
+		logger.Info("writing bulk import files", "dir", bulkCSVDir)
+		files, err := infra.WriteBulkCSV(bulkCSVDir, graphResult)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write bulk import files: %w", err)
+		}
+		return files, nil
+	}
+
 	logger.Info("connecting to Neo4j", "uri", neo4jConfig.URI)
-	repo, err := infra.NewNeo4jRepository(neo4jConfig)
+	repo, err := newRepository(cfg, neo4jConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create Neo4j repository: %w", err)
+		return nil, fmt.Errorf("failed to create graph repository: %w", err)
 	}
 	defer repo.Close()
 
 	logger.Info("storing analysis results")
-	if err := repo.StoreAnalysis(ctx, graphResult); err != nil {
-		return fmt.Errorf("failed to store analysis: %w", err)
+	if err := storeAnalysis(ctx, repo, graphResult, cfg.Snapshots.Keep); err != nil {
+		return nil, fmt.Errorf("failed to store analysis: %w", err)
 	}
-
-	duration := time.Since(startTime)
-	logger.Info("✓ analysis completed successfully",
-		"duration", duration.Round(time.Millisecond),
-		"project_id", projectID)
-
-	return nil
+	return nil, nil
 }
 
 func runAnalysisWithProgress(
-	projectPath string,
+	projectPath, projectRoot string, // Directory parsed and root its files are stored under
 	projectID core.ID,
 	parserConfig *parser.Config,
 	analyzerConfig *analyzer.Config,
 	neo4jConfig *infra.Neo4jConfig,
-) error {
+	cfg *config.Config,
+	snapshot *core.Snapshot,
+	bulkCSVDir string,
+) (*analysisOutput, error) {
 	ctx := context.Background()
 
 	// Initialize adaptive progress
@@ -240,25 +361,32 @@ func runAnalysisWithProgress(
 	// Parse project
 	parseResult, err := runParsingPhase(ctx, projectPath, parserConfig, progressIndicator)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Analyze project
 	report, err := runAnalysisPhase(ctx, projectID, parseResult, analyzerConfig, progressIndicator)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Build graph
 	graphResult, err := runGraphBuildingPhase(ctx, projectID, parseResult, report, progressIndicator)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Store results
-	err = runStoragePhase(ctx, graphResult, neo4jConfig, progressIndicator)
+	graph.RelocateFiles(graphResult, projectPath, projectRoot)
+	graphResult.Snapshot = snapshot
+	output := &analysisOutput{parseResult: parseResult, report: report}
+	if bulkCSVDir != "" {
+		output.bulkFiles, err = runBulkCSVPhase(graphResult, bulkCSVDir, progressIndicator)
+	} else {
+		err = runStoragePhase(ctx, graphResult, neo4jConfig, cfg, progressIndicator)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Success with detailed statistics
@@ -282,7 +410,71 @@ func runAnalysisWithProgress(
 
 	progressIndicator.SuccessWithStats(successMsg, stats)
 
-	return nil
+	return output, nil
+}
+
+// analysisOutput carries the in-memory results of an analysis run
+type analysisOutput struct {
+	parseResult *parser.ParseResult
+	report      *analyzer.AnalysisReport
+	bulkFiles   *infra.BulkCSVFiles // Set when the graph was written for neo4j-admin instead of stored
+}
+
+// writeAnalysisSARIF reports the findings of an analysis run as a SARIF log.
+// Unused functions are read back from the stored graph when enabled. Files
+// of the parsed directory are reported under the project root.
+func writeAnalysisSARIF(
+	cmd *cobra.Command,
+	projectPath string,
+	projectRoot string,
+	projectID core.ID,
+	cfg *config.Config,
+	neo4jConfig *infra.Neo4jConfig,
+	output *analysisOutput,
+	outputPath string,
+) error {
+	ctx := context.Background()
+	report := output.report
+
+	findings, err := collectFindings(ctx, cfg, output.parseResult.Packages, report.DependencyGraph,
+		report.CircularDependencies)
+	if err != nil {
+		return err
+	}
+	for _, finding := range findings {
+		finding.File = graph.RelocatePath(finding.File, projectPath, projectRoot)
+	}
+
+	if cfg.Architecture.UnusedFunctions {
+		repo, err := newRepository(cfg, neo4jConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create graph repository: %w", err)
+		}
+		defer repo.Close()
+
+		unused, params, err := query.NewHighLevelBuilder().FindUnusedFunctions(projectID).Build()
+		if err != nil {
+			return fmt.Errorf("failed to build unused functions query: %w", err)
+		}
+		rows, err := repo.ExecuteQuery(ctx, unused, params)
+		if err != nil {
+			return fmt.Errorf("failed to query unused functions: %w", err)
+		}
+		rule := query.FindingRules[query.RuleUnusedFunction]
+		findings = append(findings, query.FindingsFromRows(rule.ID, rule.Severity, "function is never called", rows)...)
+	}
+
+	out := cmd.OutOrStdout()
+	if outputPath != "" {
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	return writeSARIF(out, projectRoot, &checkReport{findings: findings})
 }
 
 var initAnalyzeOnce sync.Once
@@ -370,20 +562,21 @@ func runStoragePhase(
 	ctx context.Context,
 	graphResult *core.AnalysisResult,
 	neo4jConfig *infra.Neo4jConfig,
+	cfg *config.Config,
 	progressIndicator *progress.AdaptiveProgress,
 ) error {
 	progressIndicator.UpdatePhase("Storage")
 	progressIndicator.UpdateProgress(0.8, "Connecting to Neo4j database")
 
-	repo, err := infra.NewNeo4jRepository(neo4jConfig)
+	repo, err := newRepository(cfg, neo4jConfig)
 	if err != nil {
-		progressIndicator.Error(fmt.Errorf("failed to create Neo4j repository: %w", err))
-		return fmt.Errorf("failed to create Neo4j repository: %w", err)
+		progressIndicator.Error(fmt.Errorf("failed to create graph repository: %w", err))
+		return fmt.Errorf("failed to create graph repository: %w", err)
 	}
 	defer repo.Close()
 
 	progressIndicator.UpdateProgress(0.9, "Storing nodes and relationships")
-	err = repo.StoreAnalysis(ctx, graphResult)
+	err = storeAnalysis(ctx, repo, graphResult, cfg.Snapshots.Keep)
 	if err != nil {
 		progressIndicator.Error(fmt.Errorf("failed to store analysis: %w", err))
 		return fmt.Errorf("failed to store analysis: %w", err)
@@ -391,6 +584,34 @@ func runStoragePhase(
 	return nil
 }
 
+func runBulkCSVPhase(
+	graphResult *core.AnalysisResult,
+	dir string,
+	progressIndicator *progress.AdaptiveProgress,
+) (*infra.BulkCSVFiles, error) {
+	progressIndicator.UpdatePhase("Storage")
+	progressIndicator.UpdateProgress(0.8, "Writing bulk import files")
+
+	files, err := infra.WriteBulkCSV(dir, graphResult)
+	if err != nil {
+		progressIndicator.Error(fmt.Errorf("failed to write bulk import files: %w", err))
+		return nil, fmt.Errorf("failed to write bulk import files: %w", err)
+	}
+	return files, nil
+}
+
+// printBulkImportCommand tells how to load the files written by
+// --emit-bulk-csv
+func printBulkImportCommand(cmd *cobra.Command, files *infra.BulkCSVFiles, database string) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Wrote %d node and %d relationship files to %s\n",
+		len(files.Nodes), len(files.Relationships), files.Dir)
+	if files.Skipped > 0 {
+		fmt.Fprintf(out, "Skipped %d relationships whose nodes are not part of the analysis\n", files.Skipped)
+	}
+	fmt.Fprintf(out, "\nStop Neo4j, then load them into a new database with:\n\n  %s\n", files.ImportCommand(database))
+}
+
 // InitAnalyzeCommand registers the analyze command
 func InitAnalyzeCommand() {
 	initAnalyzeOnce.Do(func() {
@@ -399,5 +620,10 @@ func InitAnalyzeCommand() {
 		// Add flags
 		analyzeCmd.Flags().Bool("no-progress", false, "Disable progress indicators")
 		analyzeCmd.Flags().String("project-id", "", "Override project ID from config file")
+		analyzeCmd.Flags().String("format", findingsFormatText, "Findings output format (text, sarif)")
+		analyzeCmd.Flags().StringP("output", "o", "", "Write SARIF findings to a file instead of stdout")
+		analyzeCmd.Flags().String("ref", "", "Analyze a git commit, tag or branch from a temporary worktree")
+		analyzeCmd.Flags().String("emit-bulk-csv", "",
+			"Write CSV files for neo4j-admin database import to this directory instead of storing the graph")
 	})
 }
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/pkg/config"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var apicheckCmd = &cobra.Command{
+	Use:   "apicheck",
+	Short: "Check the exported API for breaking changes and suggest a version",
+	Long: `Compare the exported API of the project against a released revision and
+classify every change to the exported surface of each package:
+
+  Breaking:   removed packages, functions, methods, types and struct fields,
+              changed signatures and field types, and methods added to an
+              exported interface (unless the interface is sealed by an
+              unexported method)
+  Compatible: added packages, functions, methods, types, struct fields and
+              methods of sealed interfaces
+
+The next semantic version is suggested from the result: major for breaking
+changes, minor for additions and patch otherwise. Before v1.0.0 breaking
+changes bump the minor version. When --base is a version tag, optionally
+prefixed by a module directory (sdk/client/v1.4.0), the next version is
+printed as well.
+
+Packages named main and packages below an internal directory are not part of
+the public API. Test files are never analyzed. Both revisions are analyzed in
+memory, so no Neo4j connection is required.`,
+	Example: `  # Check the working tree against the last release
+  gograph apicheck --base v1.4.0
+
+  # Check a release candidate and fail CI on breaking changes
+  gograph apicheck --base v1.4.0 --head release/1.5 --fail-on-breaking
+
+  # Check one module of a multi-module repository
+  gograph apicheck --base sdk/client/v1.4.0 --path sdk/client --format markdown`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return errors.WithRecover("apicheck_command", func() error {
+			projectPath, err := cmd.Flags().GetString("path")
+			if err != nil {
+				return fmt.Errorf("failed to get path flag: %w", err)
+			}
+			baseRef, err := cmd.Flags().GetString("base")
+			if err != nil {
+				return fmt.Errorf("failed to get base flag: %w", err)
+			}
+			headRef, err := cmd.Flags().GetString("head")
+			if err != nil {
+				return fmt.Errorf("failed to get head flag: %w", err)
+			}
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return fmt.Errorf("failed to get format flag: %w", err)
+			}
+			if format != findingsFormatText && format != formatJSON && format != formatMarkdown {
+				return fmt.Errorf("unsupported format %q (use text, json or markdown)", format)
+			}
+			failOnBreaking, err := cmd.Flags().GetBool("fail-on-breaking")
+			if err != nil {
+				return fmt.Errorf("failed to get fail-on-breaking flag: %w", err)
+			}
+
+			cfg, err := config.LoadProjectConfig(projectPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// The exported API never includes test files
+			apiConfig := *cfg
+			apiConfig.Analysis.IncludeTests = false
+
+			report, err := checkAPIRevisions(context.Background(), projectPath, &apiConfig, baseRef, headRef)
+			if err != nil {
+				return err
+			}
+			if err := writeAPIReport(cmd.OutOrStdout(), format, report); err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
+
+			if breaking := report.Breaking(); failOnBreaking && breaking > 0 {
+				cmd.SilenceUsage = true
+				cmd.SilenceErrors = true
+				return fmt.Errorf("found %d breaking API change(s)", breaking)
+			}
+			return nil
+		})
+	},
+}
+
+var initAPICheckOnce sync.Once
+
+// InitAPICheckCommand registers the apicheck command
+func InitAPICheckCommand() {
+	initAPICheckOnce.Do(func() {
+		rootCmd.AddCommand(apicheckCmd)
+
+		apicheckCmd.Flags().String("base", "", "Released git revision to compare against, e.g. v1.4.0 (required)")
+		apicheckCmd.Flags().String("head", "", "Git revision to check (defaults to the working tree)")
+		apicheckCmd.Flags().String("path", ".", "Path of the project inside the repository")
+		apicheckCmd.Flags().String("format", findingsFormatText, "Output format: text, json or markdown")
+		apicheckCmd.Flags().Bool("fail-on-breaking", false, "Exit with a non-zero status when breaking changes are found")
+		_ = apicheckCmd.MarkFlagRequired("base")
+	})
+}
+
+// checkAPIRevisions analyzes the base revision and the head revision or
+// working tree and classifies the changes to their exported API
+func checkAPIRevisions(
+	ctx context.Context,
+	projectPath string,
+	cfg *config.Config,
+	baseRef, headRef string,
+) (*graph.APIReport, error) {
+	projectID := core.ID(cfg.Project.ID)
+	base, _, err := buildRevisionGraph(ctx, projectPath, projectID, cfg, baseRef)
+	if err != nil {
+		return nil, err
+	}
+
+	headLabel := workingTreeLabel
+	var head *core.AnalysisResult
+	if headRef != "" {
+		headLabel = headRef
+		head, _, err = buildRevisionGraph(ctx, projectPath, projectID, cfg, headRef)
+	} else {
+		var root string
+		root, err = filepath.Abs(projectPath)
+		if err == nil {
+			head, err = buildProjectGraph(ctx, root, projectID, cfg)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := graph.CheckAPI(base, head)
+	report.Base = baseRef
+	report.Head = headLabel
+	report.NextVersion, _ = graph.NextVersion(baseRef, report.Bump)
+	return report, nil
+}
+
+func writeAPIReport(w io.Writer, format string, report *graph.APIReport) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case formatMarkdown:
+		return report.WriteMarkdown(w)
+	default:
+		return report.WriteText(w)
+	}
+}
@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/pkg/config"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var saveCmd = &cobra.Command{
+	Use:   "save <file>",
+	Short: "Save the project graph to a portable file",
+	Long: `Write the stored graph of the project, with its totals and the commit it
+was analyzed at, to a compressed file that 'gograph load' reads into any
+storage backend. A CI job can analyze once and publish the file, so that
+developers and MCP servers load the graph without parsing the code again.
+
+The file is gzip-compressed JSON with a schema version; files of older
+versions are migrated when they are loaded. Use "-" to write to stdout.`,
+	Example: `  # Save the latest analysis
+  gograph save graph.gograph.gz
+
+  # Save an older snapshot of another project
+  gograph save old.gograph.gz --project my-backend-api --snapshot 3f2a9c1`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WithRecover("save_command", func() error {
+			snapshotID, err := cmd.Flags().GetString("snapshot")
+			if err != nil {
+				return fmt.Errorf("failed to get snapshot flag: %w", err)
+			}
+
+			repo, projectID, err := openSnapshotRepository(cmd)
+			if err != nil {
+				return err
+			}
+			defer repo.Close()
+
+			ctx := context.Background()
+			archive, err := loadArchive(ctx, repo, projectID, snapshotID)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if args[0] != "-" {
+				file, err := os.Create(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to create graph file: %w", err)
+				}
+				defer file.Close()
+				out = file
+			}
+			if err := graph.WriteArchive(out, archive); err != nil {
+				return err
+			}
+			if args[0] != "-" {
+				fmt.Fprintf(cmd.OutOrStdout(), "✓ saved %d nodes and %d relationships of project %s to %s\n",
+					len(archive.Result.Nodes), len(archive.Result.Relationships), archive.Result.ProjectID, args[0])
+			}
+			return nil
+		})
+	},
+}
+
+var loadCmd = &cobra.Command{
+	Use:   "load <file>",
+	Short: "Load a graph file written by gograph save",
+	Long: `Store the graph of a file written by 'gograph save' in the configured
+storage backend, as if the project had been analyzed. The graph replaces the
+latest analysis of the project; with snapshots enabled, the previous analysis
+is archived and the loaded graph keeps the commit and branch it was analyzed
+at.
+
+The graph is stored under the project it was saved from, or under --project.
+Use "-" to read from stdin.`,
+	Example: `  # Load a graph published by CI
+  gograph load graph.gograph.gz
+
+  # Load it under a different project ID
+  curl -sL https://ci.example.com/graph.gograph.gz | gograph load - --project backend-ci`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WithRecover("load_command", func() error {
+			var in io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open graph file: %w", err)
+				}
+				defer file.Close()
+				in = file
+			}
+			archive, err := graph.ReadArchive(in)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.LoadProjectConfig(".")
+			if err != nil {
+				return fmt.Errorf("failed to load project config: %w", err)
+			}
+			projectID := archive.Result.ProjectID
+			if project, err := cmd.Flags().GetString("project"); err == nil && project != "" {
+				projectID = core.ID(project)
+			}
+			if projectID == "" {
+				return fmt.Errorf("the graph file has no project ID; use --project")
+			}
+			result := archive.ForProject(projectID)
+			if cfg.Snapshots.Enabled {
+				result.Snapshot = loadedSnapshot(archive)
+			}
+
+			repo, err := newRepository(cfg, neo4jConfigFromConfig(cfg))
+			if err != nil {
+				return fmt.Errorf("failed to create graph repository: %w", err)
+			}
+			defer repo.Close()
+
+			if err := storeAnalysis(context.Background(), repo, result, cfg.Snapshots.Keep); err != nil {
+				return fmt.Errorf("failed to store graph: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ loaded %d nodes and %d relationships into project %s\n",
+				len(result.Nodes), len(result.Relationships), projectID)
+			return nil
+		})
+	},
+}
+
+var initArchiveOnce sync.Once
+
+// InitArchiveCommands registers the save and load commands
+func InitArchiveCommands() {
+	initArchiveOnce.Do(func() {
+		rootCmd.AddCommand(saveCmd)
+		rootCmd.AddCommand(loadCmd)
+
+		saveCmd.Flags().StringP("project", "p", "", "Project ID (defaults to current project)")
+		saveCmd.Flags().String("snapshot", "", "Save this snapshot (ID, ID prefix or commit SHA) instead of the latest")
+		loadCmd.Flags().StringP("project", "p", "", "Store the graph under this project ID instead of the saved one")
+	})
+}
+
+// loadArchive reads the stored graph of a snapshot along with the snapshot
+// metadata
+func loadArchive(
+	ctx context.Context,
+	repo graph.Repository,
+	projectID core.ID,
+	snapshotID string,
+) (*graph.Archive, error) {
+	scope, err := resolveSnapshotScope(ctx, repo, projectID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := findSnapshot(ctx, repo, projectID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := graph.LoadAnalysisResult(ctx, repo, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project graph: %w", err)
+	}
+	if len(stored.Nodes) == 0 {
+		return nil, fmt.Errorf("no analysis found for project %s; run 'gograph analyze' first", projectID)
+	}
+	return graph.NewArchive(stored, snapshot), nil
+}
+
+// loadedSnapshot describes a loaded graph as a new snapshot of the revision
+// it was analyzed at
+func loadedSnapshot(archive *graph.Archive) *core.Snapshot {
+	snapshot := &core.Snapshot{ID: core.NewID(), CreatedAt: archive.Result.AnalyzedAt}
+	if saved := archive.Result.Snapshot; saved != nil {
+		snapshot.CommitSHA = saved.CommitSHA
+		snapshot.Branch = saved.Branch
+		snapshot.ConfigHash = saved.ConfigHash
+		snapshot.Root = saved.Root
+		snapshot.CreatedAt = saved.CreatedAt
+	}
+	return snapshot
+}
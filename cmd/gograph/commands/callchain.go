@@ -98,11 +98,16 @@ func runCallChain(_ *cobra.Command, args []string) error {
 		return err
 	}
 
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
 	// Initialize Neo4j repository
 	logger.Debug("connecting to Neo4j", "uri", neo4jConfig.URI)
-	repo, err := infra.NewNeo4jRepository(neo4jConfig)
+	repo, err := newRepository(cfg, neo4jConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create Neo4j repository: %w", err)
+		return fmt.Errorf("failed to create graph repository: %w", err)
 	}
 	defer repo.Close()
 
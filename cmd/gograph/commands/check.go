@@ -0,0 +1,540 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/engine/infra"
+	"github.com/compozy/gograph/engine/parser"
+	"github.com/compozy/gograph/engine/query"
+	"github.com/compozy/gograph/pkg/config"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/compozy/gograph/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// Output formats for findings
+const (
+	findingsFormatText  = "text"
+	findingsFormatSARIF = "sarif"
+)
+
+// defaultBaselineFile is the baseline used by check when present in the project
+const defaultBaselineFile = ".gograph-baseline.json"
+
+var checkCmd = &cobra.Command{
+	Use:   "check [path]",
+	Short: "Check the project against its architecture rules",
+	Long: `Check parses a Go project, builds its package dependency graph and
+evaluates the architecture rules declared in the "architecture" section of
+gograph.yaml. No Neo4j connection is required.
+
+Layers map packages to names using go-style patterns ("..." matches any
+string, "*" a single path element). A pattern may match any trailing part
+of an import path, so "cmd/..." matches every package below a cmd directory.
+
+Each violating import is printed with its file and line, and the command
+exits with a non-zero status when any rule is broken, which makes it
+suitable for CI pipelines. Circular dependencies and functions longer than
+max_function_lines are reported as well.
+
+Example configuration:
+
+  architecture:
+    max_dependency_depth: 10
+    max_function_lines: 80
+    unused_functions: true
+    layers:
+      - name: cmd
+        packages: ["cmd/..."]
+        may_depend_on: ["*"]
+      - name: domain
+        packages: ["engine/core", "engine/domain/..."]
+        must_not_depend_on: [infra]
+      - name: infra
+        packages: ["engine/infra/..."]
+
+Setting max_dependency_depth or max_function_lines to 0 disables that rule.
+
+With --policies, the Cypher policies from the "policies" section are also
+run against the graph stored by "gograph analyze", together with the unused
+function check when unused_functions is enabled. Each policy must return
+zero rows and receives the project ID as $project_id. Rows that return
+"file" and "line" columns are reported with that location:
+
+  policies:
+    - name: no-exported-globals
+      severity: high
+      message: Exported package variables are not allowed
+      query: |
+        MATCH (v:Variable {project_id: $project_id, is_exported: true})
+        RETURN v.package AS package, v.name AS name
+
+Use --format sarif to produce a SARIF 2.1.0 log for code scanning tools.
+
+To adopt checks in an existing codebase, record the current findings with
+--write-baseline and commit the baseline file. Later runs fail only on
+findings that are not in the baseline and report the ones that were fixed.
+Findings are matched by rule and symbol, not by line number, so moving code
+around does not invalidate the baseline.`,
+	Example: `  # Check the current project
+  gograph check
+
+  # Check a specific project
+  gograph check /path/to/project
+
+  # Also evaluate Cypher policies against the analyzed graph
+  gograph check --policies
+
+  # Write findings as SARIF for CI code scanning
+  gograph check --format sarif --output gograph.sarif
+
+  # Accept the current findings, then fail only on new ones
+  gograph check --write-baseline
+  gograph check`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectPath := "."
+		if len(args) > 0 {
+			projectPath = args[0]
+		}
+
+		return errors.WithRecover("check_command", func() error {
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return fmt.Errorf("failed to get format flag: %w", err)
+			}
+			if format != findingsFormatText && format != findingsFormatSARIF {
+				return fmt.Errorf("unsupported format %q (use %s or %s)", format, findingsFormatText, findingsFormatSARIF)
+			}
+			outputPath, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return fmt.Errorf("failed to get output flag: %w", err)
+			}
+			runPolicies, err := cmd.Flags().GetBool("policies")
+			if err != nil {
+				return fmt.Errorf("failed to get policies flag: %w", err)
+			}
+
+			cfg, err := config.LoadProjectConfig(projectPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			report, err := runCheck(ctx, projectPath, cfg)
+			if err != nil {
+				return err
+			}
+
+			if runPolicies {
+				projectID := core.ID(cfg.Project.ID)
+				if projectIDFlag, err := cmd.Flags().GetString("project-id"); err == nil && projectIDFlag != "" {
+					projectID = core.ID(projectIDFlag)
+				}
+				if err := runPolicyCheck(ctx, projectID, cfg, report); err != nil {
+					return err
+				}
+			}
+
+			baselinePath, err := cmd.Flags().GetString("baseline")
+			if err != nil {
+				return fmt.Errorf("failed to get baseline flag: %w", err)
+			}
+			writeBaseline, err := cmd.Flags().GetBool("write-baseline")
+			if err != nil {
+				return fmt.Errorf("failed to get write-baseline flag: %w", err)
+			}
+			if !filepath.IsAbs(baselinePath) {
+				baselinePath = filepath.Join(projectPath, baselinePath)
+			}
+
+			if writeBaseline {
+				baseline := query.NewBaseline(report.allFindings())
+				if err := baseline.Save(baselinePath); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "✓ wrote baseline with %d finding(s) to %s\n",
+					len(baseline.Findings), baselinePath)
+				return nil
+			}
+
+			if err := report.applyBaseline(baselinePath, cmd.Flags().Changed("baseline")); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputPath != "" {
+				file, err := os.Create(outputPath)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer file.Close()
+				out = file
+			}
+
+			if format == findingsFormatSARIF {
+				if err := writeSARIF(out, projectPath, report); err != nil {
+					return err
+				}
+			} else {
+				printCheckReport(out, projectPath, report)
+			}
+
+			if failures := report.failures(); failures > 0 {
+				cmd.SilenceUsage = true
+				cmd.SilenceErrors = true
+				if report.comparison != nil {
+					return fmt.Errorf("found %d new finding(s)", failures)
+				}
+				return fmt.Errorf("found %d finding(s)", failures)
+			}
+			return nil
+		})
+	},
+}
+
+// checkReport collects everything found by a check run
+type checkReport struct {
+	findings   []*query.Finding          // Local findings: rule violations, cycles, long functions
+	policies   []*graph.PolicyResult     // Evaluated policies, if requested
+	rules      []*query.Rule             // Metadata for policy rules
+	comparison *query.BaselineComparison // Comparison against the baseline, if one is in use
+}
+
+// applyBaseline compares all findings against the baseline at path. A missing
+// baseline file is only an error when the path was given explicitly.
+func (r *checkReport) applyBaseline(path string, required bool) error {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil
+		}
+		return fmt.Errorf("failed to open baseline: %w", err)
+	}
+
+	baseline, err := query.LoadBaseline(path)
+	if err != nil {
+		return err
+	}
+	logger.Debug("comparing findings against baseline", "path", path, "entries", len(baseline.Findings))
+
+	r.comparison = baseline.Compare(r.allFindings())
+	for _, finding := range r.comparison.New {
+		finding.BaselineState = query.BaselineStateNew
+	}
+	for _, finding := range r.comparison.Unchanged {
+		finding.BaselineState = query.BaselineStateUnchanged
+	}
+	return nil
+}
+
+// failures returns the number of new findings when a baseline is in use,
+// otherwise the number of local findings plus failing policies
+func (r *checkReport) failures() int {
+	if r.comparison != nil {
+		return len(r.comparison.New)
+	}
+	failed := len(r.findings)
+	for _, result := range r.policies {
+		if !result.Passed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// allFindings returns local findings followed by one finding per offending policy row
+func (r *checkReport) allFindings() []*query.Finding {
+	findings := append([]*query.Finding{}, r.findings...)
+	for _, result := range r.policies {
+		policy := result.Policy
+		findings = append(findings, query.FindingsFromRows(
+			policyRuleID(policy), policy.Severity, policy.Message, result.Rows)...)
+	}
+	return findings
+}
+
+// runCheck parses the project and evaluates the configured architecture rules
+func runCheck(ctx context.Context, projectPath string, cfg *config.Config) (*checkReport, error) {
+	parserConfig := &parser.Config{
+		IgnoreDirs:    cfg.Analysis.IgnoreDirs,
+		IgnoreFiles:   cfg.Analysis.IgnoreFiles,
+		IncludeTests:  cfg.Analysis.IncludeTests,
+		IncludeVendor: cfg.Analysis.IncludeVendor,
+	}
+
+	logger.Debug("parsing project", "path", projectPath)
+	parserService := parser.NewService(parserConfig)
+	parseResult, err := parserService.ParseProject(ctx, projectPath, parserConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse project: %w", err)
+	}
+
+	// Synthesized test main packages live in the build cache and are not
+	// part of the project's architecture
+	packages := make([]*parser.PackageInfo, 0, len(parseResult.Packages))
+	for _, pkg := range parseResult.Packages {
+		if !strings.HasSuffix(pkg.Path, ".test") {
+			packages = append(packages, pkg)
+		}
+	}
+
+	analyzerService := analyzer.NewAnalyzer(analyzer.DefaultAnalyzerConfig())
+	depGraph, err := analyzerService.BuildDependencyGraph(ctx, packages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
+	}
+
+	cycles, err := analyzerService.DetectCircularDependencies(ctx, depGraph)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect circular dependencies: %w", err)
+	}
+
+	findings, err := collectFindings(ctx, cfg, packages, depGraph, cycles)
+	if err != nil {
+		return nil, err
+	}
+	return &checkReport{findings: findings}, nil
+}
+
+// collectFindings evaluates the configured architecture rules and converts
+// rule violations, circular dependencies and long functions into findings
+func collectFindings(
+	ctx context.Context,
+	cfg *config.Config,
+	packages []*parser.PackageInfo,
+	depGraph *analyzer.DependencyGraph,
+	cycles []*analyzer.CircularDependency,
+) ([]*query.Finding, error) {
+	analyzerConfig := analyzer.DefaultAnalyzerConfig()
+	analyzerConfig.MaxDependencyDepth = cfg.Architecture.MaxDependencyDepth
+	analyzerService := analyzer.NewAnalyzer(analyzerConfig)
+
+	violations, err := analyzerService.CheckRules(ctx, depGraph, ruleSetFromConfig(&cfg.Architecture))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check architecture rules: %w", err)
+	}
+
+	findings := query.FindingsFromViolations(violations)
+	findings = append(findings, query.FindingsFromCycles(cycles, depGraph)...)
+	findings = append(findings, query.FindingsFromFunctionLength(packages, cfg.Architecture.MaxFunctionLines)...)
+	return findings, nil
+}
+
+// ruleSetFromConfig converts the architecture configuration into analyzer rules
+func ruleSetFromConfig(cfg *config.ArchitectureConfig) *analyzer.RuleSet {
+	rules := &analyzer.RuleSet{}
+	for _, layer := range cfg.Layers {
+		rules.Layers = append(rules.Layers, &analyzer.LayerRule{
+			Name:            layer.Name,
+			Packages:        layer.Packages,
+			MayDependOn:     layer.MayDependOn,
+			MustNotDependOn: layer.MustNotDependOn,
+		})
+	}
+	return rules
+}
+
+// runPolicyCheck evaluates the configured Cypher policies against the stored project graph
+func runPolicyCheck(ctx context.Context, projectID core.ID, cfg *config.Config, report *checkReport) error {
+	policies := make([]*graph.Policy, 0, len(cfg.Policies)+1)
+	for _, policy := range cfg.Policies {
+		policies = append(policies, &graph.Policy{
+			Name:     policy.Name,
+			Query:    policy.Query,
+			Severity: analyzer.SeverityLevel(strings.ToLower(policy.Severity)),
+			Message:  policy.Message,
+		})
+	}
+	if cfg.Architecture.UnusedFunctions {
+		unused, params, err := query.NewHighLevelBuilder().FindUnusedFunctions(projectID).Build()
+		if err != nil {
+			return fmt.Errorf("failed to build unused functions query: %w", err)
+		}
+		policies = append(policies, &graph.Policy{
+			Name:     query.RuleUnusedFunction,
+			Query:    unused,
+			Severity: query.FindingRules[query.RuleUnusedFunction].Severity,
+			Message:  "function is never called",
+			Params:   params,
+		})
+	}
+	if len(policies) == 0 {
+		logger.Warn("no policies configured")
+		return nil
+	}
+
+	repo, err := newRepository(cfg, neo4jConfigFromConfig(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to create graph repository: %w", err)
+	}
+	defer repo.Close()
+
+	graphService := graph.NewService(nil, nil, nil, repo, graph.DefaultServiceConfig())
+	results, err := graph.EvaluatePolicies(ctx, graphService, projectID, policies)
+	if err != nil {
+		return fmt.Errorf("failed to check policies: %w", err)
+	}
+
+	report.policies = results
+	for _, policy := range policies {
+		if policy.Name == query.RuleUnusedFunction {
+			continue
+		}
+		report.rules = append(report.rules, &query.Rule{
+			ID:          policyRuleID(policy),
+			Name:        policy.Name,
+			Description: policy.Message,
+			Severity:    policy.Severity,
+		})
+	}
+	return nil
+}
+
+// policyRuleID returns the finding rule identifier for a policy
+func policyRuleID(policy *graph.Policy) string {
+	if policy.Name == query.RuleUnusedFunction {
+		return query.RuleUnusedFunction
+	}
+	return query.RulePolicyPrefix + policy.Name
+}
+
+// neo4jConfigFromConfig builds the Neo4j connection settings from project configuration
+func neo4jConfigFromConfig(cfg *config.Config) *infra.Neo4jConfig {
+	neo4jConfig := &infra.Neo4jConfig{
+		URI:        cfg.Neo4j.URI,
+		Username:   cfg.Neo4j.Username,
+		Password:   cfg.Neo4j.Password,
+		Database:   cfg.Neo4j.Database,
+		MaxRetries: 3,
+		BatchSize:  1000,
+	}
+	if neo4jConfig.URI == "" {
+		neo4jConfig.URI = DefaultNeo4jURI
+	}
+	if neo4jConfig.Username == "" {
+		neo4jConfig.Username = DefaultNeo4jUsername
+	}
+	if neo4jConfig.Password == "" {
+		neo4jConfig.Password = DefaultNeo4jPassword
+	}
+	return neo4jConfig
+}
+
+// writeSARIF writes all findings of a check run as a SARIF log
+func writeSARIF(w io.Writer, projectPath string, report *checkReport) error {
+	root, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project path: %w", err)
+	}
+	writer := query.NewSARIFWriter(&query.SARIFOptions{
+		ToolVersion: Version,
+		SourceRoot:  root,
+	})
+	return writer.Write(w, report.allFindings(), report.rules...)
+}
+
+// printCheckReport writes findings as file:line lines followed by policy results
+func printCheckReport(w io.Writer, projectPath string, report *checkReport) {
+	if report.comparison != nil {
+		printBaselineComparison(w, projectPath, report.comparison)
+		return
+	}
+	printFindings(w, projectPath, report.findings)
+	printPolicyResults(w, report.policies)
+	if report.failures() == 0 {
+		fmt.Fprintln(w, "✓ no architecture violations found")
+	}
+}
+
+// printBaselineComparison writes new findings and summarizes baselined and fixed ones
+func printBaselineComparison(w io.Writer, projectPath string, comparison *query.BaselineComparison) {
+	printFindings(w, projectPath, comparison.New)
+	for _, entry := range comparison.Fixed {
+		fmt.Fprintf(w, "fixed: [%s] %s\n", entry.RuleID, entry.Message)
+	}
+
+	fmt.Fprintf(w, "%d new, %d baselined, %d fixed finding(s)\n",
+		len(comparison.New), len(comparison.Unchanged), len(comparison.Fixed))
+	if len(comparison.Fixed) > 0 {
+		fmt.Fprintln(w, "run 'gograph check --write-baseline' to drop fixed findings from the baseline")
+	}
+	if len(comparison.New) == 0 {
+		fmt.Fprintln(w, "✓ no new findings")
+	}
+}
+
+// printFindings writes one line per finding using file:line locations
+func printFindings(w io.Writer, projectPath string, findings []*query.Finding) {
+	root, err := filepath.Abs(projectPath)
+	if err != nil {
+		root = projectPath
+	}
+	for _, finding := range findings {
+		location := "-"
+		if finding.File != "" {
+			file := finding.File
+			if rel, err := filepath.Rel(root, file); err == nil && filepath.IsAbs(file) {
+				file = rel
+			}
+			location = fmt.Sprintf("%s:%d", file, finding.Line)
+		}
+		fmt.Fprintf(w, "%s: [%s] %s\n", location, finding.RuleID, finding.Message)
+	}
+}
+
+// printPolicyResults writes pass/fail lines with offending rows
+func printPolicyResults(w io.Writer, results []*graph.PolicyResult) {
+	for _, result := range results {
+		policy := result.Policy
+		if result.Passed() {
+			fmt.Fprintf(w, "✓ policy %s\n", policy.Name)
+			continue
+		}
+
+		fmt.Fprintf(w, "✗ policy %s [%s]: %s (%d row(s))\n", policy.Name, policy.Severity, policy.Message, len(result.Rows))
+		for _, row := range result.Rows {
+			keys := make([]string, 0, len(row))
+			for key := range row {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			fields := make([]string, 0, len(keys))
+			for _, key := range keys {
+				fields = append(fields, fmt.Sprintf("%s=%s", key, formatValue(row[key])))
+			}
+			fmt.Fprintf(w, "    %s\n", strings.Join(fields, " "))
+		}
+	}
+}
+
+var initCheckOnce sync.Once
+
+// InitCheckCommand registers the check command
+func InitCheckCommand() {
+	initCheckOnce.Do(func() {
+		rootCmd.AddCommand(checkCmd)
+
+		checkCmd.Flags().Bool("policies", false, "Also evaluate Cypher policies against the analyzed graph in Neo4j")
+		checkCmd.Flags().String("project-id", "", "Override project ID used to scope policies")
+		checkCmd.Flags().String("format", findingsFormatText, "Output format (text, sarif)")
+		checkCmd.Flags().StringP("output", "o", "", "Write findings to a file instead of stdout")
+		checkCmd.Flags().String("baseline", defaultBaselineFile,
+			"Baseline file of accepted findings, relative to the project path")
+		checkCmd.Flags().Bool("write-baseline", false, "Record current findings in the baseline file and exit")
+	})
+}
@@ -84,10 +84,15 @@ if the data is important.`,
 			BatchSize:  1000,
 		}
 
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
 		// Initialize repository
-		repo, err := infra.NewNeo4jRepository(neo4jConfig)
+		repo, err := newRepository(cfg, neo4jConfig)
 		if err != nil {
-			return fmt.Errorf("failed to create Neo4j repository: %w", err)
+			return fmt.Errorf("failed to create graph repository: %w", err)
 		}
 		defer repo.Close()
 
@@ -0,0 +1,317 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/engine/parser"
+	"github.com/compozy/gograph/pkg/config"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/compozy/gograph/pkg/git"
+	"github.com/compozy/gograph/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+const formatMarkdown = "markdown"
+
+// workingTreeLabel names the uncommitted state of the project in diff output
+const workingTreeLabel = "working tree"
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <base> [head]",
+	Short: "Show the structural difference between two versions of the project",
+	Long: `Compare the code graph of two versions of the project and report the
+architectural effect of the change:
+  • Added, removed and moved packages, functions, methods and types
+  • Changed function signatures, struct fields and interface methods
+  • New and removed CALLS edges between functions
+  • New and removed package imports
+  • Import cycles introduced or resolved by the change
+
+By default <base> and [head] are git revisions. Each revision is checked out
+into a temporary worktree and analyzed in memory, so no Neo4j connection is
+required. When [head] is omitted, the working tree is used.
+
+With --snapshots, both arguments name stored analysis snapshots instead (see
+'gograph snapshots list'); "latest" refers to the most recent one.`,
+	Example: `  # Architectural effect of the current branch
+  gograph diff main
+
+  # Compare two revisions and paste the result into a PR description
+  gograph diff v1.2.0 HEAD --format markdown
+
+  # Compare two stored snapshots as JSON
+  gograph diff 3f2a9c1 latest --snapshots --format json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WithRecover("diff_command", func() error {
+			projectPath, err := cmd.Flags().GetString("path")
+			if err != nil {
+				return fmt.Errorf("failed to get path flag: %w", err)
+			}
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return fmt.Errorf("failed to get format flag: %w", err)
+			}
+			if format != findingsFormatText && format != formatJSON && format != formatMarkdown {
+				return fmt.Errorf("unsupported format %q (use text, json or markdown)", format)
+			}
+			outputPath, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return fmt.Errorf("failed to get output flag: %w", err)
+			}
+			useSnapshots, err := cmd.Flags().GetBool("snapshots")
+			if err != nil {
+				return fmt.Errorf("failed to get snapshots flag: %w", err)
+			}
+
+			cfg, err := config.LoadProjectConfig(projectPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			ctx := context.Background()
+			var diff *graph.GraphDiff
+			if useSnapshots {
+				diff, err = diffSnapshots(ctx, cmd, projectPath, cfg, args)
+			} else {
+				diff, err = diffRevisions(ctx, projectPath, cfg, args)
+			}
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputPath != "" {
+				file, err := os.Create(outputPath)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer file.Close()
+				out = file
+			}
+			return writeDiff(out, format, diff)
+		})
+	},
+}
+
+var initDiffOnce sync.Once
+
+// InitDiffCommand registers the diff command
+func InitDiffCommand() {
+	initDiffOnce.Do(func() {
+		rootCmd.AddCommand(diffCmd)
+
+		diffCmd.Flags().String("path", ".", "Path of the project inside the repository")
+		diffCmd.Flags().String("format", findingsFormatText, "Output format: text, json or markdown")
+		diffCmd.Flags().StringP("output", "o", "", "Write the diff to a file instead of stdout")
+		diffCmd.Flags().Bool("snapshots", false, "Compare stored snapshots instead of git revisions")
+		diffCmd.Flags().StringP("project", "p", "", "Project ID of the snapshots (defaults to current project)")
+	})
+}
+
+// diffRevisions analyzes two git revisions of the project and compares them
+func diffRevisions(ctx context.Context, projectPath string, cfg *config.Config, args []string) (*graph.GraphDiff, error) {
+	projectID := core.ID(cfg.Project.ID)
+	base, baseRoot, err := buildRevisionGraph(ctx, projectPath, projectID, cfg, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	headLabel := workingTreeLabel
+	var head *core.AnalysisResult
+	var headRoot string
+	if len(args) > 1 {
+		headLabel = args[1]
+		head, headRoot, err = buildRevisionGraph(ctx, projectPath, projectID, cfg, args[1])
+	} else {
+		headRoot, err = filepath.Abs(projectPath)
+		if err == nil {
+			head, err = buildProjectGraph(ctx, headRoot, projectID, cfg)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	diff := graph.Diff(base, head, &graph.DiffOptions{BaseRoot: baseRoot, HeadRoot: headRoot})
+	diff.Base = args[0]
+	diff.Head = headLabel
+	return diff, nil
+}
+
+// buildRevisionGraph checks out a git revision into a temporary worktree and
+// builds its graph. It returns the graph and the project root it was built from.
+func buildRevisionGraph(
+	ctx context.Context,
+	projectPath string,
+	projectID core.ID,
+	cfg *config.Config,
+	ref string,
+) (*core.AnalysisResult, string, error) {
+	logger.Info("checking out revision", "ref", ref)
+	worktree, err := git.NewWorktree(ctx, projectPath, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if err := worktree.Remove(ctx); err != nil {
+			logger.Warn("failed to remove worktree", "path", worktree.Path, "error", err)
+		}
+	}()
+
+	dir, err := worktree.ProjectDir(projectPath)
+	if err != nil {
+		return nil, "", err
+	}
+	result, err := buildProjectGraph(ctx, dir, projectID, cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to analyze %s: %w", ref, err)
+	}
+	return result, dir, nil
+}
+
+// buildProjectGraph parses and analyzes a project directory into an
+// in-memory graph without storing it
+func buildProjectGraph(
+	ctx context.Context,
+	dir string,
+	projectID core.ID,
+	cfg *config.Config,
+) (*core.AnalysisResult, error) {
+	return buildPackagesGraph(ctx, dir, projectID, cfg, nil)
+}
+
+// buildPackagesGraph is buildProjectGraph restricted to the packages matching
+// patterns, or every package when patterns is empty
+func buildPackagesGraph(
+	ctx context.Context,
+	dir string,
+	projectID core.ID,
+	cfg *config.Config,
+	patterns []string,
+) (*core.AnalysisResult, error) {
+	parserConfig := parserConfigFromConfig(cfg)
+	parserConfig.Patterns = patterns
+	parseResult, err := parser.NewService(parserConfig).ParseProject(ctx, dir, parserConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse project: %w", err)
+	}
+
+	// Synthesized test main packages live in the build cache, whose paths
+	// differ between checkouts
+	packages := make([]*parser.PackageInfo, 0, len(parseResult.Packages))
+	for _, pkg := range parseResult.Packages {
+		if !strings.HasSuffix(pkg.Path, ".test") {
+			packages = append(packages, pkg)
+		}
+	}
+	parseResult.Packages = packages
+
+	report, err := analyzer.NewAnalyzer(analyzer.DefaultAnalyzerConfig()).AnalyzeProject(ctx, &analyzer.AnalysisInput{
+		ProjectID:   projectID.String(),
+		ParseResult: parseResult,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze project: %w", err)
+	}
+
+	result, err := graph.NewBuilder(nil).BuildFromAnalysis(ctx, projectID, parseResult, report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build graph: %w", err)
+	}
+	return result, nil
+}
+
+// diffSnapshots loads two stored snapshots from Neo4j and compares them
+func diffSnapshots(
+	ctx context.Context,
+	cmd *cobra.Command,
+	projectPath string,
+	cfg *config.Config,
+	args []string,
+) (*graph.GraphDiff, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("--snapshots requires two snapshot IDs")
+	}
+	projectID := core.ID(cfg.Project.ID)
+	if project, err := cmd.Flags().GetString("project"); err == nil && project != "" {
+		projectID = core.ID(project)
+	}
+
+	repo, err := newRepository(cfg, neo4jConfigFromConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create graph repository: %w", err)
+	}
+	defer repo.Close()
+
+	root, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project path: %w", err)
+	}
+
+	// File paths are compared relative to the directory each snapshot was
+	// analyzed from; snapshots that do not record it use the project root
+	results := make([]*core.AnalysisResult, 0, len(args))
+	roots := make([]string, 0, len(args))
+	for _, snapshotID := range args {
+		scope, err := resolveSnapshotScope(ctx, repo, projectID, snapshotID)
+		if err != nil {
+			return nil, err
+		}
+		result, err := graph.LoadAnalysisResult(ctx, repo, scope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load snapshot %s: %w", snapshotID, err)
+		}
+		snapshot, err := findSnapshot(ctx, repo, projectID, scope)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+		if snapshot != nil && snapshot.Root != "" {
+			roots = append(roots, snapshot.Root)
+		} else {
+			roots = append(roots, root)
+		}
+	}
+
+	diff := graph.Diff(results[0], results[1], &graph.DiffOptions{BaseRoot: roots[0], HeadRoot: roots[1]})
+	diff.Base = args[0]
+	diff.Head = args[1]
+	return diff, nil
+}
+
+func writeDiff(w io.Writer, format string, diff *graph.GraphDiff) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	case formatMarkdown:
+		return diff.WriteMarkdown(w)
+	default:
+		return diff.WriteText(w)
+	}
+}
+
+// parserConfigFromConfig builds the parser configuration used for analysis
+func parserConfigFromConfig(cfg *config.Config) *parser.Config {
+	return &parser.Config{
+		IgnoreDirs:      cfg.Analysis.IgnoreDirs,
+		IgnoreFiles:     cfg.Analysis.IgnoreFiles,
+		IncludeTests:    cfg.Analysis.IncludeTests,
+		IncludeVendor:   cfg.Analysis.IncludeVendor,
+		EnableSSA:       true,
+		EnableCallGraph: true,
+	}
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/compozy/gograph/pkg/explore"
+	"github.com/compozy/gograph/pkg/logger"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var exploreCmd = &cobra.Command{
+	Use:   "explore [symbol]",
+	Short: "Browse callers, callees and dependencies in the terminal",
+	Long: `Open an interactive browser of the analyzed project. Fuzzy-search a
+function, method, type or package, then step through its relations with the
+keyboard while the source of the selected symbol is shown next to them:
+
+  functions and methods  callers, callees and the receiver type
+  structs                methods and the interfaces they implement
+  interfaces             implementations
+  packages               imports, importers and symbols
+
+Keys: type to search, ↑/↓ to select, enter to open, ← or backspace to go
+back, tab to jump to the next section, / to search again and q to quit.
+
+A symbol argument starts with that search, and opens the symbol when it is
+the only or an exact match.`,
+	Example: `  # Search from scratch
+  gograph explore
+
+  # Open a method directly
+  gograph explore Service.GetNodeWithRelationships`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WithRecover("explore_command", func() error {
+			if !isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()) {
+				return fmt.Errorf("explore needs an interactive terminal")
+			}
+
+			repo, projectID, err := openSnapshotRepository(cmd)
+			if err != nil {
+				return err
+			}
+			defer repo.Close()
+
+			ctx := context.Background()
+			graphService := graph.NewService(nil, nil, nil, repo, graph.DefaultServiceConfig())
+			navigator, err := explore.NewNavigator(ctx, graphService, repo, projectID)
+			if err != nil {
+				return err
+			}
+			if len(navigator.Symbols()) == 0 {
+				return fmt.Errorf("no analysis found for project %s; run 'gograph analyze' first", projectID)
+			}
+
+			// Log output would draw over the interface
+			logger.Disable()
+			defer logger.Enable()
+			model := explore.New(ctx, navigator, strings.Join(args, " "))
+			if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+				return fmt.Errorf("failed to run explorer: %w", err)
+			}
+			return nil
+		})
+	},
+}
+
+var initExploreOnce sync.Once
+
+// InitExploreCommand registers the explore command
+func InitExploreCommand() {
+	initExploreOnce.Do(func() {
+		rootCmd.AddCommand(exploreCmd)
+
+		exploreCmd.Flags().StringP("project", "p", "", "Project ID (defaults to current project)")
+	})
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// codeNodeTypes are the node types written by the analysis, which excludes
+// bookkeeping nodes such as the project metadata
+var codeNodeTypes = []core.NodeType{
+	core.NodeTypePackage,
+	core.NodeTypeFile,
+	core.NodeTypeFunction,
+	core.NodeTypeMethod,
+	core.NodeTypeStruct,
+	core.NodeTypeInterface,
+	core.NodeTypeImport,
+	core.NodeTypeConstant,
+	core.NodeTypeVariable,
+}
+
+// graphFormatExtensions maps output file extensions to graph formats
+var graphFormatExtensions = map[string]graph.GraphFormat{
+	".graphml": graph.GraphFormatGraphML,
+	".gexf":    graph.GraphFormatGEXF,
+	".dot":     graph.GraphFormatDOT,
+	".gv":      graph.GraphFormatDOT,
+	".mmd":     graph.GraphFormatMermaid,
+	".json":    graph.GraphFormatJGF,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the project graph for graph tools and documentation",
+	Long: `Write the analyzed graph of the project, or a part of it, as a graph file:
+
+  graphml  GraphML, for yEd, Gephi, Cytoscape and NetworkX
+  gexf     GEXF 1.3, for Gephi
+  dot      Graphviz DOT
+  mermaid  Mermaid flowchart, for Markdown documentation
+  jgf      JSON Graph Format v2
+
+Node types, names and properties are kept as attributes; relationship types
+become edge labels. Mermaid only carries names and types.
+
+--package keeps the nodes of matching packages, using the same patterns as
+the layer rules of 'gograph check' ("engine/..." or "*/api"). --types keeps
+the given node types. --focus then keeps the symbols with that name, or
+qualified name such as user.Service.Save, and everything within --depth edges
+of them. Only relationships between kept nodes are written.
+
+The format defaults to the extension of --output, or graphml.`,
+	Example: `  # Whole project for Gephi
+  gograph export --format gexf -o graph.gexf
+
+  # Package dependencies of the engine as a Graphviz diagram
+  gograph export --package "engine/..." --types package -o deps.dot
+
+  # Neighborhood of a function for the docs
+  gograph export --format mermaid --focus Service.Save --depth 1`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return errors.WithRecover("export_command", func() error {
+			outputPath, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return fmt.Errorf("failed to get output flag: %w", err)
+			}
+			format, err := exportFormat(cmd, outputPath)
+			if err != nil {
+				return err
+			}
+			opts, err := subgraphOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			repo, projectID, err := openSnapshotRepository(cmd)
+			if err != nil {
+				return err
+			}
+			defer repo.Close()
+
+			result, err := graph.LoadAnalysisResult(context.Background(), repo, projectID)
+			if err != nil {
+				return fmt.Errorf("failed to load project graph: %w", err)
+			}
+			if len(result.Nodes) == 0 {
+				return fmt.Errorf("no analysis found for project %s; run 'gograph analyze' first", projectID)
+			}
+			subgraph, err := graph.Subgraph(result, opts)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputPath != "" {
+				file, err := os.Create(outputPath)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer file.Close()
+				out = file
+			}
+			return graph.WriteGraph(out, format, subgraph)
+		})
+	},
+}
+
+var initExportOnce sync.Once
+
+// InitExportCommand registers the export command
+func InitExportCommand() {
+	initExportOnce.Do(func() {
+		rootCmd.AddCommand(exportCmd)
+
+		exportCmd.Flags().String("format", "", "Graph format: graphml, gexf, dot, mermaid or jgf")
+		exportCmd.Flags().StringP("output", "o", "", "Write the graph to a file instead of stdout")
+		exportCmd.Flags().StringSlice("package", nil, "Keep the nodes of packages matching these patterns")
+		exportCmd.Flags().StringSlice("types", nil, "Keep these node types, e.g. function,method")
+		exportCmd.Flags().String("focus", "", "Keep the symbols with this name and their neighborhood")
+		exportCmd.Flags().Int("depth", 2, "Edges walked from the --focus symbols (0 for no limit)")
+		exportCmd.Flags().StringP("project", "p", "", "Project ID (defaults to current project)")
+	})
+}
+
+// exportFormat returns the --format flag, or the format implied by the
+// extension of the output file
+func exportFormat(cmd *cobra.Command, outputPath string) (graph.GraphFormat, error) {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return "", fmt.Errorf("failed to get format flag: %w", err)
+	}
+	if format == "" {
+		if inferred, exists := graphFormatExtensions[strings.ToLower(filepath.Ext(outputPath))]; exists {
+			return inferred, nil
+		}
+		return graph.GraphFormatGraphML, nil
+	}
+	for _, supported := range graph.GraphFormats {
+		if strings.EqualFold(format, string(supported)) {
+			return supported, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported format %q (use graphml, gexf, dot, mermaid or jgf)", format)
+}
+
+func subgraphOptions(cmd *cobra.Command) (*graph.SubgraphOptions, error) {
+	packages, err := cmd.Flags().GetStringSlice("package")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get package flag: %w", err)
+	}
+	typeNames, err := cmd.Flags().GetStringSlice("types")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get types flag: %w", err)
+	}
+	focus, err := cmd.Flags().GetString("focus")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get focus flag: %w", err)
+	}
+	depth, err := cmd.Flags().GetInt("depth")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get depth flag: %w", err)
+	}
+	types, err := parseNodeTypes(typeNames)
+	if err != nil {
+		return nil, err
+	}
+	return &graph.SubgraphOptions{Packages: packages, NodeTypes: types, Focus: focus, Depth: depth}, nil
+}
+
+// parseNodeTypes resolves node type names case-insensitively. No names
+// selects every code node type.
+func parseNodeTypes(names []string) ([]core.NodeType, error) {
+	if len(names) == 0 {
+		return codeNodeTypes, nil
+	}
+	types := make([]core.NodeType, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, nodeType := range codeNodeTypes {
+			if strings.EqualFold(strings.TrimSpace(name), string(nodeType)) {
+				types = append(types, nodeType)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown node type %q", name)
+		}
+	}
+	return types, nil
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/compozy/gograph/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+var impactCmd = &cobra.Command{
+	Use:   "impact [diff-file]",
+	Short: "Show the blast radius of a change",
+	Long: `Map the lines changed by a unified diff onto the functions, methods and
+types of the analyzed project, then walk reverse CALLS, IMPLEMENTS, REFERENCES
+and EMBEDS edges and the methods of changed types to list every transitively
+affected function, package and entrypoint, ranked by distance.
+
+The diff is read from [diff-file], from stdin when the argument is "-", or
+from 'git diff <ref>' with --since. Line numbers are matched against the
+stored analysis, so run 'gograph analyze' first. By default the diff is
+expected to be applied already; use --unapplied for a proposed change that
+the analyzed code does not contain yet.
+
+Entrypoints are affected functions and methods that nothing in the graph
+calls, such as main, tests and exported API.`,
+	Example: `  # Blast radius of everything changed since main
+  gograph impact --since main
+
+  # Blast radius of a proposed patch before applying it
+  gograph impact change.patch --unapplied
+
+  # Read the diff from stdin and limit the walk to three hops
+  git diff HEAD~1 | gograph impact - --depth 3 --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WithRecover("impact_command", func() error {
+			since, err := cmd.Flags().GetString("since")
+			if err != nil {
+				return fmt.Errorf("failed to get since flag: %w", err)
+			}
+			unapplied, err := cmd.Flags().GetBool("unapplied")
+			if err != nil {
+				return fmt.Errorf("failed to get unapplied flag: %w", err)
+			}
+			depth, err := cmd.Flags().GetInt("depth")
+			if err != nil {
+				return fmt.Errorf("failed to get depth flag: %w", err)
+			}
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return fmt.Errorf("failed to get format flag: %w", err)
+			}
+			if format != findingsFormatText && format != formatJSON {
+				return fmt.Errorf("unsupported format %q (use text or json)", format)
+			}
+			if (since == "") == (len(args) == 0) {
+				return fmt.Errorf("provide either a diff file or --since <ref>")
+			}
+
+			ctx := context.Background()
+			changes, err := readChangedLines(ctx, cmd, args, since, unapplied)
+			if err != nil {
+				return err
+			}
+
+			repo, projectID, err := openSnapshotRepository(cmd)
+			if err != nil {
+				return err
+			}
+			defer repo.Close()
+
+			result, err := graph.LoadAnalysisResult(ctx, repo, projectID)
+			if err != nil {
+				return fmt.Errorf("failed to load project graph: %w", err)
+			}
+			if len(result.Nodes) == 0 {
+				return fmt.Errorf("no analysis found for project %s; run 'gograph analyze' first", projectID)
+			}
+
+			root, err := filepath.Abs(".")
+			if err != nil {
+				return fmt.Errorf("failed to resolve project path: %w", err)
+			}
+			report := graph.Impact(result, changes, &graph.ImpactOptions{MaxDepth: depth, Root: root})
+			return writeImpact(cmd.OutOrStdout(), format, report)
+		})
+	},
+}
+
+var initImpactOnce sync.Once
+
+// InitImpactCommand registers the impact command
+func InitImpactCommand() {
+	initImpactOnce.Do(func() {
+		rootCmd.AddCommand(impactCmd)
+
+		impactCmd.Flags().String("since", "", "Use the changes of the working tree since this git revision")
+		impactCmd.Flags().Bool("unapplied", false, "The diff is not applied to the analyzed code yet")
+		impactCmd.Flags().Int("depth", 0, "Maximum number of edges to walk from a changed entity (0 for no limit)")
+		impactCmd.Flags().String("format", findingsFormatText, "Output format: text or json")
+		impactCmd.Flags().StringP("project", "p", "", "Project ID (defaults to current project)")
+	})
+}
+
+// readChangedLines returns the changed line ranges of a diff file, stdin or
+// the working tree since a git revision
+func readChangedLines(
+	ctx context.Context,
+	cmd *cobra.Command,
+	args []string,
+	since string,
+	unapplied bool,
+) ([]git.LineRange, error) {
+	var files []*git.FileDiff
+	var err error
+	switch {
+	case since != "":
+		files, err = git.DiffSince(ctx, ".", since)
+	case args[0] == "-":
+		files, err = git.ParseDiff(cmd.InOrStdin())
+	default:
+		var file *os.File
+		file, err = os.Open(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to open diff: %w", err)
+		}
+		defer file.Close()
+		files, err = git.ParseDiff(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return git.ChangedLines(files, unapplied), nil
+}
+
+func writeImpact(w io.Writer, format string, report *graph.ImpactReport) error {
+	if format == formatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	return report.WriteText(w)
+}
@@ -111,9 +111,9 @@ func runTranslate(_ *cobra.Command, args []string) error {
 		MaxRetries: 3,
 		BatchSize:  1000,
 	}
-	repo, err := infra.NewNeo4jRepository(neo4jConfig)
+	repo, err := newRepository(cfg, neo4jConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create Neo4j repository: %w", err)
+		return fmt.Errorf("failed to create graph repository: %w", err)
 	}
 	defer repo.Close()
 
@@ -180,9 +180,9 @@ func runContext(_ *cobra.Command, args []string) error {
 		MaxRetries: 3,
 		BatchSize:  1000,
 	}
-	repo, err := infra.NewNeo4jRepository(neo4jConfig)
+	repo, err := newRepository(cfg, neo4jConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create Neo4j repository: %w", err)
+		return fmt.Errorf("failed to create graph repository: %w", err)
 	}
 	defer repo.Close()
 
@@ -16,6 +16,7 @@ import (
 	"github.com/compozy/gograph/engine/parser"
 	"github.com/compozy/gograph/pkg/logger"
 	mcpconfig "github.com/compozy/gograph/pkg/mcp"
+	"github.com/compozy/gograph/pkg/watch"
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,6 +28,9 @@ var (
 	mcpAuth       bool
 	mcpHTTP       bool
 	mcpConfigFile string
+	mcpWatch      bool
+	mcpWrites     bool
+	mcpUnscoped   bool
 )
 
 // serveMCPCmd represents the serve-mcp command
@@ -56,7 +60,10 @@ Examples:
   gograph serve-mcp --auth --http
 
   # Use custom configuration file
-  gograph serve-mcp --config mcp-config.yaml`,
+  gograph serve-mcp --config mcp-config.yaml
+
+  # Keep the graph up to date while the code is being edited
+  gograph serve-mcp --watch`,
 	RunE: runServeMCP,
 }
 
@@ -68,6 +75,10 @@ func RegisterMCPCommand() {
 	serveMCPCmd.Flags().BoolVar(&mcpAuth, "auth", false, "Enable authentication")
 	serveMCPCmd.Flags().BoolVar(&mcpHTTP, "http", false, "Use HTTP transport instead of stdio")
 	serveMCPCmd.Flags().StringVar(&mcpConfigFile, "config", "", "Path to MCP configuration file")
+	serveMCPCmd.Flags().BoolVar(&mcpWatch, "watch", false, "Update the graph of the current project as its files change")
+	serveMCPCmd.Flags().BoolVar(&mcpWrites, "allow-writes", false, "Let execute_cypher run queries that change the graph")
+	serveMCPCmd.Flags().BoolVar(&mcpUnscoped, "unscoped-queries", false,
+		"Run execute_cypher queries as written instead of limiting them to the project")
 
 	// Add to root command
 	rootCmd.AddCommand(serveMCPCmd)
@@ -90,6 +101,14 @@ func runServeMCP(cmd *cobra.Command, _ []string) error {
 	server, cleanup := createMCPServer(config)
 	defer cleanup()
 
+	if mcpWatch {
+		go func() {
+			if err := watchProject(ctx, ".", "", watch.DefaultDebounce); err != nil {
+				logger.Error("Project watch stopped", "error", err)
+			}
+		}()
+	}
+
 	runMCPServerWithGracefulShutdown(ctx, cancel, server)
 	return nil
 }
@@ -114,11 +133,22 @@ func applyCommandLineFlagOverrides(cmd *cobra.Command, config *mcpconfig.Config)
 	if cmd.Flags().Changed("auth") {
 		config.Auth.Enabled = mcpAuth
 	}
+	if cmd.Flags().Changed("allow-writes") {
+		config.Security.AllowWrites = mcpWrites
+	}
+	if cmd.Flags().Changed("unscoped-queries") {
+		config.Security.UnscopedQueries = mcpUnscoped
+	}
 }
 
 func createMCPServer(config *mcpconfig.Config) (*mcp.Server, func()) {
 	logger.Info("Creating MCP server with full service configuration")
 
+	// A broken template file should not keep the server from starting
+	if err := loadUserTemplates(); err != nil {
+		logger.Error("Failed to load user query templates", "error", err)
+	}
+
 	// Initialize Neo4j repository
 	neo4jConfig := &infra.Neo4jConfig{
 		URI:        viper.GetString("neo4j.uri"),
@@ -129,7 +159,12 @@ func createMCPServer(config *mcpconfig.Config) (*mcp.Server, func()) {
 		BatchSize:  1000,
 	}
 
-	repository, err := infra.NewNeo4jRepository(neo4jConfig)
+	cfg, err := loadConfig()
+	if err != nil {
+		logger.Error("Failed to read configuration", "error", err)
+		return mcp.NewServer(config, nil, nil, nil, nil), func() {}
+	}
+	repository, err := newRepository(cfg, neo4jConfig)
 	if err != nil {
 		logger.Error("Failed to create Neo4j repository", "error", err)
 		// Return server with nil services as fallback, but log the error
@@ -251,6 +286,12 @@ func loadMCPConfig() (*mcpconfig.Config, error) {
 	if viper.IsSet("mcp.auth.token") {
 		config.Auth.Token = viper.GetString("mcp.auth.token")
 	}
+	if viper.IsSet("mcp.security.allow_writes") {
+		config.Security.AllowWrites = viper.GetBool("mcp.security.allow_writes")
+	}
+	if viper.IsSet("mcp.security.unscoped_queries") {
+		config.Security.UnscopedQueries = viper.GetBool("mcp.security.unscoped_queries")
+	}
 
 	return config, nil
 }
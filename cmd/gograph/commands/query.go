@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"text/tabwriter"
@@ -12,6 +14,8 @@ import (
 
 	"github.com/compozy/gograph/engine/graph"
 	"github.com/compozy/gograph/engine/infra"
+	"github.com/compozy/gograph/engine/query"
+	"github.com/compozy/gograph/pkg/config"
 	"github.com/compozy/gograph/pkg/logger"
 	"github.com/compozy/gograph/pkg/progress"
 	"github.com/spf13/cobra"
@@ -35,6 +39,12 @@ func InitQueryCommand() {
 		queryCmd.Flags().Int("limit", 100, "Maximum number of results to return")
 		queryCmd.Flags().BoolP("count", "c", false, "Show result count and timing")
 		queryCmd.Flags().Bool("no-progress", false, "Disable progress indicators")
+		queryCmd.Flags().String("snapshot", "", "Bind $project_id to an older snapshot (ID, ID prefix or commit SHA)")
+		queryCmd.Flags().StringArray("param", nil, "Query parameter as name=value, typed as YAML (repeatable)")
+		queryCmd.Flags().String("params-file", "", "YAML or JSON file of query parameters")
+		queryCmd.Flags().StringP("file", "f", "", "Cypher file of ';'-separated statements to run, - for stdin")
+		queryCmd.Flags().Bool("read-only", true, "Reject statements that write and run the rest in read transactions")
+		queryCmd.Flags().Bool("scope-project", false, "Limit every node pattern to the nodes of $project_id")
 	})
 }
 
@@ -43,7 +53,7 @@ var queryCmd = &cobra.Command{
 	Short: "Execute Cypher queries against the Neo4j database",
 	Long: `Execute Cypher queries to explore the code graph stored in Neo4j. This
 command allows you to run any valid Cypher query and view results in either
-table or JSON format.
+table, JSON or CSV format.
 
 The graph contains these node types:
   • Package: Go packages in your project
@@ -75,21 +85,56 @@ Common queries:
   • Find most called functions:
     MATCH (f:Function)<-[:CALLS]-()
     RETURN f.name, count(*) as calls
-    ORDER BY calls DESC LIMIT 10`,
+    ORDER BY calls DESC LIMIT 10
+
+Parameters are passed with --param name=value, typed as YAML values: 3 is
+an integer, true a boolean, [a, b] a list and '42' a string. --params-file
+reads a YAML or JSON map of parameters, which --param values override.
+$project_id is bound to the project ID of gograph.yaml unless given.
+
+With --file, the ';'-separated statements of a Cypher file are run in order
+and the results of each are printed.
+
+Queries are read-only by default: statements with CREATE, MERGE, DELETE,
+SET, REMOVE or calls to procedures that may write are rejected before any
+statement runs, and the rest run in read transactions. Pass
+--read-only=false to run writes.
+
+--scope-project rewrites the statements so that every node pattern only
+matches nodes of $project_id, for queries that leave out the project filter.
+Statements it cannot scope, such as those that set project_id to another
+value, are rejected.`,
 	Example: `  # Find all packages
   gograph query "MATCH (p:Package) RETURN p.name"
   
   # Get function call statistics with JSON output
-  gograph query "MATCH (f:Function)<-[:CALLS]-() RETURN f.name, count(*) as calls" -f json
+  gograph query "MATCH (f:Function)<-[:CALLS]-() RETURN f.name, count(*) as calls" --format json
+
+  # Pass typed parameters; $project_id is bound to the configured project
+  gograph query "MATCH (f:Function {project_id: $project_id}) WHERE f.name IN $names RETURN f" \
+    --param 'names=[SaveUser, LoadUser]'
+
+  # Run the statements of a file with parameters from another one
+  gograph query -f queries/hotspots.cypher --params-file queries/params.yaml
   
   # Show query result count
   gograph query "MATCH (n) RETURN n" -c
   
+  # Query an older snapshot of the project through $project_id
+  gograph query "MATCH (f:Function {project_id: $project_id}) RETURN count(f)" --snapshot 3f2a9c1
+  
   # Complex query without progress indicator
   gograph query "MATCH path = (p:Package)-[:CONTAINS*]->(f:Function) RETURN path" --no-progress`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		query := args[0]
+		statements, err := queryStatements(cmd, args)
+		if err != nil {
+			return err
+		}
+		params, err := queryParams(cmd)
+		if err != nil {
+			return err
+		}
 		format, err := cmd.Flags().GetString("format")
 		if err != nil {
 			return fmt.Errorf("failed to get format flag: %w", err)
@@ -103,9 +148,34 @@ Common queries:
 			return fmt.Errorf("failed to get no-progress flag: %w", err)
 		}
 
+		snapshot, err := cmd.Flags().GetString("snapshot")
+		if err != nil {
+			return fmt.Errorf("failed to get snapshot flag: %w", err)
+		}
+		readOnly, err := cmd.Flags().GetBool("read-only")
+		if err != nil {
+			return fmt.Errorf("failed to get read-only flag: %w", err)
+		}
+		ctx := context.Background()
+		if readOnly {
+			if err := checkReadOnly(statements); err != nil {
+				return err
+			}
+			ctx = graph.WithReadOnly(ctx)
+		}
+		scope, err := cmd.Flags().GetBool("scope-project")
+		if err != nil {
+			return fmt.Errorf("failed to get scope-project flag: %w", err)
+		}
+		if scope {
+			if statements, err = scopeStatements(statements); err != nil {
+				return err
+			}
+		}
+
 		// Validate format
-		if format != formatTable && format != formatJSON {
-			return fmt.Errorf("invalid format: %s (must be 'table' or 'json')", format)
+		if format != formatTable && format != formatJSON && format != formatCSV {
+			return fmt.Errorf("invalid format: %s (must be 'table', 'json' or 'csv')", format)
 		}
 
 		// Get Neo4j configuration with fallback to defaults
@@ -136,22 +206,56 @@ Common queries:
 			return fmt.Errorf("Neo4j URI not configured. Run 'gograph init' or set NEO4J_URI environment variable")
 		}
 
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
 		if noProgress {
-			return runQueryWithoutProgress(query, format, showCount, neo4jConfig)
+			return runQueryWithoutProgress(ctx, statements, params, format, showCount, snapshot, cfg, neo4jConfig)
 		}
-		return runQueryWithProgress(query, format, showCount, neo4jConfig)
+		return runQueryWithProgress(ctx, statements, params, format, showCount, snapshot, cfg, neo4jConfig)
 	},
 }
 
-func outputJSON(results []map[string]any) error {
-	encoder := json.NewEncoder(os.Stdout)
+// outputResults writes query results in one of the output formats
+func outputResults(w io.Writer, format string, results []map[string]any) error {
+	switch format {
+	case formatJSON:
+		return outputJSON(w, results)
+	case formatCSV:
+		return outputCSV(w, results)
+	default:
+		return outputTable(w, results)
+	}
+}
+
+func outputJSON(w io.Writer, results []map[string]any) error {
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(results)
 }
 
-func outputTable(results []map[string]any) error {
+func outputCSV(w io.Writer, results []map[string]any) error {
+	return query.NewExporter(query.DefaultExportOptions(query.FormatCSV)).Export(w, results)
+}
+
+// streamCSV writes the rows of a statement as CSV while the repository reads
+// them, separated from the output of the previous statement by a blank line
+func streamCSV(ctx context.Context, repo graph.Repository, index int, statement string, params map[string]any) error {
+	if index > 0 {
+		fmt.Println()
+	}
+	rows := query.NewExporter(query.DefaultExportOptions(query.FormatCSV)).NewRowWriter(os.Stdout)
+	if err := repo.StreamQuery(ctx, statement, params, rows.WriteRow); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+func outputTable(out io.Writer, results []map[string]any) error {
 	if len(results) == 0 {
-		fmt.Println("No results found.")
+		fmt.Fprintln(out, "No results found.")
 		return nil
 	}
 
@@ -160,9 +264,10 @@ func outputTable(results []map[string]any) error {
 	for key := range results[0] {
 		columns = append(columns, key)
 	}
+	sort.Strings(columns)
 
 	// Create tabwriter for aligned output
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 
 	// Print header
 	fmt.Fprintf(w, "%s\n", strings.Join(columns, "\t"))
@@ -209,81 +314,103 @@ func formatValue(val any) string {
 	}
 }
 
-func runQueryWithoutProgress(query, format string, showCount bool, neo4jConfig *infra.Neo4jConfig) error {
-	ctx := context.Background()
-
+func runQueryWithoutProgress(
+	ctx context.Context,
+	statements []string,
+	params map[string]any,
+	format string,
+	showCount bool,
+	snapshot string,
+	cfg *config.Config,
+	neo4jConfig *infra.Neo4jConfig,
+) error {
 	// Initialize Neo4j repository
 	logger.Debug("connecting to Neo4j", "uri", neo4jConfig.URI)
-	repo, err := infra.NewNeo4jRepository(neo4jConfig)
+	repo, err := newRepository(cfg, neo4jConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create Neo4j repository: %w", err)
+		return fmt.Errorf("failed to create graph repository: %w", err)
 	}
 	defer repo.Close()
 
-	// Execute query
-	logger.Debug("executing query", "query", query)
-	start := time.Now()
-	results, err := repo.ExecuteQuery(ctx, query, nil)
+	params, err = projectQueryParams(ctx, repo, snapshot, params)
 	if err != nil {
-		return fmt.Errorf("failed to execute query: %w", err)
-	}
-	duration := time.Since(start)
-
-	// Display results
-	if showCount {
-		fmt.Printf("Query returned %d results in %v\n\n", len(results), duration)
+		return err
 	}
 
-	switch format {
-	case formatJSON:
-		return outputJSON(results)
-	case formatTable:
-		return outputTable(results)
-	default:
-		return outputTable(results)
+	for i, statement := range statements {
+		logger.Debug("executing query", "query", statement)
+		if format == formatCSV && !showCount {
+			if err := streamCSV(ctx, repo, i, statement, params); err != nil {
+				return statementError(statements, i, err)
+			}
+			continue
+		}
+		start := time.Now()
+		results, err := repo.ExecuteQuery(ctx, statement, params)
+		if err != nil {
+			return statementError(statements, i, err)
+		}
+		if err := printStatementResults(i, results, time.Since(start), format, showCount); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func runQueryWithProgress(query, format string, showCount bool, neo4jConfig *infra.Neo4jConfig) error {
-	ctx := context.Background()
-	var results []map[string]any
-	var duration time.Duration
-
+func runQueryWithProgress(
+	ctx context.Context,
+	statements []string,
+	params map[string]any,
+	format string,
+	showCount bool,
+	snapshot string,
+	cfg *config.Config,
+	neo4jConfig *infra.Neo4jConfig,
+) error {
 	// Connect to Neo4j with progress
 	var repo graph.Repository
 	err := progress.WithProgress("Connecting to Neo4j", func() error {
 		var err error
-		repo, err = infra.NewNeo4jRepository(neo4jConfig)
+		repo, err = newRepository(cfg, neo4jConfig)
 		return err
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create Neo4j repository: %w", err)
+		return fmt.Errorf("failed to create graph repository: %w", err)
 	}
 	defer repo.Close()
 
-	// Execute query with progress
-	err = progress.WithProgress("Executing query", func() error {
-		start := time.Now()
-		var err error
-		results, err = repo.ExecuteQuery(ctx, query, nil)
-		duration = time.Since(start)
-		return err
-	})
+	params, err = projectQueryParams(ctx, repo, snapshot, params)
 	if err != nil {
-		return fmt.Errorf("failed to execute query: %w", err)
-	}
-
-	// Display results
-	if showCount {
-		fmt.Printf("Query returned %d results in %v\n\n", len(results), duration)
+		return err
 	}
 
-	switch format {
-	case formatJSON:
-		return outputJSON(results)
-	case formatTable:
-		return outputTable(results)
-	default:
-		return outputTable(results)
+	for i, statement := range statements {
+		if format == formatCSV && !showCount {
+			// Rows are written as they arrive, which a spinner would garble
+			if err := streamCSV(ctx, repo, i, statement, params); err != nil {
+				return statementError(statements, i, err)
+			}
+			continue
+		}
+		description := "Executing query"
+		if len(statements) > 1 {
+			description = fmt.Sprintf("Executing statement %d of %d", i+1, len(statements))
+		}
+		var results []map[string]any
+		var duration time.Duration
+		err = progress.WithProgress(description, func() error {
+			start := time.Now()
+			var err error
+			results, err = repo.ExecuteQuery(ctx, statement, params)
+			duration = time.Since(start)
+			return err
+		})
+		if err != nil {
+			return statementError(statements, i, err)
+		}
+		if err := printStatementResults(i, results, duration, format, showCount); err != nil {
+			return err
+		}
 	}
+	return nil
 }
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/cypher"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// queryStatements returns the statements of the query argument, or of the
+// file given with --file ("-" reads standard input)
+func queryStatements(cmd *cobra.Command, args []string) ([]string, error) {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file flag: %w", err)
+	}
+	var script, source string
+	switch {
+	case file != "" && len(args) > 0:
+		return nil, fmt.Errorf("give either a query or --file, not both")
+	case file == "" && len(args) == 0:
+		return nil, fmt.Errorf("a query or --file is required")
+	case file == "":
+		script, source = args[0], "the query"
+	case file == "-":
+		content, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read standard input: %w", err)
+		}
+		script, source = string(content), "standard input"
+	default:
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read query file: %w", err)
+		}
+		script, source = string(content), file
+	}
+	statements := cypher.SplitStatements(script)
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("no statements in %s", source)
+	}
+	return statements, nil
+}
+
+// queryParams reads the parameters of --params-file, then sets each --param
+// on top of them
+func queryParams(cmd *cobra.Command) (map[string]any, error) {
+	file, err := cmd.Flags().GetString("params-file")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get params-file flag: %w", err)
+	}
+	pairs, err := cmd.Flags().GetStringArray("param")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get param flag: %w", err)
+	}
+	params := make(map[string]any)
+	if file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read params file: %w", err)
+		}
+		if err := yaml.Unmarshal(content, &params); err != nil {
+			return nil, fmt.Errorf("failed to parse params file %s: %w", file, err)
+		}
+		if params == nil { // Empty file
+			params = make(map[string]any)
+		}
+	}
+	for _, pair := range pairs {
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid --param %q (expected name=value)", pair)
+		}
+		parsed, err := parseParamValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of --param %s: %w", name, err)
+		}
+		params[name] = parsed
+	}
+	return params, nil
+}
+
+// parseParamValue types the value of a --param as YAML does: integers,
+// floats, booleans and null are typed, [a, b] is a list and {k: v} a map.
+// Quotes keep a value a string; any other value is the string as given.
+func parseParamValue(value string) (any, error) {
+	var parsed any
+	err := yaml.Unmarshal([]byte(value), &parsed)
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		if err != nil {
+			return nil, err
+		}
+		return parsed, nil
+	}
+	if err != nil {
+		return value, nil
+	}
+	switch parsed.(type) {
+	case int, float64, bool, string, nil:
+		return parsed, nil
+	}
+	return value, nil
+}
+
+// projectQueryParams binds $project_id to the scope of the requested
+// snapshot or, unless the parameter is given, to the configured project
+func projectQueryParams(
+	ctx context.Context,
+	repo graph.Repository,
+	snapshot string,
+	params map[string]any,
+) (map[string]any, error) {
+	projectID := core.ID(viper.GetString("project.id"))
+	if snapshot != "" {
+		if projectID == "" {
+			return nil, fmt.Errorf("--snapshot requires a project ID in the configuration file")
+		}
+		scope, err := resolveSnapshotScope(ctx, repo, projectID, snapshot)
+		if err != nil {
+			return nil, err
+		}
+		params["project_id"] = scope.String()
+		return params, nil
+	}
+	if _, set := params["project_id"]; !set && projectID != "" {
+		params["project_id"] = projectID.String()
+	}
+	return params, nil
+}
+
+// printStatementResults writes the results of one statement of a query,
+// separated from those of the previous one by a blank line
+func printStatementResults(
+	index int,
+	results []map[string]any,
+	duration time.Duration,
+	format string,
+	showCount bool,
+) error {
+	if index > 0 {
+		fmt.Println()
+	}
+	if showCount {
+		fmt.Printf("Query returned %d results in %v\n\n", len(results), duration)
+	}
+	return outputResults(os.Stdout, format, results)
+}
+
+// checkReadOnly rejects the statements before any of them runs when one may
+// write to the graph
+func checkReadOnly(statements []string) error {
+	for i, statement := range statements {
+		if err := cypher.CheckReadOnly(statement); err != nil {
+			if len(statements) > 1 {
+				err = fmt.Errorf("statement %d of %d: %w", i+1, len(statements), err)
+			}
+			return fmt.Errorf("%w (pass --read-only=false to run writes)", err)
+		}
+	}
+	return nil
+}
+
+// scopeStatements limits every node pattern of the statements to the nodes of
+// $project_id
+func scopeStatements(statements []string) ([]string, error) {
+	scoped := make([]string, len(statements))
+	for i, statement := range statements {
+		var err error
+		scoped[i], err = cypher.ScopeToProject(statement)
+		if err != nil {
+			if len(statements) > 1 {
+				return nil, fmt.Errorf("statement %d of %d: %w", i+1, len(statements), err)
+			}
+			return nil, err
+		}
+	}
+	return scoped, nil
+}
+
+// statementError names the failing statement when a query has several
+func statementError(statements []string, index int, err error) error {
+	if len(statements) == 1 {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	return fmt.Errorf("failed to execute statement %d of %d: %w", index+1, len(statements), err)
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate a static HTML report of the project graph",
+	Long: `Write a self-contained HTML report of the analyzed project that opens in
+any browser without a server, Neo4j or network access. Share the directory
+with anyone who should explore the code base.
+
+The report includes:
+  - An overview with project statistics, import cycles and the most called
+    and most complex functions
+  - The package dependency graph, with zoom, pan and a package filter
+  - A treemap of packages sized by lines of code and colored by cyclomatic
+    complexity, or by test coverage with --coverage
+  - A page for every package and a searchable page for every function,
+    method and type with its callers, callees, methods and implementations
+
+Complexity is recorded by 'gograph analyze'; re-analyze projects stored by
+older versions to include it. --coverage takes a profile written by
+'go test -coverprofile'.`,
+	Example: `  # Write the report to out/index.html
+  gograph report --html out/
+
+  # Color the treemap by test coverage
+  go test -coverprofile=cover.out ./...
+  gograph report --html out/ --coverage cover.out`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return errors.WithRecover("report_command", func() error {
+			dir, err := cmd.Flags().GetString("html")
+			if err != nil {
+				return fmt.Errorf("failed to get html flag: %w", err)
+			}
+			opts, err := reportOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			repo, projectID, err := openSnapshotRepository(cmd)
+			if err != nil {
+				return err
+			}
+			defer repo.Close()
+
+			ctx := context.Background()
+			result, err := graph.LoadAnalysisResult(ctx, repo, projectID)
+			if err != nil {
+				return fmt.Errorf("failed to load project graph: %w", err)
+			}
+			if len(result.Nodes) == 0 {
+				return fmt.Errorf("no analysis found for project %s; run 'gograph analyze' first", projectID)
+			}
+			graphService := graph.NewService(nil, nil, nil, repo, graph.DefaultServiceConfig())
+			stats, err := graphService.GetProjectStatistics(ctx, projectID)
+			if err != nil {
+				return fmt.Errorf("failed to get project statistics: %w", err)
+			}
+
+			report := graph.BuildReport(result, stats, opts)
+			path, err := graph.WriteHTMLReport(dir, report)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ wrote report of %d packages and %d symbols to %s\n",
+				len(report.Packages), len(report.Symbols), path)
+			return nil
+		})
+	},
+}
+
+var initReportOnce sync.Once
+
+// InitReportCommand registers the report command
+func InitReportCommand() {
+	initReportOnce.Do(func() {
+		rootCmd.AddCommand(reportCmd)
+
+		reportCmd.Flags().String("html", "", "Directory to write the HTML report to")
+		reportCmd.Flags().String("coverage", "", "Go coverage profile to color packages by test coverage")
+		reportCmd.Flags().Int("top", 20, "Number of most called functions to list")
+		reportCmd.Flags().StringP("project", "p", "", "Project ID (defaults to current project)")
+		_ = reportCmd.MarkFlagRequired("html")
+	})
+}
+
+func reportOptions(cmd *cobra.Command) (*graph.ReportOptions, error) {
+	coveragePath, err := cmd.Flags().GetString("coverage")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coverage flag: %w", err)
+	}
+	top, err := cmd.Flags().GetInt("top")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top flag: %w", err)
+	}
+	root, err := filepath.Abs(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project path: %w", err)
+	}
+	opts := &graph.ReportOptions{Root: root, TopFunctions: top}
+	if coveragePath != "" {
+		if opts.Coverage, err = graph.PackageCoverage(coveragePath); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
@@ -49,11 +49,23 @@ func Execute() {
 
 	// Initialize all commands
 	InitAnalyzeCommand()
+	InitAPICheckCommand()
+	InitArchiveCommands()
+	InitCheckCommand()
 	InitClearCommand()
+	InitDiffCommand()
+	InitExploreCommand()
+	InitExportCommand()
 	InitHelpCommands()
+	InitImpactCommand()
 	InitInitCommand()
 	InitQueryCommand()
+	InitReportCommand()
+	InitShellCommand()
+	InitSnapshotsCommand()
+	InitTestsForCommand()
 	InitVersionCommand()
+	InitWatchCommand()
 	RegisterLLMCommands()
 	RegisterTemplatesCommand()
 	RegisterMCPCommand()
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/compozy/gograph/pkg/logger"
+	"github.com/compozy/gograph/pkg/shell"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// defaultShellHistory is the history file of 'gograph shell'
+const defaultShellHistory = ".gograph/shell_history"
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Run Cypher queries interactively",
+	Long: `Open an interactive Cypher prompt on the graph store. Queries end with ';'
+and may span several lines; their results are printed in the table, JSON or
+CSV format of 'gograph query'. $project_id is bound to the current project.
+
+Meta-commands:
+  :param name => value   Set $name to the value of a Cypher expression
+  :params [clear]        List or remove the parameters
+  :format [name]         Show or set the output format
+  :schema                Reload and show the labels, types and keys
+  :explain [query]       Show the plan of a query, or of the last one
+  :profile [query]       Run a query and show its plan with row counts
+  :help, :exit
+
+Tab completes node labels, relationship types and property keys read from
+the stored graph, as well as parameters, keywords and meta-commands. Up and
+down browse the history, which is kept in .gograph/shell_history.`,
+	Example: `  # Open the shell
+  gograph shell
+
+  # Open it on another project, printing JSON
+  gograph shell --project my-backend-api --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return errors.WithRecover("shell_command", func() error {
+			if !isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()) {
+				return fmt.Errorf("shell needs an interactive terminal; use 'gograph query' in scripts")
+			}
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return fmt.Errorf("failed to get format flag: %w", err)
+			}
+			historyPath, err := cmd.Flags().GetString("history")
+			if err != nil {
+				return fmt.Errorf("failed to get history flag: %w", err)
+			}
+
+			repo, projectID, err := openSnapshotRepository(cmd)
+			if err != nil {
+				return err
+			}
+			defer repo.Close()
+
+			ctx := context.Background()
+			session, err := shell.NewSession(repo, projectID, map[string]shell.Formatter{
+				formatTable: outputTable,
+				formatJSON:  outputJSON,
+				formatCSV:   outputCSV,
+			}, format)
+			if err != nil {
+				return err
+			}
+			if err := session.LoadSchema(ctx); err != nil {
+				return err
+			}
+			history, err := shell.LoadHistory(historyPath)
+			if err != nil {
+				return err
+			}
+
+			// Log output would draw over the prompt
+			logger.Disable()
+			defer logger.Enable()
+			if _, err := tea.NewProgram(shell.New(ctx, session, history)).Run(); err != nil {
+				return fmt.Errorf("failed to run shell: %w", err)
+			}
+			return nil
+		})
+	},
+}
+
+var initShellOnce sync.Once
+
+// InitShellCommand registers the shell command
+func InitShellCommand() {
+	initShellOnce.Do(func() {
+		rootCmd.AddCommand(shellCmd)
+
+		shellCmd.Flags().StringP("project", "p", "", "Project ID (defaults to current project)")
+		shellCmd.Flags().String("format", formatTable, "Output format: table, json, csv")
+		shellCmd.Flags().String("history", defaultShellHistory, "History file, empty to keep no history")
+	})
+}
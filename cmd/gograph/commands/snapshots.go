@@ -0,0 +1,343 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/pkg/config"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/compozy/gograph/pkg/git"
+	"github.com/compozy/gograph/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var snapshotsCmd = &cobra.Command{
+	Use:   "snapshots",
+	Short: "Manage stored analysis snapshots",
+	Long: `Every run of 'gograph analyze' stores the graph as a snapshot tagged with
+the commit SHA, branch, timestamp and a hash of the analysis configuration.
+Queries run against the latest snapshot by default; older snapshots remain
+available under the project ID "<project>@<snapshot>" or through the
+--snapshot flag of 'gograph query'.
+
+Snapshots are enabled by default and can be turned off with
+'snapshots.enabled: false' in the configuration file.`,
+}
+
+var snapshotsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the stored snapshots of a project",
+	Example: `  # List snapshots of the current project
+  gograph snapshots list
+
+  # List snapshots of another project as JSON
+  gograph snapshots list --project my-backend-api --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return errors.WithRecover("snapshots_list_command", func() error {
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return fmt.Errorf("failed to get format flag: %w", err)
+			}
+			if format != formatTable && format != formatJSON {
+				return fmt.Errorf("invalid format: %s (must be 'table' or 'json')", format)
+			}
+
+			repo, projectID, err := openSnapshotRepository(cmd)
+			if err != nil {
+				return err
+			}
+			defer repo.Close()
+
+			snapshots, err := repo.ListSnapshots(context.Background(), projectID)
+			if err != nil {
+				return err
+			}
+
+			if format == formatJSON {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(snapshots)
+			}
+			return printSnapshots(cmd, snapshots)
+		})
+	},
+}
+
+var snapshotsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old snapshots of a project",
+	Long: `Delete archived snapshots that fall outside the retention policy. The
+latest snapshot is never deleted. Without flags, the 'snapshots.keep' setting
+from the configuration file is used.`,
+	Example: `  # Apply the configured retention
+  gograph snapshots prune
+
+  # Keep the five most recent snapshots
+  gograph snapshots prune --keep 5
+
+  # Delete snapshots older than 30 days, showing what would be removed
+  gograph snapshots prune --older-than 720h --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return errors.WithRecover("snapshots_prune_command", func() error {
+			cfg, err := config.LoadProjectConfig(".")
+			if err != nil {
+				return fmt.Errorf("failed to load project config: %w", err)
+			}
+			keep, err := cmd.Flags().GetInt("keep")
+			if err != nil {
+				return fmt.Errorf("failed to get keep flag: %w", err)
+			}
+			if !cmd.Flags().Changed("keep") {
+				keep = cfg.Snapshots.Keep
+			}
+			olderThan, err := cmd.Flags().GetDuration("older-than")
+			if err != nil {
+				return fmt.Errorf("failed to get older-than flag: %w", err)
+			}
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return fmt.Errorf("failed to get dry-run flag: %w", err)
+			}
+			if keep < 0 || olderThan < 0 {
+				return fmt.Errorf("--keep and --older-than must not be negative")
+			}
+
+			repo, projectID, err := openSnapshotRepository(cmd)
+			if err != nil {
+				return err
+			}
+			defer repo.Close()
+
+			ctx := context.Background()
+			snapshots, err := repo.ListSnapshots(ctx, projectID)
+			if err != nil {
+				return err
+			}
+
+			policy := &graph.RetentionPolicy{Keep: keep, OlderThan: olderThan}
+			selected := policy.Select(snapshots, time.Now())
+			out := cmd.OutOrStdout()
+			if len(selected) == 0 {
+				fmt.Fprintln(out, "No snapshots to prune.")
+				return nil
+			}
+
+			for _, snapshot := range selected {
+				if dryRun {
+					fmt.Fprintf(out, "would delete %s (%s)\n", snapshot.ID, describeSnapshot(snapshot))
+					continue
+				}
+				if err := repo.DeleteSnapshot(ctx, projectID, snapshot.ID); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "deleted %s (%s)\n", snapshot.ID, describeSnapshot(snapshot))
+			}
+			if !dryRun {
+				fmt.Fprintf(out, "✓ pruned %d snapshot(s), %d remaining\n", len(selected), len(snapshots)-len(selected))
+			}
+			return nil
+		})
+	},
+}
+
+var initSnapshotsOnce sync.Once
+
+// InitSnapshotsCommand registers the snapshots command
+func InitSnapshotsCommand() {
+	initSnapshotsOnce.Do(func() {
+		rootCmd.AddCommand(snapshotsCmd)
+		snapshotsCmd.AddCommand(snapshotsListCmd)
+		snapshotsCmd.AddCommand(snapshotsPruneCmd)
+
+		snapshotsCmd.PersistentFlags().StringP("project", "p", "", "Project ID to use (defaults to current project)")
+		snapshotsListCmd.Flags().String("format", formatTable, "Output format: table, json")
+		snapshotsPruneCmd.Flags().Int("keep", 0, "Number of most recent snapshots to keep (default from config)")
+		snapshotsPruneCmd.Flags().Duration("older-than", 0, "Delete snapshots older than this duration, e.g. 720h")
+		snapshotsPruneCmd.Flags().Bool("dry-run", false, "Show what would be deleted without deleting")
+	})
+}
+
+// openSnapshotRepository connects to Neo4j and resolves the project to manage
+func openSnapshotRepository(cmd *cobra.Command) (graph.Repository, core.ID, error) {
+	cfg, err := config.LoadProjectConfig(".")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load project config: %w", err)
+	}
+	projectID := core.ID(cfg.Project.ID)
+	if project, err := cmd.Flags().GetString("project"); err == nil && project != "" {
+		projectID = core.ID(project)
+	}
+
+	neo4jConfig := neo4jConfigFromConfig(cfg)
+	logger.Debug("connecting to Neo4j", "uri", neo4jConfig.URI)
+	repo, err := newRepository(cfg, neo4jConfig)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create graph repository: %w", err)
+	}
+	return repo, projectID, nil
+}
+
+func printSnapshots(cmd *cobra.Command, snapshots []*core.Snapshot) error {
+	out := cmd.OutOrStdout()
+	if len(snapshots) == 0 {
+		fmt.Fprintln(out, "No snapshots found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCREATED\tCOMMIT\tBRANCH\tNODES\tRELATIONSHIPS\t")
+	for _, snapshot := range snapshots {
+		id := snapshot.ID.String()
+		if snapshot.Latest {
+			id += " (latest)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t\n",
+			id,
+			snapshot.CreatedAt.Local().Format(time.DateTime),
+			shortSHA(snapshot.CommitSHA),
+			snapshot.Branch,
+			snapshot.NodeCount,
+			snapshot.RelationshipCount)
+	}
+	return w.Flush()
+}
+
+func describeSnapshot(snapshot *core.Snapshot) string {
+	parts := []string{snapshot.CreatedAt.Local().Format(time.DateTime)}
+	if snapshot.CommitSHA != "" {
+		parts = append(parts, shortSHA(snapshot.CommitSHA))
+	}
+	if snapshot.Branch != "" {
+		parts = append(parts, snapshot.Branch)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+// storeAnalysis stores an analysis result. When it is stored as a snapshot,
+// the snapshots beyond the number to keep are pruned, as 'snapshots prune'
+// does with the configured default.
+func storeAnalysis(ctx context.Context, repo graph.Repository, result *core.AnalysisResult, keep int) error {
+	if err := repo.StoreAnalysis(ctx, result); err != nil {
+		return err
+	}
+	if result.Snapshot == nil || keep <= 0 {
+		return nil
+	}
+	snapshots, err := repo.ListSnapshots(ctx, result.ProjectID)
+	if err != nil {
+		logger.Warn("failed to prune snapshots", "error", err)
+		return nil
+	}
+	policy := &graph.RetentionPolicy{Keep: keep}
+	for _, snapshot := range policy.Select(snapshots, time.Now()) {
+		if err := repo.DeleteSnapshot(ctx, result.ProjectID, snapshot.ID); err != nil {
+			logger.Warn("failed to prune snapshots", "error", err)
+			return nil
+		}
+	}
+	return nil
+}
+
+// newAnalysisSnapshot describes the analysis about to be stored, or returns
+// nil when snapshots are disabled. A backfill snapshot of an older revision
+// is dated by its commit rather than by the analysis.
+func newAnalysisSnapshot(projectPath string, cfg *config.Config, backfill bool) *core.Snapshot {
+	if !cfg.Snapshots.Enabled {
+		return nil
+	}
+
+	snapshot := &core.Snapshot{
+		ID:        core.NewID(),
+		CreatedAt: time.Now(),
+		Backfill:  backfill,
+	}
+	if revision, err := git.Head(context.Background(), projectPath); err == nil {
+		snapshot.CommitSHA = revision.CommitSHA
+		snapshot.Branch = revision.Branch
+		if backfill {
+			snapshot.CreatedAt = revision.CommittedAt
+		}
+	} else {
+		logger.Debug("snapshot without git revision", "error", err)
+	}
+	if data, err := json.Marshal(cfg.Analysis); err == nil {
+		sum := sha256.Sum256(data)
+		snapshot.ConfigHash = hex.EncodeToString(sum[:8])
+	}
+	return snapshot
+}
+
+// resolveSnapshotScope returns the project ID under which the given snapshot
+// is stored. The snapshot may be given by ID, unique ID prefix or commit SHA
+// prefix; an empty value or "latest" resolves to the project itself.
+func resolveSnapshotScope(
+	ctx context.Context,
+	repo graph.Repository,
+	projectID core.ID,
+	snapshotID string,
+) (core.ID, error) {
+	if snapshotID == "" || snapshotID == "latest" {
+		return projectID, nil
+	}
+	snapshots, err := repo.ListSnapshots(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []*core.Snapshot
+	for _, snapshot := range snapshots {
+		if snapshot.ID.String() == snapshotID {
+			return snapshot.Scope(), nil
+		}
+		if strings.HasPrefix(snapshot.ID.String(), snapshotID) {
+			matches = append(matches, snapshot)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0].Scope(), nil
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("snapshot %q is ambiguous (%d matches)", snapshotID, len(matches))
+	}
+
+	// Fall back to the newest snapshot of a commit
+	for _, snapshot := range snapshots {
+		if snapshot.CommitSHA != "" && strings.HasPrefix(snapshot.CommitSHA, snapshotID) {
+			return snapshot.Scope(), nil
+		}
+	}
+	return "", fmt.Errorf("snapshot %q not found for project %s", snapshotID, projectID)
+}
+
+// findSnapshot returns the snapshot stored under a scope, or nil when the
+// graph was stored without snapshots
+func findSnapshot(ctx context.Context, repo graph.Repository, projectID, scope core.ID) (*core.Snapshot, error) {
+	snapshots, err := repo.ListSnapshots(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Scope() == scope {
+			return snapshot, nil
+		}
+	}
+	return nil, nil
+}
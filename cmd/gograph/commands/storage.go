@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/engine/infra"
+	"github.com/compozy/gograph/pkg/config"
+	"github.com/spf13/viper"
+)
+
+// Storage backend constants
+const (
+	StorageBackendNeo4j    = "neo4j"
+	StorageBackendEmbedded = "embedded"
+	DefaultStoragePath     = ".gograph/graph.db"
+)
+
+// newRepository opens the graph store selected by the storage configuration
+// of cfg, connecting to Neo4j with neo4jConfig, which commands may override
+// with flags. A file:// Neo4j URI also selects the embedded store at that
+// path, resolved like storage.path.
+func newRepository(cfg *config.Config, neo4jConfig *infra.Neo4jConfig) (graph.Repository, error) {
+	switch cfg.Storage.Backend {
+	case "", StorageBackendNeo4j:
+		if neo4jConfig != nil && strings.HasPrefix(neo4jConfig.URI, infra.EmbeddedURIScheme) {
+			path, err := embeddedStoragePath(strings.TrimPrefix(neo4jConfig.URI, infra.EmbeddedURIScheme))
+			if err != nil {
+				return nil, err
+			}
+			return infra.NewEmbeddedRepository(path)
+		}
+		return infra.NewRepository(neo4jConfig)
+	case StorageBackendEmbedded:
+		path, err := embeddedStoragePath(cfg.Storage.Path)
+		if err != nil {
+			return nil, err
+		}
+		return infra.NewEmbeddedRepository(path)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %q (use %s or %s)",
+			cfg.Storage.Backend, StorageBackendNeo4j, StorageBackendEmbedded)
+	}
+}
+
+// loadConfig returns the configuration read by the root command, for commands
+// that take their settings from flags and environment variables rather than
+// loading the project configuration
+func loadConfig() (*config.Config, error) {
+	cfg := config.DefaultConfig()
+	if err := viper.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// embeddedStoragePath resolves the embedded store file. Relative paths are
+// relative to the directory of the configuration file.
+func embeddedStoragePath(path string) (string, error) {
+	if path == "" {
+		path = DefaultStoragePath
+	}
+	path, err := configRelativePath(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve storage path: %w", err)
+	}
+	return path, nil
+}
+
+// configRelativePath resolves a path of the configuration against the
+// directory of the configuration file, or the working directory without one
+func configRelativePath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	if configFile := viper.ConfigFileUsed(); configFile != "" {
+		if _, err := os.Stat(configFile); err == nil {
+			return filepath.Join(filepath.Dir(configFile), path), nil
+		}
+	}
+	base, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, path), nil
+}
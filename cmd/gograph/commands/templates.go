@@ -16,6 +16,7 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
 )
 
 // templatesCmd represents the templates command
@@ -36,7 +37,33 @@ Templates are organized by category:
   - dependencies: Dependency analysis
   - types: Interfaces and structs
   - calls: Function call analysis
-  - search: Search and find operations`,
+  - search: Search and find operations
+
+Teams can add their own templates as YAML files in .gograph/templates, or in
+the directory set by templates.dir in gograph.yaml. Each file holds one
+template, named after the file:
+
+  name: Hot Functions
+  description: Functions called from many packages
+  category: hotspots
+  query: |
+    MATCH (f:Function {project_id: $project_id})<-[:CALLS]-(c:Function)
+    WITH f, count(DISTINCT c.package) AS packages
+    WHERE packages >= $min_packages
+    RETURN f.name AS name, packages ORDER BY packages DESC
+  parameters:
+    min_packages:
+      type: integer        # string, integer, float, boolean or list
+      description: Minimum number of calling packages
+      default: 3           # or required: true; enum lists allowed values
+
+Templates are checked when loaded: the query must be read-only Cypher that
+uses the node labels and relationship types of the graph, and every
+parameter but $project_id must be declared. A user template replaces the
+built-in template of the same name.`,
+	PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+		return loadUserTemplates()
+	},
 }
 
 // listTemplatesCmd lists all available templates
@@ -88,8 +115,10 @@ var executeTemplateCmd = &cobra.Command{
 	Short: "Execute a query template",
 	Long: `Execute a query template with the specified parameters.
 
-Parameters can be provided as command-line flags or via a JSON file.
-The project ID is automatically added based on the current configuration.`,
+Parameters can be provided as command-line flags or via a YAML or JSON file.
+Values are converted to the types the template declares, and parameters that
+are not given take their defaults. The project ID is automatically added
+based on the current configuration.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runExecuteTemplate,
 	Example: `  # Execute with inline parameters
@@ -141,13 +170,13 @@ func RegisterTemplatesCommand() {
 	showTemplateCmd.Flags().Bool("example", false, "Show example parameter values")
 
 	// Execute command flags
-	executeTemplateCmd.Flags().StringSlice("param", []string{}, "Template parameters in key=value format")
-	executeTemplateCmd.Flags().String("params-file", "", "JSON file containing template parameters")
+	executeTemplateCmd.Flags().StringArray("param", []string{}, "Template parameters in key=value format")
+	executeTemplateCmd.Flags().String("params-file", "", "YAML or JSON file containing template parameters")
 	executeTemplateCmd.Flags().String("project", "", "Project ID (defaults to config)")
 
 	// Export command flags
-	exportTemplateCmd.Flags().StringSlice("param", []string{}, "Template parameters in key=value format")
-	exportTemplateCmd.Flags().String("params-file", "", "JSON file containing template parameters")
+	exportTemplateCmd.Flags().StringArray("param", []string{}, "Template parameters in key=value format")
+	exportTemplateCmd.Flags().String("params-file", "", "YAML or JSON file containing template parameters")
 	exportTemplateCmd.Flags().String("project", "", "Project ID (defaults to config)")
 	exportTemplateCmd.Flags().String("format", "json", "Export format: json, csv, tsv")
 	exportTemplateCmd.Flags().String("output", "", "Output file (defaults to stdout)")
@@ -156,13 +185,35 @@ func RegisterTemplatesCommand() {
 	exportTemplateCmd.Flags().String("delimiter", "", "Custom delimiter for CSV/TSV")
 }
 
-func runListTemplates(_ *cobra.Command, args []string) error {
+// loadUserTemplates registers the templates of the configured directory,
+// which is relative to the configuration file
+func loadUserTemplates() error {
+	dir := viper.GetString("templates.dir")
+	if dir == "" {
+		dir = query.DefaultTemplatesDir
+	}
+	dir, err := configRelativePath(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve templates directory: %w", err)
+	}
+	templates, err := query.LoadTemplates(dir)
+	if err != nil {
+		return err
+	}
+	query.RegisterTemplates(templates)
+	return nil
+}
+
+func runListTemplates(cmd *cobra.Command, args []string) error {
 	var category string
 	if len(args) > 0 {
 		category = args[0]
 	}
 
-	detailed := viper.GetBool("detailed")
+	detailed, err := cmd.Flags().GetBool("detailed")
+	if err != nil {
+		return fmt.Errorf("failed to get detailed flag: %w", err)
+	}
 
 	if category != "" {
 		templates := query.GetTemplatesByCategory(category)
@@ -182,9 +233,12 @@ func runListTemplates(_ *cobra.Command, args []string) error {
 	return nil
 }
 
-func runShowTemplate(_ *cobra.Command, args []string) error {
+func runShowTemplate(cmd *cobra.Command, args []string) error {
 	templateName := args[0]
-	showExample := viper.GetBool("example")
+	showExample, err := cmd.Flags().GetBool("example")
+	if err != nil {
+		return fmt.Errorf("failed to get example flag: %w", err)
+	}
 
 	template, err := query.GetTemplate(templateName)
 	if err != nil {
@@ -193,14 +247,17 @@ func runShowTemplate(_ *cobra.Command, args []string) error {
 
 	fmt.Printf("📋 Template: %s\n", template.Name)
 	fmt.Printf("📂 Category: %s\n", template.Category)
-	fmt.Printf("📝 Description: %s\n\n", template.Description)
+	fmt.Printf("📝 Description: %s\n", template.Description)
+	if template.Source != "" {
+		fmt.Printf("📄 Source: %s\n", template.Source)
+	}
 
-	fmt.Println("🔧 Parameters:")
+	fmt.Println("\n🔧 Parameters:")
 	if len(template.Parameters) == 0 {
 		fmt.Println("  No parameters required")
 	} else {
-		for name, desc := range template.Parameters {
-			fmt.Printf("  %s: %s\n", name, desc)
+		for _, name := range template.ParameterNames() {
+			fmt.Printf("  %s: %s\n", name, template.Parameters[name])
 		}
 	}
 
@@ -209,11 +266,11 @@ func runShowTemplate(_ *cobra.Command, args []string) error {
 	if showExample {
 		fmt.Println("\n💡 Example usage:")
 		fmt.Printf("gograph templates execute %s", templateName)
-		for param := range template.Parameters {
+		for _, param := range template.ParameterNames() {
 			if param == "project_id" {
 				fmt.Printf(" --project myproject")
 			} else {
-				fmt.Printf(" --param %s=example_value", param)
+				fmt.Printf(" --param %s=%s", param, exampleValue(template.Parameters[param]))
 			}
 		}
 		fmt.Println()
@@ -222,7 +279,7 @@ func runShowTemplate(_ *cobra.Command, args []string) error {
 	return nil
 }
 
-func runExecuteTemplate(_ *cobra.Command, args []string) error {
+func runExecuteTemplate(cmd *cobra.Command, args []string) error {
 	templateName := args[0]
 
 	template, err := query.GetTemplate(templateName)
@@ -230,7 +287,7 @@ func runExecuteTemplate(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	params, err := getTemplateParameters()
+	params, err := getTemplateParameters(cmd, template)
 	if err != nil {
 		return err
 	}
@@ -253,9 +310,9 @@ func runExecuteTemplate(_ *cobra.Command, args []string) error {
 		BatchSize:  1000,
 	}
 
-	repo, err := infra.NewNeo4jRepository(neo4jConfig)
+	repo, err := newRepository(cfg, neo4jConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create Neo4j repository: %w", err)
+		return fmt.Errorf("failed to create graph repository: %w", err)
 	}
 	defer repo.Close()
 
@@ -281,7 +338,7 @@ func runExecuteTemplate(_ *cobra.Command, args []string) error {
 	return nil
 }
 
-func runExportTemplate(_ *cobra.Command, args []string) error {
+func runExportTemplate(cmd *cobra.Command, args []string) error {
 	templateName := args[0]
 
 	template, err := query.GetTemplate(templateName)
@@ -289,7 +346,7 @@ func runExportTemplate(_ *cobra.Command, args []string) error {
 		return err
 	}
 
-	params, err := getTemplateParameters()
+	params, err := getTemplateParameters(cmd, template)
 	if err != nil {
 		return err
 	}
@@ -312,9 +369,9 @@ func runExportTemplate(_ *cobra.Command, args []string) error {
 		BatchSize:  1000,
 	}
 
-	repo, err := infra.NewNeo4jRepository(neo4jConfig)
+	repo, err := newRepository(cfg, neo4jConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create Neo4j repository: %w", err)
+		return fmt.Errorf("failed to create graph repository: %w", err)
 	}
 	defer repo.Close()
 
@@ -325,18 +382,18 @@ func runExportTemplate(_ *cobra.Command, args []string) error {
 	}
 
 	// Setup export options
-	format := query.ExportFormat(viper.GetString("format"))
-	options := query.DefaultExportOptions(format)
-	options.Pretty = viper.GetBool("pretty")
-	options.Headers = viper.GetBool("headers")
-	if delimiter := viper.GetString("delimiter"); delimiter != "" {
-		options.Delimiter = delimiter
+	options, err := templateExportOptions(cmd)
+	if err != nil {
+		return err
 	}
 
 	exporter := query.NewExporter(options)
 
 	// Determine output destination
-	outputFile := viper.GetString("output")
+	outputFile, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return fmt.Errorf("failed to get output flag: %w", err)
+	}
 	var writer *os.File
 	if outputFile != "" {
 		writer, err = os.Create(outputFile)
@@ -363,24 +420,52 @@ func runExportTemplate(_ *cobra.Command, args []string) error {
 	return nil
 }
 
-func getTemplateParameters() (map[string]any, error) {
+// templateExportOptions reads the export options of the flags
+func templateExportOptions(cmd *cobra.Command) (*query.ExportOptions, error) {
+	flags := cmd.Flags()
+	format, err := flags.GetString("format")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get format flag: %w", err)
+	}
+	options := query.DefaultExportOptions(query.ExportFormat(format))
+	if options.Pretty, err = flags.GetBool("pretty"); err != nil {
+		return nil, fmt.Errorf("failed to get pretty flag: %w", err)
+	}
+	if options.Headers, err = flags.GetBool("headers"); err != nil {
+		return nil, fmt.Errorf("failed to get headers flag: %w", err)
+	}
+	delimiter, err := flags.GetString("delimiter")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delimiter flag: %w", err)
+	}
+	if delimiter != "" {
+		options.Delimiter = delimiter
+	}
+	return options, nil
+}
+
+// getTemplateParameters reads the parameters of the flags and converts them
+// to the types the template declares
+func getTemplateParameters(cmd *cobra.Command, template *query.Template) (map[string]any, error) {
 	params := make(map[string]any)
 
 	// Add project ID from config or flag
-	projectID := viper.GetString("project")
+	projectID, err := cmd.Flags().GetString("project")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project flag: %w", err)
+	}
 	if projectID == "" {
-		// Try to get from config
-		cfg, err := config.Load("")
-		if err == nil && cfg.Project.Name != "" {
-			projectID = cfg.Project.Name
-		}
+		projectID = viper.GetString("project.id")
 	}
 	if projectID != "" {
 		params["project_id"] = projectID
 	}
 
 	// Load from parameters file if specified
-	paramsFile := viper.GetString("params-file")
+	paramsFile, err := cmd.Flags().GetString("params-file")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get params-file flag: %w", err)
+	}
 	if paramsFile != "" {
 		data, err := os.ReadFile(paramsFile)
 		if err != nil {
@@ -388,7 +473,7 @@ func getTemplateParameters() (map[string]any, error) {
 		}
 
 		var fileParams map[string]any
-		if err := json.Unmarshal(data, &fileParams); err != nil {
+		if err := yaml.Unmarshal(data, &fileParams); err != nil {
 			return nil, fmt.Errorf("failed to parse parameters file: %w", err)
 		}
 
@@ -397,8 +482,11 @@ func getTemplateParameters() (map[string]any, error) {
 		}
 	}
 
-	// Add command-line parameters
-	paramFlags := viper.GetStringSlice("param")
+	// Add command-line parameters, converted along with the others
+	paramFlags, err := cmd.Flags().GetStringArray("param")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get param flag: %w", err)
+	}
 	for _, param := range paramFlags {
 		parts := strings.SplitN(param, "=", 2)
 		if len(parts) != 2 {
@@ -407,7 +495,7 @@ func getTemplateParameters() (map[string]any, error) {
 		params[parts[0]] = parts[1]
 	}
 
-	return params, nil
+	return template.ResolveParameters(params)
 }
 
 func displayTemplates(templates []*query.Template, detailed bool) {
@@ -421,7 +509,10 @@ func displayTemplates(templates []*query.Template, detailed bool) {
 			fmt.Printf("  📋 %s\n", template.Name)
 			fmt.Printf("     %s\n", template.Description)
 			if len(template.Parameters) > 0 {
-				fmt.Printf("     Parameters: %s\n", strings.Join(getParameterNames(template.Parameters), ", "))
+				fmt.Printf("     Parameters: %s\n", strings.Join(template.ParameterNames(), ", "))
+			}
+			if template.Source != "" {
+				fmt.Printf("     Source: %s\n", template.Source)
 			}
 			fmt.Println()
 		} else {
@@ -430,11 +521,23 @@ func displayTemplates(templates []*query.Template, detailed bool) {
 	}
 }
 
-func getParameterNames(parameters map[string]string) []string {
-	names := make([]string, 0, len(parameters))
-	for name := range parameters {
-		names = append(names, name)
+// exampleValue returns a value of a parameter for the usage example
+func exampleValue(parameter query.Parameter) string {
+	switch {
+	case len(parameter.Enum) > 0:
+		return fmt.Sprint(parameter.Enum[0])
+	case parameter.Default != nil && parameter.Type != query.ParameterList:
+		return fmt.Sprint(parameter.Default)
+	}
+	switch parameter.Type {
+	case query.ParameterInteger:
+		return "10"
+	case query.ParameterFloat:
+		return "0.5"
+	case query.ParameterBoolean:
+		return "true"
+	case query.ParameterList:
+		return "a,b"
 	}
-	sort.Strings(names)
-	return names
+	return "example_value"
 }
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/compozy/gograph/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+var testsForCmd = &cobra.Command{
+	Use:   "tests-for",
+	Short: "Select the tests affected by a changeset",
+	Long: `Print the minimal set of Go tests whose reachable code intersects the
+changes of the working tree since a git revision, as go test commands grouped
+by package with a -run pattern.
+
+The changed lines are mapped onto the functions of the working tree, then a
+call graph built from SSA is walked backwards to the Test, Fuzz and Example
+functions reaching them. Interface calls reach every implementing method and
+calls of function values reach every address-taken function of the same
+signature.
+
+Whole packages are selected when reachability is uncertain: package-level
+declarations, package initialization or TestMain are affected, tests call
+functions through reflection, Go files are removed, or test data and other
+non-Go files change. Changes to go.mod, go.sum or go.work select every test.
+
+The project is loaded directly, so no analysis or Neo4j connection is
+required.`,
+	Example: `  # Tests affected by a branch
+  gograph tests-for --since origin/main
+
+  # Run them
+  gograph tests-for --since origin/main | sh
+
+  # Machine-readable selection for a CI matrix
+  gograph tests-for --since origin/main --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return errors.WithRecover("tests_for_command", func() error {
+			projectPath, err := cmd.Flags().GetString("path")
+			if err != nil {
+				return fmt.Errorf("failed to get path flag: %w", err)
+			}
+			since, err := cmd.Flags().GetString("since")
+			if err != nil {
+				return fmt.Errorf("failed to get since flag: %w", err)
+			}
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return fmt.Errorf("failed to get format flag: %w", err)
+			}
+			if format != findingsFormatText && format != formatJSON {
+				return fmt.Errorf("unsupported format %q (use text or json)", format)
+			}
+
+			ctx := context.Background()
+			root, err := filepath.Abs(projectPath)
+			if err != nil {
+				return fmt.Errorf("failed to resolve project path: %w", err)
+			}
+			repoRoot, err := git.TopLevel(ctx, root)
+			if err != nil {
+				return err
+			}
+			files, err := git.DiffSince(ctx, root, since)
+			if err != nil {
+				return err
+			}
+
+			selection, err := analyzer.SelectTests(ctx, root, repoRoot, files)
+			if err != nil {
+				return fmt.Errorf("failed to select tests: %w", err)
+			}
+			return writeTestSelection(cmd.OutOrStdout(), format, selection)
+		})
+	},
+}
+
+var initTestsForOnce sync.Once
+
+// InitTestsForCommand registers the tests-for command
+func InitTestsForCommand() {
+	initTestsForOnce.Do(func() {
+		rootCmd.AddCommand(testsForCmd)
+
+		testsForCmd.Flags().String("since", "", "Select tests for the changes of the working tree since this git revision (required)")
+		testsForCmd.Flags().String("path", ".", "Path of the Go module inside the repository")
+		testsForCmd.Flags().String("format", findingsFormatText, "Output format: text or json")
+		_ = testsForCmd.MarkFlagRequired("since")
+	})
+}
+
+func writeTestSelection(w io.Writer, format string, selection *analyzer.TestSelection) error {
+	if format == formatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(selection)
+	}
+	return selection.WriteText(w)
+}
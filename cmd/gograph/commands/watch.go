@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/pkg/config"
+	"github.com/compozy/gograph/pkg/errors"
+	"github.com/compozy/gograph/pkg/logger"
+	"github.com/compozy/gograph/pkg/watch"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [path]",
+	Short: "Keep the stored graph up to date while editing",
+	Long: `Watch the Go files of a project and update the stored graph as they change.
+
+Bursts of saves are collected until the project has been quiet for the
+debounce interval. Only the packages of the touched directories are parsed
+again, and their nodes and relationships are updated in the graph store in place, so
+queries and MCP tools answer against the code as it is now. Nodes keep their
+IDs when they still exist, and packages whose directories lose all their Go
+files are removed.
+
+A package that does not compile keeps its previous graph until it does again.
+Calls and implementations from a changed package into other packages are
+refreshed by the next 'gograph analyze'. When no analysis is stored yet, the
+whole project is analyzed first.`,
+	Example: `  # Keep the graph of the current project live
+  gograph watch
+
+  # Wait for a second of quiet before updating
+  gograph watch ./my-project --debounce 1s`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.WithRecover("watch_command", func() error {
+			projectPath := "."
+			if len(args) > 0 {
+				projectPath = args[0]
+			}
+			debounce, err := cmd.Flags().GetDuration("debounce")
+			if err != nil {
+				return fmt.Errorf("failed to get debounce flag: %w", err)
+			}
+			project, err := cmd.Flags().GetString("project")
+			if err != nil {
+				return fmt.Errorf("failed to get project flag: %w", err)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return watchProject(ctx, projectPath, core.ID(project), debounce)
+		})
+	},
+}
+
+var initWatchOnce sync.Once
+
+// InitWatchCommand registers the watch command
+func InitWatchCommand() {
+	initWatchOnce.Do(func() {
+		rootCmd.AddCommand(watchCmd)
+
+		watchCmd.Flags().Duration("debounce", watch.DefaultDebounce, "Quiet period that ends a burst of changes")
+		watchCmd.Flags().StringP("project", "p", "", "Project ID (defaults to current project)")
+	})
+}
+
+// watchProject updates the stored graph of a project as its Go files change
+// until ctx is done
+func watchProject(ctx context.Context, projectPath string, projectID core.ID, debounce time.Duration) error {
+	root, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project path: %w", err)
+	}
+	cfg, err := config.LoadProjectConfig(root)
+	if err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
+	}
+	if projectID == "" {
+		projectID = core.ID(cfg.Project.ID)
+	}
+
+	repo, err := newRepository(cfg, neo4jConfigFromConfig(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to create graph repository: %w", err)
+	}
+	defer repo.Close()
+
+	stored, err := loadOrAnalyzeProject(ctx, repo, root, projectID, cfg)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := watch.New(root, cfg.Analysis.IgnoreDirs, debounce)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	logger.Info("watching project for changes", "path", root, "project", projectID)
+	return watcher.Run(ctx, func(dirs []string) {
+		err := errors.WithRecover("watch_refresh", func() error {
+			return refreshPackages(ctx, repo, root, projectID, cfg, stored, dirs)
+		})
+		if err != nil {
+			logger.Warn("keeping previous graph", "error", err)
+		}
+	})
+}
+
+// loadOrAnalyzeProject loads the stored graph of a project, analyzing and
+// storing the project first when there is none
+func loadOrAnalyzeProject(
+	ctx context.Context,
+	repo graph.Repository,
+	root string,
+	projectID core.ID,
+	cfg *config.Config,
+) (*core.AnalysisResult, error) {
+	stored, err := graph.LoadAnalysisResult(ctx, repo, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project graph: %w", err)
+	}
+	if len(stored.Nodes) > 0 {
+		return stored, nil
+	}
+
+	logger.Info("no analysis stored, analyzing project", "path", root)
+	result, err := buildProjectGraph(ctx, root, projectID, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := storeAnalysis(ctx, repo, result, cfg.Snapshots.Keep); err != nil {
+		return nil, fmt.Errorf("failed to store analysis: %w", err)
+	}
+	return result, nil
+}
+
+// refreshPackages parses the packages of the changed directories again and
+// writes the difference to the stored graph
+func refreshPackages(
+	ctx context.Context,
+	repo graph.Repository,
+	root string,
+	projectID core.ID,
+	cfg *config.Config,
+	stored *core.AnalysisResult,
+	dirs []string,
+) error {
+	var patterns []string
+	for _, dir := range dirs {
+		if !hasGoFiles(dir, cfg.Analysis.IncludeTests) {
+			continue
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve package directory: %w", err)
+		}
+		patterns = append(patterns, "./"+filepath.ToSlash(rel))
+	}
+
+	rebuilt := &core.AnalysisResult{ProjectID: projectID}
+	if len(patterns) > 0 {
+		var err error
+		rebuilt, err = buildPackagesGraph(ctx, root, projectID, cfg, patterns)
+		if err != nil {
+			return err
+		}
+	}
+
+	patch := graph.PatchDirectories(stored, rebuilt, dirs)
+	if patch.Empty() {
+		logger.Debug("graph is up to date", "packages", len(dirs))
+		return nil
+	}
+	if err := graph.ApplyPatch(ctx, repo, patch); err != nil {
+		// The store may hold part of the patch, so compare against it next time
+		if reloaded, loadErr := graph.LoadAnalysisResult(ctx, repo, projectID); loadErr == nil {
+			*stored = *reloaded
+		}
+		return fmt.Errorf("failed to update graph: %w", err)
+	}
+	patch.ApplyTo(stored)
+	logger.Info("graph updated",
+		"packages", len(dirs),
+		"created", len(patch.CreateNodes),
+		"updated", len(patch.UpdateNodes),
+		"deleted", len(patch.DeleteNodes))
+	return nil
+}
+
+// hasGoFiles reports whether a directory still holds a package to parse
+func hasGoFiles(dir string, includeTests bool) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".go") && (includeTests || !strings.HasSuffix(name, "_test.go")) {
+			return true
+		}
+	}
+	return false
+}
@@ -19,6 +19,9 @@ type Analyzer interface {
 
 	// DetectCircularDependencies identifies circular import cycles
 	DetectCircularDependencies(ctx context.Context, graph *DependencyGraph) ([]*CircularDependency, error)
+
+	// CheckRules evaluates architecture rules against a dependency graph
+	CheckRules(ctx context.Context, graph *DependencyGraph, rules *RuleSet) ([]*RuleViolation, error)
 }
 
 // AnalysisInput contains the input data for analysis
@@ -55,6 +58,7 @@ type DependencyEdge struct {
 	From string         // Source path
 	To   string         // Target path
 	Type DependencyType // Import type
+	File string         // File containing the import
 	Line int            // Line number of import
 }
 
@@ -108,6 +112,44 @@ const (
 	SeverityHigh   SeverityLevel = "high"
 )
 
+// RuleSet describes the architecture rules a project must satisfy
+type RuleSet struct {
+	Layers []*LayerRule // Layer definitions and their allowed dependencies
+}
+
+// LayerRule maps packages to a named layer and restricts what it may import.
+// Package patterns follow the go tool convention: "..." matches any string,
+// "*" matches a single path element, and a pattern may match any trailing
+// portion of an import path (e.g. "cmd/..." matches "example.com/app/cmd/app").
+type LayerRule struct {
+	Name            string   // Layer name
+	Packages        []string // Package patterns belonging to the layer
+	MayDependOn     []string // Layers this layer may import ("*" for any), empty means unrestricted
+	MustNotDependOn []string // Layers this layer must never import
+}
+
+// RuleKind identifies the rule that produced a violation
+type RuleKind string
+
+const (
+	RuleKindLayer           RuleKind = "layer"
+	RuleKindDependencyDepth RuleKind = "dependency_depth"
+)
+
+// RuleViolation represents a dependency that breaks an architecture rule
+type RuleViolation struct {
+	Rule      RuleKind      // Rule that was violated
+	From      string        // Importing package
+	To        string        // Imported package
+	FromLayer string        // Layer of the importing package
+	ToLayer   string        // Layer of the imported package
+	File      string        // File containing the offending import
+	Line      int           // Line number of the offending import
+	Chain     []string      // Dependency chain for depth violations
+	Severity  SeverityLevel // Violation severity
+	Message   string        // Human readable description
+}
+
 // AnalysisReport contains comprehensive analysis results
 type AnalysisReport struct {
 	ProjectID                string                   // Project identifier
@@ -0,0 +1,239 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// anyLayer allows a layer to depend on every other layer
+const anyLayer = "*"
+
+// CheckRules evaluates architecture rules against a dependency graph.
+// Layer rules are checked for every import edge; the configured
+// MaxDependencyDepth is enforced as an additional rule when greater than zero.
+func (s *service) CheckRules(_ context.Context, graph *DependencyGraph, rules *RuleSet) ([]*RuleViolation, error) {
+	if graph == nil {
+		return nil, fmt.Errorf("dependency graph is required")
+	}
+	if rules == nil {
+		rules = &RuleSet{}
+	}
+
+	matchers, err := compileLayers(rules.Layers)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]*RuleViolation, 0)
+	for _, edge := range graph.Edges {
+		if violation := checkLayerEdge(matchers, edge); violation != nil {
+			violations = append(violations, violation)
+		}
+	}
+
+	if s.config.MaxDependencyDepth > 0 {
+		violations = append(violations, s.checkDependencyDepth(graph)...)
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].File != violations[j].File {
+			return violations[i].File < violations[j].File
+		}
+		return violations[i].Line < violations[j].Line
+	})
+
+	return violations, nil
+}
+
+// layerMatcher pairs a layer rule with its compiled package patterns
+type layerMatcher struct {
+	rule     *LayerRule
+	patterns []*regexp.Regexp
+}
+
+// compileLayers validates layer rules and compiles their package patterns
+func compileLayers(layers []*LayerRule) ([]*layerMatcher, error) {
+	names := make(map[string]bool, len(layers))
+	for _, layer := range layers {
+		if layer.Name == "" {
+			return nil, fmt.Errorf("layer name is required")
+		}
+		if names[layer.Name] {
+			return nil, fmt.Errorf("duplicate layer %q", layer.Name)
+		}
+		names[layer.Name] = true
+	}
+
+	matchers := make([]*layerMatcher, 0, len(layers))
+	for _, layer := range layers {
+		for _, ref := range append(append([]string{}, layer.MayDependOn...), layer.MustNotDependOn...) {
+			if ref != anyLayer && !names[ref] {
+				return nil, fmt.Errorf("layer %q references unknown layer %q", layer.Name, ref)
+			}
+		}
+
+		matcher := &layerMatcher{rule: layer}
+		for _, pattern := range layer.Packages {
+			re, err := CompilePackagePattern(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid package pattern %q in layer %q: %w", pattern, layer.Name, err)
+			}
+			matcher.patterns = append(matcher.patterns, re)
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// CompilePackagePattern converts a go-style package pattern such as
+// "internal/..." or "*/api" into a regular expression matching package paths
+func CompilePackagePattern(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	// "foo/..." also matches "foo" itself, as with the go tool
+	suffix := ""
+	if strings.HasSuffix(pattern, "/...") {
+		pattern = strings.TrimSuffix(pattern, "/...")
+		suffix = "(?:/.*)?"
+	}
+
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\.\.\.`, `.*`)
+	expr = strings.ReplaceAll(expr, `\*`, `[^/]*`)
+
+	return regexp.Compile("^(?:.*/)?" + expr + suffix + "$")
+}
+
+// layerOf returns the first layer whose patterns match the package path
+func layerOf(matchers []*layerMatcher, pkgPath string) *LayerRule {
+	for _, matcher := range matchers {
+		for _, re := range matcher.patterns {
+			if re.MatchString(pkgPath) {
+				return matcher.rule
+			}
+		}
+	}
+	return nil
+}
+
+// checkLayerEdge reports an import that crosses layers in a forbidden direction
+func checkLayerEdge(matchers []*layerMatcher, edge *DependencyEdge) *RuleViolation {
+	from := layerOf(matchers, edge.From)
+	to := layerOf(matchers, edge.To)
+	if from == nil || to == nil || from.Name == to.Name {
+		return nil
+	}
+
+	var reason string
+	switch {
+	case containsString(from.MustNotDependOn, to.Name) || containsString(from.MustNotDependOn, anyLayer):
+		reason = fmt.Sprintf("layer %q must not depend on layer %q", from.Name, to.Name)
+	case len(from.MayDependOn) > 0 &&
+		!containsString(from.MayDependOn, to.Name) &&
+		!containsString(from.MayDependOn, anyLayer):
+		reason = fmt.Sprintf("layer %q may only depend on %s", from.Name, strings.Join(from.MayDependOn, ", "))
+	default:
+		return nil
+	}
+
+	return &RuleViolation{
+		Rule:      RuleKindLayer,
+		From:      edge.From,
+		To:        edge.To,
+		FromLayer: from.Name,
+		ToLayer:   to.Name,
+		File:      edge.File,
+		Line:      edge.Line,
+		Severity:  SeverityHigh,
+		Message:   fmt.Sprintf("%s imports %s: %s", edge.From, edge.To, reason),
+	}
+}
+
+// checkDependencyDepth reports packages whose longest internal import chain
+// exceeds the configured maximum. Cycles are left to DetectCircularDependencies.
+func (s *service) checkDependencyDepth(graph *DependencyGraph) []*RuleViolation {
+	edges := make(map[string]*DependencyEdge, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		edges[edge.From+"->"+edge.To] = edge
+	}
+
+	// A chain that skipped a package already on the stack depends on the path
+	// it was reached by, so only chains found without hitting a cycle are memoized.
+	longest := make(map[string][]string)
+	visiting := make(map[string]bool)
+	var chainFrom func(pkg string) ([]string, bool)
+	chainFrom = func(pkg string) ([]string, bool) {
+		if chain, done := longest[pkg]; done {
+			return chain, false
+		}
+		visiting[pkg] = true
+		best := []string{pkg}
+		cyclic := false
+		for _, dep := range graph.Nodes[pkg].Dependencies {
+			if _, internal := graph.Nodes[dep]; !internal {
+				continue
+			}
+			if visiting[dep] {
+				cyclic = true
+				continue
+			}
+			chain, depCyclic := chainFrom(dep)
+			cyclic = cyclic || depCyclic
+			if len(chain)+1 > len(best) {
+				best = append([]string{pkg}, chain...)
+			}
+		}
+		visiting[pkg] = false
+		if !cyclic {
+			longest[pkg] = best
+		}
+		return best, cyclic
+	}
+
+	paths := make([]string, 0, len(graph.Nodes))
+	for path := range graph.Nodes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var violations []*RuleViolation
+	for _, pkg := range paths {
+		chain, _ := chainFrom(pkg)
+		depth := len(chain) - 1
+		if depth <= s.config.MaxDependencyDepth {
+			continue
+		}
+
+		violation := &RuleViolation{
+			Rule:     RuleKindDependencyDepth,
+			From:     pkg,
+			To:       chain[1],
+			Chain:    chain,
+			Severity: SeverityMedium,
+			Message: fmt.Sprintf("%s has a dependency chain of depth %d (max %d): %s",
+				pkg, depth, s.config.MaxDependencyDepth, strings.Join(chain, " -> ")),
+		}
+		if edge, ok := edges[pkg+"->"+chain[1]]; ok {
+			violation.File = edge.File
+			violation.Line = edge.Line
+		}
+		violations = append(violations, violation)
+	}
+	return violations
+}
+
+// containsString reports whether values contains target
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package analyzer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func layeredGraph() *analyzer.DependencyGraph {
+	return &analyzer.DependencyGraph{
+		Nodes: map[string]*analyzer.DependencyNode{
+			"example.com/app/cmd/app":      {Dependencies: []string{"example.com/app/domain", "example.com/app/infra"}},
+			"example.com/app/domain":       {Dependencies: []string{"example.com/app/infra/db", "fmt"}},
+			"example.com/app/infra":        {Dependencies: []string{"example.com/app/domain"}},
+			"example.com/app/infra/db":     {Dependencies: []string{}},
+			"example.com/app/internal/ext": {Dependencies: []string{}},
+		},
+		Edges: []*analyzer.DependencyEdge{
+			{From: "example.com/app/cmd/app", To: "example.com/app/domain", File: "cmd/app/main.go", Line: 4},
+			{From: "example.com/app/cmd/app", To: "example.com/app/infra", File: "cmd/app/main.go", Line: 5},
+			{From: "example.com/app/domain", To: "example.com/app/infra/db", File: "domain/user.go", Line: 7},
+			{From: "example.com/app/domain", To: "fmt", File: "domain/user.go", Line: 6},
+			{From: "example.com/app/infra", To: "example.com/app/domain", File: "infra/repo.go", Line: 3},
+		},
+	}
+}
+
+func TestService_CheckRules(t *testing.T) {
+	ctx := context.Background()
+	rules := &analyzer.RuleSet{
+		Layers: []*analyzer.LayerRule{
+			{Name: "cmd", Packages: []string{"cmd/..."}, MayDependOn: []string{"*"}},
+			{Name: "domain", Packages: []string{"domain/..."}, MustNotDependOn: []string{"infra"}},
+			{Name: "infra", Packages: []string{"infra/..."}, MayDependOn: []string{"domain"}},
+		},
+	}
+
+	t.Run("Should report imports that break layer rules with file and line", func(t *testing.T) {
+		service := analyzer.NewAnalyzer(&analyzer.Config{})
+
+		violations, err := service.CheckRules(ctx, layeredGraph(), rules)
+
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		violation := violations[0]
+		assert.Equal(t, analyzer.RuleKindLayer, violation.Rule)
+		assert.Equal(t, "example.com/app/domain", violation.From)
+		assert.Equal(t, "example.com/app/infra/db", violation.To)
+		assert.Equal(t, "domain", violation.FromLayer)
+		assert.Equal(t, "infra", violation.ToLayer)
+		assert.Equal(t, "domain/user.go", violation.File)
+		assert.Equal(t, 7, violation.Line)
+		assert.Contains(t, violation.Message, `layer "domain" must not depend on layer "infra"`)
+	})
+
+	t.Run("Should enforce allow lists", func(t *testing.T) {
+		service := analyzer.NewAnalyzer(&analyzer.Config{})
+		strict := &analyzer.RuleSet{
+			Layers: []*analyzer.LayerRule{
+				{Name: "cmd", Packages: []string{"cmd/*"}, MayDependOn: []string{"domain"}},
+				{Name: "domain", Packages: []string{"domain"}},
+				{Name: "infra", Packages: []string{"infra", "infra/..."}},
+			},
+		}
+
+		violations, err := service.CheckRules(ctx, layeredGraph(), strict)
+
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Equal(t, "example.com/app/infra", violations[0].To)
+		assert.Equal(t, 5, violations[0].Line)
+	})
+
+	t.Run("Should enforce max dependency depth", func(t *testing.T) {
+		service := analyzer.NewAnalyzer(&analyzer.Config{MaxDependencyDepth: 2})
+
+		violations, err := service.CheckRules(ctx, layeredGraph(), nil)
+
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		violation := violations[0]
+		assert.Equal(t, analyzer.RuleKindDependencyDepth, violation.Rule)
+		assert.Equal(t, "example.com/app/cmd/app", violation.From)
+		assert.Equal(t, []string{
+			"example.com/app/cmd/app",
+			"example.com/app/infra",
+			"example.com/app/domain",
+			"example.com/app/infra/db",
+		}, violation.Chain)
+		assert.Equal(t, "cmd/app/main.go", violation.File)
+		assert.Equal(t, 5, violation.Line)
+	})
+
+	t.Run("Should measure depth from every package of an import cycle", func(t *testing.T) {
+		service := analyzer.NewAnalyzer(&analyzer.Config{MaxDependencyDepth: 1})
+		cycle := &analyzer.DependencyGraph{
+			Nodes: map[string]*analyzer.DependencyNode{
+				"example.com/app/a": {Dependencies: []string{"example.com/app/b"}},
+				"example.com/app/b": {Dependencies: []string{"example.com/app/c"}},
+				"example.com/app/c": {Dependencies: []string{"example.com/app/a"}},
+			},
+		}
+
+		violations, err := service.CheckRules(ctx, cycle, nil)
+
+		require.NoError(t, err)
+		require.Len(t, violations, 3)
+		for _, violation := range violations {
+			assert.Len(t, violation.Chain, 3)
+		}
+	})
+
+	t.Run("Should skip depth check when disabled", func(t *testing.T) {
+		service := analyzer.NewAnalyzer(&analyzer.Config{MaxDependencyDepth: 0})
+
+		violations, err := service.CheckRules(ctx, layeredGraph(), nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("Should reject references to unknown layers", func(t *testing.T) {
+		service := analyzer.NewAnalyzer(nil)
+		invalid := &analyzer.RuleSet{
+			Layers: []*analyzer.LayerRule{
+				{Name: "domain", Packages: []string{"domain"}, MustNotDependOn: []string{"infra"}},
+			},
+		}
+
+		_, err := service.CheckRules(ctx, layeredGraph(), invalid)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown layer "infra"`)
+	})
+}
@@ -92,35 +92,35 @@ func (s *service) BuildDependencyGraph(_ context.Context, packages []*parser.Pac
 			Dependents:   []string{},
 		}
 
-		// Extract unique dependencies from all files
-		depMap := make(map[string]bool)
+		// Extract unique dependencies from all files, remembering where each
+		// one is first imported so rule violations can point at a source line
+		sites := make(map[string]*DependencyEdge)
 		for _, file := range pkg.Files {
 			for _, imp := range file.Imports {
-				depMap[imp.Path] = true
+				if _, seen := sites[imp.Path]; seen {
+					continue
+				}
+				sites[imp.Path] = &DependencyEdge{
+					From: pkg.Path,
+					To:   imp.Path,
+					Type: DependencyTypeImport,
+					File: file.Path,
+					Line: imp.Line,
+				}
+				node.Dependencies = append(node.Dependencies, imp.Path)
 			}
 		}
 
-		for dep := range depMap {
-			node.Dependencies = append(node.Dependencies, dep)
-		}
-
 		graph.Nodes[pkg.Path] = node
-	}
-
-	// Build edges and update dependents
-	for pkgPath, node := range graph.Nodes {
 		for _, dep := range node.Dependencies {
-			edge := &DependencyEdge{
-				From: pkgPath,
-				To:   dep,
-				Type: DependencyTypeImport,
-			}
-			graph.Edges = append(graph.Edges, edge)
+			graph.Edges = append(graph.Edges, sites[dep])
+		}
+	}
 
-			// Update dependents if the dependency is in our graph
-			if depNode, exists := graph.Nodes[dep]; exists {
-				depNode.Dependents = append(depNode.Dependents, pkgPath)
-			}
+	// Update dependents for dependencies that are part of the graph
+	for _, edge := range graph.Edges {
+		if depNode, exists := graph.Nodes[edge.To]; exists {
+			depNode.Dependents = append(depNode.Dependents, edge.From)
 		}
 	}
 
@@ -454,7 +454,33 @@ func (s *service) calculateMetrics(result *parser.ParseResult) *CodeMetrics {
 			}
 			metrics.TotalLines += maxLine
 		}
+		addComplexity(metrics.CyclomaticComplexity, pkg)
 	}
 
 	return metrics
 }
+
+// addComplexity records the complexity of the package's functions and methods
+// under their qualified names, e.g. "example.com/app.Service.Save"
+func addComplexity(complexity map[string]int, pkg *parser.PackageInfo) {
+	for _, fn := range pkg.Functions {
+		name := pkg.Path + "." + fn.Name
+		if fn.Receiver != nil {
+			name = pkg.Path + "." + ReceiverTypeName(fn.Receiver.Name) + "." + fn.Name
+		}
+		complexity[name] = fn.Complexity
+	}
+}
+
+// ReceiverTypeName returns the bare type name of a method receiver such as
+// *example.com/app/store.Mem[T]
+func ReceiverTypeName(receiver string) string {
+	name := strings.TrimPrefix(receiver, "*")
+	if index := strings.IndexByte(name, '['); index >= 0 {
+		name = name[:index]
+	}
+	if index := strings.LastIndexByte(name, '.'); index >= 0 {
+		name = name[index+1:]
+	}
+	return name
+}
@@ -261,8 +261,8 @@ func TestService_BuildDependencyGraph(t *testing.T) {
 						Path:    "/project/cmd/main.go",
 						Package: "main",
 						Imports: []*parser.ImportInfo{
-							{Path: "internal/server"},
-							{Path: "pkg/utils"},
+							{Path: "internal/server", Line: 4},
+							{Path: "pkg/utils", Line: 5},
 						},
 					},
 				},
@@ -342,6 +342,8 @@ func TestService_BuildDependencyGraph(t *testing.T) {
 		assert.Equal(t, "cmd/main", mainUtilsEdge.From)
 		assert.Equal(t, "pkg/utils", mainUtilsEdge.To)
 		assert.Equal(t, analyzer.DependencyTypeImport, mainUtilsEdge.Type)
+		assert.Equal(t, "/project/cmd/main.go", mainUtilsEdge.File)
+		assert.Equal(t, 5, mainUtilsEdge.Line)
 
 		// Check server -> utils edge
 		serverUtilsEdge, exists := edgeMap["internal/server->pkg/utils"]
@@ -639,8 +641,9 @@ func TestService_AnalyzeProject_WithMetrics(t *testing.T) {
 						},
 					},
 					Functions: []*parser.FunctionInfo{
-						{Name: "main"},
-						{Name: "helper"},
+						{Name: "main", Complexity: 1},
+						{Name: "helper", Complexity: 4},
+						{Name: "Load", Complexity: 2, Receiver: &parser.TypeInfo{Name: "*main.Config"}},
 					},
 					Types: []*parser.TypeInfo{
 						{
@@ -665,9 +668,11 @@ func TestService_AnalyzeProject_WithMetrics(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, report.Metrics)
 		assert.Equal(t, 1, report.Metrics.TotalFiles)
-		assert.Equal(t, 2, report.Metrics.TotalFunctions)
+		assert.Equal(t, 3, report.Metrics.TotalFunctions)
 		assert.Equal(t, 1, report.Metrics.TotalInterfaces)
 		assert.Equal(t, 1, report.Metrics.TotalStructs)
 		assert.GreaterOrEqual(t, report.Metrics.TotalLines, 0)
+		assert.Equal(t, map[string]int{"main.main": 1, "main.helper": 4, "main.Config.Load": 2},
+			report.Metrics.CyclomaticComplexity)
 	})
 }
@@ -0,0 +1,739 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/compozy/gograph/pkg/git"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// moduleFiles are files whose changes may affect every package of a module
+var moduleFiles = map[string]bool{"go.mod": true, "go.sum": true, "go.work": true, "go.work.sum": true}
+
+// reflectiveCalls are the reflect methods through which code calls functions
+// the call graph cannot see
+var reflectiveCalls = map[string]bool{
+	"(reflect.Value).Call":         true,
+	"(reflect.Value).CallSlice":    true,
+	"(reflect.Value).Method":       true,
+	"(reflect.Value).MethodByName": true,
+}
+
+// TestSelection is the set of tests to run for a changeset
+type TestSelection struct {
+	All      bool            `json:"all"`              // Every test of the module must run
+	Reason   string          `json:"reason,omitempty"` // Why every test must run
+	Packages []*PackageTests `json:"packages"`
+}
+
+// PackageTests are the selected tests of a package
+type PackageTests struct {
+	Package string   `json:"package"`
+	Tests   []string `json:"tests,omitempty"`
+	Run     string   `json:"run,omitempty"`    // -run pattern matching Tests
+	Whole   bool     `json:"whole"`            // Every test of the package must run
+	Reason  string   `json:"reason,omitempty"` // Why every test of the package must run
+}
+
+// SelectTests returns the tests of the module at projectPath whose reachable
+// code intersects a changeset. Diff paths are relative to repoRoot.
+//
+// Changed lines are mapped onto the functions of the working tree, then a
+// call graph built from SSA is walked backwards to the Test, Fuzz and Example
+// functions reaching them. Interface calls go to every method implementing
+// the interface, calls of function values go to every address-taken function
+// of the same signature, and converting a type to an interface reaches all of
+// its methods. Whole packages are selected when reachability is uncertain:
+// package-level declarations, initialization or TestMain are affected, tests
+// call functions through reflection, or non-Go files such as testdata change.
+// Changes to go.mod, go.sum or go.work select every test.
+func SelectTests(ctx context.Context, projectPath, repoRoot string, files []*git.FileDiff) (*TestSelection, error) {
+	for _, file := range files {
+		for _, path := range []string{file.OldPath, file.NewPath} {
+			if moduleFiles[filepath.Base(path)] {
+				return &TestSelection{All: true, Reason: path + " changed", Packages: []*PackageTests{}}, nil
+			}
+		}
+	}
+
+	pkgs, err := loadTestPackages(ctx, projectPath)
+	if err != nil {
+		return nil, err
+	}
+	s := newTestSelector(pkgs)
+	s.buildCallGraph()
+	for _, file := range files {
+		s.classifyFile(projectPath, repoRoot, file)
+	}
+	return s.selection(), nil
+}
+
+// loadTestPackages loads the packages of a module together with their test
+// variants, leaving out the generated test mains.
+//
+// Test selection builds its own SSA program rather than reusing the call
+// chains of AnalyzeProject. Those come from an RTA call graph rooted at main
+// functions, which leaves out test functions and the code only tests reach,
+// and lack test variants when analysis.include_tests is off. Selection also
+// needs edges RTA does not record, such as every address-taken function of a
+// signature, so that it errs towards running a test rather than skipping it.
+func loadTestPackages(ctx context.Context, projectPath string) ([]*packages.Package, error) {
+	pkgConfig := &packages.Config{
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedCompiledGoFiles |
+			packages.NeedEmbedFiles |
+			packages.NeedImports |
+			packages.NeedDeps |
+			packages.NeedTypes |
+			packages.NeedTypesInfo |
+			packages.NeedSyntax,
+		Context: ctx,
+		Dir:     projectPath,
+		Tests:   true,
+	}
+	pkgs, err := packages.Load(pkgConfig, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	var loadErrors []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			loadErrors = append(loadErrors, e.Error())
+		}
+	})
+	if len(loadErrors) > 0 {
+		return nil, fmt.Errorf("package loading failed with %d errors: %v", len(loadErrors), loadErrors)
+	}
+
+	filtered := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if !strings.HasSuffix(pkg.ID, ".test") {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered, nil
+}
+
+// syntaxFile is a parsed file of a loaded package
+type syntaxFile struct {
+	pkg  *packages.Package
+	file *ast.File
+}
+
+// invokeSite is an interface method call awaiting resolution
+type invokeSite struct {
+	caller *ssa.Function
+	common *ssa.CallCommon
+}
+
+// methodImpl is a concrete method that interface calls may dispatch to
+type methodImpl struct {
+	recv types.Type // Receiver type, nil for methods of generic types
+	fn   *ssa.Function
+}
+
+// testPackage groups the tests of a package and its external _test package
+type testPackage struct {
+	tests   map[string]*ssa.Function
+	main    *ssa.Function // TestMain, if any
+	imports map[string]bool
+}
+
+// testSelector maps changes onto a call graph of the project functions
+type testSelector struct {
+	pkgs    []*packages.Package
+	prog    *ssa.Program
+	project map[*ssa.Package]bool
+	files   map[string][]*syntaxFile       // Absolute file name to its parses, one per package variant
+	dirs    map[string][]*packages.Package // Directory to the packages compiled from it
+	embeds  map[string][]*packages.Package // Absolute file name to the packages embedding it
+	callers map[*ssa.Function]map[*ssa.Function]bool
+	// Functions calling through reflection
+	reflective map[*ssa.Function]bool
+
+	changed      map[*ssa.Function]bool
+	changedPkgs  map[string]bool   // Paths of packages containing changes
+	declChanges  map[string]string // Package path to reason, for every package importing it
+	wholeChanges map[string]string // Test package path to reason, for that package only
+}
+
+func newTestSelector(pkgs []*packages.Package) *testSelector {
+	prog, ssaPkgs := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	s := &testSelector{
+		pkgs:         pkgs,
+		prog:         prog,
+		project:      make(map[*ssa.Package]bool),
+		files:        make(map[string][]*syntaxFile),
+		dirs:         make(map[string][]*packages.Package),
+		embeds:       make(map[string][]*packages.Package),
+		callers:      make(map[*ssa.Function]map[*ssa.Function]bool),
+		reflective:   make(map[*ssa.Function]bool),
+		changed:      make(map[*ssa.Function]bool),
+		changedPkgs:  make(map[string]bool),
+		declChanges:  make(map[string]string),
+		wholeChanges: make(map[string]string),
+	}
+	// Only project packages are built: dependencies stay bodiless, so calls
+	// into them end the walk
+	for _, ssaPkg := range ssaPkgs {
+		if ssaPkg != nil {
+			ssaPkg.Build()
+			s.project[ssaPkg] = true
+		}
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			name := pkg.Fset.Position(file.Package).Filename
+			s.files[name] = append(s.files[name], &syntaxFile{pkg: pkg, file: file})
+		}
+		dirs := make(map[string]bool)
+		for _, name := range pkg.GoFiles {
+			dirs[filepath.Dir(name)] = true
+		}
+		for dir := range dirs {
+			s.dirs[dir] = append(s.dirs[dir], pkg)
+		}
+		for _, name := range pkg.EmbedFiles {
+			s.embeds[name] = append(s.embeds[name], pkg)
+		}
+	}
+	return s
+}
+
+// callGraphNode returns the function that stands for fn in the call graph:
+// generic instances are merged into their origin
+func callGraphNode(fn *ssa.Function) *ssa.Function {
+	if origin := fn.Origin(); origin != nil {
+		return origin
+	}
+	return fn
+}
+
+// inGraph reports whether fn belongs to the project or is a synthetic
+// wrapper that may lead back into it
+func (s *testSelector) inGraph(fn *ssa.Function) bool {
+	fn = callGraphNode(fn)
+	if fn.Pkg != nil {
+		return s.project[fn.Pkg]
+	}
+	return fn.Synthetic != ""
+}
+
+func (s *testSelector) addEdge(caller, callee *ssa.Function) {
+	caller, callee = callGraphNode(caller), callGraphNode(callee)
+	if caller == callee || !s.inGraph(callee) {
+		return
+	}
+	if s.callers[callee] == nil {
+		s.callers[callee] = make(map[*ssa.Function]bool)
+	}
+	s.callers[callee][caller] = true
+}
+
+// methodsOf returns the concrete methods of a named type and its pointer
+func (s *testSelector) methodsOf(typ types.Type) []*methodImpl {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := typ.(*types.Named)
+	if !ok || types.IsInterface(named) {
+		return nil
+	}
+	var methods []*methodImpl
+	if named.TypeParams().Len() > 0 || named.TypeArgs().Len() > 0 {
+		origin := named.Origin()
+		for i := 0; i < origin.NumMethods(); i++ {
+			if fn := s.prog.FuncValue(origin.Method(i)); fn != nil {
+				methods = append(methods, &methodImpl{fn: fn})
+			}
+		}
+		return methods
+	}
+	for _, recv := range []types.Type{named, types.NewPointer(named)} {
+		mset := s.prog.MethodSets.MethodSet(recv)
+		for i := 0; i < mset.Len(); i++ {
+			if fn := s.prog.MethodValue(mset.At(i)); fn != nil {
+				methods = append(methods, &methodImpl{recv: recv, fn: fn})
+			}
+		}
+	}
+	return methods
+}
+
+// buildCallGraph records the callers of every project function reachable
+// from the members of the project packages
+func (s *testSelector) buildCallGraph() {
+	seen := make(map[*ssa.Function]bool)
+	var queue []*ssa.Function
+	visit := func(fn *ssa.Function) {
+		if fn != nil && !seen[fn] && s.inGraph(fn) {
+			seen[fn] = true
+			queue = append(queue, fn)
+		}
+	}
+
+	impls := make(map[string][]*methodImpl)
+	for ssaPkg := range s.project {
+		for _, member := range ssaPkg.Members {
+			switch member := member.(type) {
+			case *ssa.Function:
+				visit(member)
+			case *ssa.Type:
+				for _, method := range s.methodsOf(member.Type()) {
+					if s.inGraph(method.fn) {
+						impls[method.fn.Name()] = append(impls[method.fn.Name()], method)
+						visit(method.fn)
+					}
+				}
+			}
+		}
+	}
+
+	var invokes []*invokeSite
+	dynamic := make(map[string][]*ssa.Function) // Signature to callers of function values
+	addressTaken := make(map[*ssa.Function]bool)
+	var operands []*ssa.Value
+	for len(queue) > 0 {
+		fn := queue[0]
+		queue = queue[1:]
+		for _, anon := range fn.AnonFuncs {
+			visit(anon)
+			s.addEdge(fn, anon)
+		}
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				var callee *ssa.Value
+				if site, ok := instr.(ssa.CallInstruction); ok {
+					common := site.Common()
+					callee = &common.Value
+					switch {
+					case common.IsInvoke():
+						invokes = append(invokes, &invokeSite{caller: fn, common: common})
+					case common.StaticCallee() != nil:
+						if reflectiveCalls[common.StaticCallee().String()] {
+							s.reflective[callGraphNode(fn)] = true
+						}
+					default:
+						key := signatureKey(common.Signature())
+						dynamic[key] = append(dynamic[key], fn)
+					}
+				}
+				if conv, ok := instr.(*ssa.MakeInterface); ok {
+					for _, method := range s.methodsOf(conv.X.Type()) {
+						visit(method.fn)
+						s.addEdge(fn, method.fn)
+					}
+				}
+				for _, operand := range instr.Operands(operands[:0]) {
+					target, ok := (*operand).(*ssa.Function)
+					if !ok {
+						continue
+					}
+					visit(target)
+					s.addEdge(fn, target)
+					if operand != callee {
+						addressTaken[target] = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, site := range invokes {
+		iface, _ := site.common.Value.Type().Underlying().(*types.Interface)
+		_, typeParam := site.common.Value.Type().(*types.TypeParam)
+		for _, method := range impls[site.common.Method.Name()] {
+			if method.recv == nil || typeParam || iface == nil || types.Implements(method.recv, iface) {
+				s.addEdge(site.caller, method.fn)
+			}
+		}
+	}
+	for target := range addressTaken {
+		for _, caller := range dynamic[signatureKey(target.Signature)] {
+			s.addEdge(caller, target)
+		}
+	}
+}
+
+// signatureKey identifies the type of a function value, ignoring receivers
+func signatureKey(sig *types.Signature) string {
+	return types.TypeString(types.NewSignatureType(nil, nil, nil, sig.Params(), sig.Results(), sig.Variadic()), nil)
+}
+
+// classifyFile marks the functions and declarations touched by a file diff
+func (s *testSelector) classifyFile(projectPath, repoRoot string, diff *git.FileDiff) {
+	path := diff.NewPath
+	if path == "" {
+		path = diff.OldPath
+	}
+	name := filepath.Join(repoRoot, path)
+	if rel, err := filepath.Rel(projectPath, name); err != nil || strings.HasPrefix(rel, "..") {
+		return
+	}
+
+	if syntaxes := s.files[name]; len(syntaxes) > 0 && diff.NewPath != "" {
+		for _, syntax := range syntaxes {
+			for _, hunk := range diff.Hunks {
+				s.classifyHunk(syntax, path, hunk)
+			}
+		}
+		return
+	}
+	if strings.HasSuffix(name, ".go") {
+		// Removed files and files excluded by build constraints
+		if pkgs := s.dirs[filepath.Dir(name)]; len(pkgs) > 0 {
+			for _, pkg := range pkgs {
+				s.markDeclarations(pkg, name, path+" changed")
+			}
+			return
+		}
+	}
+	if pkgs := s.embeds[name]; len(pkgs) > 0 {
+		for _, pkg := range pkgs {
+			s.markDeclarations(pkg, "", path+" is embedded and changed")
+		}
+		return
+	}
+	// Test data and other files read at run time belong to the nearest
+	// enclosing package
+	for dir := filepath.Dir(name); ; dir = filepath.Dir(dir) {
+		if pkgs := s.dirs[dir]; len(pkgs) > 0 {
+			for _, pkg := range pkgs {
+				s.changedPkgs[pkg.PkgPath] = true
+				s.wholeChanges[testPackagePath(pkg)] = path + " changed"
+			}
+			return
+		}
+		if dir == projectPath || dir == filepath.Dir(dir) {
+			return
+		}
+	}
+}
+
+// classifyHunk marks the functions or declarations overlapping the new side
+// of a hunk. Lines outside declarations are comments or blank, unless they
+// hold the package clause or build constraints. A hunk that only removes
+// lines between declarations may have removed one, so it counts as a
+// declaration change.
+func (s *testSelector) classifyHunk(syntax *syntaxFile, path string, hunk git.Hunk) {
+	fset := syntax.pkg.Fset
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+	start, end := hunk.NewStart, hunk.NewStart+hunk.NewLines-1
+	removal := hunk.NewLines == 0
+	overlaps := func(from, to int) bool {
+		if removal {
+			return from <= start && start+1 <= to
+		}
+		return start <= to && end >= from
+	}
+
+	name := fset.Position(syntax.file.Package).Filename
+	matched := false
+	for _, decl := range syntax.file.Decls {
+		if !overlaps(line(declPos(decl)), line(decl.End())) {
+			continue
+		}
+		matched = true
+		if fn, ok := decl.(*ast.FuncDecl); ok && !(fn.Recv == nil && fn.Name.Name == "init") {
+			s.markFunction(syntax.pkg, name, fn, path)
+		} else {
+			s.markDeclarations(syntax.pkg, name, path+" changed outside of a function")
+		}
+	}
+	if matched {
+		return
+	}
+
+	header := overlaps(line(syntax.file.Package), line(syntax.file.Name.End()))
+	for _, group := range syntax.file.Comments {
+		if group.Pos() < syntax.file.Package && overlaps(line(group.Pos()), line(group.End())) &&
+			isBuildConstraint(group) {
+			header = true
+		}
+	}
+	if header || (removal && !s.insideComment(syntax, start)) {
+		s.markDeclarations(syntax.pkg, name, path+" changed outside of a function")
+	}
+}
+
+// insideComment reports whether the lines around a removal belong to the
+// same comment
+func (s *testSelector) insideComment(syntax *syntaxFile, line int) bool {
+	for _, group := range syntax.file.Comments {
+		from := syntax.pkg.Fset.Position(group.Pos()).Line
+		to := syntax.pkg.Fset.Position(group.End()).Line
+		if from <= line && line+1 <= to {
+			return true
+		}
+	}
+	return false
+}
+
+// declPos returns the start of a declaration, including its doc comment when
+// it holds compiler directives such as //go:embed
+func declPos(decl ast.Decl) token.Pos {
+	var doc *ast.CommentGroup
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		doc = decl.Doc
+	case *ast.GenDecl:
+		doc = decl.Doc
+	}
+	if doc != nil {
+		for _, comment := range doc.List {
+			if strings.HasPrefix(comment.Text, "//go:") {
+				return doc.Pos()
+			}
+		}
+	}
+	return decl.Pos()
+}
+
+func isBuildConstraint(group *ast.CommentGroup) bool {
+	for _, comment := range group.List {
+		if strings.HasPrefix(comment.Text, "//go:build") || strings.HasPrefix(comment.Text, "// +build") {
+			return true
+		}
+	}
+	return false
+}
+
+// markFunction marks a changed function and the closures it contains
+func (s *testSelector) markFunction(pkg *packages.Package, name string, decl *ast.FuncDecl, path string) {
+	obj, _ := pkg.TypesInfo.Defs[decl.Name].(*types.Func)
+	if obj == nil {
+		s.markDeclarations(pkg, name, path+" changed outside of a function")
+		return
+	}
+	fn := s.prog.FuncValue(obj)
+	if fn == nil {
+		s.markDeclarations(pkg, name, path+" changed outside of a function")
+		return
+	}
+	s.changedPkgs[pkg.PkgPath] = true
+	var mark func(fn *ssa.Function)
+	mark = func(fn *ssa.Function) {
+		s.changed[fn] = true
+		for _, anon := range fn.AnonFuncs {
+			mark(anon)
+		}
+	}
+	mark(fn)
+}
+
+// markDeclarations records a package-level change. Changes to test files
+// only affect the tests of their own package.
+func (s *testSelector) markDeclarations(pkg *packages.Package, name, reason string) {
+	s.changedPkgs[pkg.PkgPath] = true
+	if strings.HasSuffix(name, "_test.go") {
+		s.wholeChanges[testPackagePath(pkg)] = reason
+		return
+	}
+	if _, ok := s.declChanges[pkg.PkgPath]; !ok {
+		s.declChanges[pkg.PkgPath] = reason
+	}
+}
+
+// testPackagePath returns the path of the package whose tests a package
+// variant holds, folding external _test packages into the package they test
+func testPackagePath(pkg *packages.Package) string {
+	if strings.HasSuffix(pkg.Name, "_test") {
+		return strings.TrimSuffix(pkg.PkgPath, "_test")
+	}
+	return pkg.PkgPath
+}
+
+// reachers returns the functions from which any of the targets is reachable
+func (s *testSelector) reachers(targets map[*ssa.Function]bool) map[*ssa.Function]bool {
+	reached := make(map[*ssa.Function]bool, len(targets))
+	queue := make([]*ssa.Function, 0, len(targets))
+	for fn := range targets {
+		reached[callGraphNode(fn)] = true
+		queue = append(queue, callGraphNode(fn))
+	}
+	for len(queue) > 0 {
+		fn := queue[0]
+		queue = queue[1:]
+		for caller := range s.callers[fn] {
+			if !reached[caller] {
+				reached[caller] = true
+				queue = append(queue, caller)
+			}
+		}
+	}
+	return reached
+}
+
+// testPackages collects the tests of every package variant holding test files
+func (s *testSelector) testPackages() map[string]*testPackage {
+	tests := make(map[string]*testPackage)
+	for _, pkg := range s.pkgs {
+		ssaPkg := s.prog.Package(pkg.Types)
+		if ssaPkg == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if !strings.HasSuffix(pkg.Fset.Position(file.Package).Filename, "_test.go") {
+				continue
+			}
+			path := testPackagePath(pkg)
+			tp := tests[path]
+			if tp == nil {
+				tp = &testPackage{tests: make(map[string]*ssa.Function), imports: make(map[string]bool)}
+				tests[path] = tp
+			}
+			packages.Visit([]*packages.Package{pkg}, func(dep *packages.Package) bool {
+				if tp.imports[dep.PkgPath] {
+					return false
+				}
+				tp.imports[dep.PkgPath] = true
+				return true
+			}, nil)
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv != nil {
+					continue
+				}
+				switch name := fn.Name.Name; {
+				case name == "TestMain":
+					tp.main = ssaPkg.Func(name)
+				case isTestName(name, "Test") || isTestName(name, "Fuzz") || isTestName(name, "Example"):
+					if ssaFn := ssaPkg.Func(name); ssaFn != nil {
+						tp.tests[name] = ssaFn
+					}
+				}
+			}
+		}
+	}
+	return tests
+}
+
+// isTestName reports whether name is run by go test for prefix: the prefix
+// must not be followed by a lower-case letter
+func isTestName(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
+
+// selection walks the call graph back from the changes to the tests
+func (s *testSelector) selection() *TestSelection {
+	reached := s.reachers(s.changed)
+	// Package initialization runs before every test importing the package
+	for fn := range reached {
+		if fn.Pkg != nil && fn == fn.Pkg.Func("init") {
+			path := fn.Pkg.Pkg.Path()
+			if _, ok := s.declChanges[path]; !ok {
+				s.declChanges[path] = "initialization of " + path + " is affected"
+			}
+		}
+	}
+	reflective := s.reachers(s.reflective)
+
+	result := &TestSelection{Packages: []*PackageTests{}}
+	for path, tp := range s.testPackages() {
+		reason := s.wholeReason(path, tp, reached, reflective)
+		if reason != "" {
+			result.Packages = append(result.Packages, &PackageTests{Package: path, Whole: true, Reason: reason})
+			continue
+		}
+		var names []string
+		for name, fn := range tp.tests {
+			if reached[fn] {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+		result.Packages = append(result.Packages, &PackageTests{
+			Package: path,
+			Tests:   names,
+			Run:     "^(" + strings.Join(names, "|") + ")$",
+		})
+	}
+	sort.Slice(result.Packages, func(i, j int) bool {
+		return result.Packages[i].Package < result.Packages[j].Package
+	})
+	return result
+}
+
+// wholeReason explains why every test of a package must run, or returns an
+// empty string when its tests can be selected individually
+func (s *testSelector) wholeReason(
+	path string,
+	tp *testPackage,
+	reached, reflective map[*ssa.Function]bool,
+) string {
+	if reason, ok := s.wholeChanges[path]; ok {
+		return reason
+	}
+	if tp.main != nil && reached[tp.main] {
+		return "TestMain is affected"
+	}
+	changedPaths := make([]string, 0, len(s.declChanges))
+	for changed := range s.declChanges {
+		changedPaths = append(changedPaths, changed)
+	}
+	sort.Strings(changedPaths)
+	for _, changed := range changedPaths {
+		if tp.imports[changed] {
+			return s.declChanges[changed]
+		}
+	}
+	for changed := range s.changedPkgs {
+		if !tp.imports[changed] {
+			continue
+		}
+		for _, fn := range tp.tests {
+			if reflective[fn] {
+				return "tests call functions through reflection"
+			}
+		}
+		break
+	}
+	return ""
+}
+
+// WriteText writes the selection as go test commands, one per package, with
+// the reasons for running whole packages as shell comments
+func (s *TestSelection) WriteText(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	switch {
+	case s.All:
+		fmt.Fprintf(out, "# %s\ngo test ./...\n", s.Reason)
+	case len(s.Packages) == 0:
+		fmt.Fprintln(out, "# No tests are affected by the changes")
+	}
+	if !s.All {
+		for _, pkg := range s.Packages {
+			if pkg.Whole {
+				fmt.Fprintf(out, "# %s\ngo test %s\n", pkg.Reason, pkg.Package)
+				continue
+			}
+			fmt.Fprintf(out, "go test %s -run '%s'\n", pkg.Package, pkg.Run)
+		}
+	}
+	return out.Flush()
+}
@@ -0,0 +1,222 @@
+package analyzer_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var selectionModule = map[string]string{
+	"go.mod": "module example.com/sel\n\ngo 1.24\n",
+	"store/store.go": `package store
+
+var Prefix = "record:"
+
+func Save() string {
+	return Prefix + "saved"
+}
+
+func Load() string {
+	return "loaded"
+}
+
+type English struct{}
+
+func (English) Greet() string {
+	return "hello"
+}
+`,
+	"store/store_test.go": `package store
+
+import "testing"
+
+func TestSave(t *testing.T) {
+	if Save() == "" {
+		t.Fatal("empty")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("loads", func(t *testing.T) {
+		if Load() == "" {
+			t.Fatal("empty")
+		}
+	})
+}
+
+func BenchmarkSave(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Save()
+	}
+}
+`,
+	"store/testdata/records.json": "[]\n",
+	"service/service.go": `package service
+
+import "example.com/sel/store"
+
+type Greeter interface {
+	Greet() string
+}
+
+func Create() string {
+	return store.Save()
+}
+
+func Welcome(g Greeter) string {
+	return g.Greet()
+}
+`,
+	"service/service_test.go": `package service_test
+
+import (
+	"testing"
+
+	"example.com/sel/service"
+	"example.com/sel/store"
+)
+
+func TestCreate(t *testing.T) {
+	_ = service.Create()
+}
+
+func TestWelcome(t *testing.T) {
+	_ = service.Welcome(store.English{})
+}
+
+func TestUnrelated(t *testing.T) {}
+`,
+	"dyn/dyn.go": `package dyn
+
+import "reflect"
+
+func Invoke(v any, name string) {
+	reflect.ValueOf(v).MethodByName(name).Call(nil)
+}
+
+func Describe() string {
+	return "dyn"
+}
+`,
+	"dyn/dyn_test.go": `package dyn
+
+import "testing"
+
+type target struct{}
+
+func (target) Run() {}
+
+func TestInvoke(t *testing.T) {
+	Invoke(target{}, "Run")
+}
+`,
+}
+
+func writeSelectionModule(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range selectionModule {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	return dir
+}
+
+func changed(path string, start, lines int) *git.FileDiff {
+	return &git.FileDiff{
+		OldPath: path,
+		NewPath: path,
+		Hunks:   []git.Hunk{{OldStart: start, OldLines: lines, NewStart: start, NewLines: lines}},
+	}
+}
+
+func TestSelectTests(t *testing.T) {
+	ctx := context.Background()
+	dir := writeSelectionModule(t)
+	selectTests := func(t *testing.T, files ...*git.FileDiff) *analyzer.TestSelection {
+		t.Helper()
+		selection, err := analyzer.SelectTests(ctx, dir, dir, files)
+		require.NoError(t, err)
+		return selection
+	}
+
+	t.Run("Should select the tests reaching a changed function", func(t *testing.T) {
+		selection := selectTests(t, changed("store/store.go", 6, 1))
+
+		assert.False(t, selection.All)
+		require.Len(t, selection.Packages, 2)
+		assert.Equal(t, &analyzer.PackageTests{
+			Package: "example.com/sel/service", Tests: []string{"TestCreate"}, Run: "^(TestCreate)$",
+		}, selection.Packages[0])
+		assert.Equal(t, &analyzer.PackageTests{
+			Package: "example.com/sel/store", Tests: []string{"TestSave"}, Run: "^(TestSave)$",
+		}, selection.Packages[1])
+	})
+
+	t.Run("Should follow closures and interface calls", func(t *testing.T) {
+		selection := selectTests(t, changed("store/store.go", 16, 1))
+
+		require.Len(t, selection.Packages, 1)
+		assert.Equal(t, []string{"TestWelcome"}, selection.Packages[0].Tests)
+	})
+
+	t.Run("Should select whole packages when tests use reflection", func(t *testing.T) {
+		selection := selectTests(t, changed("dyn/dyn.go", 10, 1), changed("store/store.go", 10, 1))
+
+		require.Len(t, selection.Packages, 2)
+		assert.Equal(t, &analyzer.PackageTests{
+			Package: "example.com/sel/dyn", Whole: true, Reason: "tests call functions through reflection",
+		}, selection.Packages[0])
+		assert.Equal(t, []string{"TestLoad"}, selection.Packages[1].Tests)
+	})
+
+	t.Run("Should select whole importing packages for declaration changes", func(t *testing.T) {
+		selection := selectTests(t, changed("store/store.go", 3, 1))
+
+		require.Len(t, selection.Packages, 2)
+		for _, pkg := range selection.Packages {
+			assert.True(t, pkg.Whole, pkg.Package)
+			assert.Equal(t, "store/store.go changed outside of a function", pkg.Reason)
+		}
+	})
+
+	t.Run("Should ignore blank and comment lines", func(t *testing.T) {
+		selection := selectTests(t, changed("store/store.go", 8, 1))
+
+		assert.Empty(t, selection.Packages)
+	})
+
+	t.Run("Should select the package of changed test data", func(t *testing.T) {
+		selection := selectTests(t, changed("store/testdata/records.json", 1, 1))
+
+		require.Len(t, selection.Packages, 1)
+		assert.Equal(t, &analyzer.PackageTests{
+			Package: "example.com/sel/store", Whole: true, Reason: "store/testdata/records.json changed",
+		}, selection.Packages[0])
+	})
+
+	t.Run("Should select every test when the module changes", func(t *testing.T) {
+		selection, err := analyzer.SelectTests(ctx, dir, dir, []*git.FileDiff{changed("go.sum", 1, 1)})
+
+		require.NoError(t, err)
+		assert.True(t, selection.All)
+		assert.Equal(t, "go.sum changed", selection.Reason)
+	})
+
+	t.Run("Should write go test commands", func(t *testing.T) {
+		selection := selectTests(t, changed("store/store.go", 6, 1), changed("store/testdata/records.json", 1, 1))
+		var buf bytes.Buffer
+		require.NoError(t, selection.WriteText(&buf))
+
+		assert.Equal(t, "go test example.com/sel/service -run '^(TestCreate)$'\n"+
+			"# store/testdata/records.json changed\ngo test example.com/sel/store\n", buf.String())
+	})
+}
@@ -92,4 +92,37 @@ type AnalysisResult struct {
 	TotalStructs   int            `json:"total_structs"`
 	AnalyzedAt     time.Time      `json:"analyzed_at"`
 	Duration       time.Duration  `json:"duration"`
+	Snapshot       *Snapshot      `json:"snapshot,omitempty"`
+}
+
+// Snapshot describes one stored analysis of a project. The latest snapshot is
+// stored under the project ID itself; older snapshots are kept under a scoped
+// ID so that queries default to the latest version of the graph.
+type Snapshot struct {
+	ID                ID        `json:"id"`
+	ProjectID         ID        `json:"project_id"`
+	CommitSHA         string    `json:"commit_sha,omitempty"`
+	Branch            string    `json:"branch,omitempty"`
+	ConfigHash        string    `json:"config_hash,omitempty"`
+	Root              string    `json:"root,omitempty"` // Project directory the file paths lie below
+	CreatedAt         time.Time `json:"created_at"`
+	NodeCount         int       `json:"node_count"`
+	RelationshipCount int       `json:"relationship_count"`
+	Latest            bool      `json:"latest"`
+	// Backfill stores the snapshot as an archived one, for a revision older
+	// than the latest snapshot, which is left as it is
+	Backfill bool `json:"-"`
+}
+
+// SnapshotScope returns the project ID under which an archived snapshot is stored
+func SnapshotScope(projectID, snapshotID ID) ID {
+	return ID(projectID.String() + "@" + snapshotID.String())
+}
+
+// Scope returns the project ID that the snapshot's nodes are stored under
+func (s *Snapshot) Scope() ID {
+	if s.Latest {
+		return s.ProjectID
+	}
+	return SnapshotScope(s.ProjectID, s.ID)
 }
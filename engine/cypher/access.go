@@ -0,0 +1,90 @@
+package cypher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrWrite is returned by CheckReadOnly for queries that may write to the graph
+var ErrWrite = errors.New("query may write to the graph")
+
+// writeClauses start clauses that change the graph or its schema
+var writeClauses = map[string]bool{
+	"CREATE": true, "MERGE": true, "DELETE": true, "DETACH": true, "SET": true,
+	"REMOVE": true, "DROP": true, "FOREACH": true,
+}
+
+// readProcedures are the procedures a read-only query may CALL
+var readProcedures = map[string]bool{
+	"db.labels":                            true,
+	"db.relationshipTypes":                 true,
+	"db.propertyKeys":                      true,
+	"db.indexes":                           true,
+	"db.constraints":                       true,
+	"db.schema.visualization":              true,
+	"db.schema.nodeTypeProperties":         true,
+	"db.schema.relTypeProperties":          true,
+	"db.index.fulltext.queryNodes":         true,
+	"db.index.fulltext.queryRelationships": true,
+	"dbms.components":                      true,
+}
+
+// CheckReadOnly returns an error wrapping ErrWrite when a query may write to
+// the graph: when it has an updating or schema clause, or calls a procedure
+// that is not known to only read. Unlike Parse, it accepts any Cypher, so it
+// can guard queries run by Neo4j. A variable named like an updating clause is
+// taken for one, so the check errs on the side of rejecting.
+func CheckReadOnly(query string) error {
+	tokens, err := lex(query)
+	if err != nil {
+		return err
+	}
+	for i, tok := range tokens {
+		if tok.kind != tokenIdent || !isClausePosition(tokens, i) {
+			continue
+		}
+		word := strings.ToUpper(tok.text)
+		switch {
+		case writeClauses[word]:
+			return fmt.Errorf("%w: %s is not allowed in read-only mode", ErrWrite, word)
+		case word == "CALL":
+			if name := procedureName(tokens[i+1:]); name != "" && !readProcedures[name] {
+				return fmt.Errorf("%w: procedure %s is not known to be read-only", ErrWrite, name)
+			}
+		}
+	}
+	return nil
+}
+
+// isClausePosition reports whether the identifier at i can start a clause
+// rather than being a property key, label, map key or alias
+func isClausePosition(tokens []token, i int) bool {
+	if i > 0 {
+		prev := tokens[i-1]
+		if prev.kind == tokenSymbol && (prev.text == "." || prev.text == ":") {
+			return false
+		}
+		if prev.kind == tokenIdent && strings.EqualFold(prev.text, "AS") {
+			return false
+		}
+	}
+	next := tokens[i+1] // The last token is EOF, so an identifier has a next token
+	return next.kind != tokenSymbol || next.text != ":"
+}
+
+// procedureName returns the dotted name of the procedure a CALL invokes, or
+// "" for a CALL subquery
+func procedureName(tokens []token) string {
+	var parts []string
+	for i := 0; i < len(tokens); i += 2 {
+		if tokens[i].kind != tokenIdent && tokens[i].kind != tokenQuotedIdent {
+			break
+		}
+		parts = append(parts, tokens[i].text)
+		if tokens[i+1].kind != tokenSymbol || tokens[i+1].text != "." {
+			break
+		}
+	}
+	return strings.Join(parts, ".")
+}
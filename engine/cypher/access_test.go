@@ -0,0 +1,51 @@
+package cypher_test
+
+import (
+	"testing"
+
+	"github.com/compozy/gograph/engine/cypher"
+	"github.com/compozy/gograph/engine/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	t.Run("Should accept read queries", func(t *testing.T) {
+		queries := []string{
+			"MATCH (n:Function {project_id: $project_id}) RETURN n.name AS name",
+			"MATCH (n) WHERE n.set = 1 AND n:Create RETURN n {create: n.name, .delete} AS merge",
+			"MATCH (n) RETURN n.name AS set",
+			"CALL db.labels() YIELD label RETURN label",
+			"CALL { MATCH (n) RETURN n } RETURN count(n) AS total",
+			"MATCH (n) WHERE n.name = 'CREATE (x)' RETURN n // DETACH DELETE n",
+			"SHOW INDEXES",
+		}
+		for _, q := range queries {
+			assert.NoError(t, cypher.CheckReadOnly(q), q)
+		}
+		for name, template := range query.CommonTemplates {
+			assert.NoError(t, cypher.CheckReadOnly(template.Query), name)
+		}
+	})
+
+	t.Run("Should reject writes, schema changes and unknown procedures", func(t *testing.T) {
+		queries := map[string]string{
+			"CREATE (n:Function) RETURN n":                        "CREATE",
+			"MATCH (n {project_id: 'other'}) DETACH DELETE n":     "DETACH",
+			"MATCH (n) delete n":                                  "DELETE",
+			"MATCH (n) SET n.name = 'x' RETURN n":                 "SET",
+			"MATCH (n) REMOVE n:Function":                         "REMOVE",
+			"MERGE (n:Package {name: 'x'})":                       "MERGE",
+			"DROP INDEX function_name":                            "DROP",
+			"MATCH (n) RETURN n; MATCH (m) DELETE m":              "DELETE",
+			"CALL { MATCH (n) DETACH DELETE n } IN TRANSACTIONS":  "DETACH",
+			"CALL apoc.periodic.iterate('MATCH (n)', 'DELETE n')": "procedure apoc.periodic.iterate",
+			"CALL db.createLabel('Function')":                     "procedure db.createLabel",
+			"MATCH (n) FOREACH (x IN [1] | SET n.visited = true)": "FOREACH",
+		}
+		for q, message := range queries {
+			err := cypher.CheckReadOnly(q)
+			assert.ErrorIs(t, err, cypher.ErrWrite, q)
+			assert.ErrorContains(t, err, message, q)
+		}
+	})
+}
@@ -0,0 +1,287 @@
+package cypher
+
+// Query is a parsed read-only Cypher query
+type Query struct {
+	parts    []*singleQuery
+	unionAll bool // UNION ALL keeps duplicate rows between the parts
+}
+
+// singleQuery is a sequence of clauses, one part of a UNION
+type singleQuery struct {
+	clauses []clause
+	texts   []string // Source text of each clause
+}
+
+type clause interface {
+	clauseName() string
+}
+
+// matchClause is MATCH or OPTIONAL MATCH with its WHERE predicate
+type matchClause struct {
+	optional bool
+	patterns []*pattern
+	where    expr
+}
+
+func (c *matchClause) clauseName() string {
+	if c.optional {
+		return "OPTIONAL MATCH"
+	}
+	return "MATCH"
+}
+
+// unwindClause expands a list into one row per element
+type unwindClause struct {
+	list  expr
+	alias string
+}
+
+func (c *unwindClause) clauseName() string { return "UNWIND" }
+
+// projectionClause is WITH or RETURN
+type projectionClause struct {
+	with     bool
+	distinct bool
+	star     bool
+	items    []*projectionItem
+	order    []*sortItem
+	skip     expr
+	limit    expr
+	where    expr // WITH only
+}
+
+func (c *projectionClause) clauseName() string {
+	if c.with {
+		return "WITH"
+	}
+	return "RETURN"
+}
+
+// projectionItem is an expression and its column name: the alias, or else the
+// source text of the expression
+type projectionItem struct {
+	expr  expr
+	alias string
+}
+
+type sortItem struct {
+	expr       expr
+	descending bool
+}
+
+type direction int
+
+const (
+	directionBoth direction = iota
+	directionOut
+	directionIn
+)
+
+type shortestKind int
+
+const (
+	shortestNone shortestKind = iota
+	shortestSingle
+	shortestAll
+)
+
+// pattern is a chain of nodes joined by relationships, optionally bound to a
+// path variable
+type pattern struct {
+	pathVar  string
+	shortest shortestKind
+	nodes    []*nodePattern
+	rels     []*relPattern // rels[i] joins nodes[i] and nodes[i+1]
+}
+
+type nodePattern struct {
+	variable string
+	labels   []string
+	props    expr // Map literal or parameter
+	start    int  // Offsets of the '(' and ')' in the query
+	end      int
+	propsEnd int // Offset of the '}' closing a property map
+}
+
+type relPattern struct {
+	variable  string
+	types     []string
+	props     expr
+	direction direction
+	varLength bool
+	minHops   int
+	maxHops   int // Negative when unbounded
+}
+
+// expr is an expression. children returns the subexpressions evaluated in the
+// same scope, which excludes the bodies of subqueries.
+type expr interface {
+	eval(ex *executor, r row) (any, error)
+	children() []expr
+}
+
+type literalExpr struct{ value any }
+
+type paramExpr struct{ name string }
+
+type variableExpr struct{ name string }
+
+type propertyExpr struct {
+	subject expr
+	key     string
+}
+
+type indexExpr struct {
+	subject expr
+	index   expr
+}
+
+type sliceExpr struct {
+	subject  expr
+	from, to expr
+}
+
+type labelExpr struct {
+	subject expr
+	labels  []string
+}
+
+type unaryExpr struct {
+	op      string
+	operand expr
+}
+
+type binaryExpr struct {
+	op          string
+	left, right expr
+}
+
+// comparisonExpr is a chain such as a < b <= c, which holds when every
+// adjacent pair does
+type comparisonExpr struct {
+	operands []expr
+	ops      []string
+}
+
+type isNullExpr struct {
+	operand expr
+	not     bool
+}
+
+type listExpr struct{ items []expr }
+
+type mapExpr struct {
+	keys   []string
+	values []expr
+}
+
+// mapProjectionExpr is n {.name, .*, key: value}
+type mapProjectionExpr struct {
+	subject string
+	items   []mapProjectionItem
+}
+
+type mapProjectionItem struct {
+	key      string
+	property bool // .key
+	all      bool // .*
+	value    expr
+}
+
+type funcExpr struct {
+	name     string // Lower case
+	distinct bool
+	star     bool // count(*)
+	args     []expr
+}
+
+type caseExpr struct {
+	subject   expr // Nil for the generic form
+	whens     []expr
+	thens     []expr
+	otherwise expr
+}
+
+type listComprehensionExpr struct {
+	variable string
+	list     expr
+	where    expr
+	project  expr
+}
+
+// quantifierExpr is any, all, none or single over a list
+type quantifierExpr struct {
+	kind     string
+	variable string
+	list     expr
+	where    expr
+}
+
+// patternExpr is a pattern used as a predicate: true when it has a match
+type patternExpr struct{ pattern *pattern }
+
+type patternComprehensionExpr struct {
+	pattern *pattern
+	where   expr
+	project expr
+}
+
+// subqueryExpr is EXISTS { ... } or COUNT { ... }
+type subqueryExpr struct {
+	count bool
+	query *singleQuery
+}
+
+// The bodies of patterns, pattern comprehensions and subqueries have their own
+// scope, so they are not children.
+func (e *literalExpr) children() []expr              { return nil }
+func (e *paramExpr) children() []expr                { return nil }
+func (e *variableExpr) children() []expr             { return nil }
+func (e *propertyExpr) children() []expr             { return []expr{e.subject} }
+func (e *indexExpr) children() []expr                { return []expr{e.subject, e.index} }
+func (e *sliceExpr) children() []expr                { return nonNil(e.subject, e.from, e.to) }
+func (e *labelExpr) children() []expr                { return []expr{e.subject} }
+func (e *unaryExpr) children() []expr                { return []expr{e.operand} }
+func (e *binaryExpr) children() []expr               { return []expr{e.left, e.right} }
+func (e *comparisonExpr) children() []expr           { return e.operands }
+func (e *isNullExpr) children() []expr               { return []expr{e.operand} }
+func (e *listExpr) children() []expr                 { return e.items }
+func (e *mapExpr) children() []expr                  { return e.values }
+func (e *funcExpr) children() []expr                 { return e.args }
+func (e *patternExpr) children() []expr              { return nil }
+func (e *patternComprehensionExpr) children() []expr { return nil }
+func (e *subqueryExpr) children() []expr             { return nil }
+
+func (e *mapProjectionExpr) children() []expr {
+	var children []expr
+	for _, item := range e.items {
+		if item.value != nil {
+			children = append(children, item.value)
+		}
+	}
+	return children
+}
+
+func (e *caseExpr) children() []expr {
+	children := nonNil(e.subject, e.otherwise)
+	children = append(children, e.whens...)
+	return append(children, e.thens...)
+}
+
+func (e *listComprehensionExpr) children() []expr {
+	return nonNil(e.list, e.where, e.project)
+}
+
+func (e *quantifierExpr) children() []expr {
+	return nonNil(e.list, e.where)
+}
+
+func nonNil(exprs ...expr) []expr {
+	var result []expr
+	for _, e := range exprs {
+		if e != nil {
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,649 @@
+package cypher
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+func (e *literalExpr) eval(_ *executor, _ row) (any, error) {
+	return e.value, nil
+}
+
+func (e *paramExpr) eval(ex *executor, _ row) (any, error) {
+	value, ok := ex.params[e.name]
+	if !ok {
+		return nil, fmt.Errorf("expected parameter $%s", e.name)
+	}
+	return value, nil
+}
+
+func (e *variableExpr) eval(_ *executor, r row) (any, error) {
+	value, ok := r[e.name]
+	if !ok {
+		return nil, fmt.Errorf("variable `%s` not defined", e.name)
+	}
+	return value, nil
+}
+
+func (e *propertyExpr) eval(ex *executor, r row) (any, error) {
+	subject, err := e.subject.eval(ex, r)
+	if err != nil {
+		return nil, err
+	}
+	return ex.property(subject, e.key)
+}
+
+// property reads a key of a node, relationship or map. Missing keys read as
+// null.
+func (ex *executor) property(subject any, key string) (any, error) {
+	switch v := subject.(type) {
+	case nil:
+		return nil, nil
+	case nodeRef:
+		return ex.graph.nodeProps[v][key], nil
+	case relRef:
+		return ex.graph.relProps[v][key], nil
+	case map[string]any:
+		return v[key], nil
+	}
+	return nil, fmt.Errorf("type mismatch: cannot read property %q of %s", key, typeName(subject))
+}
+
+func (e *indexExpr) eval(ex *executor, r row) (any, error) {
+	subject, index, err := evalPair(ex, r, e.subject, e.index)
+	if err != nil || subject == nil || index == nil {
+		return nil, err
+	}
+	if list, ok := subject.([]any); ok {
+		i, ok := index.(int64)
+		if !ok {
+			return nil, fmt.Errorf("type mismatch: list index must be an Integer, not %s", typeName(index))
+		}
+		if i < 0 {
+			i += int64(len(list))
+		}
+		if i < 0 || i >= int64(len(list)) {
+			return nil, nil
+		}
+		return list[i], nil
+	}
+	key, ok := index.(string)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: map key must be a String, not %s", typeName(index))
+	}
+	return ex.property(subject, key)
+}
+
+func (e *sliceExpr) eval(ex *executor, r row) (any, error) {
+	subject, err := e.subject.eval(ex, r)
+	if err != nil || subject == nil {
+		return nil, err
+	}
+	list, ok := subject.([]any)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: cannot slice %s", typeName(subject))
+	}
+	from, err := sliceBound(ex, r, e.from, 0, len(list))
+	if err != nil {
+		return nil, err
+	}
+	to, err := sliceBound(ex, r, e.to, len(list), len(list))
+	if err != nil {
+		return nil, err
+	}
+	if from >= to {
+		return []any{}, nil
+	}
+	return list[from:to], nil
+}
+
+// sliceBound evaluates a slice bound, counting negative bounds from the end
+// and clamping to the list
+func sliceBound(ex *executor, r row, e expr, missing, length int) (int, error) {
+	if e == nil {
+		return missing, nil
+	}
+	value, err := e.eval(ex, r)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := value.(int64)
+	if !ok {
+		return 0, fmt.Errorf("type mismatch: list slice bound must be an Integer, not %s", typeName(value))
+	}
+	if n < 0 {
+		n += int64(length)
+	}
+	return int(max(0, min(n, int64(length)))), nil
+}
+
+func (e *labelExpr) eval(ex *executor, r row) (any, error) {
+	subject, err := e.subject.eval(ex, r)
+	if err != nil || subject == nil {
+		return nil, err
+	}
+	n, ok := subject.(nodeRef)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: expected a Node but was %s", typeName(subject))
+	}
+	for _, label := range e.labels {
+		if label != ex.graph.label(n) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (e *unaryExpr) eval(ex *executor, r row) (any, error) {
+	operand, err := e.operand.eval(ex, r)
+	if err != nil || operand == nil {
+		return nil, err
+	}
+	switch v := operand.(type) {
+	case bool:
+		if e.op == "NOT" {
+			return !v, nil
+		}
+	case int64:
+		if e.op == "-" {
+			return -v, nil
+		}
+		if e.op == "+" {
+			return v, nil
+		}
+	case float64:
+		if e.op == "-" {
+			return -v, nil
+		}
+		if e.op == "+" {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("type mismatch: cannot apply %s to %s", e.op, typeName(operand))
+}
+
+func (e *binaryExpr) eval(ex *executor, r row) (any, error) {
+	switch e.op {
+	case "OR", "XOR", "AND":
+		return e.evalLogical(ex, r)
+	}
+	left, right, err := evalPair(ex, r, e.left, e.right)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "STARTS WITH", "ENDS WITH", "CONTAINS":
+		return stringPredicate(e.op, left, right), nil
+	case "IN":
+		return inList(left, right)
+	case "=~":
+		return ex.matchRegexp(left, right)
+	}
+	return arithmetic(e.op, left, right)
+}
+
+// evalLogical applies a boolean operator with three-valued logic, skipping the
+// right operand when the left one decides the result
+func (e *binaryExpr) evalLogical(ex *executor, r row) (any, error) {
+	left, err := evalBoolean(ex, r, e.left)
+	if err != nil {
+		return nil, err
+	}
+	if (e.op == "OR" && left == true) || (e.op == "AND" && left == false) {
+		return left, nil
+	}
+	right, err := evalBoolean(ex, r, e.right)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case e.op == "OR" && right == true:
+		return true, nil
+	case e.op == "AND" && right == false:
+		return false, nil
+	case left == nil || right == nil:
+		return nil, nil
+	case e.op == "XOR":
+		return left != right, nil
+	}
+	return right, nil
+}
+
+// evalBoolean evaluates an expression that must be a boolean or null
+func evalBoolean(ex *executor, r row, e expr) (any, error) {
+	value, err := e.eval(ex, r)
+	if err != nil {
+		return nil, err
+	}
+	switch value.(type) {
+	case nil, bool:
+		return value, nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a Boolean but was %s", typeName(value))
+}
+
+func evalPair(ex *executor, r row, a, b expr) (any, any, error) {
+	left, err := a.eval(ex, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err := b.eval(ex, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return left, right, nil
+}
+
+func stringPredicate(op string, left, right any) any {
+	s, ok := left.(string)
+	sub, subOK := right.(string)
+	if !ok || !subOK {
+		return nil
+	}
+	switch op {
+	case "STARTS WITH":
+		return strings.HasPrefix(s, sub)
+	case "ENDS WITH":
+		return strings.HasSuffix(s, sub)
+	}
+	return strings.Contains(s, sub)
+}
+
+func inList(value, list any) (any, error) {
+	if list == nil {
+		return nil, nil
+	}
+	items, ok := list.([]any)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: IN expects a List but was %s", typeName(list))
+	}
+	var result any = false
+	for _, item := range items {
+		switch equals(value, item) {
+		case true:
+			return true, nil
+		case nil:
+			result = nil
+		}
+	}
+	return result, nil
+}
+
+// matchRegexp applies =~, which must match the whole string
+func (ex *executor) matchRegexp(value, pattern any) (any, error) {
+	s, ok := value.(string)
+	p, patternOK := pattern.(string)
+	if !ok || !patternOK {
+		return nil, nil
+	}
+	re, cached := ex.regexps[p]
+	if !cached {
+		var err error
+		if re, err = regexp.Compile(`^(?:` + p + `)$`); err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", p, err)
+		}
+		ex.regexps[p] = re
+	}
+	return re.MatchString(s), nil
+}
+
+func arithmetic(op string, left, right any) (any, error) {
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	if op == "+" {
+		if sum, ok := concatenate(left, right); ok {
+			return sum, nil
+		}
+	}
+	x, xInt := left.(int64)
+	y, yInt := right.(int64)
+	if xInt && yInt && op != "^" {
+		return integerArithmetic(op, x, y)
+	}
+	fx, okX := toFloat(left)
+	fy, okY := toFloat(right)
+	if !okX || !okY {
+		return nil, fmt.Errorf("type mismatch: cannot apply %s to %s and %s", op, typeName(left), typeName(right))
+	}
+	switch op {
+	case "+":
+		return fx + fy, nil
+	case "-":
+		return fx - fy, nil
+	case "*":
+		return fx * fy, nil
+	case "/":
+		return fx / fy, nil
+	case "%":
+		return math.Mod(fx, fy), nil
+	}
+	return math.Pow(fx, fy), nil
+}
+
+func integerArithmetic(op string, x, y int64) (any, error) {
+	switch op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	}
+	if y == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	if op == "/" {
+		return x / y, nil
+	}
+	return x % y, nil
+}
+
+// concatenate applies + to strings and lists
+func concatenate(left, right any) (any, bool) {
+	if l, ok := left.([]any); ok {
+		if r, ok := right.([]any); ok {
+			return append(append([]any{}, l...), r...), true
+		}
+		return append(append([]any{}, l...), right), true
+	}
+	if r, ok := right.([]any); ok {
+		return append([]any{left}, r...), true
+	}
+	ls, lString := left.(string)
+	rs, rString := right.(string)
+	switch {
+	case lString && rString:
+		return ls + rs, true
+	case lString && isNumber(right):
+		return ls + formatValue(right), true
+	case rString && isNumber(left):
+		return formatValue(left) + rs, true
+	}
+	return nil, false
+}
+
+func (e *comparisonExpr) eval(ex *executor, r row) (any, error) {
+	left, err := e.operands[0].eval(ex, r)
+	if err != nil {
+		return nil, err
+	}
+	var result any = true
+	for i, op := range e.ops {
+		right, err := e.operands[i+1].eval(ex, r)
+		if err != nil {
+			return nil, err
+		}
+		switch compareWith(op, left, right) {
+		case false:
+			return false, nil
+		case nil:
+			result = nil
+		}
+		left = right
+	}
+	return result, nil
+}
+
+func compareWith(op string, left, right any) any {
+	switch op {
+	case "=":
+		return equals(left, right)
+	case "<>":
+		if eq, ok := equals(left, right).(bool); ok {
+			return !eq
+		}
+		return nil
+	}
+	c, ok := compare(left, right)
+	if !ok {
+		return nil
+	}
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+func (e *isNullExpr) eval(ex *executor, r row) (any, error) {
+	value, err := e.operand.eval(ex, r)
+	if err != nil {
+		return nil, err
+	}
+	return (value == nil) != e.not, nil
+}
+
+func (e *listExpr) eval(ex *executor, r row) (any, error) {
+	list := make([]any, len(e.items))
+	for i, item := range e.items {
+		value, err := item.eval(ex, r)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = value
+	}
+	return list, nil
+}
+
+func (e *mapExpr) eval(ex *executor, r row) (any, error) {
+	m := make(map[string]any, len(e.keys))
+	for i, key := range e.keys {
+		value, err := e.values[i].eval(ex, r)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+func (e *mapProjectionExpr) eval(ex *executor, r row) (any, error) {
+	subject, ok := r[e.subject]
+	if !ok {
+		return nil, fmt.Errorf("variable `%s` not defined", e.subject)
+	}
+	var props map[string]any
+	switch v := subject.(type) {
+	case nil:
+		return nil, nil
+	case nodeRef:
+		props = ex.graph.nodeProps[v]
+	case relRef:
+		props = ex.graph.relProps[v]
+	case map[string]any:
+		props = v
+	default:
+		return nil, fmt.Errorf("type mismatch: cannot project %s", typeName(subject))
+	}
+	m := make(map[string]any)
+	for _, item := range e.items {
+		switch {
+		case item.all:
+			for key, value := range props {
+				m[key] = value
+			}
+		case item.property:
+			m[item.key] = props[item.key]
+		default:
+			value, err := item.value.eval(ex, r)
+			if err != nil {
+				return nil, err
+			}
+			m[item.key] = value
+		}
+	}
+	return m, nil
+}
+
+func (e *funcExpr) eval(ex *executor, r row) (any, error) {
+	if aggregateFunctions[e.name] {
+		value, ok := ex.aggregates[e]
+		if !ok {
+			return nil, fmt.Errorf("invalid use of aggregating function %s() in this context", e.name)
+		}
+		return value, nil
+	}
+	if e.name == "exists" && len(e.args) == 1 {
+		value, err := e.args[0].eval(ex, r)
+		if _, isPattern := e.args[0].(*patternExpr); isPattern || err != nil {
+			return value, err
+		}
+		return value != nil, nil
+	}
+	fn, ok := functions[e.name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown function %s()", ErrUnsupported, e.name)
+	}
+	if len(e.args) < fn.minArgs || (fn.maxArgs >= 0 && len(e.args) > fn.maxArgs) {
+		return nil, fmt.Errorf("wrong number of arguments to %s(): %d", e.name, len(e.args))
+	}
+	args := make([]any, len(e.args))
+	for i, arg := range e.args {
+		value, err := arg.eval(ex, r)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	return fn.call(ex, args)
+}
+
+func (e *caseExpr) eval(ex *executor, r row) (any, error) {
+	var subject any
+	if e.subject != nil {
+		var err error
+		if subject, err = e.subject.eval(ex, r); err != nil {
+			return nil, err
+		}
+	}
+	for i, when := range e.whens {
+		value, err := when.eval(ex, r)
+		if err != nil {
+			return nil, err
+		}
+		if (e.subject == nil && value == true) || (e.subject != nil && equals(subject, value) == true) {
+			return e.thens[i].eval(ex, r)
+		}
+	}
+	if e.otherwise == nil {
+		return nil, nil
+	}
+	return e.otherwise.eval(ex, r)
+}
+
+func (e *listComprehensionExpr) eval(ex *executor, r row) (any, error) {
+	items, err := evalList(ex, r, e.list)
+	if err != nil || items == nil {
+		return nil, err
+	}
+	result := make([]any, 0, len(items))
+	for _, item := range items {
+		scope := r.with(e.variable, item)
+		if e.where != nil {
+			keep, err := e.where.eval(ex, scope)
+			if err != nil {
+				return nil, err
+			}
+			if keep != true {
+				continue
+			}
+		}
+		if e.project != nil {
+			if item, err = e.project.eval(ex, scope); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// evalList evaluates an expression that must be a list or null
+func evalList(ex *executor, r row, e expr) ([]any, error) {
+	value, err := e.eval(ex, r)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: expected a List but was %s", typeName(value))
+	}
+	return list, nil
+}
+
+func (e *quantifierExpr) eval(ex *executor, r row) (any, error) {
+	value, err := e.list.eval(ex, r)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: expected a List but was %s", typeName(value))
+	}
+	trues, nulls := 0, 0
+	for _, item := range items {
+		result, err := evalBoolean(ex, r.with(e.variable, item), e.where)
+		if err != nil {
+			return nil, err
+		}
+		switch result {
+		case true:
+			trues++
+		case nil:
+			nulls++
+		}
+	}
+	falses := len(items) - trues - nulls
+	switch {
+	case e.kind == "any" && trues > 0, e.kind == "all" && falses > 0,
+		e.kind == "none" && trues > 0, e.kind == "single" && trues > 1:
+		return e.kind == "any", nil
+	case nulls > 0:
+		return nil, nil
+	case e.kind == "single":
+		return trues == 1, nil
+	}
+	return e.kind != "any", nil
+}
+
+func (e *patternExpr) eval(ex *executor, r row) (any, error) {
+	found, err := ex.hasMatch([]*pattern{e.pattern}, r)
+	return found, err
+}
+
+func (e *patternComprehensionExpr) eval(ex *executor, r row) (any, error) {
+	result := []any{}
+	err := ex.matchPatterns([]*pattern{e.pattern}, r, func(m row) error {
+		if e.where != nil {
+			keep, err := e.where.eval(ex, m)
+			if err != nil || keep != true {
+				return err
+			}
+		}
+		value, err := e.project.eval(ex, m)
+		if err != nil {
+			return err
+		}
+		result = append(result, value)
+		return nil
+	})
+	return result, err
+}
+
+func (e *subqueryExpr) eval(ex *executor, r row) (any, error) {
+	rows, err := ex.run(e.query, []row{r})
+	if err != nil {
+		return nil, err
+	}
+	if e.count {
+		return int64(len(rows)), nil
+	}
+	return len(rows) > 0, nil
+}
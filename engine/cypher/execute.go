@@ -0,0 +1,534 @@
+package cypher
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// Execute parses a read-only query and runs it against a graph. Result rows
+// map column names to values of the types the Neo4j driver returns.
+func Execute(ctx context.Context, g *Graph, query string, params map[string]any) ([]map[string]any, error) {
+	parsed, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Execute(ctx, g, params)
+}
+
+// Execute runs the query against a graph
+func (q *Query) Execute(ctx context.Context, g *Graph, params map[string]any) ([]map[string]any, error) {
+	results := make([]map[string]any, 0)
+	err := q.Stream(ctx, g, params, func(result map[string]any) error {
+		results = append(results, result)
+		return nil
+	})
+	return results, err
+}
+
+// Stream runs the query against a graph and passes each result row to fn,
+// converting rows one at a time. An error of fn stops the query and is
+// returned.
+func (q *Query) Stream(ctx context.Context, g *Graph, params map[string]any, fn func(map[string]any) error) error {
+	ex := newExecutor(ctx, g, params)
+	rows, err := q.execute(ex)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(ex.result(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// result converts a row to a result of the values the Neo4j driver returns
+func (ex *executor) result(r row) map[string]any {
+	result := make(map[string]any, len(r))
+	for key, value := range r {
+		result[key] = ex.graph.result(value)
+	}
+	return result
+}
+
+func (q *Query) execute(ex *executor) ([]row, error) {
+	var columns []string
+	var rows []row
+	seen := make(map[string]bool)
+	for i, part := range q.parts {
+		partRows, err := ex.run(part, []row{{}})
+		if err != nil {
+			return nil, err
+		}
+		partColumns := part.columns()
+		if i > 0 && partColumns != nil && columns != nil && !slices.Equal(columns, partColumns) {
+			return nil, fmt.Errorf("all queries in a UNION must return the same columns")
+		}
+		columns = partColumns
+		for _, r := range partRows {
+			if len(q.parts) > 1 && !q.unionAll {
+				key := valueKey(map[string]any(r))
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			rows = append(rows, r)
+		}
+	}
+	return rows, nil
+}
+
+// columns returns the sorted names a query returns, or nil when RETURN * leaves
+// them to the rows
+func (q *singleQuery) columns() []string {
+	last, ok := q.clauses[len(q.clauses)-1].(*projectionClause)
+	if !ok || last.star {
+		return nil
+	}
+	columns := make([]string, len(last.items))
+	for i, item := range last.items {
+		columns[i] = item.alias
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// executor holds the state of one query execution
+type executor struct {
+	ctx        context.Context
+	graph      *Graph
+	params     map[string]any
+	regexps    map[string]*regexp.Regexp
+	aggregates map[*funcExpr]any // Values of the aggregates of the group being projected
+	reversed   map[*pattern]*pattern
+	steps      int
+	profile    map[clause]int // Rows produced by each clause, when profiling
+}
+
+func newExecutor(ctx context.Context, g *Graph, params map[string]any) *executor {
+	normalized := make(map[string]any, len(params))
+	for key, value := range params {
+		normalized[key] = normalize(value)
+	}
+	return &executor{
+		ctx:      ctx,
+		graph:    g,
+		params:   normalized,
+		regexps:  make(map[string]*regexp.Regexp),
+		reversed: make(map[*pattern]*pattern),
+	}
+}
+
+// tick checks for cancellation every so many matching steps
+func (ex *executor) tick() error {
+	ex.steps++
+	if ex.steps%1024 == 0 {
+		return ex.ctx.Err()
+	}
+	return nil
+}
+
+// run applies the clauses of a query to input rows
+func (ex *executor) run(q *singleQuery, rows []row) ([]row, error) {
+	for _, c := range q.clauses {
+		if err := ex.ctx.Err(); err != nil {
+			return nil, err
+		}
+		var err error
+		switch c := c.(type) {
+		case *matchClause:
+			rows, err = ex.match(c, rows)
+		case *unwindClause:
+			rows, err = ex.unwind(c, rows)
+		case *projectionClause:
+			rows, err = ex.project(c, rows)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ex.profile != nil {
+			ex.profile[c] += len(rows)
+		}
+	}
+	return rows, nil
+}
+
+func (ex *executor) unwind(c *unwindClause, rows []row) ([]row, error) {
+	var out []row
+	for _, r := range rows {
+		value, err := c.list.eval(ex, r)
+		if err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case nil:
+		case []any:
+			for _, item := range v {
+				out = append(out, r.with(c.alias, item))
+			}
+		default:
+			out = append(out, r.with(c.alias, v))
+		}
+	}
+	return out, nil
+}
+
+// projected is an output row of WITH or RETURN with the row it came from,
+// which ORDER BY can still read
+type projected struct {
+	values     row
+	source     row
+	aggregates map[*funcExpr]any
+}
+
+func (ex *executor) project(c *projectionClause, rows []row) ([]row, error) {
+	items := c.items
+	if c.star {
+		items = append(starItems(rows), items...)
+	}
+	var sortExprs []expr
+	for _, item := range c.order {
+		sortExprs = append(sortExprs, item.expr)
+	}
+	var out []*projected
+	var err error
+	if aggregates := findAggregates(items, sortExprs); len(aggregates) > 0 {
+		out, err = ex.aggregate(items, aggregates, rows)
+	} else {
+		out, err = ex.projectRows(items, rows)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.distinct {
+		out = distinctRows(out)
+	}
+	if err := ex.sortRows(c.order, out); err != nil {
+		return nil, err
+	}
+	if out, err = ex.paginate(c, out); err != nil {
+		return nil, err
+	}
+	result := make([]row, 0, len(out))
+	for _, p := range out {
+		if c.where != nil {
+			keep, err := c.where.eval(ex, p.values)
+			if err != nil {
+				return nil, err
+			}
+			if keep != true {
+				continue
+			}
+		}
+		result = append(result, p.values)
+	}
+	return result, nil
+}
+
+// starItems returns an item for every variable in scope, for WITH * and
+// RETURN *
+func starItems(rows []row) []*projectionItem {
+	if len(rows) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(rows[0]))
+	for name := range rows[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := make([]*projectionItem, len(names))
+	for i, name := range names {
+		items[i] = &projectionItem{expr: &variableExpr{name: name}, alias: name}
+	}
+	return items
+}
+
+// findAggregates returns the aggregating function calls of projection items
+// and sort expressions
+func findAggregates(items []*projectionItem, sortExprs []expr) []*funcExpr {
+	var found []*funcExpr
+	var walk func(e expr)
+	walk = func(e expr) {
+		if f, ok := e.(*funcExpr); ok && aggregateFunctions[f.name] {
+			found = append(found, f)
+			return
+		}
+		for _, child := range e.children() {
+			walk(child)
+		}
+	}
+	for _, item := range items {
+		walk(item.expr)
+	}
+	for _, e := range sortExprs {
+		walk(e)
+	}
+	return found
+}
+
+func (ex *executor) projectRows(items []*projectionItem, rows []row) ([]*projected, error) {
+	out := make([]*projected, 0, len(rows))
+	for _, r := range rows {
+		values, err := ex.projectItems(items, r)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &projected{values: values, source: r})
+	}
+	return out, nil
+}
+
+func (ex *executor) projectItems(items []*projectionItem, r row) (row, error) {
+	values := make(row, len(items))
+	for _, item := range items {
+		value, err := item.expr.eval(ex, r)
+		if err != nil {
+			return nil, err
+		}
+		values[item.alias] = value
+	}
+	return values, nil
+}
+
+type group struct {
+	first row
+	rows  []row
+}
+
+// aggregate groups rows by the items without aggregates and projects one row
+// per group. Without grouping items, even no rows make one group.
+func (ex *executor) aggregate(items []*projectionItem, aggregates []*funcExpr, rows []row) ([]*projected, error) {
+	var keyItems []*projectionItem
+	for _, item := range items {
+		if len(findAggregates([]*projectionItem{item}, nil)) == 0 {
+			keyItems = append(keyItems, item)
+		}
+	}
+	groups := make(map[string]*group)
+	var order []*group
+	for _, r := range rows {
+		keyValues, err := ex.projectItems(keyItems, r)
+		if err != nil {
+			return nil, err
+		}
+		key := valueKey(map[string]any(keyValues))
+		g, ok := groups[key]
+		if !ok {
+			g = &group{first: r}
+			groups[key] = g
+			order = append(order, g)
+		}
+		g.rows = append(g.rows, r)
+	}
+	if len(keyItems) == 0 && len(order) == 0 {
+		order = append(order, &group{first: row{}})
+	}
+	out := make([]*projected, 0, len(order))
+	for _, g := range order {
+		computed := make(map[*funcExpr]any, len(aggregates))
+		for _, f := range aggregates {
+			value, err := ex.computeAggregate(f, g.rows)
+			if err != nil {
+				return nil, err
+			}
+			computed[f] = value
+		}
+		var values row
+		err := ex.withAggregates(computed, func() error {
+			var err error
+			values, err = ex.projectItems(items, g.first)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &projected{values: values, source: g.first, aggregates: computed})
+	}
+	return out, nil
+}
+
+// withAggregates runs fn with the aggregate values of a group in scope
+func (ex *executor) withAggregates(values map[*funcExpr]any, fn func() error) error {
+	saved := ex.aggregates
+	ex.aggregates = values
+	defer func() { ex.aggregates = saved }()
+	return fn()
+}
+
+func (ex *executor) computeAggregate(f *funcExpr, rows []row) (any, error) {
+	if f.star {
+		if f.name != "count" {
+			return nil, fmt.Errorf("%s(*) is not supported, only count(*) is", f.name)
+		}
+		return int64(len(rows)), nil
+	}
+	if len(f.args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to %s(): %d", f.name, len(f.args))
+	}
+	values := []any{}
+	seen := make(map[string]bool)
+	for _, r := range rows {
+		value, err := f.args[0].eval(ex, r)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		if f.distinct {
+			key := valueKey(value)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		values = append(values, value)
+	}
+	switch f.name {
+	case "count":
+		return int64(len(values)), nil
+	case "collect":
+		return values, nil
+	case "sum", "avg":
+		return sumValues(f.name, values)
+	}
+	return extreme(f.name, values), nil
+}
+
+// sumValues adds numbers for sum and avg. Sums of integers stay integers.
+func sumValues(name string, values []any) (any, error) {
+	var intSum int64
+	var floatSum float64
+	floats := false
+	for _, value := range values {
+		switch v := value.(type) {
+		case int64:
+			intSum += v
+			floatSum += float64(v)
+		case float64:
+			floats = true
+			floatSum += v
+		default:
+			return nil, fmt.Errorf("type mismatch: %s() expects numbers but got %s", name, typeName(value))
+		}
+	}
+	switch {
+	case name == "avg" && len(values) == 0:
+		return nil, nil
+	case name == "avg":
+		return floatSum / float64(len(values)), nil
+	case floats:
+		return floatSum, nil
+	}
+	return intSum, nil
+}
+
+// extreme returns the smallest value for min or the largest for max
+func extreme(name string, values []any) any {
+	var result any
+	for _, value := range values {
+		c := orderCompare(value, result)
+		if result == nil || (name == "min" && c < 0) || (name == "max" && c > 0) {
+			result = value
+		}
+	}
+	return result
+}
+
+func distinctRows(out []*projected) []*projected {
+	seen := make(map[string]bool, len(out))
+	unique := out[:0]
+	for _, p := range out {
+		key := valueKey(map[string]any(p.values))
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, p)
+		}
+	}
+	return unique
+}
+
+// sortRows orders rows by sort items, which see both the projected columns
+// and the variables of the rows they came from
+func (ex *executor) sortRows(order []*sortItem, out []*projected) error {
+	if len(order) == 0 {
+		return nil
+	}
+	keys := make(map[*projected][]any, len(out))
+	for _, p := range out {
+		scope := make(row, len(p.source)+len(p.values))
+		for name, value := range p.source {
+			scope[name] = value
+		}
+		for name, value := range p.values {
+			scope[name] = value
+		}
+		key := make([]any, len(order))
+		err := ex.withAggregates(p.aggregates, func() error {
+			for i, item := range order {
+				value, err := item.expr.eval(ex, scope)
+				if err != nil {
+					return err
+				}
+				key[i] = value
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		keys[p] = key
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := keys[out[i]], keys[out[j]]
+		for k, item := range order {
+			c := orderCompare(a[k], b[k])
+			if item.descending {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+func (ex *executor) paginate(c *projectionClause, out []*projected) ([]*projected, error) {
+	if c.skip != nil {
+		skip, err := ex.count("SKIP", c.skip)
+		if err != nil {
+			return nil, err
+		}
+		out = out[min(skip, len(out)):]
+	}
+	if c.limit != nil {
+		limit, err := ex.count("LIMIT", c.limit)
+		if err != nil {
+			return nil, err
+		}
+		out = out[:min(limit, len(out))]
+	}
+	return out, nil
+}
+
+// count evaluates the non-negative integer of SKIP or LIMIT
+func (ex *executor) count(clause string, e expr) (int, error) {
+	value, err := e.eval(ex, row{})
+	if err != nil {
+		return 0, err
+	}
+	n, ok := value.(int64)
+	if !ok || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %v", clause, value)
+	}
+	return int(n), nil
+}
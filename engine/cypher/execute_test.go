@@ -0,0 +1,361 @@
+package cypher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/cypher"
+	"github.com/compozy/gograph/engine/query"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixture is a small project of two packages that depend on each other, with
+// calls, an interface and a struct, plus one node of another project
+type fixture struct {
+	graph *cypher.Graph
+	ids   map[string]core.ID
+}
+
+func newFixture() *fixture {
+	f := &fixture{ids: make(map[string]core.ID)}
+	var nodes []*core.Node
+	var rels []*core.Relationship
+	node := func(key string, nodeType core.NodeType, name string, props map[string]any) {
+		if props["project_id"] == nil {
+			props["project_id"] = "p1"
+		}
+		f.ids[key] = core.ID(key + "-id")
+		nodes = append(nodes, &core.Node{ID: f.ids[key], Type: nodeType, Name: name, Properties: props})
+	}
+	rel := func(from string, relType core.RelationType, to string) {
+		rels = append(rels, &core.Relationship{ID: core.ID(from + "-" + string(relType) + "-" + to),
+			Type: relType, FromNodeID: f.ids[from], ToNodeID: f.ids[to],
+			Properties: map[string]any{"project_id": "p1"}})
+	}
+	node("pkgA", core.NodeTypePackage, "a", map[string]any{})
+	node("pkgB", core.NodeTypePackage, "b", map[string]any{})
+	node("fileA", core.NodeTypeFile, "a.go", map[string]any{"package": "a"})
+	node("fileB", core.NodeTypeFile, "b.go", map[string]any{"package": "b"})
+	node("main", core.NodeTypeFunction, "main", map[string]any{"package": "a", "is_exported": false,
+		"line_start": 1, "line_end": 10, "signature": "func main()"})
+	node("run", core.NodeTypeFunction, "Run", map[string]any{"package": "a", "is_exported": true,
+		"line_start": 12, "line_end": 40, "signature": "func Run() error", "returns": []string{"error"}})
+	node("helper", core.NodeTypeFunction, "Helper", map[string]any{"package": "b", "is_exported": true,
+		"line_start": 3, "line_end": 5, "signature": "func Helper(x int) int", "returns": []string{"int"}})
+	node("unused", core.NodeTypeFunction, "unused", map[string]any{"package": "b", "is_exported": false,
+		"line_start": 7, "line_end": 9, "signature": "func unused()"})
+	node("reader", core.NodeTypeInterface, "Reader", map[string]any{"package": "b", "is_exported": true})
+	node("writer", core.NodeTypeInterface, "Writer", map[string]any{"package": "b", "is_exported": true})
+	node("file", core.NodeTypeStruct, "File", map[string]any{"package": "b", "is_exported": true})
+	node("read", core.NodeTypeFunction, "Read", map[string]any{"package": "b", "is_exported": true,
+		"line_start": 12, "line_end": 14, "signature": "func (f *File) Read() error"})
+	node("fmt", core.NodeTypeImport, "fmt", map[string]any{"path": "fmt"})
+	node("local", core.NodeTypeImport, "local", map[string]any{"path": "./local"})
+	node("other", core.NodeTypeFunction, "Run", map[string]any{"project_id": "p2", "package": "x"})
+	rel("pkgA", core.RelationContains, "fileA")
+	rel("pkgB", core.RelationContains, "fileB")
+	rel("fileA", core.RelationContains, "main")
+	rel("fileA", core.RelationContains, "run")
+	rel("fileB", core.RelationContains, "helper")
+	rel("fileB", core.RelationContains, "unused")
+	rel("main", core.RelationCalls, "run")
+	rel("main", core.RelationCalls, "helper")
+	rel("run", core.RelationCalls, "helper")
+	rel("run", core.RelationCalls, "read")
+	rel("fileA", core.RelationDependsOn, "fileB")
+	rel("pkgA", core.RelationDependsOn, "pkgB")
+	rel("pkgB", core.RelationDependsOn, "pkgA")
+	rel("file", core.RelationImplements, "reader")
+	rel("file", core.RelationType("HAS_METHOD"), "read")
+	f.graph = cypher.NewGraph(nodes, rels)
+	return f
+}
+
+func (f *fixture) run(t *testing.T, q string, params map[string]any) []map[string]any {
+	t.Helper()
+	rows, err := cypher.Execute(context.Background(), f.graph, q, params)
+	require.NoError(t, err, q)
+	return rows
+}
+
+// column returns the values of one column
+func column(rows []map[string]any, name string) []any {
+	values := make([]any, len(rows))
+	for i, row := range rows {
+		values[i] = row[name]
+	}
+	return values
+}
+
+func TestExecute_Matching(t *testing.T) {
+	f := newFixture()
+
+	t.Run("Should filter, project and order rows", func(t *testing.T) {
+		rows := f.run(t, `MATCH (fn:Function) WHERE fn.project_id = $project_id AND fn.is_exported
+			RETURN fn.name AS name, fn.line_end - fn.line_start AS size ORDER BY size DESC, name SKIP 1 LIMIT 2`,
+			map[string]any{"project_id": "p1"})
+		assert.Equal(t, []any{"Helper", "Read"}, column(rows, "name"))
+		assert.Equal(t, []any{int64(2), int64(2)}, column(rows, "size"))
+	})
+
+	t.Run("Should return nodes, relationships and paths as the Neo4j driver does", func(t *testing.T) {
+		rows := f.run(t, `MATCH p = (a:Function {name: 'main'})-[r:CALLS]->(b {id: $id}) RETURN a, r, p`,
+			map[string]any{"id": string(f.ids["run"])})
+		require.Len(t, rows, 1)
+		a, ok := rows[0]["a"].(dbtype.Node)
+		require.True(t, ok)
+		assert.Equal(t, []string{"Function"}, a.Labels)
+		assert.Equal(t, string(f.ids["main"]), a.ElementId)
+		assert.Equal(t, "main", a.Props["name"])
+		assert.Equal(t, int64(1), a.Props["line_start"])
+		r, ok := rows[0]["r"].(dbtype.Relationship)
+		require.True(t, ok)
+		assert.Equal(t, "CALLS", r.Type)
+		assert.Equal(t, a.ElementId, r.StartElementId)
+		p, ok := rows[0]["p"].(dbtype.Path)
+		require.True(t, ok)
+		assert.Len(t, p.Nodes, 2)
+		assert.Len(t, p.Relationships, 1)
+	})
+
+	t.Run("Should match in either direction and across comma-separated patterns", func(t *testing.T) {
+		rows := f.run(t, `MATCH (h:Function {name: 'Helper'})-[:CALLS]-(caller), (caller)<-[:CONTAINS]-(file)
+			RETURN caller.name AS caller, file.name AS file ORDER BY caller`, nil)
+		assert.Equal(t, []any{"Run", "main"}, column(rows, "caller"))
+		assert.Equal(t, []any{"a.go", "a.go"}, column(rows, "file"))
+	})
+
+	t.Run("Should keep rows without a match in OPTIONAL MATCH", func(t *testing.T) {
+		rows := f.run(t, `MATCH (fn:Function {project_id: 'p1'}) OPTIONAL MATCH (fn)-[:CALLS]->(callee)
+			WHERE callee.package = 'b'
+			RETURN fn.name AS name, count(callee) AS calls ORDER BY name`, nil)
+		assert.Equal(t, []any{"Helper", "Read", "Run", "main", "unused"}, column(rows, "name"))
+		assert.Equal(t, []any{int64(0), int64(0), int64(2), int64(1), int64(0)}, column(rows, "calls"))
+	})
+
+	t.Run("Should follow variable-length relationships", func(t *testing.T) {
+		rows := f.run(t, `MATCH path = (:Function {name: 'main'})-[:CALLS*1..2]->(fn)
+			RETURN DISTINCT fn.name AS name, min(length(path)) AS depth ORDER BY depth, name`, nil)
+		assert.Equal(t, []any{"Helper", "Run", "Read"}, column(rows, "name"))
+		assert.Equal(t, []any{int64(1), int64(1), int64(2)}, column(rows, "depth"))
+	})
+
+	t.Run("Should find cycles", func(t *testing.T) {
+		rows := f.run(t, `MATCH path = (p:Package)-[:DEPENDS_ON*2..]->(p)
+			RETURN p.name AS name, [n IN nodes(path) | n.name] AS cycle ORDER BY name`, nil)
+		assert.Equal(t, []any{"a", "b"}, column(rows, "name"))
+		assert.Equal(t, []any{"a", "b", "a"}, rows[0]["cycle"])
+	})
+
+	t.Run("Should find shortest paths", func(t *testing.T) {
+		rows := f.run(t, `MATCH p = shortestPath((a {id: $from})-[*..5]-(b {id: $to}))
+			RETURN length(p) AS hops, [n IN nodes(p) | n.name] AS names`,
+			map[string]any{"from": string(f.ids["main"]), "to": string(f.ids["read"])})
+		require.Len(t, rows, 1)
+		assert.Equal(t, int64(2), rows[0]["hops"])
+		assert.Equal(t, []any{"main", "Run", "Read"}, rows[0]["names"])
+
+		rows = f.run(t, `MATCH p = allShortestPaths((a:Function {name: 'main'})-[*]-(b:Function {name: 'unused'}))
+			RETURN p`, nil)
+		assert.Len(t, rows, 2, "through the call to Helper and through the file dependency")
+	})
+
+	t.Run("Should evaluate pattern predicates and subqueries", func(t *testing.T) {
+		rows := f.run(t, `MATCH (fn:Function {project_id: 'p1'})
+			WHERE NOT EXISTS { MATCH ()-[:CALLS]->(fn) } AND NOT (fn)<-[:HAS_METHOD]-()
+			RETURN fn.name AS name, COUNT { (fn)-[:CALLS]->() } AS calls ORDER BY name`, nil)
+		assert.Equal(t, []any{"main", "unused"}, column(rows, "name"))
+		assert.Equal(t, []any{int64(2), int64(0)}, column(rows, "calls"))
+
+		rows = f.run(t, `MATCH (i:Interface) WHERE exists((i)<-[:IMPLEMENTS]-(:Struct)) RETURN i.name AS name`, nil)
+		assert.Equal(t, []any{"Reader"}, column(rows, "name"))
+	})
+
+	t.Run("Should bind the same node at both ends of a pattern", func(t *testing.T) {
+		rows := f.run(t, `MATCH (a:Package)-[:DEPENDS_ON]->(b)-[:DEPENDS_ON]->(a) RETURN a.name AS a ORDER BY a`, nil)
+		assert.Equal(t, []any{"a", "b"}, column(rows, "a"))
+	})
+}
+
+func TestExecute_Expressions(t *testing.T) {
+	f := newFixture()
+
+	t.Run("Should aggregate per group and over no rows", func(t *testing.T) {
+		rows := f.run(t, `MATCH (n) WHERE n.project_id = 'p1'
+			RETURN labels(n)[0] AS type, count(n) AS count ORDER BY count DESC, type LIMIT 2`, nil)
+		assert.Equal(t, []any{"Function", "File"}, column(rows, "type"))
+		assert.Equal(t, []any{int64(5), int64(2)}, column(rows, "count"))
+
+		rows = f.run(t, `MATCH (n:Missing) RETURN count(n) > 0 AS exists, collect(n.name) AS names, sum(n.x) AS total`, nil)
+		require.Len(t, rows, 1)
+		assert.Equal(t, false, rows[0]["exists"])
+		assert.Equal(t, []any{}, rows[0]["names"])
+		assert.Equal(t, int64(0), rows[0]["total"])
+
+		rows = f.run(t, `MATCH (n:Missing) RETURN n.name AS name, count(*) AS c`, nil)
+		assert.Empty(t, rows)
+	})
+
+	t.Run("Should collect distinct values and maps", func(t *testing.T) {
+		rows := f.run(t, `MATCH (fn:Function {project_id: 'p1'})
+			WITH fn.package AS pkg, collect(DISTINCT {exported: fn.is_exported}) AS kinds, avg(fn.line_start) AS avg
+			RETURN pkg, size(kinds) AS kinds, avg ORDER BY pkg`, nil)
+		assert.Equal(t, []any{"a", "b"}, column(rows, "pkg"))
+		assert.Equal(t, []any{int64(2), int64(2)}, column(rows, "kinds"))
+		assert.InDelta(t, 6.5, rows[0]["avg"], 0.001)
+	})
+
+	t.Run("Should evaluate functions, CASE, comprehensions and regular expressions", func(t *testing.T) {
+		rows := f.run(t, `MATCH (fn:Function {name: 'Run', project_id: 'p1'})
+			RETURN toUpper(fn.name) + '!' AS shout,
+			       CASE WHEN fn.is_exported THEN 'public' ELSE 'private' END AS visibility,
+			       coalesce(fn.missing, fn.package) AS pkg,
+			       any(r IN fn.returns WHERE r = 'error') AS fails,
+			       [x IN range(1, 5) WHERE x % 2 = 1 | x * 10] AS odd,
+			       fn.name =~ '(?i)r.*' AS matches,
+			       abs(size(fn.name) - size($other)) AS distance,
+			       split(fn.signature, ' ')[-1] AS result,
+			       keys(fn {.name, .package}) AS keys`,
+			map[string]any{"other": "Runner"})
+		require.Len(t, rows, 1)
+		row := rows[0]
+		assert.Equal(t, "RUN!", row["shout"])
+		assert.Equal(t, "public", row["visibility"])
+		assert.Equal(t, "a", row["pkg"])
+		assert.Equal(t, true, row["fails"])
+		assert.Equal(t, []any{int64(10), int64(30), int64(50)}, row["odd"])
+		assert.Equal(t, true, row["matches"])
+		assert.Equal(t, int64(3), row["distance"])
+		assert.Equal(t, "error", row["result"])
+		assert.Equal(t, []any{"name", "package"}, row["keys"])
+	})
+
+	t.Run("Should apply three-valued logic to nulls", func(t *testing.T) {
+		rows := f.run(t, `RETURN null = null AS eq, null OR true AS either, null AND true AS both,
+			1 IN [null, 2] AS member, null IS NULL AS missing, 2 < 3 <= 3 AS chain, 1 = 1.0 AS numeric`, nil)
+		require.Len(t, rows, 1)
+		assert.Nil(t, rows[0]["eq"])
+		assert.Equal(t, true, rows[0]["either"])
+		assert.Nil(t, rows[0]["both"])
+		assert.Nil(t, rows[0]["member"])
+		assert.Equal(t, true, rows[0]["missing"])
+		assert.Equal(t, true, rows[0]["chain"])
+		assert.Equal(t, true, rows[0]["numeric"])
+	})
+
+	t.Run("Should unwind lists and combine unions", func(t *testing.T) {
+		rows := f.run(t, `UNWIND ['b', 'a', 'b'] AS name RETURN name
+			UNION MATCH (p:Package) RETURN p.name AS name`, nil)
+		assert.ElementsMatch(t, []any{"a", "b"}, column(rows, "name"))
+
+		rows = f.run(t, `UNWIND [3, 1, 2] AS x RETURN x ORDER BY x UNION ALL RETURN 1 AS x`, nil)
+		assert.Equal(t, []any{int64(1), int64(2), int64(3), int64(1)}, column(rows, "x"))
+	})
+
+	t.Run("Should report errors for missing parameters, unknown functions and type mismatches", func(t *testing.T) {
+		_, err := cypher.Execute(context.Background(), f.graph, "MATCH (n {id: $id}) RETURN n", nil)
+		assert.ErrorContains(t, err, "$id")
+		_, err = cypher.Execute(context.Background(), f.graph, "RETURN apoc.text.join(['a'], ',') AS s", nil)
+		assert.ErrorIs(t, err, cypher.ErrUnsupported)
+		_, err = cypher.Execute(context.Background(), f.graph, "RETURN 1 + true AS x", nil)
+		assert.ErrorContains(t, err, "type mismatch")
+		_, err = cypher.Execute(context.Background(), f.graph, "RETURN x", nil)
+		assert.ErrorContains(t, err, "not defined")
+	})
+
+	t.Run("Should stop when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := cypher.Execute(ctx, f.graph, "MATCH (a)-[*]-(b) RETURN count(*) AS paths", nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestExecute_Templates(t *testing.T) {
+	f := newFixture()
+	params := map[string]any{
+		"project_id":     "p1",
+		"function_name":  "run",
+		"struct_name":    "file",
+		"interface_name": "read",
+		"search_term":    "error",
+	}
+
+	t.Run("Should run every query template", func(t *testing.T) {
+		for name, template := range query.CommonTemplates {
+			_, err := cypher.Execute(context.Background(), f.graph, template.Query, params)
+			assert.NoError(t, err, name)
+		}
+	})
+
+	t.Run("Should answer templates as Neo4j would", func(t *testing.T) {
+		rows := f.run(t, query.CommonTemplates["unimplemented_interfaces"].Query, params)
+		assert.Equal(t, []any{"Writer"}, column(rows, "interface_name"))
+
+		rows = f.run(t, query.CommonTemplates["unused_functions"].Query, params)
+		assert.Equal(t, []any{"unused"}, column(rows, "function_name"))
+
+		rows = f.run(t, query.CommonTemplates["most_called_functions"].Query, params)
+		assert.Equal(t, "Helper", rows[0]["function_name"])
+		assert.Equal(t, int64(2), rows[0]["call_count"])
+
+		rows = f.run(t, query.CommonTemplates["external_dependencies"].Query, params)
+		assert.Equal(t, []any{"fmt"}, column(rows, "external_package"))
+
+		rows = f.run(t, query.CommonTemplates["find_function"].Query, params)
+		assert.Equal(t, []any{"Run"}, column(rows, "function_name"))
+	})
+}
+
+func TestQuery_Plan(t *testing.T) {
+	f := newFixture()
+	query := `MATCH (fn:Function {project_id: 'p1'}) WHERE fn.is_exported
+		WITH fn ORDER BY fn.name LIMIT 2
+		OPTIONAL MATCH (fn)-[:CALLS]->(callee)
+		RETURN fn.name AS name, count(callee) AS calls`
+
+	t.Run("Should describe each clause in order", func(t *testing.T) {
+		parsed, err := cypher.Parse(query)
+		require.NoError(t, err)
+		plan := parsed.Explain()
+		var operators []string
+		for step := plan; step != nil; {
+			operators = append(operators, step.Operator)
+			if len(step.Children) == 0 {
+				break
+			}
+			step = step.Children[0]
+		}
+		assert.Equal(t, []string{"RETURN", "OPTIONAL MATCH", "WITH", "MATCH"}, operators)
+		assert.Equal(t, "fn.name AS name, count(callee) AS calls", plan.Details)
+		assert.Equal(t, "(fn)-[:CALLS]->(callee)", plan.Children[0].Details)
+		assert.Zero(t, plan.Rows)
+	})
+
+	t.Run("Should count the rows of each clause when profiling", func(t *testing.T) {
+		parsed, err := cypher.Parse(query)
+		require.NoError(t, err)
+		plan, rows, err := parsed.Profile(context.Background(), f.graph, nil)
+		require.NoError(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, 2, plan.Rows)
+		assert.Equal(t, 2, plan.Children[0].Rows) // Helper and Read call nothing
+		assert.Equal(t, 2, plan.Children[0].Children[0].Rows)
+		assert.Equal(t, 3, plan.Children[0].Children[0].Children[0].Rows)
+	})
+
+	t.Run("Should put the parts of a union under one operator", func(t *testing.T) {
+		parsed, err := cypher.Parse(`RETURN 1 AS x UNION ALL UNWIND [2, 3] AS x RETURN x`)
+		require.NoError(t, err)
+		plan, _, err := parsed.Profile(context.Background(), f.graph, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "UNION ALL", plan.Operator)
+		assert.Equal(t, 3, plan.Rows)
+		require.Len(t, plan.Children, 2)
+		assert.Equal(t, "UNWIND", plan.Children[1].Children[0].Operator)
+		assert.Equal(t, "[2, 3] AS x", plan.Children[1].Children[0].Details)
+	})
+}
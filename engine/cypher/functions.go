@@ -0,0 +1,528 @@
+package cypher
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// aggregateFunctions are computed over the rows of a group rather than one row
+var aggregateFunctions = map[string]bool{
+	"count": true, "collect": true, "sum": true, "avg": true, "min": true, "max": true,
+}
+
+// function is a scalar function. A negative maxArgs allows any number of
+// arguments.
+type function struct {
+	minArgs, maxArgs int
+	call             func(ex *executor, args []any) (any, error)
+}
+
+var functions = map[string]function{
+	"coalesce":      {1, -1, coalesce},
+	"id":            {1, 1, entityID},
+	"elementid":     {1, 1, elementID},
+	"labels":        {1, 1, labels},
+	"type":          {1, 1, relationshipType},
+	"properties":    {1, 1, properties},
+	"keys":          {1, 1, keys},
+	"nodes":         {1, 1, pathNodes},
+	"relationships": {1, 1, pathRelationships},
+	"startnode":     {1, 1, startNode},
+	"endnode":       {1, 1, endNode},
+	"length":        {1, 1, length},
+	"size":          {1, 1, size},
+	"head":          {1, 1, nullable(listFunc(head))},
+	"last":          {1, 1, nullable(listFunc(last))},
+	"tail":          {1, 1, nullable(listFunc(tail))},
+	"isempty":       {1, 1, nullable(isEmpty)},
+	"reverse":       {1, 1, nullable(reverse)},
+	"range":         {2, 3, rangeList},
+	"tolower":       {1, 1, nullable(stringFunc(strings.ToLower))},
+	"toupper":       {1, 1, nullable(stringFunc(strings.ToUpper))},
+	"trim":          {1, 1, nullable(stringFunc(strings.TrimSpace))},
+	"ltrim":         {1, 1, nullable(stringFunc(trimLeft))},
+	"rtrim":         {1, 1, nullable(stringFunc(trimRight))},
+	"replace":       {3, 3, nullable(replace)},
+	"substring":     {2, 3, nullable(substring)},
+	"left":          {2, 2, nullable(left)},
+	"right":         {2, 2, nullable(right)},
+	"split":         {2, 2, nullable(split)},
+	"tostring":      {1, 1, nullable(toStringValue)},
+	"tointeger":     {1, 1, nullable(toInteger)},
+	"tofloat":       {1, 1, nullable(toFloatValue)},
+	"toboolean":     {1, 1, nullable(toBoolean)},
+	"abs":           {1, 1, nullable(numberFunc(math.Abs, true))},
+	"ceil":          {1, 1, nullable(numberFunc(math.Ceil, false))},
+	"floor":         {1, 1, nullable(numberFunc(math.Floor, false))},
+	"round":         {1, 1, nullable(numberFunc(roundHalfUp, false))},
+	"sqrt":          {1, 1, nullable(numberFunc(math.Sqrt, false))},
+	"sign":          {1, 1, nullable(sign)},
+	"timestamp":     {0, 0, timestamp},
+}
+
+// nullable wraps a function that returns null when its first argument is null
+func nullable(fn func(ex *executor, args []any) (any, error)) func(ex *executor, args []any) (any, error) {
+	return func(ex *executor, args []any) (any, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		return fn(ex, args)
+	}
+}
+
+func listFunc(fn func(list []any) any) func(ex *executor, args []any) (any, error) {
+	return func(_ *executor, args []any) (any, error) {
+		list, ok := args[0].([]any)
+		if !ok {
+			return nil, fmt.Errorf("type mismatch: expected a List but was %s", typeName(args[0]))
+		}
+		return fn(list), nil
+	}
+}
+
+func stringFunc(fn func(s string) string) func(ex *executor, args []any) (any, error) {
+	return func(_ *executor, args []any) (any, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("type mismatch: expected a String but was %s", typeName(args[0]))
+		}
+		return fn(s), nil
+	}
+}
+
+// numberFunc applies a float function to a number. Integers stay integers
+// when keepInt is set.
+func numberFunc(fn func(x float64) float64, keepInt bool) func(ex *executor, args []any) (any, error) {
+	return func(_ *executor, args []any) (any, error) {
+		if n, ok := args[0].(int64); ok && keepInt {
+			return int64(fn(float64(n))), nil
+		}
+		x, ok := toFloat(args[0])
+		if !ok {
+			return nil, fmt.Errorf("type mismatch: expected a number but was %s", typeName(args[0]))
+		}
+		return fn(x), nil
+	}
+}
+
+func coalesce(_ *executor, args []any) (any, error) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+func entityID(_ *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case nodeRef:
+		return int64(v), nil
+	case relRef:
+		return int64(v), nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a Node or Relationship but was %s", typeName(args[0]))
+}
+
+func elementID(ex *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case nodeRef:
+		return ex.graph.nodes[v].ID.String(), nil
+	case relRef:
+		return ex.graph.rels[v].ID.String(), nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a Node or Relationship but was %s", typeName(args[0]))
+}
+
+func labels(ex *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case nodeRef:
+		return []any{ex.graph.label(v)}, nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a Node but was %s", typeName(args[0]))
+}
+
+func relationshipType(ex *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case relRef:
+		return ex.graph.relType(v), nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a Relationship but was %s", typeName(args[0]))
+}
+
+func properties(ex *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case nodeRef:
+		return copyProps(ex.graph.nodeProps[v]), nil
+	case relRef:
+		return copyProps(ex.graph.relProps[v]), nil
+	case map[string]any:
+		return v, nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a Node, Relationship or Map but was %s", typeName(args[0]))
+}
+
+func keys(ex *executor, args []any) (any, error) {
+	props, err := properties(ex, args)
+	m, ok := props.(map[string]any)
+	if err != nil || !ok {
+		return nil, err
+	}
+	list := make([]any, 0, len(m))
+	for _, key := range sortedKeys(m) {
+		list = append(list, key)
+	}
+	return list, nil
+}
+
+func pathNodes(_ *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case pathValue:
+		list := make([]any, len(v.nodes))
+		for i, n := range v.nodes {
+			list[i] = n
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a Path but was %s", typeName(args[0]))
+}
+
+func pathRelationships(_ *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case pathValue:
+		list := make([]any, len(v.rels))
+		for i, r := range v.rels {
+			list[i] = r
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a Path but was %s", typeName(args[0]))
+}
+
+func startNode(ex *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case relRef:
+		return nodeRef(ex.graph.relStart[v]), nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a Relationship but was %s", typeName(args[0]))
+}
+
+func endNode(ex *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case relRef:
+		return nodeRef(ex.graph.relEnd[v]), nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a Relationship but was %s", typeName(args[0]))
+}
+
+// length is the number of relationships of a path. Like older Neo4j versions
+// it also measures strings and lists.
+func length(ex *executor, args []any) (any, error) {
+	if path, ok := args[0].(pathValue); ok {
+		return int64(len(path.rels)), nil
+	}
+	return size(ex, args)
+}
+
+func size(_ *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return int64(utf8.RuneCountInString(v)), nil
+	case []any:
+		return int64(len(v)), nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a String or List but was %s", typeName(args[0]))
+}
+
+func head(list []any) any {
+	if len(list) == 0 {
+		return nil
+	}
+	return list[0]
+}
+
+func last(list []any) any {
+	if len(list) == 0 {
+		return nil
+	}
+	return list[len(list)-1]
+}
+
+func tail(list []any) any {
+	if len(list) == 0 {
+		return []any{}
+	}
+	return list[1:]
+}
+
+func isEmpty(_ *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case string:
+		return v == "", nil
+	case []any:
+		return len(v) == 0, nil
+	case map[string]any:
+		return len(v) == 0, nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a String, List or Map but was %s", typeName(args[0]))
+}
+
+func reverse(_ *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case string:
+		runes := []rune(v)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[len(v)-1-i] = item
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("type mismatch: expected a String or List but was %s", typeName(args[0]))
+}
+
+func rangeList(_ *executor, args []any) (any, error) {
+	ints, err := integerArgs("range", args)
+	if err != nil {
+		return nil, err
+	}
+	start, end, step := ints[0], ints[1], int64(1)
+	if len(ints) == 3 {
+		step = ints[2]
+	}
+	if step == 0 {
+		return nil, fmt.Errorf("range() step cannot be zero")
+	}
+	list := []any{}
+	for i := start; (step > 0 && i <= end) || (step < 0 && i >= end); i += step {
+		list = append(list, i)
+	}
+	return list, nil
+}
+
+func integerArgs(name string, args []any) ([]int64, error) {
+	ints := make([]int64, len(args))
+	for i, arg := range args {
+		n, ok := arg.(int64)
+		if !ok {
+			return nil, fmt.Errorf("type mismatch: %s() expects Integer arguments but got %s", name, typeName(arg))
+		}
+		ints[i] = n
+	}
+	return ints, nil
+}
+
+func trimLeft(s string) string {
+	return strings.TrimLeftFunc(s, isSpace)
+}
+
+func trimRight(s string) string {
+	return strings.TrimRightFunc(s, isSpace)
+}
+
+func isSpace(r rune) bool {
+	return strings.ContainsRune(" \t\n\r\f\v", r)
+}
+
+func replace(_ *executor, args []any) (any, error) {
+	s, ok1 := args[0].(string)
+	search, ok2 := args[1].(string)
+	with, ok3 := args[2].(string)
+	switch {
+	case args[1] == nil || args[2] == nil:
+		return nil, nil
+	case !ok1 || !ok2 || !ok3:
+		return nil, fmt.Errorf("type mismatch: replace() expects String arguments")
+	}
+	return strings.ReplaceAll(s, search, with), nil
+}
+
+func substring(_ *executor, args []any) (any, error) {
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: expected a String but was %s", typeName(args[0]))
+	}
+	ints, err := integerArgs("substring", args[1:])
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune(s)
+	start := min(max(ints[0], 0), int64(len(runes)))
+	end := int64(len(runes))
+	if len(ints) == 2 {
+		if ints[1] < 0 {
+			return nil, fmt.Errorf("substring() length cannot be negative")
+		}
+		end = min(start+ints[1], end)
+	}
+	return string(runes[start:end]), nil
+}
+
+func left(_ *executor, args []any) (any, error) {
+	return sideOf("left", args, func(runes []rune, n int) string { return string(runes[:n]) })
+}
+
+func right(_ *executor, args []any) (any, error) {
+	return sideOf("right", args, func(runes []rune, n int) string { return string(runes[len(runes)-n:]) })
+}
+
+func sideOf(name string, args []any, cut func(runes []rune, n int) string) (any, error) {
+	s, ok := args[0].(string)
+	n, nOK := args[1].(int64)
+	if !ok || !nOK || n < 0 {
+		return nil, fmt.Errorf("%s() expects a String and a non-negative Integer", name)
+	}
+	runes := []rune(s)
+	return cut(runes, int(min(n, int64(len(runes))))), nil
+}
+
+func split(_ *executor, args []any) (any, error) {
+	s, ok := args[0].(string)
+	sep, sepOK := args[1].(string)
+	if args[1] == nil {
+		return nil, nil
+	}
+	if !ok || !sepOK {
+		return nil, fmt.Errorf("type mismatch: split() expects String arguments")
+	}
+	parts := strings.Split(s, sep)
+	list := make([]any, len(parts))
+	for i, part := range parts {
+		list[i] = part
+	}
+	return list, nil
+}
+
+func toStringValue(_ *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case string, bool, int64, float64, time.Time:
+		return formatValue(v), nil
+	}
+	return nil, fmt.Errorf("type mismatch: cannot convert %s to a String", typeName(args[0]))
+}
+
+// formatValue writes a primitive value as toString does
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.ContainsAny(s, ".eEIN") {
+			s += ".0"
+		}
+		return s
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
+
+func toInteger(_ *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			return n, nil
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return int64(f), nil
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("type mismatch: cannot convert %s to an Integer", typeName(args[0]))
+}
+
+func toFloatValue(_ *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f, nil
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("type mismatch: cannot convert %s to a Float", typeName(args[0]))
+}
+
+func toBoolean(_ *executor, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, nil
+	case int64:
+		return v != 0, nil
+	}
+	return nil, fmt.Errorf("type mismatch: cannot convert %s to a Boolean", typeName(args[0]))
+}
+
+// roundHalfUp rounds as Cypher does, with halves towards positive infinity
+func roundHalfUp(x float64) float64 {
+	return math.Floor(x + 0.5)
+}
+
+func sign(_ *executor, args []any) (any, error) {
+	x, ok := toFloat(args[0])
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: expected a number but was %s", typeName(args[0]))
+	}
+	switch {
+	case x > 0:
+		return int64(1), nil
+	case x < 0:
+		return int64(-1), nil
+	}
+	return int64(0), nil
+}
+
+func timestamp(_ *executor, _ []any) (any, error) {
+	return time.Now().UnixMilli(), nil
+}
@@ -0,0 +1,111 @@
+package cypher
+
+import (
+	"github.com/compozy/gograph/engine/core"
+)
+
+// Graph is an indexed, read-only view of nodes and relationships for queries
+// to run against. Node and relationship properties are seen as Neo4j stores
+// them: nodes also carry id, name and path, and relationships carry id.
+type Graph struct {
+	nodes     []*core.Node
+	rels      []*core.Relationship
+	nodeProps []map[string]any
+	relProps  []map[string]any
+	byID      map[core.ID]int
+	byLabel   map[string][]int
+	relStart  []int
+	relEnd    []int
+	out       [][]int // Relationships starting at each node
+	in        [][]int // Relationships ending at each node
+}
+
+// nodeRef and relRef identify a node or relationship of the graph a query runs
+// against by its index
+type (
+	nodeRef int
+	relRef  int
+)
+
+// pathValue is a path of nodes joined by relationships
+type pathValue struct {
+	nodes []nodeRef
+	rels  []relRef
+}
+
+// NewGraph indexes nodes and relationships for querying. Relationships whose
+// nodes are missing are left out. The graph must not be changed while queries
+// run against it.
+func NewGraph(nodes []*core.Node, rels []*core.Relationship) *Graph {
+	g := &Graph{
+		nodes:     nodes,
+		nodeProps: make([]map[string]any, len(nodes)),
+		byID:      make(map[core.ID]int, len(nodes)),
+		byLabel:   make(map[string][]int),
+		out:       make([][]int, len(nodes)),
+		in:        make([][]int, len(nodes)),
+	}
+	for i, node := range nodes {
+		props := map[string]any{"id": node.ID.String(), "name": node.Name, "path": node.Path}
+		for key, value := range node.Properties {
+			props[key] = normalize(value)
+		}
+		g.nodeProps[i] = props
+		g.byID[node.ID] = i
+		g.byLabel[string(node.Type)] = append(g.byLabel[string(node.Type)], i)
+	}
+	for _, rel := range rels {
+		start, okStart := g.byID[rel.FromNodeID]
+		end, okEnd := g.byID[rel.ToNodeID]
+		if !okStart || !okEnd {
+			continue
+		}
+		index := len(g.rels)
+		props := map[string]any{"id": rel.ID.String()}
+		for key, value := range rel.Properties {
+			props[key] = normalize(value)
+		}
+		g.rels = append(g.rels, rel)
+		g.relProps = append(g.relProps, props)
+		g.relStart = append(g.relStart, start)
+		g.relEnd = append(g.relEnd, end)
+		g.out[start] = append(g.out[start], index)
+		g.in[end] = append(g.in[end], index)
+	}
+	return g
+}
+
+func (g *Graph) label(n nodeRef) string {
+	return string(g.nodes[n].Type)
+}
+
+func (g *Graph) relType(r relRef) string {
+	return string(g.rels[r].Type)
+}
+
+// otherNode returns the node at the other end of a relationship
+func (g *Graph) otherNode(r relRef, n nodeRef) nodeRef {
+	if g.relStart[r] == int(n) {
+		return nodeRef(g.relEnd[r])
+	}
+	return nodeRef(g.relStart[r])
+}
+
+// relationships returns the relationships of a node in a direction, as seen
+// from that node
+func (g *Graph) relationships(n nodeRef, dir direction) []int {
+	switch dir {
+	case directionOut:
+		return g.out[n]
+	case directionIn:
+		return g.in[n]
+	}
+	both := make([]int, 0, len(g.out[n])+len(g.in[n]))
+	both = append(both, g.out[n]...)
+	for _, r := range g.in[n] {
+		if g.relStart[r] != int(n) {
+			both = append(both, r)
+		}
+	}
+	return both
+}
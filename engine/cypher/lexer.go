@@ -0,0 +1,231 @@
+package cypher
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenParam
+	tokenSymbol
+)
+
+// token is a lexical token of a query. Keywords are identifiers; the parser
+// compares them case-insensitively.
+type token struct {
+	kind  tokenKind
+	text  string // Symbol, identifier or parameter name, or decoded string
+	start int    // Byte offset in the query
+	end   int
+}
+
+// symbols are the punctuation and operator tokens, longest first
+var symbols = []string{
+	"..", "<>", "!=", "<=", ">=", "=~",
+	"(", ")", "[", "]", "{", "}", ",", ".", ":", ";", "|",
+	"=", "<", ">", "+", "-", "*", "/", "%", "^",
+}
+
+// lex splits a query into tokens
+func lex(query string) ([]token, error) {
+	l := &lexer{src: query}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		l.tokens = append(l.tokens, tok)
+		if tok.kind == tokenEOF {
+			return l.tokens, nil
+		}
+	}
+}
+
+type lexer struct {
+	src    string
+	pos    int
+	tokens []token
+}
+
+func (l *lexer) next() (token, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		return token{}, err
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, start: start, end: start}, nil
+	}
+	c := l.src[l.pos]
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	switch {
+	case isIdentStart(r):
+		return l.lexIdent(), nil
+	case c >= '0' && c <= '9':
+		return l.lexNumber(), nil
+	case c == '\'' || c == '"':
+		return l.lexString()
+	case c == '`':
+		return l.lexQuotedIdent()
+	case c == '$':
+		l.pos++
+		ident := l.lexIdent()
+		if ident.text == "" {
+			return token{}, l.errorf(start, "expected parameter name after $")
+		}
+		return token{kind: tokenParam, text: ident.text, start: start, end: l.pos}, nil
+	}
+	for _, symbol := range symbols {
+		if strings.HasPrefix(l.src[l.pos:], symbol) {
+			l.pos += len(symbol)
+			return token{kind: tokenSymbol, text: symbol, start: start, end: l.pos}, nil
+		}
+	}
+	return token{}, l.errorf(start, "unexpected character %q", c)
+}
+
+func (l *lexer) skipSpaceAndComments() error {
+	for l.pos < len(l.src) {
+		rest := l.src[l.pos:]
+		switch {
+		case unicode.IsSpace(rune(rest[0])):
+			l.pos++
+		case strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			l.pos += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return l.errorf(l.pos, "unterminated comment")
+			}
+			l.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *lexer) lexIdent() token {
+	start := l.pos
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !isIdentPart(r) {
+			break
+		}
+		l.pos += size
+	}
+	return token{kind: tokenIdent, text: l.src[start:l.pos], start: start, end: l.pos}
+}
+
+func (l *lexer) lexQuotedIdent() (token, error) {
+	start := l.pos
+	end := strings.IndexByte(l.src[l.pos+1:], '`')
+	if end < 0 {
+		return token{}, l.errorf(start, "unterminated quoted identifier")
+	}
+	l.pos += end + 2
+	return token{kind: tokenQuotedIdent, text: l.src[start+1 : l.pos-1], start: start, end: l.pos}, nil
+}
+
+// lexNumber reads an integer or a float. A dot starts a fraction only when a
+// digit follows, so that ranges such as 1..3 lex as two integers.
+func (l *lexer) lexNumber() token {
+	start := l.pos
+	kind := tokenInt
+	l.skipDigits()
+	if l.pos+1 < len(l.src) && l.src[l.pos] == '.' && isDigit(l.src[l.pos+1]) {
+		kind = tokenFloat
+		l.pos++
+		l.skipDigits()
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		exp := l.pos + 1
+		if exp < len(l.src) && (l.src[exp] == '-' || l.src[exp] == '+') {
+			exp++
+		}
+		if exp < len(l.src) && isDigit(l.src[exp]) {
+			kind = tokenFloat
+			l.pos = exp
+			l.skipDigits()
+		}
+	}
+	return token{kind: kind, text: l.src[start:l.pos], start: start, end: l.pos}
+}
+
+func (l *lexer) skipDigits() {
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+}
+
+func (l *lexer) lexString() (token, error) {
+	start := l.pos
+	quote := l.src[l.pos]
+	l.pos++
+	var text strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == quote:
+			l.pos++
+			return token{kind: tokenString, text: text.String(), start: start, end: l.pos}, nil
+		case c == '\\' && l.pos+1 < len(l.src):
+			text.WriteString(unescape(l.src[l.pos+1]))
+			l.pos += 2
+		default:
+			text.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, l.errorf(start, "unterminated string")
+}
+
+func unescape(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case 'r':
+		return "\r"
+	default:
+		return string(c)
+	}
+}
+
+func (l *lexer) errorf(pos int, format string, args ...any) error {
+	return syntaxError(l.src, pos, fmt.Sprintf(format, args...))
+}
+
+// syntaxError reports a problem at a byte offset of the query with its line
+// and column
+func syntaxError(src string, pos int, message string) error {
+	line := strings.Count(src[:pos], "\n") + 1
+	column := pos - strings.LastIndexByte(src[:pos], '\n')
+	return fmt.Errorf("syntax error at line %d, column %d: %s", line, column, message)
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
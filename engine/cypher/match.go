@@ -0,0 +1,492 @@
+package cypher
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// errMatched stops matching once a pattern predicate has its answer
+var errMatched = errors.New("matched")
+
+// match runs MATCH or OPTIONAL MATCH for every input row. OPTIONAL MATCH
+// keeps a row that has no match, with the new variables bound to null.
+func (ex *executor) match(c *matchClause, rows []row) ([]row, error) {
+	var out []row
+	for _, r := range rows {
+		matched := false
+		err := ex.matchPatterns(c.patterns, r, func(m row) error {
+			if c.where != nil {
+				keep, err := c.where.eval(ex, m)
+				if err != nil || keep != true {
+					return err
+				}
+			}
+			matched = true
+			out = append(out, m)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !matched && c.optional {
+			out = append(out, withNulls(c.patterns, r))
+		}
+	}
+	return out, nil
+}
+
+func withNulls(patterns []*pattern, r row) row {
+	out := r
+	bind := func(name string) {
+		if _, bound := out[name]; name != "" && !bound {
+			out = out.with(name, nil)
+		}
+	}
+	for _, pat := range patterns {
+		bind(pat.pathVar)
+		for _, node := range pat.nodes {
+			bind(node.variable)
+		}
+		for _, rel := range pat.rels {
+			bind(rel.variable)
+		}
+	}
+	return out
+}
+
+// hasMatch reports whether patterns match with a row's bindings
+func (ex *executor) hasMatch(patterns []*pattern, r row) (bool, error) {
+	err := ex.matchPatterns(patterns, r, func(row) error { return errMatched })
+	if errors.Is(err, errMatched) {
+		return true, nil
+	}
+	return false, err
+}
+
+// matchPatterns calls emit with every extension of a row that matches the
+// comma-separated patterns. A relationship matches at most once across them.
+func (ex *executor) matchPatterns(patterns []*pattern, r row, emit func(row) error) error {
+	used := make(map[relRef]bool)
+	var matchFrom func(i int, r row) error
+	matchFrom = func(i int, r row) error {
+		if i == len(patterns) {
+			return emit(r)
+		}
+		return ex.matchPattern(patterns[i], r, used, func(m row) error {
+			return matchFrom(i+1, m)
+		})
+	}
+	return matchFrom(0, r)
+}
+
+func (ex *executor) matchPattern(pat *pattern, r row, used map[relRef]bool, emit func(row) error) error {
+	if pat.shortest != shortestNone {
+		return ex.matchShortest(pat, r, used, emit)
+	}
+	oriented, reversed := ex.orient(pat, r)
+	m := &chainMatcher{ex: ex, pat: oriented, reversed: reversed, used: used, emit: emit}
+	return ex.eachNode(oriented.nodes[0], r, func(n nodeRef, r row) error {
+		m.nodes, m.rels = append(m.nodes[:0], n), m.rels[:0]
+		return m.step(0, n, r)
+	})
+}
+
+// orient returns the pattern to walk from its better anchored end: a bound
+// variable, then an id, then a label. Reversed patterns are cached.
+func (ex *executor) orient(pat *pattern, r row) (*pattern, bool) {
+	if len(pat.rels) == 0 || anchoring(pat.nodes[len(pat.nodes)-1], r) <= anchoring(pat.nodes[0], r) {
+		return pat, false
+	}
+	if reversed, ok := ex.reversed[pat]; ok {
+		return reversed, true
+	}
+	reversed := &pattern{pathVar: pat.pathVar}
+	for i := len(pat.nodes) - 1; i >= 0; i-- {
+		reversed.nodes = append(reversed.nodes, pat.nodes[i])
+	}
+	for i := len(pat.rels) - 1; i >= 0; i-- {
+		rel := *pat.rels[i]
+		switch rel.direction {
+		case directionOut:
+			rel.direction = directionIn
+		case directionIn:
+			rel.direction = directionOut
+		}
+		reversed.rels = append(reversed.rels, &rel)
+	}
+	ex.reversed[pat] = reversed
+	return reversed, true
+}
+
+func anchoring(node *nodePattern, r row) int {
+	if value, bound := r[node.variable]; node.variable != "" && bound && value != nil {
+		return 3
+	}
+	if props, ok := node.props.(*mapExpr); ok && slices.Contains(props.keys, "id") {
+		return 2
+	}
+	if len(node.labels) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// eachNode calls fn with every node that matches a node pattern and the row
+// with its variable bound, looking candidates up by variable, id or label
+func (ex *executor) eachNode(node *nodePattern, r row, fn func(n nodeRef, r row) error) error {
+	props, err := ex.patternProperties(node.props, r)
+	if err != nil {
+		return err
+	}
+	try := func(n nodeRef) error {
+		bound, ok := ex.bindNode(node, props, n, r)
+		if !ok {
+			return nil
+		}
+		return fn(n, bound)
+	}
+	if value, bound := r[node.variable]; node.variable != "" && bound {
+		if value == nil {
+			return nil
+		}
+		n, ok := value.(nodeRef)
+		if !ok {
+			return fmt.Errorf("type mismatch: `%s` is a %s, not a Node", node.variable, typeName(value))
+		}
+		return try(n)
+	}
+	if id, ok := props["id"].(string); ok {
+		if index, found := ex.graph.byID[core.ID(id)]; found {
+			return try(nodeRef(index))
+		}
+		return nil
+	}
+	if len(node.labels) > 0 {
+		for _, index := range ex.graph.byLabel[node.labels[0]] {
+			if err := try(nodeRef(index)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for index := range ex.graph.nodes {
+		if err := ex.tick(); err != nil {
+			return err
+		}
+		if err := try(nodeRef(index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patternProperties evaluates the property map of a node or relationship
+// pattern
+func (ex *executor) patternProperties(e expr, r row) (map[string]any, error) {
+	if e == nil {
+		return nil, nil
+	}
+	value, err := e.eval(ex, r)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	props, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: pattern properties must be a Map, not %s", typeName(value))
+	}
+	return props, nil
+}
+
+// bindNode checks a node against a node pattern and binds the pattern's
+// variable, which must agree with an earlier binding
+func (ex *executor) bindNode(node *nodePattern, props map[string]any, n nodeRef, r row) (row, bool) {
+	for _, label := range node.labels {
+		if label != ex.graph.label(n) {
+			return nil, false
+		}
+	}
+	if !matchesProperties(ex.graph.nodeProps[n], props) {
+		return nil, false
+	}
+	if node.variable == "" {
+		return r, true
+	}
+	if bound, ok := r[node.variable]; ok {
+		return r, bound == n
+	}
+	return r.with(node.variable, n), true
+}
+
+func matchesProperties(actual, wanted map[string]any) bool {
+	for key, value := range wanted {
+		if equals(actual[key], value) != true {
+			return false
+		}
+	}
+	return true
+}
+
+func (ex *executor) relMatches(rel *relPattern, props map[string]any, r relRef) bool {
+	if len(rel.types) > 0 && !slices.Contains(rel.types, ex.graph.relType(r)) {
+		return false
+	}
+	return matchesProperties(ex.graph.relProps[r], props)
+}
+
+// bindValue binds a relationship or path variable, which must agree with an
+// earlier binding
+func bindValue(name string, value any, r row) (row, bool) {
+	if name == "" {
+		return r, true
+	}
+	if bound, ok := r[name]; ok {
+		return r, equals(bound, value) == true
+	}
+	return r.with(name, value), true
+}
+
+// chainMatcher walks a pattern from its first node, tracking the path so far
+type chainMatcher struct {
+	ex       *executor
+	pat      *pattern
+	reversed bool // The pattern was reversed, so the path is too
+	used     map[relRef]bool
+	emit     func(row) error
+	nodes    []nodeRef
+	rels     []relRef
+}
+
+// step matches the relationship after nodes[i], which has matched current
+func (m *chainMatcher) step(i int, current nodeRef, r row) error {
+	if err := m.ex.tick(); err != nil {
+		return err
+	}
+	if i == len(m.pat.rels) {
+		return m.complete(r)
+	}
+	rel := m.pat.rels[i]
+	relProps, err := m.ex.patternProperties(rel.props, r)
+	if err != nil {
+		return err
+	}
+	nodeProps, err := m.ex.patternProperties(m.pat.nodes[i+1].props, r)
+	if err != nil {
+		return err
+	}
+	if rel.varLength {
+		return m.expand(i, current, relProps, nodeProps, nil, r)
+	}
+	for _, index := range m.ex.graph.relationships(current, rel.direction) {
+		id := relRef(index)
+		if m.used[id] || !m.ex.relMatches(rel, relProps, id) {
+			continue
+		}
+		next := m.ex.graph.otherNode(id, current)
+		bound, ok := bindValue(rel.variable, id, r)
+		if !ok {
+			continue
+		}
+		if bound, ok = m.ex.bindNode(m.pat.nodes[i+1], nodeProps, next, bound); !ok {
+			continue
+		}
+		if err := m.follow(id, next, func() error { return m.step(i+1, next, bound) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expand matches a variable-length relationship depth first, ending at every
+// node within its range of hops
+func (m *chainMatcher) expand(i int, current nodeRef, relProps, nodeProps map[string]any, trail []relRef, r row) error {
+	rel := m.pat.rels[i]
+	if err := m.ex.tick(); err != nil {
+		return err
+	}
+	if len(trail) >= rel.minHops {
+		value := make([]any, len(trail))
+		for k, id := range trail {
+			value[k] = id
+		}
+		if m.reversed {
+			slices.Reverse(value)
+		}
+		if bound, ok := bindValue(rel.variable, value, r); ok {
+			if bound, ok = m.ex.bindNode(m.pat.nodes[i+1], nodeProps, current, bound); ok {
+				if err := m.step(i+1, current, bound); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if rel.maxHops >= 0 && len(trail) >= rel.maxHops {
+		return nil
+	}
+	for _, index := range m.ex.graph.relationships(current, rel.direction) {
+		id := relRef(index)
+		if m.used[id] || !m.ex.relMatches(rel, relProps, id) {
+			continue
+		}
+		next := m.ex.graph.otherNode(id, current)
+		err := m.follow(id, next, func() error {
+			return m.expand(i, next, relProps, nodeProps, append(trail, id), r)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// follow extends the path by a relationship for the duration of fn
+func (m *chainMatcher) follow(id relRef, next nodeRef, fn func() error) error {
+	m.used[id] = true
+	m.nodes, m.rels = append(m.nodes, next), append(m.rels, id)
+	err := fn()
+	m.nodes, m.rels = m.nodes[:len(m.nodes)-1], m.rels[:len(m.rels)-1]
+	delete(m.used, id)
+	return err
+}
+
+func (m *chainMatcher) complete(r row) error {
+	if m.pat.pathVar != "" {
+		path := pathValue{nodes: slices.Clone(m.nodes), rels: slices.Clone(m.rels)}
+		if m.reversed {
+			slices.Reverse(path.nodes)
+			slices.Reverse(path.rels)
+		}
+		var ok bool
+		if r, ok = bindValue(m.pat.pathVar, path, r); !ok {
+			return nil
+		}
+	}
+	return m.emit(r)
+}
+
+// hop is how breadth-first search reached a node
+type hop struct {
+	from nodeRef
+	rel  relRef
+}
+
+// matchShortest matches shortestPath or allShortestPaths, searching breadth
+// first from every start node
+func (ex *executor) matchShortest(pat *pattern, r row, used map[relRef]bool, emit func(row) error) error {
+	rel, end := pat.rels[0], pat.nodes[1]
+	relProps, err := ex.patternProperties(rel.props, r)
+	if err != nil {
+		return err
+	}
+	endProps, err := ex.patternProperties(end.props, r)
+	if err != nil {
+		return err
+	}
+	return ex.eachNode(pat.nodes[0], r, func(start nodeRef, r row) error {
+		depths, hops, order, err := ex.breadthFirst(start, rel, relProps, used)
+		if err != nil {
+			return err
+		}
+		for _, n := range order {
+			if depths[n] < rel.minHops {
+				continue
+			}
+			bound, ok := ex.bindNode(end, endProps, n, r)
+			if !ok {
+				continue
+			}
+			for _, path := range shortestPaths(start, n, hops, pat.shortest == shortestAll) {
+				if err := emitPath(pat, path, bound, emit); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// breadthFirst finds the depth of every node reachable from start within the
+// hops of a relationship pattern, and every hop on a shortest way to it
+func (ex *executor) breadthFirst(
+	start nodeRef, rel *relPattern, relProps map[string]any, used map[relRef]bool,
+) (map[nodeRef]int, map[nodeRef][]hop, []nodeRef, error) {
+	maxHops := rel.maxHops
+	if !rel.varLength {
+		maxHops = 1
+	}
+	depths := map[nodeRef]int{start: 0}
+	hops := make(map[nodeRef][]hop)
+	order := []nodeRef{start}
+	frontier := []nodeRef{start}
+	for depth := 0; len(frontier) > 0 && (maxHops < 0 || depth < maxHops); depth++ {
+		var next []nodeRef
+		for _, n := range frontier {
+			if err := ex.tick(); err != nil {
+				return nil, nil, nil, err
+			}
+			for _, index := range ex.graph.relationships(n, rel.direction) {
+				id := relRef(index)
+				if used[id] || !ex.relMatches(rel, relProps, id) {
+					continue
+				}
+				other := ex.graph.otherNode(id, n)
+				if d, seen := depths[other]; !seen {
+					depths[other] = depth + 1
+					next = append(next, other)
+					order = append(order, other)
+				} else if d != depth+1 {
+					continue
+				}
+				hops[other] = append(hops[other], hop{from: n, rel: id})
+			}
+		}
+		frontier = next
+	}
+	return depths, hops, order, nil
+}
+
+// shortestPaths rebuilds the shortest paths from start to end from the hops
+// of a breadth-first search: one of them, or all of them
+func shortestPaths(start, end nodeRef, hops map[nodeRef][]hop, all bool) []pathValue {
+	if end == start {
+		return []pathValue{{nodes: []nodeRef{start}}}
+	}
+	ways := hops[end]
+	if !all && len(ways) > 1 {
+		ways = ways[:1]
+	}
+	var paths []pathValue
+	for _, h := range ways {
+		for _, path := range shortestPaths(start, h.from, hops, all) {
+			if !all && len(paths) > 0 {
+				return paths
+			}
+			paths = append(paths, pathValue{
+				nodes: append(slices.Clone(path.nodes), end),
+				rels:  append(slices.Clone(path.rels), h.rel),
+			})
+		}
+	}
+	return paths
+}
+
+func emitPath(pat *pattern, path pathValue, r row, emit func(row) error) error {
+	rels := make([]any, len(path.rels))
+	for i, id := range path.rels {
+		rels[i] = id
+	}
+	var relValue any = rels
+	if !pat.rels[0].varLength {
+		relValue = rels[0]
+	}
+	r, ok := bindValue(pat.rels[0].variable, relValue, r)
+	if !ok {
+		return nil
+	}
+	if r, ok = bindValue(pat.pathVar, path, r); !ok {
+		return nil
+	}
+	return emit(r)
+}
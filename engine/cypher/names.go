@@ -0,0 +1,126 @@
+package cypher
+
+import "sort"
+
+// Names are the labels, relationship types and parameters a query refers to
+type Names struct {
+	Labels            []string
+	RelationshipTypes []string
+	Parameters        []string
+}
+
+// Names returns the names the query refers to, each sorted and without
+// duplicates
+func (q *Query) Names() *Names {
+	labels := make(map[string]bool)
+	types := make(map[string]bool)
+	params := make(map[string]bool)
+	w := &walker{
+		pattern: func(p *pattern) {
+			for _, node := range p.nodes {
+				for _, label := range node.labels {
+					labels[label] = true
+				}
+			}
+			for _, rel := range p.rels {
+				for _, typ := range rel.types {
+					types[typ] = true
+				}
+			}
+		},
+		expr: func(e expr) {
+			switch e := e.(type) {
+			case *paramExpr:
+				params[e.name] = true
+			case *labelExpr:
+				for _, label := range e.labels {
+					labels[label] = true
+				}
+			}
+		},
+	}
+	w.walk(q)
+	return &Names{
+		Labels:            sortedSet(labels),
+		RelationshipTypes: sortedSet(types),
+		Parameters:        sortedSet(params),
+	}
+}
+
+// walker visits every pattern and expression of a query, including those in
+// the bodies of patterns and subqueries
+type walker struct {
+	pattern func(p *pattern)
+	expr    func(e expr)
+}
+
+func (w *walker) walk(q *Query) {
+	for _, part := range q.parts {
+		w.query(part)
+	}
+}
+
+func (w *walker) query(q *singleQuery) {
+	for _, cl := range q.clauses {
+		switch cl := cl.(type) {
+		case *matchClause:
+			for _, p := range cl.patterns {
+				w.visitPattern(p)
+			}
+			w.visitExpr(cl.where)
+		case *unwindClause:
+			w.visitExpr(cl.list)
+		case *projectionClause:
+			for _, item := range cl.items {
+				w.visitExpr(item.expr)
+			}
+			for _, item := range cl.order {
+				w.visitExpr(item.expr)
+			}
+			w.visitExpr(cl.skip)
+			w.visitExpr(cl.limit)
+			w.visitExpr(cl.where)
+		}
+	}
+}
+
+func (w *walker) visitPattern(p *pattern) {
+	w.pattern(p)
+	for _, node := range p.nodes {
+		w.visitExpr(node.props)
+	}
+	for _, rel := range p.rels {
+		w.visitExpr(rel.props)
+	}
+}
+
+// visitExpr visits an expression and its subexpressions, including those in
+// the bodies of patterns and subqueries, which children leaves out
+func (w *walker) visitExpr(e expr) {
+	if e == nil {
+		return
+	}
+	w.expr(e)
+	switch e := e.(type) {
+	case *patternExpr:
+		w.visitPattern(e.pattern)
+	case *patternComprehensionExpr:
+		w.visitPattern(e.pattern)
+		w.visitExpr(e.where)
+		w.visitExpr(e.project)
+	case *subqueryExpr:
+		w.query(e.query)
+	}
+	for _, child := range e.children() {
+		w.visitExpr(child)
+	}
+}
+
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,530 @@
+package cypher
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned for queries outside the supported read-only
+// subset of Cypher, such as updating clauses and procedure calls
+var ErrUnsupported = errors.New("unsupported Cypher")
+
+// updatingClauses start clauses that write to the graph or call procedures
+var updatingClauses = map[string]bool{
+	"CREATE": true, "MERGE": true, "DELETE": true, "DETACH": true, "SET": true,
+	"REMOVE": true, "FOREACH": true, "CALL": true, "LOAD": true, "USE": true,
+}
+
+// Parse parses a read-only Cypher query
+func Parse(query string) (*Query, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: query, tokens: tokens}
+	parsed, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+type parser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) parseQuery() (*Query, error) {
+	query := &Query{}
+	for {
+		part, err := p.parseSingleQuery(false)
+		if err != nil {
+			return nil, err
+		}
+		query.parts = append(query.parts, part)
+		if !p.acceptKeyword("UNION") {
+			break
+		}
+		all := p.acceptKeyword("ALL")
+		if len(query.parts) > 1 && all != query.unionAll {
+			return nil, p.errorf("cannot mix UNION and UNION ALL")
+		}
+		query.unionAll = all
+	}
+	p.acceptSymbol(";")
+	if !p.at(tokenEOF) {
+		return nil, p.errorf("unexpected %s", p.describe())
+	}
+	return query, nil
+}
+
+// parseSingleQuery parses clauses up to a UNION, the end of the query or, in a
+// subquery, the closing brace. Only subqueries may end without RETURN.
+func (p *parser) parseSingleQuery(subquery bool) (*singleQuery, error) {
+	query := &singleQuery{}
+	for {
+		if p.at(tokenEOF) || p.isSymbol(";") || p.isKeyword("UNION") || (subquery && p.isSymbol("}")) {
+			break
+		}
+		start := p.peek().start
+		c, err := p.parseClause()
+		if err != nil {
+			return nil, err
+		}
+		if len(query.clauses) > 0 {
+			if last, ok := query.clauses[len(query.clauses)-1].(*projectionClause); ok && !last.with {
+				return nil, p.errorf("RETURN must be the last clause")
+			}
+		}
+		query.clauses = append(query.clauses, c)
+		query.texts = append(query.texts, p.textFrom(start))
+	}
+	if len(query.clauses) == 0 {
+		return nil, p.errorf("expected a clause, found %s", p.describe())
+	}
+	last, ok := query.clauses[len(query.clauses)-1].(*projectionClause)
+	if !subquery && (!ok || last.with) {
+		return nil, p.errorf("query cannot conclude with %s", query.clauses[len(query.clauses)-1].clauseName())
+	}
+	return query, nil
+}
+
+func (p *parser) parseClause() (clause, error) {
+	tok := p.peek()
+	word := strings.ToUpper(tok.text)
+	switch {
+	case tok.kind != tokenIdent:
+	case word == "MATCH" || word == "OPTIONAL":
+		return p.parseMatch()
+	case word == "WITH" || word == "RETURN":
+		return p.parseProjection()
+	case word == "UNWIND":
+		return p.parseUnwind()
+	case updatingClauses[word]:
+		return nil, fmt.Errorf("%w: %s is not supported, only read queries are", ErrUnsupported, word)
+	}
+	return nil, p.errorf("expected a clause, found %s", p.describe())
+}
+
+func (p *parser) parseMatch() (*matchClause, error) {
+	c := &matchClause{optional: p.acceptKeyword("OPTIONAL")}
+	if err := p.expectKeyword("MATCH"); err != nil {
+		return nil, err
+	}
+	for {
+		pat, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+		c.patterns = append(c.patterns, pat)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	if p.acceptKeyword("WHERE") {
+		where, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		c.where = where
+	}
+	return c, nil
+}
+
+func (p *parser) parseUnwind() (*unwindClause, error) {
+	p.next()
+	list, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	alias, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	return &unwindClause{list: list, alias: alias}, nil
+}
+
+func (p *parser) parseProjection() (*projectionClause, error) {
+	c := &projectionClause{with: strings.EqualFold(p.next().text, "WITH")}
+	c.distinct = p.acceptKeyword("DISTINCT")
+	if p.acceptSymbol("*") {
+		c.star = true
+		if !p.acceptSymbol(",") {
+			return c, p.parseProjectionTail(c)
+		}
+	}
+	aliases := make(map[string]bool)
+	for {
+		item, err := p.parseProjectionItem()
+		if err != nil {
+			return nil, err
+		}
+		if aliases[item.alias] {
+			return nil, p.errorf("multiple columns named %q", item.alias)
+		}
+		aliases[item.alias] = true
+		c.items = append(c.items, item)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return c, p.parseProjectionTail(c)
+}
+
+func (p *parser) parseProjectionItem() (*projectionItem, error) {
+	start := p.peek().start
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	item := &projectionItem{expr: e, alias: p.textFrom(start)}
+	if p.acceptKeyword("AS") {
+		if item.alias, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	} else if v, ok := e.(*variableExpr); ok {
+		item.alias = v.name
+	}
+	return item, nil
+}
+
+// parseProjectionTail parses ORDER BY, SKIP, LIMIT and, after WITH, WHERE
+func (p *parser) parseProjectionTail(c *projectionClause) error {
+	if p.acceptKeyword("ORDER") {
+		if err := p.parseOrderBy(c); err != nil {
+			return err
+		}
+	}
+	var err error
+	if p.acceptKeyword("SKIP") {
+		if c.skip, err = p.parseExpr(); err != nil {
+			return err
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		if c.limit, err = p.parseExpr(); err != nil {
+			return err
+		}
+	}
+	if c.with && p.acceptKeyword("WHERE") {
+		if c.where, err = p.parseExpr(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseOrderBy(c *projectionClause) error {
+	if err := p.expectKeyword("BY"); err != nil {
+		return err
+	}
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		item := &sortItem{expr: e}
+		switch {
+		case p.acceptKeyword("DESC") || p.acceptKeyword("DESCENDING"):
+			item.descending = true
+		case p.acceptKeyword("ASC") || p.acceptKeyword("ASCENDING"):
+		}
+		c.order = append(c.order, item)
+		if !p.acceptSymbol(",") {
+			return nil
+		}
+	}
+}
+
+// parsePattern parses [var =] [shortestPath(] chain [)]
+func (p *parser) parsePattern() (*pattern, error) {
+	var pathVar string
+	if p.isSymbolAt(1, "=") && p.isName(p.peek()) {
+		pathVar = p.next().text
+		p.next()
+	}
+	shortest := shortestNone
+	switch {
+	case p.isKeyword("shortestPath") && p.isSymbolAt(1, "("):
+		shortest = shortestSingle
+	case p.isKeyword("allShortestPaths") && p.isSymbolAt(1, "("):
+		shortest = shortestAll
+	}
+	if shortest != shortestNone {
+		p.next()
+		p.next()
+	}
+	pat, err := p.parseChain()
+	if err != nil {
+		return nil, err
+	}
+	pat.pathVar, pat.shortest = pathVar, shortest
+	if shortest != shortestNone {
+		if len(pat.rels) != 1 {
+			return nil, p.errorf("shortest path patterns need exactly one relationship")
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+	return pat, nil
+}
+
+func (p *parser) parseChain() (*pattern, error) {
+	node, err := p.parseNodePattern()
+	if err != nil {
+		return nil, err
+	}
+	pat := &pattern{nodes: []*nodePattern{node}}
+	for p.isSymbol("-") || p.isSymbol("<") {
+		rel, err := p.parseRelPattern()
+		if err != nil {
+			return nil, err
+		}
+		node, err := p.parseNodePattern()
+		if err != nil {
+			return nil, err
+		}
+		pat.rels = append(pat.rels, rel)
+		pat.nodes = append(pat.nodes, node)
+	}
+	return pat, nil
+}
+
+func (p *parser) parseNodePattern() (*nodePattern, error) {
+	node := &nodePattern{start: p.peek().start}
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	if p.isName(p.peek()) {
+		node.variable = p.next().text
+	}
+	for p.acceptSymbol(":") {
+		label, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		node.labels = append(node.labels, label)
+	}
+	props, err := p.parsePatternProperties()
+	if err != nil {
+		return nil, err
+	}
+	node.props = props
+	if _, ok := props.(*mapExpr); ok {
+		node.propsEnd = p.tokens[p.pos-1].start
+	}
+	node.end = p.peek().start
+	return node, p.expectSymbol(")")
+}
+
+// parseRelPattern parses -[...]-, -[...]->, <-[...]- and their short forms
+func (p *parser) parseRelPattern() (*relPattern, error) {
+	rel := &relPattern{direction: directionBoth, minHops: 1, maxHops: 1}
+	if p.acceptSymbol("<") {
+		rel.direction = directionIn
+	}
+	if err := p.expectSymbol("-"); err != nil {
+		return nil, err
+	}
+	if p.acceptSymbol("[") {
+		if err := p.parseRelDetail(rel); err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol("]"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectSymbol("-"); err != nil {
+		return nil, err
+	}
+	if p.acceptSymbol(">") {
+		if rel.direction == directionIn {
+			return nil, p.errorf("a relationship cannot point both ways")
+		}
+		rel.direction = directionOut
+	}
+	return rel, nil
+}
+
+func (p *parser) parseRelDetail(rel *relPattern) error {
+	if p.isName(p.peek()) {
+		rel.variable = p.next().text
+	}
+	if p.acceptSymbol(":") {
+		for {
+			relType, err := p.parseName()
+			if err != nil {
+				return err
+			}
+			rel.types = append(rel.types, relType)
+			if !p.acceptSymbol("|") {
+				break
+			}
+			p.acceptSymbol(":")
+		}
+	}
+	if p.acceptSymbol("*") {
+		if err := p.parseHops(rel); err != nil {
+			return err
+		}
+	}
+	props, err := p.parsePatternProperties()
+	rel.props = props
+	return err
+}
+
+// parseHops parses the range of a variable-length relationship: *, *n, *n..,
+// *..m or *n..m
+func (p *parser) parseHops(rel *relPattern) error {
+	rel.varLength, rel.minHops, rel.maxHops = true, 1, -1
+	var err error
+	if p.at(tokenInt) {
+		if rel.minHops, err = p.parseHopCount(); err != nil {
+			return err
+		}
+		rel.maxHops = rel.minHops
+	}
+	if !p.acceptSymbol("..") {
+		return nil
+	}
+	rel.maxHops = -1
+	if p.at(tokenInt) {
+		if rel.maxHops, err = p.parseHopCount(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseHopCount() (int, error) {
+	tok := p.next()
+	n, err := strconv.Atoi(tok.text)
+	if err != nil {
+		return 0, syntaxError(p.src, tok.start, "invalid path length "+tok.text)
+	}
+	return n, nil
+}
+
+func (p *parser) parsePatternProperties() (expr, error) {
+	switch {
+	case p.isSymbol("{"):
+		return p.parseMapLiteral()
+	case p.at(tokenParam):
+		return &paramExpr{name: p.next().text}, nil
+	}
+	return nil, nil
+}
+
+// parseName parses a variable, label, type, key or alias name
+func (p *parser) parseName() (string, error) {
+	tok := p.peek()
+	if tok.kind != tokenIdent && tok.kind != tokenQuotedIdent {
+		return "", p.errorf("expected a name, found %s", p.describe())
+	}
+	p.next()
+	return tok.text, nil
+}
+
+// isName reports whether a token can name a variable inside a pattern
+func (p *parser) isName(tok token) bool {
+	return tok.kind == tokenIdent || tok.kind == tokenQuotedIdent
+}
+
+func (p *parser) peek() token {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	tok := p.peek()
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) at(kind tokenKind) bool {
+	return p.peek().kind == kind
+}
+
+func (p *parser) isSymbol(symbol string) bool {
+	return p.isSymbolAt(0, symbol)
+}
+
+func (p *parser) isSymbolAt(n int, symbol string) bool {
+	tok := p.peekAt(n)
+	return tok.kind == tokenSymbol && tok.text == symbol
+}
+
+func (p *parser) acceptSymbol(symbol string) bool {
+	if p.isSymbol(symbol) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.errorf("expected %q, found %s", symbol, p.describe())
+	}
+	return nil
+}
+
+func (p *parser) isKeyword(word string) bool {
+	return p.isKeywordAt(0, word)
+}
+
+func (p *parser) isKeywordAt(n int, word string) bool {
+	tok := p.peekAt(n)
+	return tok.kind == tokenIdent && strings.EqualFold(tok.text, word)
+}
+
+func (p *parser) acceptKeyword(word string) bool {
+	if p.isKeyword(word) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(word string) error {
+	if !p.acceptKeyword(word) {
+		return p.errorf("expected %s, found %s", word, p.describe())
+	}
+	return nil
+}
+
+// textFrom returns the source text from an offset to the end of the last
+// consumed token
+func (p *parser) textFrom(start int) string {
+	if p.pos == 0 {
+		return ""
+	}
+	return strings.TrimSpace(p.src[start:p.tokens[p.pos-1].end])
+}
+
+func (p *parser) describe() string {
+	tok := p.peek()
+	if tok.kind == tokenEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", p.src[tok.start:tok.end])
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return syntaxError(p.src, p.peek().start, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,610 @@
+package cypher
+
+import (
+	"strconv"
+	"strings"
+)
+
+var comparisonOps = map[string]bool{"=": true, "<>": true, "!=": true, "<": true, ">": true, "<=": true, ">=": true}
+
+var quantifiers = map[string]bool{"any": true, "all": true, "none": true, "single": true}
+
+func (p *parser) parseExpr() (expr, error) {
+	return p.parseBinary(0)
+}
+
+// logicalOps lists the boolean operators from the loosest binding
+var logicalOps = []string{"OR", "XOR", "AND"}
+
+func (p *parser) parseBinary(level int) (expr, error) {
+	if level == len(logicalOps) {
+		return p.parseNot()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword(logicalOps[level]) {
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: logicalOps[level], left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.acceptKeyword("NOT") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: "NOT", operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	first, err := p.parsePredicate()
+	if err != nil {
+		return nil, err
+	}
+	cmp := &comparisonExpr{operands: []expr{first}}
+	for p.at(tokenSymbol) && comparisonOps[p.peek().text] {
+		op := p.next().text
+		if op == "!=" {
+			op = "<>"
+		}
+		operand, err := p.parsePredicate()
+		if err != nil {
+			return nil, err
+		}
+		cmp.ops = append(cmp.ops, op)
+		cmp.operands = append(cmp.operands, operand)
+	}
+	if len(cmp.ops) == 0 {
+		return first, nil
+	}
+	return cmp, nil
+}
+
+// parsePredicate parses the string, list and null predicates, which bind
+// tighter than comparisons
+func (p *parser) parsePredicate() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.predicateOp()
+		if op == "" {
+			return left, nil
+		}
+		if op == "IS NULL" || op == "IS NOT NULL" {
+			left = &isNullExpr{operand: left, not: op == "IS NOT NULL"}
+			continue
+		}
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+// predicateOp consumes a predicate operator and returns its canonical form
+func (p *parser) predicateOp() string {
+	switch {
+	case p.isSymbol("=~"):
+		p.next()
+		return "=~"
+	case p.acceptKeyword("IN"):
+		return "IN"
+	case p.acceptKeyword("CONTAINS"):
+		return "CONTAINS"
+	case p.isKeyword("STARTS") && p.isKeywordAt(1, "WITH"):
+		p.next()
+		p.next()
+		return "STARTS WITH"
+	case p.isKeyword("ENDS") && p.isKeywordAt(1, "WITH"):
+		p.next()
+		p.next()
+		return "ENDS WITH"
+	case p.isKeyword("IS"):
+		start := p.pos
+		p.next()
+		not := p.acceptKeyword("NOT")
+		if !p.acceptKeyword("NULL") {
+			p.pos = start
+			return ""
+		}
+		if not {
+			return "IS NOT NULL"
+		}
+		return "IS NULL"
+	}
+	return ""
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	return p.parseArithmetic([]string{"+", "-"}, p.parseMultiplicative)
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	return p.parseArithmetic([]string{"*", "/", "%"}, p.parsePower)
+}
+
+func (p *parser) parsePower() (expr, error) {
+	return p.parseArithmetic([]string{"^"}, p.parseUnary)
+}
+
+// parseArithmetic parses a left-associative chain of operators
+func (p *parser) parseArithmetic(ops []string, operand func() (expr, error)) (expr, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, candidate := range ops {
+			if p.isSymbol(candidate) {
+				op = candidate
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.isSymbol("-") || p.isSymbol("+") {
+		op := p.next().text
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if lit, ok := operand.(*literalExpr); ok && op == "-" {
+			switch v := lit.value.(type) {
+			case int64:
+				return &literalExpr{value: -v}, nil
+			case float64:
+				return &literalExpr{value: -v}, nil
+			}
+		}
+		return &unaryExpr{op: op, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (expr, error) {
+	e, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		variable, isVar := e.(*variableExpr)
+		switch {
+		case p.acceptSymbol("."):
+			key, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			e = &propertyExpr{subject: e, key: key}
+		case p.isSymbol("["):
+			if e, err = p.parseSubscript(e); err != nil {
+				return nil, err
+			}
+		case p.isSymbol(":") && isVar:
+			label := &labelExpr{subject: e}
+			for p.acceptSymbol(":") {
+				name, err := p.parseName()
+				if err != nil {
+					return nil, err
+				}
+				label.labels = append(label.labels, name)
+			}
+			e = label
+		case p.isSymbol("{") && isVar:
+			if e, err = p.parseMapProjection(variable.name); err != nil {
+				return nil, err
+			}
+		default:
+			return e, nil
+		}
+	}
+}
+
+// parseSubscript parses [index] or [from..to]
+func (p *parser) parseSubscript(subject expr) (expr, error) {
+	p.next()
+	var from, to expr
+	var err error
+	if !p.isSymbol("..") {
+		if from, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.acceptSymbol("..") {
+		if err := p.expectSymbol("]"); err != nil {
+			return nil, err
+		}
+		return &indexExpr{subject: subject, index: from}, nil
+	}
+	if !p.isSymbol("]") {
+		if to, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectSymbol("]"); err != nil {
+		return nil, err
+	}
+	return &sliceExpr{subject: subject, from: from, to: to}, nil
+}
+
+func (p *parser) parseAtom() (expr, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenInt:
+		p.next()
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, syntaxError(p.src, tok.start, "invalid integer "+tok.text)
+		}
+		return &literalExpr{value: n}, nil
+	case tokenFloat:
+		p.next()
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, syntaxError(p.src, tok.start, "invalid number "+tok.text)
+		}
+		return &literalExpr{value: f}, nil
+	case tokenString:
+		p.next()
+		return &literalExpr{value: tok.text}, nil
+	case tokenParam:
+		p.next()
+		return &paramExpr{name: tok.text}, nil
+	case tokenQuotedIdent:
+		p.next()
+		return &variableExpr{name: tok.text}, nil
+	case tokenIdent:
+		return p.parseIdentAtom()
+	case tokenSymbol:
+		switch tok.text {
+		case "(":
+			return p.parseParenthesized()
+		case "[":
+			return p.parseListAtom()
+		case "{":
+			return p.parseMapLiteral()
+		}
+	}
+	return nil, p.errorf("expected an expression, found %s", p.describe())
+}
+
+func (p *parser) parseIdentAtom() (expr, error) {
+	tok := p.peek()
+	word := strings.ToLower(tok.text)
+	switch {
+	case word == "true" || word == "false":
+		p.next()
+		return &literalExpr{value: word == "true"}, nil
+	case word == "null":
+		p.next()
+		return &literalExpr{}, nil
+	case word == "case":
+		return p.parseCase()
+	case (word == "exists" || word == "count") && p.isSymbolAt(1, "{"):
+		return p.parseSubquery(word == "count")
+	case quantifiers[word] && p.isSymbolAt(1, "(") && p.isName(p.peekAt(2)) && p.isKeywordAt(3, "IN"):
+		return p.parseQuantifier()
+	}
+	if name, ok := p.functionName(); ok {
+		return p.parseFunctionCall(name)
+	}
+	p.next()
+	return &variableExpr{name: tok.text}, nil
+}
+
+// functionName consumes a possibly namespaced function name when a call
+// follows
+func (p *parser) functionName() (string, bool) {
+	n := 0
+	for p.peekAt(n).kind == tokenIdent && p.isSymbolAt(n+1, ".") {
+		n += 2
+	}
+	if p.peekAt(n).kind != tokenIdent || !p.isSymbolAt(n+1, "(") {
+		return "", false
+	}
+	var name strings.Builder
+	for i := 0; i <= n; i++ {
+		name.WriteString(p.next().text)
+	}
+	return strings.ToLower(name.String()), true
+}
+
+func (p *parser) parseFunctionCall(name string) (expr, error) {
+	p.next()
+	call := &funcExpr{name: name}
+	if p.acceptSymbol("*") {
+		call.star = true
+		return call, p.expectSymbol(")")
+	}
+	call.distinct = p.acceptKeyword("DISTINCT")
+	if p.acceptSymbol(")") {
+		return call, nil
+	}
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return call, p.expectSymbol(")")
+}
+
+// parseParenthesized parses a pattern predicate such as (a)-[:CALLS]->(b) or
+// a parenthesized expression
+func (p *parser) parseParenthesized() (expr, error) {
+	start := p.pos
+	if pat, err := p.parseChain(); err == nil && len(pat.rels) > 0 {
+		return &patternExpr{pattern: pat}, nil
+	}
+	p.pos = start
+	p.next()
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return e, p.expectSymbol(")")
+}
+
+// parseListAtom parses a list comprehension, a pattern comprehension or a list
+// literal
+func (p *parser) parseListAtom() (expr, error) {
+	p.next()
+	if p.isName(p.peek()) && p.isKeywordAt(1, "IN") {
+		return p.parseListComprehension()
+	}
+	if comprehension, ok := p.tryPatternComprehension(); ok {
+		return comprehension, nil
+	}
+	list := &listExpr{}
+	if p.acceptSymbol("]") {
+		return list, nil
+	}
+	for {
+		item, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		list.items = append(list.items, item)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return list, p.expectSymbol("]")
+}
+
+func (p *parser) parseListComprehension() (expr, error) {
+	c := &listComprehensionExpr{variable: p.next().text}
+	p.next()
+	var err error
+	if c.list, err = p.parseExpr(); err != nil {
+		return nil, err
+	}
+	if p.acceptKeyword("WHERE") {
+		if c.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptSymbol("|") {
+		if c.project, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	return c, p.expectSymbol("]")
+}
+
+// tryPatternComprehension parses [pattern WHERE predicate | projection],
+// restoring the position when the list is not one
+func (p *parser) tryPatternComprehension() (expr, bool) {
+	start := p.pos
+	if !p.isSymbol("(") && !p.isSymbolAt(1, "=") {
+		return nil, false
+	}
+	pat, err := p.parsePattern()
+	if err != nil || len(pat.rels) == 0 || (!p.isKeyword("WHERE") && !p.isSymbol("|")) {
+		p.pos = start
+		return nil, false
+	}
+	c := &patternComprehensionExpr{pattern: pat}
+	if p.acceptKeyword("WHERE") {
+		if c.where, err = p.parseExpr(); err != nil {
+			p.pos = start
+			return nil, false
+		}
+	}
+	if p.expectSymbol("|") != nil {
+		p.pos = start
+		return nil, false
+	}
+	if c.project, err = p.parseExpr(); err != nil || p.expectSymbol("]") != nil {
+		p.pos = start
+		return nil, false
+	}
+	return c, true
+}
+
+func (p *parser) parseMapLiteral() (expr, error) {
+	if err := p.expectSymbol("{"); err != nil {
+		return nil, err
+	}
+	m := &mapExpr{}
+	if p.acceptSymbol("}") {
+		return m, nil
+	}
+	for {
+		key, err := p.parseMapKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		m.keys = append(m.keys, key)
+		m.values = append(m.values, value)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return m, p.expectSymbol("}")
+}
+
+func (p *parser) parseMapKey() (string, error) {
+	if p.at(tokenString) {
+		return p.next().text, nil
+	}
+	return p.parseName()
+}
+
+func (p *parser) parseMapProjection(subject string) (expr, error) {
+	p.next()
+	m := &mapProjectionExpr{subject: subject}
+	for !p.acceptSymbol("}") {
+		if len(m.items) > 0 {
+			if err := p.expectSymbol(","); err != nil {
+				return nil, err
+			}
+		}
+		item, err := p.parseMapProjectionItem()
+		if err != nil {
+			return nil, err
+		}
+		m.items = append(m.items, item)
+	}
+	return m, nil
+}
+
+func (p *parser) parseMapProjectionItem() (mapProjectionItem, error) {
+	if p.acceptSymbol(".") {
+		if p.acceptSymbol("*") {
+			return mapProjectionItem{all: true}, nil
+		}
+		key, err := p.parseName()
+		return mapProjectionItem{key: key, property: true}, err
+	}
+	key, err := p.parseMapKey()
+	if err != nil {
+		return mapProjectionItem{}, err
+	}
+	if !p.acceptSymbol(":") {
+		return mapProjectionItem{key: key, value: &variableExpr{name: key}}, nil
+	}
+	value, err := p.parseExpr()
+	return mapProjectionItem{key: key, value: value}, err
+}
+
+func (p *parser) parseCase() (expr, error) {
+	p.next()
+	c := &caseExpr{}
+	var err error
+	if !p.isKeyword("WHEN") {
+		if c.subject, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	for p.acceptKeyword("WHEN") {
+		when, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		then, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		c.whens = append(c.whens, when)
+		c.thens = append(c.thens, then)
+	}
+	if len(c.whens) == 0 {
+		return nil, p.errorf("expected WHEN, found %s", p.describe())
+	}
+	if p.acceptKeyword("ELSE") {
+		if c.otherwise, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	return c, p.expectKeyword("END")
+}
+
+func (p *parser) parseQuantifier() (expr, error) {
+	q := &quantifierExpr{kind: strings.ToLower(p.next().text)}
+	p.next()
+	q.variable = p.next().text
+	p.next()
+	var err error
+	if q.list, err = p.parseExpr(); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("WHERE"); err != nil {
+		return nil, err
+	}
+	if q.where, err = p.parseExpr(); err != nil {
+		return nil, err
+	}
+	return q, p.expectSymbol(")")
+}
+
+// parseSubquery parses EXISTS { ... } or COUNT { ... }, whose body is either a
+// query or a list of patterns with an optional WHERE
+func (p *parser) parseSubquery(count bool) (expr, error) {
+	p.next()
+	p.next()
+	var query *singleQuery
+	if p.isSymbol("(") || p.isSymbolAt(1, "=") {
+		c := &matchClause{}
+		for {
+			pat, err := p.parsePattern()
+			if err != nil {
+				return nil, err
+			}
+			c.patterns = append(c.patterns, pat)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if p.acceptKeyword("WHERE") {
+			where, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			c.where = where
+		}
+		query = &singleQuery{clauses: []clause{c}}
+	} else {
+		var err error
+		if query, err = p.parseSingleQuery(true); err != nil {
+			return nil, err
+		}
+	}
+	return &subqueryExpr{count: count, query: query}, p.expectSymbol("}")
+}
@@ -0,0 +1,107 @@
+package cypher_test
+
+import (
+	"testing"
+
+	"github.com/compozy/gograph/engine/cypher"
+	"github.com/compozy/gograph/engine/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Should parse every query template", func(t *testing.T) {
+		for name, template := range query.CommonTemplates {
+			_, err := cypher.Parse(template.Query)
+			assert.NoError(t, err, name)
+		}
+	})
+
+	t.Run("Should parse read queries with comments, unions and subqueries", func(t *testing.T) {
+		queries := []string{
+			"// find callers\nMATCH (f:Function {name: $name})<-[:CALLS]-(caller) RETURN caller /* done */",
+			"MATCH (a) RETURN a.name AS name UNION MATCH (b) RETURN b.name AS name;",
+			"MATCH p = shortestPath((a {id: $from})-[*..5]-(b {id: $to})) RETURN p",
+			"MATCH (n) WHERE n:Function OR n:Method RETURN n {.name, kind: labels(n)[0]}",
+			"MATCH (n) RETURN [x IN n.tags WHERE x STARTS WITH 'a' | toUpper(x)] AS tags, " +
+				"[(n)-[:CALLS]->(m) | m.name] AS callees, COUNT { (n)-->() } AS degree",
+			"UNWIND range(1, 3) AS i WITH i WHERE i % 2 = 1 RETURN collect(i)[0..2] AS odd",
+		}
+		for _, q := range queries {
+			_, err := cypher.Parse(q)
+			assert.NoError(t, err, q)
+		}
+	})
+
+	t.Run("Should reject updating clauses as unsupported", func(t *testing.T) {
+		for _, q := range []string{
+			"CREATE (n:Function) RETURN n",
+			"MATCH (n) DETACH DELETE n",
+			"MATCH (n) SET n.name = 'x' RETURN n",
+			"CALL db.labels()",
+		} {
+			_, err := cypher.Parse(q)
+			assert.ErrorIs(t, err, cypher.ErrUnsupported, q)
+		}
+	})
+
+	t.Run("Should report syntax errors with their position", func(t *testing.T) {
+		_, err := cypher.Parse("MATCH (n)\nRETURN n.name AS")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 2, column 17")
+		assert.NotErrorIs(t, err, cypher.ErrUnsupported)
+	})
+
+	t.Run("Should reject malformed queries", func(t *testing.T) {
+		for _, q := range []string{
+			"MATCH (n)",
+			"MATCH (n) RETURN n MATCH (m) RETURN m",
+			"MATCH (a) RETURN a UNION ALL MATCH (b) RETURN b AS a UNION MATCH (c) RETURN c AS a",
+			"MATCH (a)<-[:CALLS]->(b) RETURN a",
+			"MATCH (n) RETURN 'unterminated",
+			"MATCH (n) RETURN n.name AS n, n",
+		} {
+			_, err := cypher.Parse(q)
+			assert.Error(t, err, q)
+		}
+	})
+}
+
+func TestQuery_Names(t *testing.T) {
+	t.Run("Should collect names from patterns, predicates and subqueries", func(t *testing.T) {
+		q, err := cypher.Parse(`MATCH (f:Function {project_id: $project_id})-[:CALLS|REFERENCES*1..3]->(g)
+			WHERE g:Method OR EXISTS { (g)<-[:DEFINES]-(:Struct {name: $owner}) }
+			WITH f, [(f)<-[:CONTAINS]-(p:Package) | p.name] AS packages
+			RETURN f.name AS name, packages ORDER BY name LIMIT $limit
+			UNION
+			MATCH (i:Interface) RETURN i.name AS name, [] AS packages`)
+		require.NoError(t, err)
+		assert.Equal(t, &cypher.Names{
+			Labels:            []string{"Function", "Interface", "Method", "Package", "Struct"},
+			RelationshipTypes: []string{"CALLS", "CONTAINS", "DEFINES", "REFERENCES"},
+			Parameters:        []string{"limit", "owner", "project_id"},
+		}, q.Names())
+	})
+}
+
+func TestSplitStatements(t *testing.T) {
+	t.Run("Should split a script on semicolons outside literals and comments", func(t *testing.T) {
+		script := `// Packages; one row each
+MATCH (p:Package) RETURN p.name;
+
+MATCH (f:Function) WHERE f.name = 'a;b' OR f.signature CONTAINS "c\";"
+RETURN f /* ; */ ;
+MATCH (n:` + "`odd;label`" + `) RETURN n
+`
+		assert.Equal(t, []string{
+			"// Packages; one row each\nMATCH (p:Package) RETURN p.name",
+			"MATCH (f:Function) WHERE f.name = 'a;b' OR f.signature CONTAINS \"c\\\";\"\nRETURN f /* ; */",
+			"MATCH (n:`odd;label`) RETURN n",
+		}, cypher.SplitStatements(script))
+	})
+
+	t.Run("Should drop empty and comment-only statements", func(t *testing.T) {
+		assert.Equal(t, []string{"RETURN 1"}, cypher.SplitStatements("RETURN 1;;\n// trailing note\n"))
+		assert.Empty(t, cypher.SplitStatements("  /* nothing */ ; "))
+	})
+}
@@ -0,0 +1,77 @@
+package cypher
+
+import (
+	"context"
+	"strings"
+)
+
+// Plan is an operator of the way a query runs. Clauses run in order, so the
+// plan of a query is a chain: each clause reads the rows of its child, the
+// clause before it. The parts of a UNION are the children of a Union operator.
+type Plan struct {
+	Operator string
+	Details  string // Clause text after its keyword
+	Rows     int    // Rows produced, when profiled
+	Children []*Plan
+}
+
+// Explain returns the plan of the query without running it
+func (q *Query) Explain() *Plan {
+	return q.plan(nil, 0)
+}
+
+// Profile runs the query and returns its rows with the plan annotated with
+// the rows each clause produced
+func (q *Query) Profile(ctx context.Context, g *Graph, params map[string]any) (*Plan, []map[string]any, error) {
+	ex := newExecutor(ctx, g, params)
+	ex.profile = make(map[clause]int)
+	rows, err := q.execute(ex)
+	if err != nil {
+		return nil, nil, err
+	}
+	results := make([]map[string]any, len(rows))
+	for i, r := range rows {
+		results[i] = ex.result(r)
+	}
+	return q.plan(ex.profile, len(rows)), results, nil
+}
+
+func (q *Query) plan(profile map[clause]int, rows int) *Plan {
+	if len(q.parts) == 1 {
+		return q.parts[0].plan(profile)
+	}
+	union := &Plan{Operator: "UNION", Rows: rows}
+	if q.unionAll {
+		union.Operator = "UNION ALL"
+	}
+	for _, part := range q.parts {
+		union.Children = append(union.Children, part.plan(profile))
+	}
+	return union
+}
+
+func (q *singleQuery) plan(profile map[clause]int) *Plan {
+	var plan *Plan
+	for i, c := range q.clauses {
+		step := &Plan{
+			Operator: c.clauseName(),
+			Details:  clauseDetails(c.clauseName(), q.texts[i]),
+			Rows:     profile[c],
+		}
+		if plan != nil {
+			step.Children = []*Plan{plan}
+		}
+		plan = step
+	}
+	return plan
+}
+
+// clauseDetails strips the keywords of a clause from its text
+func clauseDetails(name, text string) string {
+	for _, keyword := range strings.Fields(name) {
+		if len(text) >= len(keyword) && strings.EqualFold(text[:len(keyword)], keyword) {
+			text = strings.TrimSpace(text[len(keyword):])
+		}
+	}
+	return text
+}
@@ -0,0 +1,126 @@
+package cypher
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrUnscoped is returned by ScopeToProject for queries it cannot limit to a
+// project
+var ErrUnscoped = errors.New("cannot scope query to the project")
+
+// ProjectParameter is the parameter ScopeToProject constrains the project_id
+// property of nodes to, as query.Builder.ProjectFilter and the query DSL do
+const ProjectParameter = "project_id"
+
+// ScopeToProject rewrites a query so that every node pattern, including those
+// of pattern predicates, comprehensions and subqueries, only matches nodes
+// whose project_id is $project_id. Patterns that already constrain project_id
+// to $project_id are kept as they are. Queries outside the supported subset
+// are kept as they are when they have no pattern, such as
+// CALL db.labels(); the rows of procedures are not scoped. Other queries
+// outside the subset, node patterns whose properties are a parameter and
+// those that constrain project_id to another value fail with ErrUnscoped.
+func ScopeToProject(query string) (string, error) {
+	parsed, err := Parse(query)
+	if err != nil {
+		if tokens, lexErr := lex(query); lexErr == nil && !hasPatterns(tokens) {
+			return query, nil
+		}
+		return "", fmt.Errorf("%w: %w", ErrUnscoped, err)
+	}
+	var nodes []*nodePattern
+	w := &walker{
+		pattern: func(p *pattern) { nodes = append(nodes, p.nodes...) },
+		expr:    func(expr) {},
+	}
+	w.walk(parsed)
+
+	type insertion struct {
+		at   int
+		text string
+	}
+	insertions := make([]insertion, 0, len(nodes))
+	for _, node := range nodes {
+		text, err := scopeNode(query, node)
+		if err != nil {
+			return "", err
+		}
+		if text != "" {
+			at := node.end
+			if node.props != nil {
+				at = node.propsEnd
+			}
+			insertions = append(insertions, insertion{at: at, text: text})
+		}
+	}
+	sort.Slice(insertions, func(i, j int) bool { return insertions[i].at > insertions[j].at })
+	for _, ins := range insertions {
+		query = query[:ins.at] + ins.text + query[ins.at:]
+	}
+	return query, nil
+}
+
+// scopeNode returns the text that constrains a node pattern to the project,
+// or "" when the pattern already is
+func scopeNode(query string, node *nodePattern) (string, error) {
+	constraint := ProjectParameter + ": $" + ProjectParameter
+	switch props := node.props.(type) {
+	case nil:
+		if node.variable == "" && len(node.labels) == 0 {
+			return "{" + constraint + "}", nil
+		}
+		return " {" + constraint + "}", nil
+	case *mapExpr:
+		for i, key := range props.keys {
+			if key != ProjectParameter {
+				continue
+			}
+			if param, ok := props.values[i].(*paramExpr); ok && param.name == ProjectParameter {
+				return "", nil
+			}
+			return "", fmt.Errorf("%w: %s sets project_id to something other than $%s",
+				ErrUnscoped, query[node.start:node.end+1], ProjectParameter)
+		}
+		if len(props.keys) == 0 {
+			return constraint, nil
+		}
+		return ", " + constraint, nil
+	}
+	return "", fmt.Errorf("%w: the properties of %s are a parameter",
+		ErrUnscoped, query[node.start:node.end+1])
+}
+
+// hasPatterns reports whether a query may have a node pattern: a clause or
+// subquery that takes patterns, or a relationship joining two nodes
+func hasPatterns(tokens []token) bool {
+	for i, tok := range tokens {
+		switch tok.kind {
+		case tokenIdent:
+			switch strings.ToUpper(tok.text) {
+			case "MATCH", "MERGE", "CREATE":
+				return true
+			case "EXISTS", "COUNT", "COLLECT":
+				if next := tokens[i+1]; next.kind == tokenSymbol && next.text == "{" {
+					return true
+				}
+			}
+		case tokenSymbol:
+			// A relationship starts with -, <- or )- and ends with -, -> or -(
+			if tok.text == "-" && i > 0 && isSymbolToken(tokens[i-1], ")", "<", "]") {
+				return true
+			}
+			if tok.text == "-" && isSymbolToken(tokens[i+1], "(", "[", ">", "-") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isSymbolToken(tok token, symbols ...string) bool {
+	return tok.kind == tokenSymbol && slices.Contains(symbols, tok.text)
+}
@@ -0,0 +1,80 @@
+package cypher_test
+
+import (
+	"testing"
+
+	"github.com/compozy/gograph/engine/cypher"
+	"github.com/compozy/gograph/engine/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeToProject(t *testing.T) {
+	t.Run("Should constrain every node pattern to $project_id", func(t *testing.T) {
+		cases := map[string]string{
+			"MATCH (f:Function) RETURN f.name AS name": "MATCH (f:Function {project_id: $project_id}) RETURN f.name AS name",
+			"MATCH (f {name: 'Run'})-->() RETURN f": "MATCH (f {name: 'Run', project_id: $project_id})-->" +
+				"({project_id: $project_id}) RETURN f",
+			"MATCH (f:Function {}) RETURN f":                                  "MATCH (f:Function {project_id: $project_id}) RETURN f",
+			"MATCH (f {project_id: $project_id}) RETURN f":                    "MATCH (f {project_id: $project_id}) RETURN f",
+			"MATCH (f) WHERE (f)-[:CALLS]->(:Function) RETURN f":              "MATCH (f {project_id: $project_id}) WHERE (f {project_id: $project_id})-[:CALLS]->(:Function {project_id: $project_id}) RETURN f",
+			"MATCH (f) RETURN [(f)-->(g) | g.name] AS callees":                "MATCH (f {project_id: $project_id}) RETURN [(f {project_id: $project_id})-->(g {project_id: $project_id}) | g.name] AS callees",
+			"MATCH (f) WHERE EXISTS { MATCH (f)<--(c) } RETURN f":             "MATCH (f {project_id: $project_id}) WHERE EXISTS { MATCH (f {project_id: $project_id})<--(c {project_id: $project_id}) } RETURN f",
+			"MATCH (a) RETURN a.name AS n UNION MATCH (b) RETURN b.name AS n": "MATCH (a {project_id: $project_id}) RETURN a.name AS n UNION MATCH (b {project_id: $project_id}) RETURN b.name AS n",
+		}
+		for q, expected := range cases {
+			scoped, err := cypher.ScopeToProject(q)
+			require.NoError(t, err, q)
+			assert.Equal(t, expected, scoped)
+		}
+	})
+
+	t.Run("Should keep scoped queries as they are", func(t *testing.T) {
+		for name, template := range query.CommonTemplates {
+			scoped, err := cypher.ScopeToProject(template.Query)
+			require.NoError(t, err, name)
+			_, err = cypher.Parse(scoped)
+			require.NoError(t, err, name)
+			again, err := cypher.ScopeToProject(scoped)
+			require.NoError(t, err, name)
+			assert.Equal(t, scoped, again, name)
+		}
+	})
+
+	t.Run("Should keep queries outside the subset that have no pattern", func(t *testing.T) {
+		for _, q := range []string{
+			"CALL db.labels() YIELD label RETURN label",
+			"CALL db.relationshipTypes() YIELD relationshipType RETURN relationshipType ORDER BY relationshipType",
+			"SHOW INDEXES",
+		} {
+			scoped, err := cypher.ScopeToProject(q)
+			require.NoError(t, err, q)
+			assert.Equal(t, q, scoped)
+		}
+	})
+
+	t.Run("Should only return nodes of the project", func(t *testing.T) {
+		f := newFixture()
+		q := "MATCH (f:Function) WHERE f.name = 'Run' RETURN f.package AS package"
+		assert.Len(t, f.run(t, q, nil), 2)
+		scoped, err := cypher.ScopeToProject(q)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"a"}, column(f.run(t, scoped, map[string]any{"project_id": "p1"}), "package"))
+	})
+
+	t.Run("Should reject patterns it cannot scope", func(t *testing.T) {
+		cases := map[string]string{
+			"MATCH (f {project_id: 'p2'}) RETURN f":            "(f {project_id: 'p2'}) sets project_id",
+			"MATCH (f $props) RETURN f":                        "the properties of (f $props) are a parameter",
+			"MATCH (n) DETACH DELETE n":                        "DETACH is not supported",
+			"CALL db.labels() YIELD label MATCH (n) RETURN n":  "CALL is not supported",
+			"CALL { MATCH (n) RETURN n } RETURN n":             "CALL is not supported",
+			"CALL x.y() YIELD a RETURN COUNT { (a)--() } AS c": "CALL is not supported",
+		}
+		for q, message := range cases {
+			_, err := cypher.ScopeToProject(q)
+			assert.ErrorIs(t, err, cypher.ErrUnscoped, q)
+			assert.ErrorContains(t, err, message, q)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package cypher
+
+import "strings"
+
+// SplitStatements splits a script into its ';'-separated statements. Semicolons
+// in string literals, quoted names and comments do not end a statement.
+// Statements are trimmed, and those holding only comments are dropped.
+func SplitStatements(script string) []string {
+	var statements []string
+	start := 0
+	code := false // The current statement has more than comments
+	flush := func(end int) {
+		if code {
+			statements = append(statements, strings.TrimSpace(script[start:end]))
+		}
+		start, code = end+1, false
+	}
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == ';':
+			flush(i)
+		case c == '/' && strings.HasPrefix(script[i:], "//"):
+			if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(script)
+			}
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			if end := strings.Index(script[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(script)
+			}
+		case c == '\'' || c == '"' || c == '`':
+			code = true
+			i = endOfQuoted(script, i)
+		case !isSpace(rune(c)):
+			code = true
+		}
+	}
+	flush(len(script))
+	return statements
+}
+
+// endOfQuoted returns the index of the quote closing the literal opened at
+// start, or the end of the script
+func endOfQuoted(script string, start int) int {
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if script[start] != '`' {
+				i++
+			}
+		case script[start]:
+			return i
+		}
+	}
+	return len(script)
+}
@@ -0,0 +1,497 @@
+package cypher
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+)
+
+// row binds variable names to values. Values are nil, bool, int64, float64,
+// string, time.Time, []any, map[string]any, nodeRef, relRef or pathValue.
+type row map[string]any
+
+// with returns a copy of the row with one more binding
+func (r row) with(name string, value any) row {
+	next := make(row, len(r)+1)
+	for key, v := range r {
+		next[key] = v
+	}
+	next[name] = value
+	return next
+}
+
+// normalize converts a Go value from parameters or stored properties to the
+// value types queries work with
+func normalize(value any) any {
+	switch v := value.(type) {
+	case nil, bool, int64, float64, string, time.Time, []any, map[string]any:
+		return normalizeContainer(v)
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Slice, reflect.Array:
+		list := make([]any, rv.Len())
+		for i := range list {
+			list[i] = normalize(rv.Index(i).Interface())
+		}
+		return list
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		m := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = normalize(iter.Value().Interface())
+		}
+		return m
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalize(rv.Elem().Interface())
+	}
+	return value
+}
+
+func normalizeContainer(value any) any {
+	switch v := value.(type) {
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = normalize(item)
+		}
+		return list
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			m[key] = normalize(item)
+		}
+		return m
+	}
+	return value
+}
+
+// toFloat returns a number as a float
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func isNumber(value any) bool {
+	_, ok := toFloat(value)
+	return ok
+}
+
+// equals compares two values as Cypher's = does: the result is nil when the
+// answer depends on a null
+func equals(a, b any) any {
+	if a == nil || b == nil {
+		return nil
+	}
+	if isNumber(a) && isNumber(b) {
+		return compareNumbers(a, b) == 0
+	}
+	switch x := a.(type) {
+	case []any:
+		y, ok := b.([]any)
+		if !ok {
+			return false
+		}
+		return equalLists(x, y)
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok {
+			return false
+		}
+		return equalMaps(x, y)
+	case pathValue:
+		y, ok := b.(pathValue)
+		return ok && reflect.DeepEqual(x, y)
+	case time.Time:
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	}
+	return a == b
+}
+
+func equalLists(x, y []any) any {
+	if len(x) != len(y) {
+		return false
+	}
+	var result any = true
+	for i := range x {
+		switch equals(x[i], y[i]) {
+		case false:
+			return false
+		case nil:
+			result = nil
+		}
+	}
+	return result
+}
+
+func equalMaps(x, y map[string]any) any {
+	if len(x) != len(y) {
+		return false
+	}
+	var result any = true
+	for key, value := range x {
+		other, ok := y[key]
+		if !ok {
+			return false
+		}
+		switch equals(value, other) {
+		case false:
+			return false
+		case nil:
+			result = nil
+		}
+	}
+	return result
+}
+
+func compareNumbers(a, b any) int {
+	x, xInt := a.(int64)
+	y, yInt := b.(int64)
+	if xInt && yInt {
+		return compareOrdered(x, y)
+	}
+	fx, _ := toFloat(a)
+	fy, _ := toFloat(b)
+	return compareOrdered(fx, fy)
+}
+
+func compareOrdered[T int64 | float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compare orders two values for <, <=, > and >=. It returns false when they
+// cannot be ordered, such as values of different types or nulls.
+func compare(a, b any) (int, bool) {
+	if isNumber(a) && isNumber(b) {
+		fa, _ := toFloat(a)
+		fb, _ := toFloat(b)
+		if math.IsNaN(fa) || math.IsNaN(fb) {
+			return 0, false
+		}
+		return compareNumbers(a, b), true
+	}
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return compareOrdered(x, y), true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return compareBools(x, y), true
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), true
+		}
+	case []any:
+		if y, ok := b.([]any); ok {
+			return compareLists(x, y)
+		}
+	}
+	return 0, false
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
+
+func compareLists(x, y []any) (int, bool) {
+	for i := 0; i < len(x) && i < len(y); i++ {
+		c, ok := compare(x[i], y[i])
+		if !ok {
+			return 0, false
+		}
+		if c != 0 {
+			return c, true
+		}
+	}
+	return compareOrdered(int64(len(x)), int64(len(y))), true
+}
+
+// orderRank ranks the value types for sorting, where values of different
+// types still need an order. Nulls sort last.
+func orderRank(value any) int {
+	switch value.(type) {
+	case map[string]any:
+		return 0
+	case nodeRef:
+		return 1
+	case relRef:
+		return 2
+	case []any:
+		return 3
+	case pathValue:
+		return 4
+	case time.Time:
+		return 5
+	case string:
+		return 6
+	case bool:
+		return 7
+	case int64, float64:
+		return 8
+	case nil:
+		return 9
+	}
+	return 10
+}
+
+// orderCompare is the total order of ORDER BY, min and max
+func orderCompare(a, b any) int {
+	ra, rb := orderRank(a), orderRank(b)
+	if ra != rb {
+		return compareOrdered(int64(ra), int64(rb))
+	}
+	switch x := a.(type) {
+	case nodeRef:
+		if y, ok := b.(nodeRef); ok {
+			return compareOrdered(int64(x), int64(y))
+		}
+	case relRef:
+		if y, ok := b.(relRef); ok {
+			return compareOrdered(int64(x), int64(y))
+		}
+	case []any:
+		if y, ok := b.([]any); ok {
+			return compareListOrder(x, y)
+		}
+	case pathValue:
+		if y, ok := b.(pathValue); ok {
+			return compareOrdered(int64(len(x.rels)), int64(len(y.rels)))
+		}
+	case map[string]any:
+		return compareOrdered(valueKey(a), valueKey(b))
+	}
+	c, ok := compare(a, b)
+	if !ok {
+		return 0
+	}
+	return c
+}
+
+func compareListOrder(x, y []any) int {
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if c := orderCompare(x[i], y[i]); c != 0 {
+			return c
+		}
+	}
+	return compareOrdered(int64(len(x)), int64(len(y)))
+}
+
+// valueKey returns a string that is equal for values that are equal, for
+// DISTINCT and grouping. Whole floats share the key of the integer.
+func valueKey(value any) string {
+	var b strings.Builder
+	writeKey(&b, value)
+	return b.String()
+}
+
+func writeKey(b *strings.Builder, value any) {
+	switch v := value.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case int64:
+		b.WriteString("n" + strconv.FormatInt(v, 10))
+	case float64:
+		b.WriteString("n" + formatKeyNumber(v))
+	case string:
+		b.WriteString(strconv.Quote(v))
+	case time.Time:
+		b.WriteString("t" + v.UTC().Format(time.RFC3339Nano))
+	case nodeRef:
+		b.WriteString("node" + strconv.Itoa(int(v)))
+	case relRef:
+		b.WriteString("rel" + strconv.Itoa(int(v)))
+	case pathValue:
+		b.WriteString("path")
+		writeKey(b, refsToList(v.nodes, v.rels))
+	case []any:
+		writeListKey(b, v)
+	case map[string]any:
+		writeMapKey(b, v)
+	default:
+		fmt.Fprintf(b, "%T:%v", v, v)
+	}
+}
+
+// formatKeyNumber writes whole floats as integers, so that 1.0 and 1 share a
+// key
+func formatKeyNumber(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func writeListKey(b *strings.Builder, list []any) {
+	b.WriteByte('[')
+	for _, item := range list {
+		writeKey(b, item)
+		b.WriteByte(',')
+	}
+	b.WriteByte(']')
+}
+
+func writeMapKey(b *strings.Builder, m map[string]any) {
+	b.WriteByte('{')
+	for _, key := range sortedKeys(m) {
+		b.WriteString(strconv.Quote(key) + ":")
+		writeKey(b, m[key])
+		b.WriteByte(',')
+	}
+	b.WriteByte('}')
+}
+
+func refsToList(nodes []nodeRef, rels []relRef) []any {
+	list := make([]any, 0, len(nodes)+len(rels))
+	for _, n := range nodes {
+		list = append(list, n)
+	}
+	for _, r := range rels {
+		list = append(list, r)
+	}
+	return list
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// typeName names the type of a value in error messages
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "Null"
+	case bool:
+		return "Boolean"
+	case int64:
+		return "Integer"
+	case float64:
+		return "Float"
+	case string:
+		return "String"
+	case time.Time:
+		return "DateTime"
+	case []any:
+		return "List"
+	case map[string]any:
+		return "Map"
+	case nodeRef:
+		return "Node"
+	case relRef:
+		return "Relationship"
+	case pathValue:
+		return "Path"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// result converts a value to what the Neo4j driver returns, so that results
+// read the same whichever store ran the query
+func (g *Graph) result(value any) any {
+	switch v := value.(type) {
+	case nodeRef:
+		return g.resultNode(v)
+	case relRef:
+		return g.resultRelationship(v)
+	case pathValue:
+		path := dbtype.Path{}
+		for _, n := range v.nodes {
+			path.Nodes = append(path.Nodes, g.resultNode(n))
+		}
+		for _, r := range v.rels {
+			path.Relationships = append(path.Relationships, g.resultRelationship(r))
+		}
+		return path
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = g.result(item)
+		}
+		return list
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			m[key] = g.result(item)
+		}
+		return m
+	}
+	return value
+}
+
+func (g *Graph) resultNode(n nodeRef) dbtype.Node {
+	return dbtype.Node{
+		Id:        int64(n),
+		ElementId: g.nodes[n].ID.String(),
+		Labels:    []string{g.label(n)},
+		Props:     copyProps(g.nodeProps[n]),
+	}
+}
+
+func (g *Graph) resultRelationship(r relRef) dbtype.Relationship {
+	return dbtype.Relationship{
+		Id:             int64(r),
+		ElementId:      g.rels[r].ID.String(),
+		StartId:        int64(g.relStart[r]),
+		StartElementId: g.nodes[g.relStart[r]].ID.String(),
+		EndId:          int64(g.relEnd[r]),
+		EndElementId:   g.nodes[g.relEnd[r]].ID.String(),
+		Type:           g.relType(r),
+		Props:          copyProps(g.relProps[r]),
+	}
+}
+
+func copyProps(props map[string]any) map[string]any {
+	result := make(map[string]any, len(props))
+	for key, value := range props {
+		result[key] = value
+	}
+	return result
+}
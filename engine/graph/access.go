@@ -0,0 +1,17 @@
+package graph
+
+import "context"
+
+type readOnlyKey struct{}
+
+// WithReadOnly returns a context whose queries repositories run in read
+// transactions, so that the database itself rejects writes
+func WithReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+// IsReadOnly reports whether the queries of a context must only read
+func IsReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey{}).(bool)
+	return readOnly
+}
@@ -0,0 +1,296 @@
+package graph
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/core"
+)
+
+// APICompatibility classifies a change to the exported API of a package
+type APICompatibility string
+
+const (
+	APIBreaking   APICompatibility = "breaking"
+	APICompatible APICompatibility = "compatible"
+)
+
+// SemverBump is the part of a semantic version that a set of changes requires
+// to be incremented
+type SemverBump string
+
+const (
+	SemverMajor SemverBump = "major"
+	SemverMinor SemverBump = "minor"
+	SemverPatch SemverBump = "patch"
+)
+
+// APIChange is a change to one exported symbol of a package
+type APIChange struct {
+	Package       string           `json:"package"`
+	Kind          core.NodeType    `json:"kind"`
+	Symbol        string           `json:"symbol"` // e.g. Client, Client.Do or NewClient
+	Description   string           `json:"description"`
+	Before        string           `json:"before,omitempty"`
+	After         string           `json:"after,omitempty"`
+	Compatibility APICompatibility `json:"compatibility"`
+}
+
+// APIReport is the classified difference between the exported API of two
+// versions of a project
+type APIReport struct {
+	Base        string       `json:"base"`
+	Head        string       `json:"head"`
+	Changes     []*APIChange `json:"changes"`
+	Bump        SemverBump   `json:"bump"`
+	NextVersion string       `json:"next_version,omitempty"`
+}
+
+// Breaking returns the number of breaking changes in the report
+func (r *APIReport) Breaking() int {
+	count := 0
+	for _, change := range r.Changes {
+		if change.Compatibility == APIBreaking {
+			count++
+		}
+	}
+	return count
+}
+
+// apiSymbol is an exported function, method or type of a package
+type apiSymbol struct {
+	kind          core.NodeType
+	signature     string
+	signatureType string            // Signature without parameter names, compared for functions and methods
+	members       map[string]string // Struct fields or interface methods by name
+	sealed        bool              // Interface with unexported methods, so it cannot be implemented elsewhere
+}
+
+// apiSurface holds the exported symbols of every public package by import path
+type apiSurface map[string]map[string]*apiSymbol
+
+// CheckAPI compares the exported API of two project graphs and classifies
+// every change as compatible or breaking. Packages named main and packages
+// below an internal directory are not part of the public API.
+func CheckAPI(base, head *core.AnalysisResult) *APIReport {
+	before, after := newAPISurface(base), newAPISurface(head)
+	report := &APIReport{Changes: []*APIChange{}}
+
+	for pkg, symbols := range before {
+		headSymbols, exists := after[pkg]
+		if !exists {
+			report.Changes = append(report.Changes, &APIChange{
+				Package: pkg, Kind: core.NodeTypePackage, Description: "package removed", Compatibility: APIBreaking,
+			})
+			continue
+		}
+		for name, symbol := range symbols {
+			report.Changes = append(report.Changes, compareAPISymbol(pkg, name, symbol, headSymbols[name])...)
+		}
+	}
+	for pkg, symbols := range after {
+		baseSymbols, exists := before[pkg]
+		if !exists {
+			report.Changes = append(report.Changes, &APIChange{
+				Package: pkg, Kind: core.NodeTypePackage, Description: "package added", Compatibility: APICompatible,
+			})
+			continue
+		}
+		for name, symbol := range symbols {
+			if _, exists := baseSymbols[name]; !exists {
+				report.Changes = append(report.Changes, &APIChange{
+					Package: pkg, Kind: symbol.kind, Symbol: name, Description: "added",
+					After: symbol.signature, Compatibility: APICompatible,
+				})
+			}
+		}
+	}
+
+	sort.Slice(report.Changes, func(i, j int) bool {
+		a, b := report.Changes[i], report.Changes[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Description < b.Description
+	})
+
+	report.Bump = SemverPatch
+	for _, change := range report.Changes {
+		if change.Compatibility == APIBreaking {
+			report.Bump = SemverMajor
+			break
+		}
+		report.Bump = SemverMinor
+	}
+	return report
+}
+
+// compareAPISymbol classifies the changes between the base and head version
+// of an exported symbol; after is nil when the symbol was removed
+func compareAPISymbol(pkg, name string, before, after *apiSymbol) []*APIChange {
+	change := func(description string, compatibility APICompatibility, beforeValue, afterValue string) *APIChange {
+		return &APIChange{
+			Package: pkg, Kind: before.kind, Symbol: name, Description: description,
+			Before: beforeValue, After: afterValue, Compatibility: compatibility,
+		}
+	}
+
+	switch {
+	case after == nil:
+		return []*APIChange{change("removed", APIBreaking, before.signature, "")}
+	case before.kind != after.kind:
+		description := fmt.Sprintf("changed from %s to %s", kindLabel(before.kind), kindLabel(after.kind))
+		return []*APIChange{change(description, APIBreaking, "", "")}
+	case before.kind == core.NodeTypeFunction || before.kind == core.NodeTypeMethod:
+		if before.signatureType != after.signatureType {
+			return []*APIChange{change("signature changed", APIBreaking, before.signature, after.signature)}
+		}
+		return nil
+	}
+
+	member := "field"
+	// New fields are compatible; a new interface method breaks every
+	// implementation outside the package unless the interface is sealed
+	addedCompatibility := APICompatible
+	if before.kind == core.NodeTypeInterface {
+		member = "method"
+		if !before.sealed {
+			addedCompatibility = APIBreaking
+		}
+	}
+
+	var changes []*APIChange
+	for memberName, memberType := range before.members {
+		headType, exists := after.members[memberName]
+		switch {
+		case !exists:
+			changes = append(changes, change(member+" "+memberName+" removed", APIBreaking, memberType, ""))
+		case headType != memberType:
+			changes = append(changes, change(member+" "+memberName+" changed", APIBreaking, memberType, headType))
+		}
+	}
+	for memberName, memberType := range after.members {
+		if _, exists := before.members[memberName]; !exists {
+			changes = append(changes, change(member+" "+memberName+" added", addedCompatibility, "", memberType))
+		}
+	}
+	return changes
+}
+
+// newAPISurface collects the exported symbols of the public packages of a graph
+func newAPISurface(result *core.AnalysisResult) apiSurface {
+	surface := make(apiSurface)
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		if node.Type == core.NodeTypePackage && node.Name != "main" && !isInternalPackage(packagePath(node)) {
+			surface[packagePath(node)] = make(map[string]*apiSymbol)
+		}
+	}
+
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		pkg, _ := node.Properties["package"].(string)
+		symbols, public := surface[pkg]
+		if !public || !ast.IsExported(node.Name) {
+			continue
+		}
+
+		switch node.Type {
+		case core.NodeTypeFunction:
+			symbols[node.Name] = newFunctionSymbol(node)
+		case core.NodeTypeMethod:
+			receiver, _ := node.Properties["receiver"].(string)
+			typeName := analyzer.ReceiverTypeName(receiver)
+			if ast.IsExported(typeName) {
+				symbols[typeName+"."+node.Name] = newFunctionSymbol(node)
+			}
+		case core.NodeTypeStruct, core.NodeTypeInterface:
+			symbols[node.Name] = newTypeSymbol(node)
+		}
+	}
+	return surface
+}
+
+// newFunctionSymbol describes an exported function or method
+func newFunctionSymbol(node *core.Node) *apiSymbol {
+	signature, _ := node.Properties["signature"].(string)
+	return &apiSymbol{kind: node.Type, signature: signature, signatureType: signatureType(node.Properties)}
+}
+
+// newTypeSymbol describes an exported struct by its exported fields or an
+// interface by its methods
+func newTypeSymbol(node *core.Node) *apiSymbol {
+	symbol := &apiSymbol{kind: node.Type, signature: typeShape(node), members: make(map[string]string)}
+	if node.Type == core.NodeTypeStruct {
+		for _, field := range propertyList(node.Properties["fields"]) {
+			name := fmt.Sprint(field["name"])
+			if ast.IsExported(name) {
+				symbol.members[name] = fmt.Sprint(field["type"])
+			}
+		}
+		return symbol
+	}
+	for _, method := range propertyList(node.Properties["methods"]) {
+		name := fmt.Sprint(method["name"])
+		symbol.members[name] = signatureType(method)
+		if !ast.IsExported(name) {
+			symbol.sealed = true
+		}
+	}
+	return symbol
+}
+
+// signatureType returns the signature of a function or interface method
+// without parameter names. Graphs built before the name-free form was stored
+// only have the full signature.
+func signatureType(props map[string]any) string {
+	if signature, ok := props["signature_type"].(string); ok {
+		return signature
+	}
+	return fmt.Sprint(props["signature"])
+}
+
+func isInternalPackage(importPath string) bool {
+	for _, element := range strings.Split(importPath, "/") {
+		if element == "internal" {
+			return true
+		}
+	}
+	return false
+}
+
+// semverPattern matches a semantic version tag with an optional module
+// directory prefix, e.g. v1.4.0 or sdk/client/v1.4.0-rc.1
+var semverPattern = regexp.MustCompile(`^(.*/)?v(\d+)\.(\d+)\.(\d+)(?:[-+].*)?$`)
+
+// NextVersion returns the version that follows the semantic version tag
+// current for the given bump, keeping any module directory prefix. Before
+// v1.0.0 breaking changes only increment the minor version. It returns false
+// when current is not a semantic version.
+func NextVersion(current string, bump SemverBump) (string, bool) {
+	match := semverPattern.FindStringSubmatch(current)
+	if match == nil {
+		return "", false
+	}
+	major, _ := strconv.Atoi(match[2])
+	minor, _ := strconv.Atoi(match[3])
+	patch, _ := strconv.Atoi(match[4])
+
+	switch {
+	case bump == SemverMajor && major > 0:
+		major, minor, patch = major+1, 0, 0
+	case bump == SemverMajor || bump == SemverMinor:
+		minor, patch = minor+1, 0
+	default:
+		patch++
+	}
+	return fmt.Sprintf("%sv%d.%d.%d", match[1], major, minor, patch), true
+}
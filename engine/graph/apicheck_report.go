@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// apiChangeLabel renders the symbol and description of a change
+func apiChangeLabel(change *APIChange) string {
+	if change.Symbol == "" {
+		return change.Description
+	}
+	return fmt.Sprintf("%s %s: %s", kindLabel(change.Kind), change.Symbol, change.Description)
+}
+
+// summary returns the one-line change counts and the suggested bump
+func (r *APIReport) summary() string {
+	breaking := r.Breaking()
+	line := fmt.Sprintf("%d breaking, %d compatible change(s); suggested bump: %s",
+		breaking, len(r.Changes)-breaking, r.Bump)
+	if r.NextVersion != "" {
+		line += fmt.Sprintf(" (%s → %s)", r.Base, r.NextVersion)
+	}
+	return line
+}
+
+// majorVersionNote reminds that Go modules from v2 on need a major version
+// suffix on their module path
+func (r *APIReport) majorVersionNote() string {
+	match := semverPattern.FindStringSubmatch(r.NextVersion)
+	if r.Bump != SemverMajor || match == nil || match[2] == "0" || match[2] == "1" {
+		return ""
+	}
+	return fmt.Sprintf("Module paths of v%s and later must end in /v%s.", match[2], match[2])
+}
+
+// WriteText writes the report as plain text grouped by package
+func (r *APIReport) WriteText(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "API compatibility %s..%s\n", r.Base, r.Head)
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(out, "No changes to the exported API.")
+	}
+
+	pkg := ""
+	for _, change := range r.Changes {
+		if change.Package != pkg {
+			pkg = change.Package
+			fmt.Fprintf(out, "\n%s\n", pkg)
+		}
+		fmt.Fprintf(out, "  %-10s  %s\n", change.Compatibility, apiChangeLabel(change))
+		if change.Before != "" && change.After != "" {
+			fmt.Fprintf(out, "%14s- %s\n%14s+ %s\n", "", change.Before, "", change.After)
+		}
+	}
+
+	fmt.Fprintf(out, "\n%s\n", r.summary())
+	if note := r.majorVersionNote(); note != "" {
+		fmt.Fprintln(out, note)
+	}
+	return out.Flush()
+}
+
+// WriteMarkdown writes the report as Markdown suitable for release notes
+func (r *APIReport) WriteMarkdown(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "### API compatibility `%s`..`%s`\n\n", r.Base, r.Head)
+	fmt.Fprintln(out, r.summary())
+	if note := r.majorVersionNote(); note != "" {
+		fmt.Fprintf(out, "\n%s\n", note)
+	}
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(out, "\nNo changes to the exported API.")
+		return out.Flush()
+	}
+
+	fmt.Fprintln(out, "\n| Package | Change | Compatibility |")
+	fmt.Fprintln(out, "| --- | --- | --- |")
+	for _, change := range r.Changes {
+		label := change.Description
+		if change.Symbol != "" {
+			label = fmt.Sprintf("%s `%s`: %s", kindLabel(change.Kind), change.Symbol, change.Description)
+		}
+		compatibility := string(change.Compatibility)
+		if change.Compatibility == APIBreaking {
+			compatibility = "**" + compatibility + "**"
+		}
+		fmt.Fprintf(out, "| `%s` | %s | %s |\n", change.Package, strings.ReplaceAll(label, "|", "\\|"), compatibility)
+	}
+	return out.Flush()
+}
@@ -0,0 +1,198 @@
+package graph_test
+
+import (
+	"bytes"
+	"context"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/engine/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (f *graphFixture) method(file, pkg, receiver, name, signature string) *graphFixture {
+	id := f.node(core.NodeTypeMethod, name, map[string]any{
+		"package": pkg, "receiver": "*" + pkg + "." + receiver, "signature": signature,
+	})
+	f.rel(core.RelationDefines, f.files[file], id)
+	return f
+}
+
+func (f *graphFixture) iface(file, pkg, name string, methods string) *graphFixture {
+	id := f.node(core.NodeTypeInterface, name, map[string]any{"package": pkg, "methods": methods})
+	f.rel(core.RelationDefines, f.files[file], id)
+	return f
+}
+
+func TestCheckAPI(t *testing.T) {
+	base := newGraphFixture().
+		file("sdk/client", "client/client.go").
+		file("sdk/internal/wire", "internal/wire/wire.go").
+		file("sdk/old", "old/old.go").
+		function("client/client.go", "sdk/client", "NewClient", "func() *sdk/client.Client").
+		function("client/client.go", "sdk/client", "helper", "func()").
+		structType("client/client.go", "sdk/client", "Client", `[{"name":"Timeout","type":"int"},{"name":"Retries","type":"int"}]`).
+		method("client/client.go", "sdk/client", "Client", "Do", "func(r string) error").
+		method("client/client.go", "sdk/client", "Client", "Close", "func() error").
+		iface("client/client.go", "sdk/client", "Doer", `[{"name":"Do","signature":"func(r string) error"}]`).
+		iface("client/client.go", "sdk/client", "Option", `[{"name":"apply","signature":"func()"}]`).
+		function("internal/wire/wire.go", "sdk/internal/wire", "Encode", "func()").
+		function("old/old.go", "sdk/old", "Legacy", "func()").
+		result
+	head := newGraphFixture().
+		file("sdk/client", "client/client.go").
+		file("sdk/internal/wire", "internal/wire/wire.go").
+		file("sdk/extra", "extra/extra.go").
+		function("client/client.go", "sdk/client", "NewClient", "func() *sdk/client.Client").
+		function("client/client.go", "sdk/client", "NewDefault", "func() *sdk/client.Client").
+		structType("client/client.go", "sdk/client", "Client", `[{"name":"Timeout","type":"int64"},{"name":"Logger","type":"string"}]`).
+		method("client/client.go", "sdk/client", "Client", "Do", "func(ctx context.Context, r string) error").
+		iface("client/client.go", "sdk/client", "Doer", `[{"name":"Do","signature":"func(r string) error"},{"name":"Close","signature":"func() error"}]`).
+		iface("client/client.go", "sdk/client", "Option", `[{"name":"apply","signature":"func()"},{"name":"Name","signature":"func() string"}]`).
+		function("extra/extra.go", "sdk/extra", "Extra", "func()").
+		result
+
+	report := graph.CheckAPI(base, head)
+
+	changes := make(map[string]graph.APICompatibility)
+	for _, change := range report.Changes {
+		changes[change.Package+" "+change.Symbol+": "+change.Description] = change.Compatibility
+	}
+
+	t.Run("Should classify breaking changes", func(t *testing.T) {
+		assert.Equal(t, graph.APIBreaking, changes["sdk/client Client.Close: removed"])
+		assert.Equal(t, graph.APIBreaking, changes["sdk/client Client.Do: signature changed"])
+		assert.Equal(t, graph.APIBreaking, changes["sdk/client Doer: method Close added"])
+		assert.Equal(t, graph.APIBreaking, changes["sdk/client Client: field Retries removed"])
+		assert.Equal(t, graph.APIBreaking, changes["sdk/client Client: field Timeout changed"])
+		assert.Equal(t, graph.APIBreaking, changes["sdk/old : package removed"])
+	})
+
+	t.Run("Should classify compatible changes", func(t *testing.T) {
+		assert.Equal(t, graph.APICompatible, changes["sdk/client NewDefault: added"])
+		assert.Equal(t, graph.APICompatible, changes["sdk/client Client: field Logger added"])
+		assert.Equal(t, graph.APICompatible, changes["sdk/client Option: method Name added"])
+		assert.Equal(t, graph.APICompatible, changes["sdk/extra : package added"])
+	})
+
+	t.Run("Should ignore unexported symbols and internal packages", func(t *testing.T) {
+		assert.Len(t, report.Changes, 10)
+		for _, change := range report.Changes {
+			assert.NotEqual(t, "sdk/internal/wire", change.Package)
+		}
+	})
+
+	t.Run("Should suggest a major bump for breaking changes", func(t *testing.T) {
+		assert.Equal(t, graph.SemverMajor, report.Bump)
+		assert.Equal(t, 6, report.Breaking())
+	})
+
+	t.Run("Should suggest a minor bump for additions and a patch otherwise", func(t *testing.T) {
+		assert.Empty(t, graph.CheckAPI(head, head).Changes)
+
+		additions := graph.CheckAPI(newGraphFixture().file("sdk/a", "a/a.go").result,
+			newGraphFixture().file("sdk/a", "a/a.go").function("a/a.go", "sdk/a", "New", "func()").result)
+		assert.Equal(t, graph.SemverMinor, additions.Bump)
+		assert.Equal(t, graph.SemverPatch, graph.CheckAPI(head, head).Bump)
+	})
+}
+
+// builtAPIGraph builds the graph of a package with one exported function and
+// one exported interface method, both taking a string parameter of the given name
+func builtAPIGraph(t *testing.T, param string) *core.AnalysisResult {
+	t.Helper()
+	pkg := types.NewPackage("sdk/client", "client")
+	signature := types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(types.NewParam(token.NoPos, pkg, param, types.Typ[types.String])),
+		types.NewTuple(types.NewParam(token.NoPos, pkg, "", types.Universe.Lookup("error").Type())),
+		false)
+	fn := &parser.FunctionInfo{Name: "Send", Signature: signature, IsExported: true}
+	doer := &parser.TypeInfo{Name: "Doer", Type: types.NewInterfaceType(nil, nil), IsExported: true}
+	file := &parser.FileInfo{
+		Path: "/src/client/client.go", Package: "client",
+		Functions: []*parser.FunctionInfo{fn}, Types: []*parser.TypeInfo{doer},
+	}
+	parseResult := &parser.ParseResult{Packages: []*parser.PackageInfo{{
+		Path: "sdk/client", Name: "client", Files: []*parser.FileInfo{file},
+		Functions: []*parser.FunctionInfo{fn}, Types: []*parser.TypeInfo{doer},
+		Interfaces: []*parser.InterfaceInfo{{
+			Name: "Doer", Package: "sdk/client", IsExported: true,
+			Methods: []*parser.MethodInfo{{Name: "Do", Signature: signature}},
+		}},
+	}}}
+	result, err := graph.NewBuilder(nil).BuildFromAnalysis(context.Background(), "sdk", parseResult,
+		&analyzer.AnalysisReport{})
+	require.NoError(t, err)
+	return result
+}
+
+func TestCheckAPI_ParameterNames(t *testing.T) {
+	t.Run("Should not report a renamed parameter as a change", func(t *testing.T) {
+		report := graph.CheckAPI(builtAPIGraph(t, "name"), builtAPIGraph(t, "id"))
+
+		assert.Empty(t, report.Changes)
+		assert.Equal(t, graph.SemverPatch, report.Bump)
+	})
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		current string
+		bump    graph.SemverBump
+		want    string
+	}{
+		{"v1.4.0", graph.SemverMajor, "v2.0.0"},
+		{"v1.4.2", graph.SemverMinor, "v1.5.0"},
+		{"v1.4.2", graph.SemverPatch, "v1.4.3"},
+		{"v0.3.1", graph.SemverMajor, "v0.4.0"},
+		{"sdk/client/v1.4.0-rc.1", graph.SemverMinor, "sdk/client/v1.5.0"},
+	}
+	for _, tt := range tests {
+		t.Run("Should bump "+tt.current+" by "+string(tt.bump), func(t *testing.T) {
+			next, ok := graph.NextVersion(tt.current, tt.bump)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, next)
+		})
+	}
+
+	t.Run("Should reject non-semver revisions", func(t *testing.T) {
+		_, ok := graph.NextVersion("main", graph.SemverMinor)
+		assert.False(t, ok)
+	})
+}
+
+func TestAPIReport_Write(t *testing.T) {
+	base := newGraphFixture().
+		file("sdk/a", "a/a.go").
+		function("a/a.go", "sdk/a", "Run", "func()").
+		result
+	head := newGraphFixture().
+		file("sdk/a", "a/a.go").
+		function("a/a.go", "sdk/a", "Run", "func() error").
+		result
+	report := graph.CheckAPI(base, head)
+	report.Base, report.Head = "v1.4.0", "HEAD"
+	report.NextVersion, _ = graph.NextVersion(report.Base, report.Bump)
+
+	t.Run("Should write text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, report.WriteText(&buf))
+
+		assert.Contains(t, buf.String(), "sdk/a\n  breaking    function Run: signature changed\n")
+		assert.Contains(t, buf.String(), "- func()\n")
+		assert.Contains(t, buf.String(), "1 breaking, 0 compatible change(s); suggested bump: major (v1.4.0 → v2.0.0)")
+		assert.Contains(t, buf.String(), "must end in /v2")
+	})
+
+	t.Run("Should write Markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, report.WriteMarkdown(&buf))
+
+		assert.Contains(t, buf.String(), "| `sdk/a` | function `Run`: signature changed | **breaking** |")
+	})
+}
@@ -0,0 +1,270 @@
+package graph
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// ArchiveFormat identifies graph archive files
+const ArchiveFormat = "gograph-graph"
+
+// ArchiveVersion is the schema version written by WriteArchive
+const ArchiveVersion = 1
+
+// projectMetadataType labels the node that summarizes a stored analysis
+const projectMetadataType core.NodeType = "ProjectMetadata"
+
+// storedProperties are set by the stores from the node and relationship
+// fields, or when the graph is stored, and are not kept in archives
+var storedProperties = []string{"id", "name", "path", "created_at", "snapshot_id"}
+
+// archiveMigrations upgrade an archive document to the next schema version.
+// The migration at index i reads version i+1 and writes version i+2.
+var archiveMigrations []func(doc map[string]json.RawMessage) error
+
+// Archive is a portable copy of the graph of a project. The analysis result
+// carries the project totals and, when the graph was stored as a snapshot,
+// the commit it was analyzed at.
+type Archive struct {
+	Format  string               `json:"format"`
+	Version int                  `json:"version"`
+	SavedAt time.Time            `json:"saved_at"`
+	Result  *core.AnalysisResult `json:"result"`
+}
+
+// NewArchive prepares a graph read with LoadAnalysisResult for saving. The
+// project metadata node supplies the totals and is left out, like the
+// properties the stores derive from the node fields. Graphs read from a
+// snapshot scope are saved under the project ID.
+func NewArchive(stored *core.AnalysisResult, snapshot *core.Snapshot) *Archive {
+	projectID := stored.ProjectID
+	if snapshot != nil {
+		projectID = snapshot.ProjectID
+	}
+	result := &core.AnalysisResult{
+		ProjectID:     projectID,
+		Nodes:         make([]core.Node, 0, len(stored.Nodes)),
+		Relationships: make([]core.Relationship, 0, len(stored.Relationships)),
+		Snapshot:      snapshot,
+	}
+	for i := range stored.Nodes {
+		node := stored.Nodes[i]
+		if node.Type == projectMetadataType {
+			applyProjectMetadata(result, node.Properties)
+			continue
+		}
+		node.CreatedAt = createdAt(node.Properties, node.CreatedAt)
+		node.Properties = archivedProperties(node.Properties, projectID)
+		result.Nodes = append(result.Nodes, node)
+	}
+	for i := range stored.Relationships {
+		rel := stored.Relationships[i]
+		rel.CreatedAt = createdAt(rel.Properties, rel.CreatedAt)
+		rel.Properties = archivedProperties(rel.Properties, projectID)
+		result.Relationships = append(result.Relationships, rel)
+	}
+	if result.AnalyzedAt.IsZero() && snapshot != nil {
+		result.AnalyzedAt = snapshot.CreatedAt
+	}
+	return &Archive{Format: ArchiveFormat, Version: ArchiveVersion, SavedAt: time.Now().UTC(), Result: result}
+}
+
+// WriteArchive writes the archive as gzip-compressed JSON
+func WriteArchive(w io.Writer, archive *Archive) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(archive); err != nil {
+		return fmt.Errorf("failed to encode graph archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress graph archive: %w", err)
+	}
+	return nil
+}
+
+// ReadArchive reads an archive written by WriteArchive, migrating archives
+// of older schema versions. Property values get the types the stores use:
+// whole numbers become int64 and lists of one primitive type become typed
+// slices.
+func ReadArchive(r io.Reader) (*Archive, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a graph archive: %w", err)
+	}
+	defer zr.Close()
+
+	var doc map[string]json.RawMessage
+	if err := json.NewDecoder(zr).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode graph archive: %w", err)
+	}
+	var format string
+	if err := json.Unmarshal(doc["format"], &format); err != nil || format != ArchiveFormat {
+		return nil, errors.New("not a graph archive: missing format marker")
+	}
+	var version int
+	if err := json.Unmarshal(doc["version"], &version); err != nil || version < 1 {
+		return nil, errors.New("graph archive has no valid schema version")
+	}
+	if version > ArchiveVersion {
+		return nil, fmt.Errorf("graph archive version %d is newer than the supported version %d; upgrade gograph",
+			version, ArchiveVersion)
+	}
+	for ; version < ArchiveVersion; version++ {
+		if err := archiveMigrations[version-1](doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate graph archive from version %d: %w", version, err)
+		}
+	}
+	doc["version"] = json.RawMessage(strconv.Itoa(ArchiveVersion))
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode graph archive: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	archive := &Archive{}
+	if err := decoder.Decode(archive); err != nil {
+		return nil, fmt.Errorf("failed to decode graph archive: %w", err)
+	}
+	if archive.Result == nil {
+		return nil, errors.New("graph archive has no graph")
+	}
+	for i := range archive.Result.Nodes {
+		archive.Result.Nodes[i].Properties = restoreProperties(archive.Result.Nodes[i].Properties)
+	}
+	for i := range archive.Result.Relationships {
+		archive.Result.Relationships[i].Properties = restoreProperties(archive.Result.Relationships[i].Properties)
+	}
+	return archive, nil
+}
+
+// ForProject returns the archived analysis result stored under a project ID,
+// which may differ from the project the archive was saved from. Nodes and
+// relationships get new IDs, so loading never replaces the graph the archive
+// was saved from, whether it is still the latest or archived as a snapshot.
+func (a *Archive) ForProject(projectID core.ID) *core.AnalysisResult {
+	result := *a.Result
+	result.ProjectID = projectID
+	result.Snapshot = nil
+	result.Nodes = make([]core.Node, len(a.Result.Nodes))
+	result.Relationships = make([]core.Relationship, len(a.Result.Relationships))
+	ids := make(map[core.ID]core.ID, len(a.Result.Nodes))
+	for i, node := range a.Result.Nodes {
+		ids[node.ID] = core.NewID()
+		node.ID = ids[node.ID]
+		node.Properties = archivedProperties(node.Properties, projectID)
+		result.Nodes[i] = node
+	}
+	remap := func(id core.ID) core.ID {
+		if newID, ok := ids[id]; ok {
+			return newID
+		}
+		return id
+	}
+	for i, rel := range a.Result.Relationships {
+		rel.ID = core.NewID()
+		rel.FromNodeID = remap(rel.FromNodeID)
+		rel.ToNodeID = remap(rel.ToNodeID)
+		rel.Properties = archivedProperties(rel.Properties, projectID)
+		result.Relationships[i] = rel
+	}
+	return &result
+}
+
+// applyProjectMetadata copies the totals of the project metadata node
+func applyProjectMetadata(result *core.AnalysisResult, props map[string]any) {
+	result.TotalFiles = intValue(props["total_files"])
+	result.TotalPackages = intValue(props["total_packages"])
+	result.TotalFunctions = intValue(props["total_functions"])
+	result.TotalStructs = intValue(props["total_structs"])
+	if analyzedAt, ok := props["analyzed_at"].(time.Time); ok {
+		result.AnalyzedAt = analyzedAt.UTC()
+	}
+}
+
+func createdAt(props map[string]any, fallback time.Time) time.Time {
+	if t, ok := props["created_at"].(time.Time); ok {
+		return t.UTC()
+	}
+	return fallback
+}
+
+// archivedProperties copies properties without the stored ones, scoped to
+// the project
+func archivedProperties(props map[string]any, projectID core.ID) map[string]any {
+	copied := make(map[string]any, len(props))
+	for name, value := range props {
+		copied[name] = value
+	}
+	for _, name := range storedProperties {
+		delete(copied, name)
+	}
+	copied["project_id"] = projectID.String()
+	return copied
+}
+
+// restoreProperties converts decoded JSON values to store property types
+func restoreProperties(props map[string]any) map[string]any {
+	for name, value := range props {
+		props[name] = restoreValue(value)
+	}
+	return props
+}
+
+func restoreValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []any:
+		return restoreList(v)
+	default:
+		return value
+	}
+}
+
+// restoreList types a list whose elements share a primitive type. Other
+// lists are kept, and stored as JSON like any complex value.
+func restoreList(values []any) any {
+	restored := make([]any, len(values))
+	for i, value := range values {
+		restored[i] = restoreValue(value)
+	}
+	if typed, ok := typedList[string](restored); ok {
+		return typed
+	}
+	if typed, ok := typedList[int64](restored); ok {
+		return typed
+	}
+	if typed, ok := typedList[float64](restored); ok {
+		return typed
+	}
+	if typed, ok := typedList[bool](restored); ok {
+		return typed
+	}
+	return restored
+}
+
+func typedList[T any](values []any) ([]T, bool) {
+	typed := make([]T, len(values))
+	for i, value := range values {
+		v, ok := value.(T)
+		if !ok {
+			return nil, false
+		}
+		typed[i] = v
+	}
+	return typed, true
+}
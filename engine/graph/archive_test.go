@@ -0,0 +1,104 @@
+package graph_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storedGraph mimics a graph read back from a store with LoadAnalysisResult
+func storedGraph(createdAt time.Time) *core.AnalysisResult {
+	return &core.AnalysisResult{
+		ProjectID: "proj@s1",
+		Nodes: []core.Node{
+			{ID: "f1", Type: core.NodeTypeFunction, Name: "Run", Path: "main.go", Properties: map[string]any{
+				"id": "f1", "name": "Run", "path": "main.go", "created_at": createdAt,
+				"project_id": "proj@s1", "snapshot_id": "s1",
+				"line_start": int64(10), "exported": true, "params": []string{"ctx"}, "ratio": 0.5,
+			}},
+			{ID: "f2", Type: core.NodeTypeFunction, Name: "help", Properties: map[string]any{
+				"id": "f2", "project_id": "proj@s1", "lines": []int64{1, 2},
+			}},
+			{Type: "ProjectMetadata", Properties: map[string]any{
+				"project_id": "proj@s1", "total_files": int64(3), "total_functions": int64(2), "analyzed_at": createdAt,
+			}},
+		},
+		Relationships: []core.Relationship{
+			{ID: "r1", Type: core.RelationCalls, FromNodeID: "f1", ToNodeID: "f2", Properties: map[string]any{
+				"id": "r1", "created_at": createdAt, "project_id": "proj@s1", "snapshot_id": "s1",
+			}},
+		},
+	}
+}
+
+func TestArchive(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := &core.Snapshot{ID: "s1", ProjectID: "proj", CommitSHA: "abc123", CreatedAt: createdAt}
+	archive := graph.NewArchive(storedGraph(createdAt), snapshot)
+
+	t.Run("Should keep the graph and metadata without stored properties", func(t *testing.T) {
+		result := archive.Result
+		assert.Equal(t, core.ID("proj"), result.ProjectID)
+		assert.Equal(t, 3, result.TotalFiles)
+		assert.Equal(t, 2, result.TotalFunctions)
+		assert.Equal(t, createdAt, result.AnalyzedAt)
+		require.Len(t, result.Nodes, 2)
+		assert.Equal(t, createdAt, result.Nodes[0].CreatedAt)
+		assert.Equal(t, map[string]any{
+			"project_id": "proj", "line_start": int64(10), "exported": true, "params": []string{"ctx"}, "ratio": 0.5,
+		}, result.Nodes[0].Properties)
+		assert.Equal(t, map[string]any{"project_id": "proj"}, result.Relationships[0].Properties)
+	})
+
+	t.Run("Should read back a written archive with property types", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, graph.WriteArchive(&buf, archive))
+		read, err := graph.ReadArchive(&buf)
+		require.NoError(t, err)
+
+		assert.Equal(t, graph.ArchiveVersion, read.Version)
+		assert.Equal(t, "abc123", read.Result.Snapshot.CommitSHA)
+		assert.Equal(t, archive.Result.Nodes[0].Properties, read.Result.Nodes[0].Properties)
+		assert.Equal(t, []int64{1, 2}, read.Result.Nodes[1].Properties["lines"])
+		assert.True(t, createdAt.Equal(read.Result.Nodes[0].CreatedAt))
+		assert.Equal(t, core.ID("f2"), read.Result.Relationships[0].ToNodeID)
+	})
+
+	t.Run("Should store the graph under another project", func(t *testing.T) {
+		result := archive.ForProject("other")
+		assert.Equal(t, core.ID("other"), result.ProjectID)
+		assert.Nil(t, result.Snapshot)
+		assert.Equal(t, "other", result.Nodes[0].Properties["project_id"])
+		assert.Equal(t, "other", result.Relationships[0].Properties["project_id"])
+		assert.Equal(t, "proj", archive.Result.Nodes[0].Properties["project_id"])
+	})
+
+	t.Run("Should give nodes and relationships new IDs", func(t *testing.T) {
+		result := archive.ForProject("proj")
+		assert.NotEqual(t, core.ID("f1"), result.Nodes[0].ID)
+		assert.NotEqual(t, core.ID("r1"), result.Relationships[0].ID)
+		assert.Equal(t, result.Nodes[0].ID, result.Relationships[0].FromNodeID)
+		assert.Equal(t, result.Nodes[1].ID, result.Relationships[0].ToNodeID)
+		assert.Equal(t, core.ID("f1"), archive.Result.Nodes[0].ID)
+		assert.NotEqual(t, result.Nodes[0].ID, archive.ForProject("proj").Nodes[0].ID)
+	})
+
+	t.Run("Should reject other files and newer versions", func(t *testing.T) {
+		_, err := graph.ReadArchive(bytes.NewReader([]byte("plain text")))
+		assert.ErrorContains(t, err, "not a graph archive")
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err = zw.Write([]byte(`{"format":"gograph-graph","version":99,"result":{}}`))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		_, err = graph.ReadArchive(&buf)
+		assert.ErrorContains(t, err, "version 99 is newer")
+	})
+}
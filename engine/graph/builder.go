@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go/types"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/compozy/gograph/engine/analyzer"
@@ -311,6 +312,7 @@ func (b *builder) createFunctionNode(
 			"is_exported": fn.IsExported,
 			"package":     pkg.Path,
 			"project_id":  result.ProjectID.String(),
+			"complexity":  fn.Complexity,
 		},
 		CreatedAt: time.Now(),
 	}
@@ -318,6 +320,7 @@ func (b *builder) createFunctionNode(
 	// Add signature if available
 	if fn.Signature != nil {
 		fnNode.Properties["signature"] = fn.Signature.String()
+		fnNode.Properties["signature_type"] = getSignatureType(fn.Signature)
 	}
 
 	// Add receiver info for methods
@@ -437,6 +440,7 @@ func (b *builder) processPackageTypes(
 					}
 					if method.Signature != nil {
 						methodMap["signature"] = method.Signature.String()
+						methodMap["signature_type"] = getSignatureType(method.Signature)
 					}
 					methods = append(methods, methodMap)
 				}
@@ -645,6 +649,30 @@ func getTypeString(t types.Type) string {
 	return t.String()
 }
 
+// getSignatureType converts a function signature to a string without
+// parameter and result names, so that renaming a parameter leaves it unchanged
+func getSignatureType(sig *types.Signature) string {
+	unnamed := func(tuple *types.Tuple) *types.Tuple {
+		vars := make([]*types.Var, tuple.Len())
+		for i := range vars {
+			v := tuple.At(i)
+			vars[i] = types.NewParam(v.Pos(), v.Pkg(), "", v.Type())
+		}
+		return types.NewTuple(vars...)
+	}
+	signature := types.NewSignatureType(nil, nil, nil, unnamed(sig.Params()), unnamed(sig.Results()), sig.Variadic())
+
+	typeParams := make([]string, 0, sig.TypeParams().Len())
+	for i := 0; i < sig.TypeParams().Len(); i++ {
+		param := sig.TypeParams().At(i)
+		typeParams = append(typeParams, param.Obj().Name()+" "+param.Constraint().String())
+	}
+	if len(typeParams) == 0 {
+		return signature.String()
+	}
+	return "func[" + strings.Join(typeParams, ", ") + "]" + strings.TrimPrefix(signature.String(), "func")
+}
+
 // getPackageFromType extracts package path from TypeInfo
 func getPackageFromType(t *parser.TypeInfo) string {
 	if t.Type == nil {
@@ -0,0 +1,454 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// DiffOptions configures how two project graphs are compared
+type DiffOptions struct {
+	BaseRoot string // Project root of the base graph, stripped from file paths
+	HeadRoot string // Project root of the head graph, stripped from file paths
+}
+
+// DiffEntity is a package, function, method or type of a project graph
+type DiffEntity struct {
+	Kind      core.NodeType `json:"kind"`
+	Name      string        `json:"name"` // Qualified name, e.g. example.com/app/user.Service.Save
+	Package   string        `json:"package"`
+	File      string        `json:"file,omitempty"`
+	Signature string        `json:"signature,omitempty"`
+}
+
+// EntityChange pairs the base and head versions of an entity
+type EntityChange struct {
+	Before *DiffEntity `json:"before"`
+	After  *DiffEntity `json:"after"`
+}
+
+// DiffEdge is a CALLS edge between functions or an IMPORTS edge between packages
+type DiffEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DiffCycle is a set of packages that import each other
+type DiffCycle struct {
+	Packages []string `json:"packages"`
+}
+
+// GraphDiff is the structural difference between two project graphs
+type GraphDiff struct {
+	Base           string          `json:"base"`
+	Head           string          `json:"head"`
+	Added          []*DiffEntity   `json:"added"`
+	Removed        []*DiffEntity   `json:"removed"`
+	Moved          []*EntityChange `json:"moved"`
+	Changed        []*EntityChange `json:"changed"`
+	AddedCalls     []*DiffEdge     `json:"added_calls"`
+	RemovedCalls   []*DiffEdge     `json:"removed_calls"`
+	AddedImports   []*DiffEdge     `json:"added_imports"`
+	RemovedImports []*DiffEdge     `json:"removed_imports"`
+	NewCycles      []*DiffCycle    `json:"new_cycles"`
+	ResolvedCycles []*DiffCycle    `json:"resolved_cycles"`
+}
+
+// Empty reports whether the two graphs are structurally identical
+func (d *GraphDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0 && len(d.Changed) == 0 &&
+		len(d.AddedCalls) == 0 && len(d.RemovedCalls) == 0 &&
+		len(d.AddedImports) == 0 && len(d.RemovedImports) == 0 &&
+		len(d.NewCycles) == 0 && len(d.ResolvedCycles) == 0
+}
+
+// Diff compares two project graphs. Entities are matched by qualified name,
+// so node IDs do not need to be stable between the graphs.
+func Diff(base, head *core.AnalysisResult, opts *DiffOptions) *GraphDiff {
+	if opts == nil {
+		opts = &DiffOptions{}
+	}
+	before := indexGraph(base, opts.BaseRoot)
+	after := indexGraph(head, opts.HeadRoot)
+
+	diff := &GraphDiff{
+		Added:          make([]*DiffEntity, 0),
+		Removed:        make([]*DiffEntity, 0),
+		Moved:          make([]*EntityChange, 0),
+		Changed:        make([]*EntityChange, 0),
+		AddedCalls:     edgeDifference(after.calls, before.calls),
+		RemovedCalls:   edgeDifference(before.calls, after.calls),
+		AddedImports:   edgeDifference(after.imports, before.imports),
+		RemovedImports: edgeDifference(before.imports, after.imports),
+		NewCycles:      cycleDifference(after.cycles(), before.cycles()),
+		ResolvedCycles: cycleDifference(before.cycles(), after.cycles()),
+	}
+
+	for key, old := range before.entities {
+		current, exists := after.entities[key]
+		if !exists {
+			diff.Removed = append(diff.Removed, old)
+			continue
+		}
+		if old.Kind != core.NodeTypePackage && old.File != "" && current.File != "" && old.File != current.File {
+			diff.Moved = append(diff.Moved, &EntityChange{Before: old, After: current})
+		}
+		if old.Signature != current.Signature {
+			diff.Changed = append(diff.Changed, &EntityChange{Before: old, After: current})
+		}
+	}
+	for key, current := range after.entities {
+		if _, exists := before.entities[key]; !exists {
+			diff.Added = append(diff.Added, current)
+		}
+	}
+	diff.matchMoves()
+
+	sortEntities(diff.Added)
+	sortEntities(diff.Removed)
+	sortChanges(diff.Moved)
+	sortChanges(diff.Changed)
+	return diff
+}
+
+// matchMoves pairs removed and added entities that only changed package.
+// A pair is only reported when the match is unambiguous.
+func (d *GraphDiff) matchMoves() {
+	removed := make(map[string][]*DiffEntity)
+	for _, entity := range d.Removed {
+		removed[moveKey(entity)] = append(removed[moveKey(entity)], entity)
+	}
+	added := make(map[string][]*DiffEntity)
+	for _, entity := range d.Added {
+		added[moveKey(entity)] = append(added[moveKey(entity)], entity)
+	}
+
+	matched := make(map[*DiffEntity]bool)
+	for key, candidates := range removed {
+		if len(candidates) != 1 || len(added[key]) != 1 {
+			continue
+		}
+		before, after := candidates[0], added[key][0]
+		d.Moved = append(d.Moved, &EntityChange{Before: before, After: after})
+		matched[before] = true
+		matched[after] = true
+	}
+
+	d.Removed = filterEntities(d.Removed, matched)
+	d.Added = filterEntities(d.Added, matched)
+}
+
+// moveKey identifies an entity independently of its package
+func moveKey(entity *DiffEntity) string {
+	short := path.Base(entity.Name)
+	if entity.Kind != core.NodeTypePackage {
+		short = strings.TrimPrefix(entity.Name, entity.Package+".")
+	}
+	return string(entity.Kind) + " " + short + " " + entity.Signature
+}
+
+func filterEntities(entities []*DiffEntity, exclude map[*DiffEntity]bool) []*DiffEntity {
+	filtered := make([]*DiffEntity, 0, len(entities))
+	for _, entity := range entities {
+		if !exclude[entity] {
+			filtered = append(filtered, entity)
+		}
+	}
+	return filtered
+}
+
+// graphIndex holds the comparable parts of a project graph
+type graphIndex struct {
+	entities map[string]*DiffEntity // Keyed by kind and qualified name
+	calls    map[DiffEdge]bool
+	imports  map[DiffEdge]bool
+	packages map[string]bool
+}
+
+func indexGraph(result *core.AnalysisResult, root string) *graphIndex {
+	index := &graphIndex{
+		entities: make(map[string]*DiffEntity),
+		calls:    make(map[DiffEdge]bool),
+		imports:  make(map[DiffEdge]bool),
+		packages: make(map[string]bool),
+	}
+	if result == nil {
+		return index
+	}
+
+	nodes := make(map[core.ID]*core.Node, len(result.Nodes))
+	for i := range result.Nodes {
+		nodes[result.Nodes[i].ID] = &result.Nodes[i]
+	}
+
+	// Resolve the defining file of entities and the package of files
+	definedIn := make(map[core.ID]string)
+	filePackage := make(map[core.ID]string)
+	for _, rel := range result.Relationships {
+		from, to := nodes[rel.FromNodeID], nodes[rel.ToNodeID]
+		if from == nil || to == nil {
+			continue
+		}
+		switch {
+		case rel.Type == core.RelationDefines && from.Type == core.NodeTypeFile:
+			definedIn[to.ID] = relativePath(root, fmt.Sprint(from.Properties["path"]))
+		case rel.Type == core.RelationContains && from.Type == core.NodeTypePackage && to.Type == core.NodeTypeFile:
+			filePackage[to.ID] = packagePath(from)
+		}
+	}
+
+	names := make(map[core.ID]string)
+	for i := range result.Nodes {
+		entity := newDiffEntity(&result.Nodes[i])
+		if entity == nil {
+			continue
+		}
+		entity.File = definedIn[result.Nodes[i].ID]
+		names[result.Nodes[i].ID] = entity.Name
+		key := string(entity.Kind) + " " + entity.Name
+		if _, exists := index.entities[key]; !exists {
+			index.entities[key] = entity
+		}
+		if entity.Kind == core.NodeTypePackage {
+			index.packages[entity.Name] = true
+		}
+	}
+
+	for _, rel := range result.Relationships {
+		switch rel.Type {
+		case core.RelationCalls:
+			from, to := names[rel.FromNodeID], names[rel.ToNodeID]
+			if from != "" && to != "" {
+				index.calls[DiffEdge{From: from, To: to}] = true
+			}
+		case core.RelationImports:
+			pkg, imported := filePackage[rel.FromNodeID], nodes[rel.ToNodeID]
+			if pkg != "" && imported != nil {
+				index.imports[DiffEdge{From: pkg, To: imported.Name}] = true
+			}
+		}
+	}
+	return index
+}
+
+// newDiffEntity converts a node into a comparable entity, or returns nil for
+// node types that are not compared
+func newDiffEntity(node *core.Node) *DiffEntity {
+	pkg, _ := node.Properties["package"].(string)
+	switch node.Type {
+	case core.NodeTypePackage:
+		importPath := packagePath(node)
+		return &DiffEntity{Kind: node.Type, Name: importPath, Package: importPath}
+	case core.NodeTypeFunction:
+		signature, _ := node.Properties["signature"].(string)
+		return &DiffEntity{Kind: node.Type, Name: pkg + "." + node.Name, Package: pkg, Signature: signature}
+	case core.NodeTypeMethod:
+		signature, _ := node.Properties["signature"].(string)
+		receiver, _ := node.Properties["receiver"].(string)
+		return &DiffEntity{
+			Kind:      node.Type,
+			Name:      pkg + "." + receiver + "." + node.Name,
+			Package:   pkg,
+			Signature: signature,
+		}
+	case core.NodeTypeStruct, core.NodeTypeInterface:
+		return &DiffEntity{Kind: node.Type, Name: pkg + "." + node.Name, Package: pkg, Signature: typeShape(node)}
+	default:
+		return nil
+	}
+}
+
+func packagePath(node *core.Node) string {
+	if node.Path != "" {
+		return node.Path
+	}
+	if importPath, ok := node.Properties["import_path"].(string); ok && importPath != "" {
+		return importPath
+	}
+	return node.Name
+}
+
+// typeShape renders the fields of a struct or the methods of an interface so
+// that changes to either are reported as signature changes
+func typeShape(node *core.Node) string {
+	if node.Type == core.NodeTypeInterface {
+		var methods []string
+		for _, method := range propertyList(node.Properties["methods"]) {
+			methods = append(methods, fmt.Sprintf("%v%v", method["name"], strings.TrimPrefix(fmt.Sprint(method["signature"]), "func")))
+		}
+		if len(methods) == 0 {
+			return ""
+		}
+		sort.Strings(methods)
+		return "interface{ " + strings.Join(methods, "; ") + " }"
+	}
+
+	var fields []string
+	for _, field := range propertyList(node.Properties["fields"]) {
+		if anonymous, _ := field["anonymous"].(bool); anonymous {
+			fields = append(fields, fmt.Sprint(field["type"]))
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%v %v", field["name"], field["type"]))
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	return "struct{ " + strings.Join(fields, "; ") + " }"
+}
+
+// propertyList reads a list of maps that may have been serialized to JSON
+// when it was stored
+func propertyList(value any) []map[string]any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []map[string]any:
+		return v
+	case string:
+		var list []map[string]any
+		if err := json.Unmarshal([]byte(v), &list); err != nil {
+			return nil
+		}
+		return list
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var list []map[string]any
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil
+		}
+		return list
+	}
+}
+
+func relativePath(root, file string) string {
+	if root == "" {
+		return file
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+// cycles returns the import cycles between the project's packages as sorted
+// strongly connected components
+func (g *graphIndex) cycles() map[string]*DiffCycle {
+	adjacency := make(map[string][]string)
+	for edge := range g.imports {
+		if g.packages[edge.From] && g.packages[edge.To] && edge.From != edge.To {
+			adjacency[edge.From] = append(adjacency[edge.From], edge.To)
+		}
+	}
+	packages := make([]string, 0, len(g.packages))
+	for pkg := range g.packages {
+		packages = append(packages, pkg)
+		sort.Strings(adjacency[pkg])
+	}
+	sort.Strings(packages)
+
+	// Tarjan's algorithm
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	cycles := make(map[string]*DiffCycle)
+	var strongConnect func(pkg string)
+	strongConnect = func(pkg string) {
+		index[pkg] = len(index)
+		lowlink[pkg] = index[pkg]
+		stack = append(stack, pkg)
+		onStack[pkg] = true
+
+		for _, dep := range adjacency[pkg] {
+			if _, visited := index[dep]; !visited {
+				strongConnect(dep)
+				lowlink[pkg] = min(lowlink[pkg], lowlink[dep])
+			} else if onStack[dep] {
+				lowlink[pkg] = min(lowlink[pkg], index[dep])
+			}
+		}
+
+		if lowlink[pkg] != index[pkg] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == pkg {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Strings(component)
+			cycles[strings.Join(component, " ")] = &DiffCycle{Packages: component}
+		}
+	}
+	for _, pkg := range packages {
+		if _, visited := index[pkg]; !visited {
+			strongConnect(pkg)
+		}
+	}
+	return cycles
+}
+
+func edgeDifference(a, b map[DiffEdge]bool) []*DiffEdge {
+	edges := make([]*DiffEdge, 0)
+	for edge := range a {
+		if !b[edge] {
+			edges = append(edges, &DiffEdge{From: edge.From, To: edge.To})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+func cycleDifference(a, b map[string]*DiffCycle) []*DiffCycle {
+	keys := make([]string, 0)
+	for key := range a {
+		if _, exists := b[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	cycles := make([]*DiffCycle, 0, len(keys))
+	for _, key := range keys {
+		cycles = append(cycles, a[key])
+	}
+	return cycles
+}
+
+func sortEntities(entities []*DiffEntity) {
+	sort.Slice(entities, func(i, j int) bool {
+		if entities[i].Name != entities[j].Name {
+			return entities[i].Name < entities[j].Name
+		}
+		return entities[i].Kind < entities[j].Kind
+	})
+}
+
+func sortChanges(changes []*EntityChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Before.Name != changes[j].Before.Name {
+			return changes[i].Before.Name < changes[j].Before.Name
+		}
+		return changes[i].Before.Kind < changes[j].Before.Kind
+	})
+}
@@ -0,0 +1,202 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// markdownCollapseThreshold is the number of list items above which a
+// Markdown section is collapsed into a details block
+const markdownCollapseThreshold = 20
+
+// diffSection is a titled list of rendered diff lines
+type diffSection struct {
+	title string
+	lines []string
+	code  bool // Render the lines as a diff code block in Markdown
+}
+
+// sections renders the diff into titled sections, skipping empty ones
+func (d *GraphDiff) sections() []*diffSection {
+	var sections []*diffSection
+	add := func(section *diffSection) {
+		if len(section.lines) > 0 {
+			sections = append(sections, section)
+		}
+	}
+
+	add(&diffSection{title: "New cycles", lines: cycleLines(d.NewCycles)})
+	add(&diffSection{title: "Resolved cycles", lines: cycleLines(d.ResolvedCycles)})
+	add(&diffSection{title: "Added", lines: entityLines(d.Added)})
+	add(&diffSection{title: "Removed", lines: entityLines(d.Removed)})
+
+	moved := &diffSection{title: "Moved"}
+	for _, change := range d.Moved {
+		line := fmt.Sprintf("%s %s → %s", kindLabel(change.Before.Kind), change.Before.Name, change.After.Name)
+		if change.Before.Name == change.After.Name {
+			line = fmt.Sprintf("%s %s (%s → %s)",
+				kindLabel(change.Before.Kind), change.Before.Name, change.Before.File, change.After.File)
+		}
+		moved.lines = append(moved.lines, line)
+	}
+	add(moved)
+
+	changed := &diffSection{title: "Changed signatures", code: true}
+	for _, change := range d.Changed {
+		changed.lines = append(changed.lines,
+			fmt.Sprintf("  %s %s", kindLabel(change.Before.Kind), change.Before.Name),
+			"- "+change.Before.Signature,
+			"+ "+change.After.Signature)
+	}
+	add(changed)
+
+	add(&diffSection{title: "Added calls", lines: edgeLines(d.AddedCalls)})
+	add(&diffSection{title: "Removed calls", lines: edgeLines(d.RemovedCalls)})
+	add(&diffSection{title: "Added imports", lines: edgeLines(d.AddedImports)})
+	add(&diffSection{title: "Removed imports", lines: edgeLines(d.RemovedImports)})
+	return sections
+}
+
+// summary returns the one-line change counts of the diff
+func (d *GraphDiff) summary() string {
+	return fmt.Sprintf("%d added, %d removed, %d moved, %d changed, calls +%d/-%d, imports +%d/-%d, new cycles %d",
+		len(d.Added), len(d.Removed), len(d.Moved), len(d.Changed),
+		len(d.AddedCalls), len(d.RemovedCalls),
+		len(d.AddedImports), len(d.RemovedImports),
+		len(d.NewCycles))
+}
+
+// WriteText writes the diff as plain text
+func (d *GraphDiff) WriteText(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "Structural diff %s..%s\n", d.Base, d.Head)
+	if d.Empty() {
+		fmt.Fprintln(out, "No structural changes.")
+		return out.Flush()
+	}
+	fmt.Fprintln(out, d.summary())
+
+	for _, section := range d.sections() {
+		fmt.Fprintf(out, "\n%s (%d):\n", section.title, sectionCount(section))
+		for _, line := range section.lines {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+	return out.Flush()
+}
+
+// WriteMarkdown writes the diff as Markdown suitable for PR descriptions
+func (d *GraphDiff) WriteMarkdown(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "### Structural diff `%s`..`%s`\n\n", d.Base, d.Head)
+	if d.Empty() {
+		fmt.Fprintln(out, "No structural changes.")
+		return out.Flush()
+	}
+
+	fmt.Fprintln(out, "| Change | Count |")
+	fmt.Fprintln(out, "| --- | ---: |")
+	rows := []struct {
+		label string
+		count int
+	}{
+		{"Added", len(d.Added)},
+		{"Removed", len(d.Removed)},
+		{"Moved", len(d.Moved)},
+		{"Changed signatures", len(d.Changed)},
+		{"Calls added / removed", len(d.AddedCalls) + len(d.RemovedCalls)},
+		{"Imports added / removed", len(d.AddedImports) + len(d.RemovedImports)},
+		{"New cycles", len(d.NewCycles)},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(out, "| %s | %d |\n", row.label, row.count)
+	}
+
+	for _, section := range d.sections() {
+		count := sectionCount(section)
+		collapsed := count > markdownCollapseThreshold
+		if collapsed {
+			fmt.Fprintf(out, "\n<details>\n<summary>%s (%d)</summary>\n\n", section.title, count)
+		} else {
+			fmt.Fprintf(out, "\n#### %s (%d)\n\n", section.title, count)
+		}
+
+		if section.code {
+			fmt.Fprintln(out, "```diff")
+			for _, line := range section.lines {
+				fmt.Fprintln(out, line)
+			}
+			fmt.Fprintln(out, "```")
+		} else {
+			for _, line := range section.lines {
+				fmt.Fprintf(out, "- %s\n", markdownCode(line))
+			}
+		}
+
+		if collapsed {
+			fmt.Fprintln(out, "\n</details>")
+		}
+	}
+	return out.Flush()
+}
+
+func sectionCount(section *diffSection) int {
+	if section.code {
+		return len(section.lines) / 3
+	}
+	return len(section.lines)
+}
+
+// markdownCode wraps the identifiers of a rendered line in code spans
+func markdownCode(line string) string {
+	kind, rest, found := strings.Cut(line, " ")
+	if !found {
+		return "`" + line + "`"
+	}
+	switch kind {
+	case "package", "function", "method", "struct", "interface":
+		// Keep the trailing file location outside the code span
+		if name, location, found := strings.Cut(rest, " ("); found {
+			return kind + " `" + name + "` (" + location
+		}
+		return kind + " `" + rest + "`"
+	default:
+		return "`" + line + "`"
+	}
+}
+
+func kindLabel(kind core.NodeType) string {
+	return strings.ToLower(string(kind))
+}
+
+func entityLines(entities []*DiffEntity) []string {
+	lines := make([]string, 0, len(entities))
+	for _, entity := range entities {
+		line := kindLabel(entity.Kind) + " " + entity.Name
+		if entity.File != "" {
+			line += " (" + entity.File + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func edgeLines(edges []*DiffEdge) []string {
+	lines := make([]string, 0, len(edges))
+	for _, edge := range edges {
+		lines = append(lines, edge.From+" → "+edge.To)
+	}
+	return lines
+}
+
+func cycleLines(cycles []*DiffCycle) []string {
+	lines := make([]string, 0, len(cycles))
+	for _, cycle := range cycles {
+		lines = append(lines, strings.Join(cycle.Packages, " ↔ "))
+	}
+	return lines
+}
@@ -0,0 +1,194 @@
+package graph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphFixture builds analysis results the way the graph builder lays them out
+type graphFixture struct {
+	result   *core.AnalysisResult
+	packages map[string]core.ID
+	files    map[string]core.ID
+	entities map[string]core.ID
+}
+
+func newGraphFixture() *graphFixture {
+	return &graphFixture{
+		result:   &core.AnalysisResult{ProjectID: "proj"},
+		packages: make(map[string]core.ID),
+		files:    make(map[string]core.ID),
+		entities: make(map[string]core.ID),
+	}
+}
+
+func (f *graphFixture) node(nodeType core.NodeType, name string, props map[string]any) core.ID {
+	id := core.NewID()
+	f.result.Nodes = append(f.result.Nodes, core.Node{ID: id, Type: nodeType, Name: name, Properties: props})
+	return id
+}
+
+func (f *graphFixture) rel(relType core.RelationType, from, to core.ID) {
+	f.result.Relationships = append(f.result.Relationships, core.Relationship{
+		ID: core.NewID(), Type: relType, FromNodeID: from, ToNodeID: to,
+	})
+}
+
+func (f *graphFixture) file(pkg, path string) *graphFixture {
+	pkgID, exists := f.packages[pkg]
+	if !exists {
+		pkgID = core.NewID()
+		f.result.Nodes = append(f.result.Nodes, core.Node{ID: pkgID, Type: core.NodeTypePackage, Name: pkg, Path: pkg})
+		f.packages[pkg] = pkgID
+	}
+	fileID := f.node(core.NodeTypeFile, path, map[string]any{"path": "/repo/" + path})
+	f.files[path] = fileID
+	f.rel(core.RelationContains, pkgID, fileID)
+	return f
+}
+
+func (f *graphFixture) function(file, pkg, name, signature string) *graphFixture {
+	id := f.node(core.NodeTypeFunction, name, map[string]any{"package": pkg, "signature": signature})
+	f.entities[pkg+"."+name] = id
+	f.rel(core.RelationDefines, f.files[file], id)
+	return f
+}
+
+func (f *graphFixture) structType(file, pkg, name string, fields string) *graphFixture {
+	id := f.node(core.NodeTypeStruct, name, map[string]any{"package": pkg, "fields": fields})
+	f.entities[pkg+"."+name] = id
+	f.rel(core.RelationDefines, f.files[file], id)
+	return f
+}
+
+func (f *graphFixture) imports(file, path string) *graphFixture {
+	id := f.node(core.NodeTypeImport, path, nil)
+	f.rel(core.RelationImports, f.files[file], id)
+	return f
+}
+
+func (f *graphFixture) calls(from, to string) *graphFixture {
+	f.rel(core.RelationCalls, f.entities[from], f.entities[to])
+	return f
+}
+
+func TestDiff(t *testing.T) {
+	base := newGraphFixture().
+		file("app/a", "a/a.go").
+		file("app/b", "b/b.go").
+		function("a/a.go", "app/a", "Run", "func(x int) int").
+		function("a/a.go", "app/a", "Old", "func()").
+		function("a/a.go", "app/a", "Gone", "func()").
+		function("b/b.go", "app/b", "Helper", "func(x int) int").
+		structType("a/a.go", "app/a", "Service", `[{"name":"Name","type":"string"}]`).
+		imports("a/a.go", "app/b").
+		calls("app/a.Run", "app/b.Helper").
+		result
+	head := newGraphFixture().
+		file("app/a", "a/a.go").
+		file("app/a", "a/run.go").
+		file("app/b", "b/b.go").
+		file("app/c", "c/c.go").
+		function("a/run.go", "app/a", "Run", "func(x int, y string) int").
+		function("c/c.go", "app/c", "Old", "func()").
+		function("a/a.go", "app/a", "New", "func() int").
+		function("b/b.go", "app/b", "Helper", "func(x int) int").
+		structType("a/a.go", "app/a", "Service", `[{"name":"Name","type":"string"},{"name":"Age","type":"int"}]`).
+		imports("a/run.go", "app/b").
+		imports("b/b.go", "app/a").
+		calls("app/a.Run", "app/a.New").
+		result
+
+	diff := graph.Diff(base, head, &graph.DiffOptions{BaseRoot: "/repo", HeadRoot: "/repo"})
+
+	t.Run("Should report added and removed entities", func(t *testing.T) {
+		require.Len(t, diff.Added, 2)
+		assert.Equal(t, "app/a.New", diff.Added[0].Name)
+		assert.Equal(t, "a/a.go", diff.Added[0].File)
+		assert.Equal(t, "app/c", diff.Added[1].Name)
+		require.Len(t, diff.Removed, 1)
+		assert.Equal(t, "app/a.Gone", diff.Removed[0].Name)
+	})
+
+	t.Run("Should report entities that moved file or package", func(t *testing.T) {
+		require.Len(t, diff.Moved, 2)
+		assert.Equal(t, "app/a.Old", diff.Moved[0].Before.Name)
+		assert.Equal(t, "app/c.Old", diff.Moved[0].After.Name)
+		assert.Equal(t, "app/a.Run", diff.Moved[1].Before.Name)
+		assert.Equal(t, "a/a.go", diff.Moved[1].Before.File)
+		assert.Equal(t, "a/run.go", diff.Moved[1].After.File)
+	})
+
+	t.Run("Should report changed signatures and struct fields", func(t *testing.T) {
+		require.Len(t, diff.Changed, 2)
+		assert.Equal(t, "app/a.Run", diff.Changed[0].Before.Name)
+		assert.Equal(t, "func(x int, y string) int", diff.Changed[0].After.Signature)
+		assert.Equal(t, "app/a.Service", diff.Changed[1].Before.Name)
+		assert.Equal(t, "struct{ Name string; Age int }", diff.Changed[1].After.Signature)
+	})
+
+	t.Run("Should report call and import edge changes", func(t *testing.T) {
+		assert.Equal(t, []*graph.DiffEdge{{From: "app/a.Run", To: "app/a.New"}}, diff.AddedCalls)
+		assert.Equal(t, []*graph.DiffEdge{{From: "app/a.Run", To: "app/b.Helper"}}, diff.RemovedCalls)
+		assert.Equal(t, []*graph.DiffEdge{{From: "app/b", To: "app/a"}}, diff.AddedImports)
+		assert.Empty(t, diff.RemovedImports)
+	})
+
+	t.Run("Should report new import cycles", func(t *testing.T) {
+		require.Len(t, diff.NewCycles, 1)
+		assert.Equal(t, []string{"app/a", "app/b"}, diff.NewCycles[0].Packages)
+		assert.Empty(t, diff.ResolvedCycles)
+	})
+
+	t.Run("Should report no changes for identical graphs", func(t *testing.T) {
+		assert.True(t, graph.Diff(base, base, nil).Empty())
+	})
+}
+
+func TestGraphDiff_Write(t *testing.T) {
+	base := newGraphFixture().
+		file("app/a", "a/a.go").
+		function("a/a.go", "app/a", "Run", "func()").
+		result
+	head := newGraphFixture().
+		file("app/a", "a/a.go").
+		function("a/a.go", "app/a", "Run", "func() error").
+		function("a/a.go", "app/a", "New", "func()").
+		result
+	diff := graph.Diff(base, head, &graph.DiffOptions{BaseRoot: "/repo", HeadRoot: "/repo"})
+	diff.Base, diff.Head = "main", "HEAD"
+
+	t.Run("Should write text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, diff.WriteText(&buf))
+
+		assert.Contains(t, buf.String(), "Structural diff main..HEAD")
+		assert.Contains(t, buf.String(), "function app/a.New (a/a.go)")
+		assert.Contains(t, buf.String(), "+ func() error")
+	})
+
+	t.Run("Should write Markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, diff.WriteMarkdown(&buf))
+
+		assert.Contains(t, buf.String(), "| Added | 1 |")
+		assert.Contains(t, buf.String(), "- function `app/a.New` (a/a.go)")
+		assert.Contains(t, buf.String(), "```diff\n  function app/a.Run\n- func()\n+ func() error\n```")
+	})
+
+	t.Run("Should encode JSON", func(t *testing.T) {
+		data, err := json.Marshal(diff)
+		require.NoError(t, err)
+
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, "main", decoded["base"])
+		assert.Len(t, decoded["changed"], 1)
+	})
+}
@@ -0,0 +1,205 @@
+package graph
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/core"
+)
+
+// SubgraphOptions selects the part of a project graph to export. Filters are
+// applied first; the focus walk then only follows edges between kept nodes.
+type SubgraphOptions struct {
+	Packages  []string        // Go-style package patterns, e.g. "engine/..." (empty keeps every package)
+	NodeTypes []core.NodeType // Node types to keep (empty keeps every type)
+	Focus     string          // Name or qualified name of the symbols to center the subgraph on
+	Depth     int             // Edges walked from the focus symbols in either direction, 0 for no limit
+}
+
+// Subgraph returns the nodes of a project graph selected by the options and
+// the relationships between them, sorted by ID for stable output. The input
+// graph is not modified.
+func Subgraph(result *core.AnalysisResult, opts *SubgraphOptions) (*core.AnalysisResult, error) {
+	if opts == nil {
+		opts = &SubgraphOptions{}
+	}
+	if opts.Depth < 0 {
+		return nil, fmt.Errorf("depth must not be negative")
+	}
+	kept, err := selectNodes(result, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Focus != "" {
+		focused, err := focusNodes(result, kept, opts.Focus, opts.Depth)
+		if err != nil {
+			return nil, err
+		}
+		kept = focused
+	}
+
+	subgraph := &core.AnalysisResult{
+		ProjectID:  result.ProjectID,
+		AnalyzedAt: result.AnalyzedAt,
+	}
+	for i := range result.Nodes {
+		if kept[result.Nodes[i].ID] {
+			subgraph.Nodes = append(subgraph.Nodes, result.Nodes[i])
+		}
+	}
+	for i := range result.Relationships {
+		rel := &result.Relationships[i]
+		if kept[rel.FromNodeID] && kept[rel.ToNodeID] {
+			subgraph.Relationships = append(subgraph.Relationships, *rel)
+		}
+	}
+	sort.Slice(subgraph.Nodes, func(i, j int) bool {
+		return subgraph.Nodes[i].ID < subgraph.Nodes[j].ID
+	})
+	sort.Slice(subgraph.Relationships, func(i, j int) bool {
+		return subgraph.Relationships[i].ID < subgraph.Relationships[j].ID
+	})
+	return subgraph, nil
+}
+
+// selectNodes returns the nodes passing the type and package filters
+func selectNodes(result *core.AnalysisResult, opts *SubgraphOptions) (map[core.ID]bool, error) {
+	patterns := make([]*regexp.Regexp, 0, len(opts.Packages))
+	for _, pattern := range opts.Packages {
+		re, err := analyzer.CompilePackagePattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid package pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+	types := make(map[core.NodeType]bool, len(opts.NodeTypes))
+	for _, nodeType := range opts.NodeTypes {
+		types[nodeType] = true
+	}
+
+	packages := nodePackages(result)
+	kept := make(map[core.ID]bool, len(result.Nodes))
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		if len(types) > 0 && !types[node.Type] {
+			continue
+		}
+		if len(patterns) > 0 && !matchesAny(patterns, packages[node.ID]) {
+			continue
+		}
+		kept[node.ID] = true
+	}
+	return kept, nil
+}
+
+// nodePackages maps every node to the import path of its package. Files and
+// imports carry no import path of their own and take the package of the node
+// containing or importing them.
+func nodePackages(result *core.AnalysisResult) map[core.ID]string {
+	packages := make(map[core.ID]string, len(result.Nodes))
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		switch node.Type {
+		case core.NodeTypePackage:
+			packages[node.ID] = node.Path
+			if path, ok := node.Properties["import_path"].(string); ok && path != "" {
+				packages[node.ID] = path
+			}
+		case core.NodeTypeFile, core.NodeTypeImport:
+			// Resolved from their parents below
+		default:
+			if pkg, ok := node.Properties["package"].(string); ok {
+				packages[node.ID] = pkg
+			}
+		}
+	}
+	// Files first, then the imports of those files
+	for _, relType := range []core.RelationType{core.RelationContains, core.RelationImports} {
+		for i := range result.Relationships {
+			rel := &result.Relationships[i]
+			if rel.Type != relType || packages[rel.ToNodeID] != "" {
+				continue
+			}
+			if pkg := packages[rel.FromNodeID]; pkg != "" {
+				packages[rel.ToNodeID] = pkg
+			}
+		}
+	}
+	return packages
+}
+
+func matchesAny(patterns []*regexp.Regexp, pkg string) bool {
+	for _, re := range patterns {
+		if re.MatchString(pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// focusNodes returns the kept nodes within depth edges of the nodes named by
+// focus, walking relationships in either direction
+func focusNodes(
+	result *core.AnalysisResult,
+	kept map[core.ID]bool,
+	focus string,
+	depth int,
+) (map[core.ID]bool, error) {
+	distances := make(map[core.ID]int)
+	var queue []core.ID
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		if kept[node.ID] && matchesFocus(node, focus) {
+			distances[node.ID] = 0
+			queue = append(queue, node.ID)
+		}
+	}
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("no node named %q in the selected graph", focus)
+	}
+
+	neighbors := make(map[core.ID][]core.ID)
+	for i := range result.Relationships {
+		rel := &result.Relationships[i]
+		if kept[rel.FromNodeID] && kept[rel.ToNodeID] {
+			neighbors[rel.FromNodeID] = append(neighbors[rel.FromNodeID], rel.ToNodeID)
+			neighbors[rel.ToNodeID] = append(neighbors[rel.ToNodeID], rel.FromNodeID)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if depth > 0 && distances[id] >= depth {
+			continue
+		}
+		for _, next := range neighbors[id] {
+			if _, seen := distances[next]; !seen {
+				distances[next] = distances[id] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	focused := make(map[core.ID]bool, len(distances))
+	for id := range distances {
+		focused[id] = true
+	}
+	return focused, nil
+}
+
+// matchesFocus reports whether a node is named by focus, either by its plain
+// name, its qualified name or a suffix of the qualified name such as
+// Service.Save or user.Service.Save
+func matchesFocus(node *core.Node, focus string) bool {
+	if node.Name == focus || node.Path == focus {
+		return true
+	}
+	if node.Type == core.NodeTypeFile || node.Type == core.NodeTypeImport || node.Type == core.NodeTypePackage {
+		return false
+	}
+	qualified := qualifiedName(node)
+	return qualified == focus || strings.HasSuffix(qualified, "."+focus) || strings.HasSuffix(qualified, "/"+focus)
+}
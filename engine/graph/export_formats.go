@@ -0,0 +1,371 @@
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// GraphFormat is a file format for whole graphs, as opposed to query rows
+type GraphFormat string
+
+const (
+	GraphFormatGraphML GraphFormat = "graphml"
+	GraphFormatGEXF    GraphFormat = "gexf"
+	GraphFormatDOT     GraphFormat = "dot"
+	GraphFormatMermaid GraphFormat = "mermaid"
+	GraphFormatJGF     GraphFormat = "jgf"
+)
+
+// GraphFormats lists the supported graph formats
+var GraphFormats = []GraphFormat{
+	GraphFormatGraphML, GraphFormatGEXF, GraphFormatDOT, GraphFormatMermaid, GraphFormatJGF,
+}
+
+// Attribute names for the node type and the relationship type, following the
+// GraphML export of Neo4j
+const (
+	attrNodeLabels = "labels"
+	attrEdgeLabel  = "label"
+)
+
+// WriteGraph writes the nodes and relationships of a graph in the given
+// format. Node types, names and properties are kept as attributes in every
+// format except Mermaid, which only carries names and types.
+func WriteGraph(w io.Writer, format GraphFormat, result *core.AnalysisResult) error {
+	switch format {
+	case GraphFormatGraphML:
+		return writeGraphML(w, result)
+	case GraphFormatGEXF:
+		return writeGEXF(w, result)
+	case GraphFormatDOT:
+		return writeDOT(w, result)
+	case GraphFormatMermaid:
+		return writeMermaid(w, result)
+	case GraphFormatJGF:
+		return writeJGF(w, result)
+	default:
+		return fmt.Errorf("unsupported graph format %q", format)
+	}
+}
+
+// nodeAttributes returns the properties of a node together with its name and
+// path. The ID is left out since every format stores it as the element ID.
+func nodeAttributes(node *core.Node) map[string]any {
+	attrs := relationshipAttributes(node.Properties)
+	attrs["name"] = node.Name
+	if node.Path != "" {
+		attrs["path"] = node.Path
+	} else if path, ok := attrs["path"].(string); ok && path == "" {
+		delete(attrs, "path") // Stored for every node, even without a path
+	}
+	return attrs
+}
+
+func relationshipAttributes(props map[string]any) map[string]any {
+	attrs := make(map[string]any, len(props)+2)
+	for key, value := range props {
+		if key != "id" && value != nil {
+			attrs[key] = value
+		}
+	}
+	return attrs
+}
+
+// attributeSchema declares the attributes of a set of elements with the type
+// shared by all their values, falling back to string when the types differ
+type attributeSchema struct {
+	names []string
+	types map[string]string
+}
+
+func newAttributeSchema(items []map[string]any) *attributeSchema {
+	schema := &attributeSchema{types: make(map[string]string)}
+	for _, attrs := range items {
+		for name, value := range attrs {
+			valueType := attributeType(value)
+			current, exists := schema.types[name]
+			switch {
+			case !exists:
+				schema.names = append(schema.names, name)
+				schema.types[name] = valueType
+			case current != valueType:
+				schema.types[name] = "string"
+			}
+		}
+	}
+	sort.Strings(schema.names)
+	return schema
+}
+
+// attributeType returns the GraphML and GEXF type name of a value
+func attributeType(value any) string {
+	switch value.(type) {
+	case bool:
+		return "boolean"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "long"
+	case float32, float64:
+		return "double"
+	default:
+		return "string"
+	}
+}
+
+// attributeString formats a value for text-based formats. Lists and maps are
+// written as JSON.
+func attributeString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32:
+		return fmt.Sprint(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// GraphML
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string           `xml:"id,attr"`
+	EdgeDefault string           `xml:"edgedefault,attr"`
+	Nodes       []graphMLElement `xml:"node"`
+	Edges       []graphMLElement `xml:"edge"`
+}
+
+type graphMLElement struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr,omitempty"`
+	Target string        `xml:"target,attr,omitempty"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func writeGraphML(w io.Writer, result *core.AnalysisResult) error {
+	nodeAttrs, edgeAttrs := elementAttributes(result)
+	nodeSchema := newAttributeSchema(nodeAttrs)
+	edgeSchema := newAttributeSchema(edgeAttrs)
+
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Graph: graphMLGraph{ID: result.ProjectID.String(), EdgeDefault: "directed"},
+	}
+	doc.Keys = append(graphMLKeys("node", attrNodeLabels, nodeSchema), graphMLKeys("edge", attrEdgeLabel, edgeSchema)...)
+
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		element := graphMLElement{
+			ID:   node.ID.String(),
+			Data: []graphMLData{{Key: "n_" + attrNodeLabels, Value: string(node.Type)}},
+		}
+		element.Data = append(element.Data, graphMLValues("n_", nodeSchema, nodeAttrs[i])...)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, element)
+	}
+	for i := range result.Relationships {
+		rel := &result.Relationships[i]
+		element := graphMLElement{
+			ID:     rel.ID.String(),
+			Source: rel.FromNodeID.String(),
+			Target: rel.ToNodeID.String(),
+			Data:   []graphMLData{{Key: "e_" + attrEdgeLabel, Value: string(rel.Type)}},
+		}
+		element.Data = append(element.Data, graphMLValues("e_", edgeSchema, edgeAttrs[i])...)
+		doc.Graph.Edges = append(doc.Graph.Edges, element)
+	}
+	return writeXML(w, doc)
+}
+
+// graphMLKeys declares the attributes of a domain, with the element type
+// first. Key IDs are prefixed with the domain since node and edge attributes
+// may share a name.
+func graphMLKeys(domain, typeAttribute string, schema *attributeSchema) []graphMLKey {
+	prefix := domain[:1] + "_"
+	keys := []graphMLKey{{ID: prefix + typeAttribute, For: domain, Name: typeAttribute, Type: "string"}}
+	for _, name := range schema.names {
+		keys = append(keys, graphMLKey{ID: prefix + name, For: domain, Name: name, Type: schema.types[name]})
+	}
+	return keys
+}
+
+func graphMLValues(prefix string, schema *attributeSchema, attrs map[string]any) []graphMLData {
+	var data []graphMLData
+	for _, name := range schema.names {
+		if value, exists := attrs[name]; exists {
+			data = append(data, graphMLData{Key: prefix + name, Value: attributeString(value)})
+		}
+	}
+	return data
+}
+
+// elementAttributes returns the attributes of every node and relationship,
+// in the order of the graph
+func elementAttributes(result *core.AnalysisResult) (nodes, edges []map[string]any) {
+	nodes = make([]map[string]any, len(result.Nodes))
+	for i := range result.Nodes {
+		nodes[i] = nodeAttributes(&result.Nodes[i])
+	}
+	edges = make([]map[string]any, len(result.Relationships))
+	for i := range result.Relationships {
+		edges[i] = relationshipAttributes(result.Relationships[i].Properties)
+	}
+	return nodes, edges
+}
+
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode graph: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// GEXF
+
+type gexfDocument struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Creator string    `xml:"meta>creator"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfElement    `xml:"nodes>node"`
+	Edges           []gexfElement    `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfElement struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr,omitempty"`
+	Target    string         `xml:"target,attr,omitempty"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func writeGEXF(w io.Writer, result *core.AnalysisResult) error {
+	nodeAttrs, edgeAttrs := elementAttributes(result)
+	nodeSchema := newAttributeSchema(nodeAttrs)
+	edgeSchema := newAttributeSchema(edgeAttrs)
+
+	doc := gexfDocument{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Creator: "gograph",
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Mode:            "static",
+			Attributes: []gexfAttributes{
+				gexfDeclarations("node", attrNodeLabels, nodeSchema),
+				gexfDeclarations("edge", attrEdgeLabel, edgeSchema),
+			},
+		},
+	}
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		element := gexfElement{
+			ID:        node.ID.String(),
+			Label:     node.Name,
+			AttValues: []gexfAttValue{{For: attrNodeLabels, Value: string(node.Type)}},
+		}
+		element.AttValues = append(element.AttValues, gexfValues(nodeSchema, nodeAttrs[i])...)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, element)
+	}
+	for i := range result.Relationships {
+		rel := &result.Relationships[i]
+		element := gexfElement{
+			ID:        rel.ID.String(),
+			Source:    rel.FromNodeID.String(),
+			Target:    rel.ToNodeID.String(),
+			Label:     string(rel.Type),
+			AttValues: []gexfAttValue{{For: attrEdgeLabel, Value: string(rel.Type)}},
+		}
+		element.AttValues = append(element.AttValues, gexfValues(edgeSchema, edgeAttrs[i])...)
+		doc.Graph.Edges = append(doc.Graph.Edges, element)
+	}
+	return writeXML(w, doc)
+}
+
+// gexfDeclarations declares the attributes of a class, using their names as
+// IDs, with the element type first
+func gexfDeclarations(class, typeAttribute string, schema *attributeSchema) gexfAttributes {
+	declarations := gexfAttributes{
+		Class:      class,
+		Attributes: []gexfAttribute{{ID: typeAttribute, Title: typeAttribute, Type: "string"}},
+	}
+	for _, name := range schema.names {
+		declarations.Attributes = append(declarations.Attributes,
+			gexfAttribute{ID: name, Title: name, Type: schema.types[name]})
+	}
+	return declarations
+}
+
+func gexfValues(schema *attributeSchema, attrs map[string]any) []gexfAttValue {
+	var values []gexfAttValue
+	for _, name := range schema.names {
+		if value, exists := attrs[name]; exists {
+			values = append(values, gexfAttValue{For: name, Value: attributeString(value)})
+		}
+	}
+	return values
+}
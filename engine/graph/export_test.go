@@ -0,0 +1,185 @@
+package graph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportFixture() *graphFixture {
+	fixture := newGraphFixture().
+		file("app/store", "store/store.go").
+		file("app/service", "service/service.go").
+		file("app/cmd", "cmd/main.go").
+		function("store/store.go", "app/store", "Save", "func() error").
+		function("service/service.go", "app/service", "Create", "func() error").
+		function("cmd/main.go", "app/cmd", "main", "func()").
+		imports("cmd/main.go", "app/service").
+		calls("app/service.Create", "app/store.Save").
+		calls("app/cmd.main", "app/service.Create")
+	fixture.method("store/store.go", "app/store", "Record", "Save", "func() error")
+	return fixture
+}
+
+func nodeNames(result *core.AnalysisResult) []string {
+	names := make([]string, 0, len(result.Nodes))
+	for i := range result.Nodes {
+		names = append(names, result.Nodes[i].Name)
+	}
+	return names
+}
+
+func TestSubgraph(t *testing.T) {
+	result := exportFixture().result
+
+	t.Run("Should keep the whole graph without options", func(t *testing.T) {
+		subgraph, err := graph.Subgraph(result, nil)
+		require.NoError(t, err)
+		assert.Len(t, subgraph.Nodes, len(result.Nodes))
+		assert.Len(t, subgraph.Relationships, len(result.Relationships))
+	})
+
+	t.Run("Should filter by package pattern and node type", func(t *testing.T) {
+		subgraph, err := graph.Subgraph(result, &graph.SubgraphOptions{
+			Packages:  []string{"app/service", "*/cmd"},
+			NodeTypes: []core.NodeType{core.NodeTypeFunction, core.NodeTypeImport},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Create", "main", "app/service"}, nodeNames(subgraph))
+		require.Len(t, subgraph.Relationships, 1)
+		assert.Equal(t, core.RelationCalls, subgraph.Relationships[0].Type)
+	})
+
+	t.Run("Should keep the neighborhood of the focus symbols", func(t *testing.T) {
+		subgraph, err := graph.Subgraph(result, &graph.SubgraphOptions{
+			NodeTypes: []core.NodeType{core.NodeTypeFunction},
+			Focus:     "service.Create",
+			Depth:     1,
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Create", "Save", "main"}, nodeNames(subgraph))
+
+		subgraph, err = graph.Subgraph(result, &graph.SubgraphOptions{Focus: "Record.Save", Depth: 1})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Save", "store/store.go"}, nodeNames(subgraph))
+	})
+
+	t.Run("Should reject unknown focus symbols and invalid patterns", func(t *testing.T) {
+		_, err := graph.Subgraph(result, &graph.SubgraphOptions{Focus: "Missing"})
+		assert.ErrorContains(t, err, `no node named "Missing"`)
+		_, err = graph.Subgraph(result, &graph.SubgraphOptions{Packages: []string{" "}})
+		assert.ErrorContains(t, err, "invalid package pattern")
+	})
+}
+
+func TestWriteGraph(t *testing.T) {
+	result, err := graph.Subgraph(exportFixture().result, &graph.SubgraphOptions{
+		NodeTypes: []core.NodeType{core.NodeTypeFunction},
+		Focus:     "main",
+		Depth:     1,
+	})
+	require.NoError(t, err)
+	for i := range result.Nodes {
+		if result.Nodes[i].Name == "main" {
+			result.Nodes[i].Properties["line_start"] = int64(3)
+		}
+	}
+
+	t.Run("Should write GraphML with typed attribute keys", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, graph.WriteGraph(&buf, graph.GraphFormatGraphML, result))
+
+		var doc struct {
+			Keys []struct {
+				ID   string `xml:"id,attr"`
+				Type string `xml:"attr.type,attr"`
+			} `xml:"key"`
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"graph>node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+			} `xml:"graph>edge"`
+		}
+		require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+		assert.Len(t, doc.Nodes, 2)
+		assert.Len(t, doc.Edges, 1)
+		assert.Contains(t, doc.Keys, struct {
+			ID   string `xml:"id,attr"`
+			Type string `xml:"attr.type,attr"`
+		}{ID: "n_line_start", Type: "long"})
+		assert.Contains(t, buf.String(), `<data key="e_label">CALLS</data>`)
+		assert.Contains(t, buf.String(), `<data key="n_labels">Function</data>`)
+	})
+
+	t.Run("Should write GEXF with node labels and attributes", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, graph.WriteGraph(&buf, graph.GraphFormatGEXF, result))
+
+		var doc struct {
+			Nodes []struct {
+				Label string `xml:"label,attr"`
+			} `xml:"graph>nodes>node"`
+			Edges []struct {
+				Label string `xml:"label,attr"`
+			} `xml:"graph>edges>edge"`
+		}
+		require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+		require.Len(t, doc.Nodes, 2)
+		require.Len(t, doc.Edges, 1)
+		assert.Equal(t, "CALLS", doc.Edges[0].Label)
+		assert.Contains(t, buf.String(), `<attvalue for="signature" value="func() error"></attvalue>`)
+	})
+
+	t.Run("Should write DOT and Mermaid", func(t *testing.T) {
+		var dot bytes.Buffer
+		require.NoError(t, graph.WriteGraph(&dot, graph.GraphFormatDOT, result))
+		assert.Contains(t, dot.String(), `digraph "proj" {`)
+		assert.Contains(t, dot.String(), `[label="main", "labels"="Function", "line_start"="3", "name"="main", "package"="app/cmd"`)
+		assert.Contains(t, dot.String(), `[label="CALLS"];`)
+
+		var mermaid bytes.Buffer
+		require.NoError(t, graph.WriteGraph(&mermaid, graph.GraphFormatMermaid, result))
+		assert.Contains(t, mermaid.String(), "flowchart LR\n")
+		assert.Regexp(t, `n\d -->\|CALLS\| n\d`, mermaid.String())
+		assert.Contains(t, mermaid.String(), `["main"]:::Function`)
+		assert.Contains(t, mermaid.String(), "classDef Function fill:")
+	})
+
+	t.Run("Should write the JSON Graph Format", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, graph.WriteGraph(&buf, graph.GraphFormatJGF, result))
+
+		var doc struct {
+			Graph struct {
+				Directed bool `json:"directed"`
+				Nodes    map[string]struct {
+					Label    string         `json:"label"`
+					Metadata map[string]any `json:"metadata"`
+				} `json:"nodes"`
+				Edges []struct {
+					Relation string `json:"relation"`
+				} `json:"edges"`
+			} `json:"graph"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.True(t, doc.Graph.Directed)
+		assert.Len(t, doc.Graph.Nodes, 2)
+		require.Len(t, doc.Graph.Edges, 1)
+		assert.Equal(t, "CALLS", doc.Graph.Edges[0].Relation)
+		for _, node := range doc.Graph.Nodes {
+			assert.Equal(t, "Function", node.Metadata["type"])
+		}
+	})
+
+	t.Run("Should reject unknown formats", func(t *testing.T) {
+		err := graph.WriteGraph(&bytes.Buffer{}, "svg", result)
+		assert.ErrorContains(t, err, `unsupported graph format "svg"`)
+	})
+}
@@ -0,0 +1,175 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// DOT
+
+func writeDOT(w io.Writer, result *core.AnalysisResult) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "digraph %s {\n", dotID(result.ProjectID.String()))
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		attrs := nodeAttributes(node)
+		attrs[attrNodeLabels] = string(node.Type)
+		attrs["label"] = node.Name
+		fmt.Fprintf(out, "  %s [%s];\n", dotID(node.ID.String()), dotAttributes(attrs))
+	}
+	for i := range result.Relationships {
+		rel := &result.Relationships[i]
+		attrs := relationshipAttributes(rel.Properties)
+		attrs[attrEdgeLabel] = string(rel.Type)
+		fmt.Fprintf(out, "  %s -> %s [%s];\n",
+			dotID(rel.FromNodeID.String()), dotID(rel.ToNodeID.String()), dotAttributes(attrs))
+	}
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+// dotAttributes formats an attribute list with the label first and the
+// remaining attributes sorted by name
+func dotAttributes(attrs map[string]any) string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		if name != "label" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	parts := []string{"label=" + dotID(attributeString(attrs["label"]))}
+	for _, name := range names {
+		parts = append(parts, dotID(name)+"="+dotID(attributeString(attrs[name])))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// dotID quotes a string as a DOT identifier
+func dotID(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// Mermaid
+
+// mermaidStyles are the fill colors of the node types in Mermaid flowcharts
+var mermaidStyles = map[core.NodeType]string{
+	core.NodeTypePackage:   "#e3f2fd",
+	core.NodeTypeFile:      "#f5f5f5",
+	core.NodeTypeFunction:  "#e8f5e9",
+	core.NodeTypeMethod:    "#f1f8e9",
+	core.NodeTypeStruct:    "#fff3e0",
+	core.NodeTypeInterface: "#fce4ec",
+	core.NodeTypeImport:    "#ede7f6",
+	core.NodeTypeConstant:  "#fffde7",
+	core.NodeTypeVariable:  "#fffde7",
+}
+
+// writeMermaid writes a flowchart with one class per node type. Mermaid node
+// IDs must be plain words, so nodes are numbered in the order of the graph.
+func writeMermaid(w io.Writer, result *core.AnalysisResult) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "flowchart LR")
+	ids := make(map[core.ID]string, len(result.Nodes))
+	types := make(map[core.NodeType]bool)
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+		types[node.Type] = true
+		fmt.Fprintf(out, "  %s[\"%s\"]:::%s\n", ids[node.ID], mermaidText(node.Name), node.Type)
+	}
+	for i := range result.Relationships {
+		rel := &result.Relationships[i]
+		fmt.Fprintf(out, "  %s -->|%s| %s\n", ids[rel.FromNodeID], rel.Type, ids[rel.ToNodeID])
+	}
+	for _, nodeType := range sortedNodeTypes(types) {
+		if fill, exists := mermaidStyles[nodeType]; exists {
+			fmt.Fprintf(out, "  classDef %s fill:%s,stroke:#555\n", nodeType, fill)
+		}
+	}
+	return out.Flush()
+}
+
+// mermaidText escapes the characters Mermaid interprets inside quoted labels
+func mermaidText(value string) string {
+	replacer := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", " ")
+	return replacer.Replace(value)
+}
+
+func sortedNodeTypes(types map[core.NodeType]bool) []core.NodeType {
+	sorted := make([]core.NodeType, 0, len(types))
+	for nodeType := range types {
+		sorted = append(sorted, nodeType)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// JSON Graph Format
+
+type jgfDocument struct {
+	Graph jgfGraph `json:"graph"`
+}
+
+type jgfGraph struct {
+	ID       string             `json:"id"`
+	Type     string             `json:"type"`
+	Label    string             `json:"label"`
+	Directed bool               `json:"directed"`
+	Nodes    map[string]jgfNode `json:"nodes"`
+	Edges    []jgfEdge          `json:"edges"`
+}
+
+type jgfNode struct {
+	Label    string         `json:"label"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+type jgfEdge struct {
+	ID       string         `json:"id"`
+	Source   string         `json:"source"`
+	Target   string         `json:"target"`
+	Relation string         `json:"relation"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+// writeJGF writes a JSON Graph Format v2 document. Node metadata holds the
+// node type and properties; edge metadata holds the relationship properties.
+func writeJGF(w io.Writer, result *core.AnalysisResult) error {
+	doc := jgfDocument{Graph: jgfGraph{
+		ID:       result.ProjectID.String(),
+		Type:     "gograph",
+		Label:    result.ProjectID.String(),
+		Directed: true,
+		Nodes:    make(map[string]jgfNode, len(result.Nodes)),
+		Edges:    make([]jgfEdge, 0, len(result.Relationships)),
+	}}
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		doc.Graph.Nodes[node.ID.String()] = jgfNode{
+			Label:    node.Name,
+			Metadata: map[string]any{"type": node.Type, "properties": nodeAttributes(node)},
+		}
+	}
+	for i := range result.Relationships {
+		rel := &result.Relationships[i]
+		doc.Graph.Edges = append(doc.Graph.Edges, jgfEdge{
+			ID:       rel.ID.String(),
+			Source:   rel.FromNodeID.String(),
+			Target:   rel.ToNodeID.String(),
+			Relation: string(rel.Type),
+			Metadata: map[string]any{"properties": relationshipAttributes(rel.Properties)},
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
@@ -0,0 +1,271 @@
+package graph
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/pkg/git"
+)
+
+// impactRelations are the edges along which a change propagates, from the
+// dependent node to the node it depends on. Walking them in reverse finds
+// everything that may be affected by a change:
+//   - CALLS: callers of a changed function
+//   - IMPLEMENTS: types implementing a changed interface
+//   - REFERENCES and EMBEDS: entities using or embedding a changed type
+//   - BELONGS_TO: methods of a changed type
+var impactRelations = map[core.RelationType]string{
+	core.RelationCalls:      "calls",
+	core.RelationImplements: "implements",
+	core.RelationReferences: "references",
+	core.RelationEmbeds:     "embeds",
+	core.RelationBelongsTo:  "is a method of",
+}
+
+// ImpactOptions configures change impact analysis
+type ImpactOptions struct {
+	MaxDepth int    // Maximum number of edges walked from a changed entity, 0 for no limit
+	Root     string // Project root stripped from file paths
+}
+
+// ImpactedEntity is a function, method or type reached by a change
+type ImpactedEntity struct {
+	Kind     core.NodeType     `json:"kind"`
+	Name     string            `json:"name"` // Qualified name, e.g. example.com/app/user.Service.Save
+	Package  string            `json:"package"`
+	File     string            `json:"file,omitempty"`
+	Line     int               `json:"line,omitempty"`
+	Distance int               `json:"distance"`      // Number of edges from the nearest changed entity
+	Via      core.RelationType `json:"via,omitempty"` // Edge through which the entity is affected
+	From     string            `json:"from,omitempty"`
+}
+
+// ImpactedPackage is a package containing changed or affected entities
+type ImpactedPackage struct {
+	Path     string `json:"path"`
+	Distance int    `json:"distance"`
+}
+
+// ImpactReport is the blast radius of a set of changed lines
+type ImpactReport struct {
+	Changed        []*ImpactedEntity  `json:"changed"`
+	Affected       []*ImpactedEntity  `json:"affected"`
+	Packages       []*ImpactedPackage `json:"packages"`
+	Entrypoints    []*ImpactedEntity  `json:"entrypoints"`
+	UnmatchedFiles []string           `json:"unmatched_files,omitempty"`
+}
+
+// impactEdge is a reversed dependency edge
+type impactEdge struct {
+	from    core.ID
+	relType core.RelationType
+}
+
+// Impact maps changed line ranges onto the functions, methods and types of
+// a graph using their line_start and line_end properties, then walks reverse
+// dependency edges to rank every transitively affected entity by distance.
+// Entrypoints are reached functions and methods that nothing in the graph
+// calls, such as main, tests and exported API.
+func Impact(result *core.AnalysisResult, changes []git.LineRange, opts *ImpactOptions) *ImpactReport {
+	if opts == nil {
+		opts = &ImpactOptions{}
+	}
+	nodes := make(map[core.ID]*core.Node, len(result.Nodes))
+	filePaths := make(map[core.ID]string)
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		nodes[node.ID] = node
+		if node.Type == core.NodeTypeFile {
+			path, _ := node.Properties["path"].(string)
+			filePaths[node.ID] = filepath.ToSlash(path)
+		}
+	}
+
+	defined := make(map[core.ID][]core.ID)
+	entityFiles := make(map[core.ID]string)
+	incoming := make(map[core.ID][]impactEdge)
+	callers := make(map[core.ID]int)
+	for _, rel := range result.Relationships {
+		switch {
+		case rel.Type == core.RelationDefines:
+			if path, isFile := filePaths[rel.FromNodeID]; isFile {
+				defined[rel.FromNodeID] = append(defined[rel.FromNodeID], rel.ToNodeID)
+				entityFiles[rel.ToNodeID] = path
+			}
+		case impactRelations[rel.Type] != "":
+			incoming[rel.ToNodeID] = append(incoming[rel.ToNodeID], impactEdge{from: rel.FromNodeID, relType: rel.Type})
+		}
+		if rel.Type == core.RelationCalls && rel.FromNodeID != rel.ToNodeID {
+			callers[rel.ToNodeID]++
+		}
+	}
+
+	// Link methods to their receiver types by name as well, since the stored
+	// BELONGS_TO edges only exist when the receiver type could be resolved
+	types := make(map[string]core.ID)
+	for i := range result.Nodes {
+		if node := &result.Nodes[i]; node.Type == core.NodeTypeStruct {
+			types[qualifiedName(node)] = node.ID
+		}
+	}
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		if node.Type != core.NodeTypeMethod {
+			continue
+		}
+		pkg, _ := node.Properties["package"].(string)
+		receiver, _ := node.Properties["receiver"].(string)
+		if typeID, exists := types[pkg+"."+analyzer.ReceiverTypeName(receiver)]; exists {
+			incoming[typeID] = append(incoming[typeID], impactEdge{from: node.ID, relType: core.RelationBelongsTo})
+		}
+	}
+
+	entity := func(id core.ID, distance int) *ImpactedEntity {
+		node := nodes[id]
+		pkg, _ := node.Properties["package"].(string)
+		return &ImpactedEntity{
+			Kind:     node.Type,
+			Name:     qualifiedName(node),
+			Package:  pkg,
+			File:     relativePath(opts.Root, entityFiles[id]),
+			Line:     intValue(node.Properties["line_start"]),
+			Distance: distance,
+		}
+	}
+
+	report := &ImpactReport{
+		Changed:     []*ImpactedEntity{},
+		Affected:    []*ImpactedEntity{},
+		Packages:    []*ImpactedPackage{},
+		Entrypoints: []*ImpactedEntity{},
+	}
+	reached := make(map[core.ID]*ImpactedEntity)
+	var queue []core.ID
+	unmatched := make(map[string]bool)
+
+	for _, change := range changes {
+		changedPath := filepath.ToSlash(change.Path)
+		matched := false
+		for fileID, path := range filePaths {
+			if path != changedPath && !strings.HasSuffix(path, "/"+changedPath) {
+				continue
+			}
+			matched = true
+			for _, id := range defined[fileID] {
+				node := nodes[id]
+				if node == nil || reached[id] != nil || !isImpactEntity(node.Type) {
+					continue
+				}
+				start, end := intValue(node.Properties["line_start"]), intValue(node.Properties["line_end"])
+				if start == 0 || start > change.End || end < change.Start {
+					continue
+				}
+				reached[id] = entity(id, 0)
+				report.Changed = append(report.Changed, reached[id])
+				queue = append(queue, id)
+			}
+		}
+		if !matched && strings.HasSuffix(changedPath, ".go") {
+			unmatched[changedPath] = true
+		}
+	}
+
+	// Walk from the changed entities in name order so that ties in distance
+	// are attributed deterministically
+	sort.Slice(queue, func(i, j int) bool { return reached[queue[i]].Name < reached[queue[j]].Name })
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		current := reached[id]
+		if opts.MaxDepth > 0 && current.Distance >= opts.MaxDepth {
+			continue
+		}
+		for _, edge := range incoming[id] {
+			if reached[edge.from] != nil || nodes[edge.from] == nil || !isImpactEntity(nodes[edge.from].Type) {
+				continue
+			}
+			affected := entity(edge.from, current.Distance+1)
+			affected.Via = edge.relType
+			affected.From = current.Name
+			reached[edge.from] = affected
+			report.Affected = append(report.Affected, affected)
+			queue = append(queue, edge.from)
+		}
+	}
+
+	packages := make(map[string]int)
+	for id, reachedEntity := range reached {
+		if distance, exists := packages[reachedEntity.Package]; !exists || reachedEntity.Distance < distance {
+			packages[reachedEntity.Package] = reachedEntity.Distance
+		}
+		kind := reachedEntity.Kind
+		if (kind == core.NodeTypeFunction || kind == core.NodeTypeMethod) && callers[id] == 0 {
+			report.Entrypoints = append(report.Entrypoints, reachedEntity)
+		}
+	}
+	for pkg, distance := range packages {
+		report.Packages = append(report.Packages, &ImpactedPackage{Path: pkg, Distance: distance})
+	}
+	for path := range unmatched {
+		report.UnmatchedFiles = append(report.UnmatchedFiles, path)
+	}
+
+	sortImpactedEntities(report.Changed)
+	sortImpactedEntities(report.Affected)
+	sortImpactedEntities(report.Entrypoints)
+	sort.Slice(report.Packages, func(i, j int) bool {
+		a, b := report.Packages[i], report.Packages[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		return a.Path < b.Path
+	})
+	sort.Strings(report.UnmatchedFiles)
+	return report
+}
+
+func isImpactEntity(nodeType core.NodeType) bool {
+	switch nodeType {
+	case core.NodeTypeFunction, core.NodeTypeMethod, core.NodeTypeStruct, core.NodeTypeInterface:
+		return true
+	default:
+		return false
+	}
+}
+
+// qualifiedName returns the package-qualified name of a function, method or
+// type, naming methods after their bare receiver type
+func qualifiedName(node *core.Node) string {
+	pkg, _ := node.Properties["package"].(string)
+	if node.Type == core.NodeTypeMethod {
+		receiver, _ := node.Properties["receiver"].(string)
+		return pkg + "." + analyzer.ReceiverTypeName(receiver) + "." + node.Name
+	}
+	return pkg + "." + node.Name
+}
+
+func sortImpactedEntities(entities []*ImpactedEntity) {
+	sort.Slice(entities, func(i, j int) bool {
+		if entities[i].Distance != entities[j].Distance {
+			return entities[i].Distance < entities[j].Distance
+		}
+		return entities[i].Name < entities[j].Name
+	})
+}
+
+// intValue converts a numeric property, which Neo4j returns as int64, to int
+func intValue(value any) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// impactedLine renders an entity with its location
+func impactedLine(entity *ImpactedEntity) string {
+	line := kindLabel(entity.Kind) + " " + entity.Name
+	switch {
+	case entity.File != "" && entity.Line > 0:
+		line += fmt.Sprintf(" (%s:%d)", entity.File, entity.Line)
+	case entity.File != "":
+		line += " (" + entity.File + ")"
+	}
+	return line
+}
+
+// WriteText writes the report as plain text ranked by distance
+func (r *ImpactReport) WriteText(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	if len(r.Changed) == 0 {
+		fmt.Fprintln(out, "No analyzed functions or types overlap the changed lines.")
+	} else {
+		fmt.Fprintf(out, "%d changed, %d affected, %d package(s), %d entrypoint(s)\n",
+			len(r.Changed), len(r.Affected), len(r.Packages), len(r.Entrypoints))
+
+		fmt.Fprintf(out, "\nChanged (%d):\n", len(r.Changed))
+		for _, entity := range r.Changed {
+			fmt.Fprintf(out, "  %s\n", impactedLine(entity))
+		}
+	}
+
+	if len(r.Affected) > 0 {
+		fmt.Fprintf(out, "\nAffected (%d):\n", len(r.Affected))
+		for _, entity := range r.Affected {
+			fmt.Fprintf(out, "  %3d  %s %s %s\n", entity.Distance, impactedLine(entity), impactRelations[entity.Via], entity.From)
+		}
+	}
+	if len(r.Changed) > 0 {
+		fmt.Fprintf(out, "\nPackages (%d):\n", len(r.Packages))
+		for _, pkg := range r.Packages {
+			fmt.Fprintf(out, "  %3d  %s\n", pkg.Distance, pkg.Path)
+		}
+	}
+	if len(r.Entrypoints) > 0 {
+		fmt.Fprintf(out, "\nEntrypoints (%d):\n", len(r.Entrypoints))
+		for _, entity := range r.Entrypoints {
+			fmt.Fprintf(out, "  %3d  %s\n", entity.Distance, impactedLine(entity))
+		}
+	}
+	if len(r.UnmatchedFiles) > 0 {
+		fmt.Fprintf(out, "\nChanged Go files missing from the graph (re-run 'gograph analyze'):\n")
+		for _, path := range r.UnmatchedFiles {
+			fmt.Fprintf(out, "  %s\n", path)
+		}
+	}
+	return out.Flush()
+}
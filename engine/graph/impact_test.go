@@ -0,0 +1,104 @@
+package graph_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lines sets the line range of an entity added with function or structType
+func (f *graphFixture) lines(key string, start, end int) *graphFixture {
+	for i := range f.result.Nodes {
+		if f.result.Nodes[i].ID == f.entities[key] {
+			f.result.Nodes[i].Properties["line_start"] = start
+			f.result.Nodes[i].Properties["line_end"] = int64(end) // As returned by Neo4j
+		}
+	}
+	return f
+}
+
+func TestImpact(t *testing.T) {
+	fixture := newGraphFixture().
+		file("app/store", "store/store.go").
+		file("app/service", "service/service.go").
+		file("app/cmd", "cmd/main.go").
+		function("store/store.go", "app/store", "Save", "func() error").lines("app/store.Save", 10, 20).
+		function("store/store.go", "app/store", "Load", "func() error").lines("app/store.Load", 22, 30).
+		structType("store/store.go", "app/store", "Record", "").lines("app/store.Record", 1, 5).
+		function("service/service.go", "app/service", "Create", "func() error").lines("app/service.Create", 5, 15).
+		function("cmd/main.go", "app/cmd", "main", "func()").lines("app/cmd.main", 3, 8).
+		function("cmd/main.go", "app/cmd", "unrelated", "func()").lines("app/cmd.unrelated", 10, 12).
+		calls("app/service.Create", "app/store.Save").
+		calls("app/cmd.main", "app/service.Create").
+		calls("app/cmd.unrelated", "app/store.Load")
+	fixture.method("store/store.go", "app/store", "Record", "Validate", "func() error")
+	result := fixture.result
+
+	t.Run("Should rank transitively affected entities by distance", func(t *testing.T) {
+		report := graph.Impact(result, []git.LineRange{{Path: "store/store.go", Start: 12, End: 13}},
+			&graph.ImpactOptions{Root: "/repo"})
+
+		require.Len(t, report.Changed, 1)
+		assert.Equal(t, "app/store.Save", report.Changed[0].Name)
+		assert.Equal(t, "store/store.go", report.Changed[0].File)
+		assert.Equal(t, 10, report.Changed[0].Line)
+
+		require.Len(t, report.Affected, 2)
+		assert.Equal(t, &graph.ImpactedEntity{
+			Kind: core.NodeTypeFunction, Name: "app/service.Create", Package: "app/service",
+			File: "service/service.go", Line: 5, Distance: 1, Via: core.RelationCalls, From: "app/store.Save",
+		}, report.Affected[0])
+		assert.Equal(t, "app/cmd.main", report.Affected[1].Name)
+		assert.Equal(t, 2, report.Affected[1].Distance)
+
+		assert.Equal(t, []*graph.ImpactedPackage{
+			{Path: "app/store", Distance: 0}, {Path: "app/service", Distance: 1}, {Path: "app/cmd", Distance: 2},
+		}, report.Packages)
+		require.Len(t, report.Entrypoints, 1)
+		assert.Equal(t, "app/cmd.main", report.Entrypoints[0].Name)
+	})
+
+	t.Run("Should stop at the maximum depth", func(t *testing.T) {
+		report := graph.Impact(result, []git.LineRange{{Path: "store/store.go", Start: 12, End: 12}},
+			&graph.ImpactOptions{MaxDepth: 1})
+
+		require.Len(t, report.Affected, 1)
+		assert.Equal(t, "app/service.Create", report.Affected[0].Name)
+	})
+
+	t.Run("Should reach the methods of a changed type", func(t *testing.T) {
+		report := graph.Impact(result, []git.LineRange{{Path: "store/store.go", Start: 2, End: 2}}, nil)
+
+		require.Len(t, report.Affected, 1)
+		assert.Equal(t, "app/store.Record.Validate", report.Affected[0].Name)
+		assert.Equal(t, core.RelationBelongsTo, report.Affected[0].Via)
+	})
+
+	t.Run("Should report changed Go files missing from the graph", func(t *testing.T) {
+		report := graph.Impact(result, []git.LineRange{
+			{Path: "store/new.go", Start: 1, End: 3},
+			{Path: "README.md", Start: 1, End: 1},
+			{Path: "store/store.go", Start: 6, End: 9},
+		}, nil)
+
+		assert.Empty(t, report.Changed)
+		assert.Equal(t, []string{"store/new.go"}, report.UnmatchedFiles)
+	})
+
+	t.Run("Should write text", func(t *testing.T) {
+		report := graph.Impact(result, []git.LineRange{{Path: "store/store.go", Start: 12, End: 13}},
+			&graph.ImpactOptions{Root: "/repo"})
+		var buf bytes.Buffer
+		require.NoError(t, report.WriteText(&buf))
+
+		assert.Contains(t, buf.String(), "1 changed, 2 affected, 3 package(s), 1 entrypoint(s)")
+		assert.Contains(t, buf.String(),
+			"    1  function app/service.Create (service/service.go:5) calls app/store.Save\n")
+		assert.Contains(t, buf.String(), "Entrypoints (1):\n    2  function app/cmd.main (cmd/main.go:3)\n")
+	})
+}
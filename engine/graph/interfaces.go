@@ -28,12 +28,19 @@ type Repository interface {
 
 	// Query operations
 	ExecuteQuery(ctx context.Context, query string, params map[string]any) ([]map[string]any, error)
+	// StreamQuery runs a query and passes its rows to fn as they are read,
+	// without collecting them. fn must not use the repository.
+	StreamQuery(ctx context.Context, query string, params map[string]any, fn RowFunc) error
 
 	// Bulk operations
 	ImportAnalysisResult(ctx context.Context, result *core.AnalysisResult) error
 	StoreAnalysis(ctx context.Context, result *core.AnalysisResult) error
 	ClearProject(ctx context.Context, projectID core.ID) error
 
+	// Snapshot operations
+	ListSnapshots(ctx context.Context, projectID core.ID) ([]*core.Snapshot, error)
+	DeleteSnapshot(ctx context.Context, projectID, snapshotID core.ID) error
+
 	// Search operations
 	FindNodesByType(ctx context.Context, nodeType core.NodeType, projectID core.ID) ([]core.Node, error)
 	FindNodesByName(ctx context.Context, name string, projectID core.ID) ([]core.Node, error)
@@ -0,0 +1,75 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// QueryExecutor runs Cypher queries against the graph store
+type QueryExecutor interface {
+	ExecuteQuery(ctx context.Context, query string, params map[string]any) ([]map[string]any, error)
+}
+
+// LoadAnalysisResult reads all nodes and relationships stored under a project
+// ID back into an analysis result
+func LoadAnalysisResult(ctx context.Context, repo QueryExecutor, projectID core.ID) (*core.AnalysisResult, error) {
+	params := map[string]any{"project_id": projectID.String()}
+
+	nodeRows, err := repo.ExecuteQuery(ctx, `
+		MATCH (n {project_id: $project_id})
+		RETURN n.id AS id, labels(n)[0] AS type, n.name AS name, n.path AS path, properties(n) AS props
+	`, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load nodes: %w", err)
+	}
+	relRows, err := repo.ExecuteQuery(ctx, `
+		MATCH (a {project_id: $project_id})-[r]->(b {project_id: $project_id})
+		RETURN r.id AS id, type(r) AS type, a.id AS from_id, b.id AS to_id, properties(r) AS props
+	`, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load relationships: %w", err)
+	}
+
+	result := &core.AnalysisResult{
+		ProjectID:     projectID,
+		Nodes:         make([]core.Node, 0, len(nodeRows)),
+		Relationships: make([]core.Relationship, 0, len(relRows)),
+	}
+	for _, row := range nodeRows {
+		node := core.Node{
+			ID:         core.ID(stringValue(row["id"])),
+			Type:       core.NodeType(stringValue(row["type"])),
+			Name:       stringValue(row["name"]),
+			Path:       stringValue(row["path"]),
+			Properties: propertiesValue(row["props"]),
+		}
+		result.Nodes = append(result.Nodes, node)
+	}
+	for _, row := range relRows {
+		rel := core.Relationship{
+			ID:         core.ID(stringValue(row["id"])),
+			Type:       core.RelationType(stringValue(row["type"])),
+			FromNodeID: core.ID(stringValue(row["from_id"])),
+			ToNodeID:   core.ID(stringValue(row["to_id"])),
+			Properties: propertiesValue(row["props"]),
+		}
+		result.Relationships = append(result.Relationships, rel)
+	}
+	return result, nil
+}
+
+func stringValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func propertiesValue(value any) map[string]any {
+	if props, ok := value.(map[string]any); ok {
+		return props
+	}
+	return map[string]any{}
+}
@@ -0,0 +1,345 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// volatileProperties change on every build without changing the code
+var volatileProperties = map[string]bool{"analyzed_at": true, "created_at": true, "snapshot_id": true}
+
+// GraphPatch is the set of writes that brings a stored graph in line with a
+// rebuilt part of it
+type GraphPatch struct {
+	CreateNodes         []core.Node
+	UpdateNodes         []core.Node
+	DeleteNodes         []core.ID
+	CreateRelationships []core.Relationship
+	DeleteRelationships []core.ID
+}
+
+// Empty reports whether the patch has no writes
+func (p *GraphPatch) Empty() bool {
+	return len(p.CreateNodes) == 0 && len(p.UpdateNodes) == 0 && len(p.DeleteNodes) == 0 &&
+		len(p.CreateRelationships) == 0 && len(p.DeleteRelationships) == 0
+}
+
+// PatchDirectories compares the packages stored for a set of directories with
+// a graph rebuilt from them. A package belongs to a directory when one of its
+// files does; its files, the entities they define and their imports belong
+// to it as well.
+//
+// Nodes are matched by kind and qualified name, so matched nodes keep their
+// stored IDs and relationships from the rest of the graph stay attached.
+// Relationships between nodes of the directories are replaced by the rebuilt
+// ones, relationships to nodes outside them are kept until the next full
+// analysis. Packages whose directories no longer contain Go files are removed.
+func PatchDirectories(stored, rebuilt *core.AnalysisResult, dirs []string) *GraphPatch {
+	dirSet := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		dirSet[filepath.Clean(dir)] = true
+	}
+	patch := &GraphPatch{}
+	snapshotID := storedSnapshotID(stored)
+	ids, matched := patch.reconcileNodes(stored, rebuilt, dirSet, snapshotID)
+	patch.reconcileRelationships(stored, rebuilt, ids, matched, snapshotID)
+	return patch
+}
+
+// reconcileNodes records the node writes and returns the stored ID of every
+// rebuilt node along with the stored nodes that are kept
+func (p *GraphPatch) reconcileNodes(
+	stored, rebuilt *core.AnalysisResult,
+	dirs map[string]bool,
+	snapshotID string,
+) (ids map[core.ID]core.ID, matched map[core.ID]bool) {
+	storedKeys := ownedNodes(stored, dirs)
+	rebuiltKeys := ownedNodes(rebuilt, dirs)
+	storedByKey := make(map[string]*core.Node, len(storedKeys))
+	for i := range stored.Nodes {
+		if key, ok := storedKeys[stored.Nodes[i].ID]; ok {
+			storedByKey[key] = &stored.Nodes[i]
+		}
+	}
+
+	ids = make(map[core.ID]core.ID, len(rebuiltKeys))
+	matched = make(map[core.ID]bool, len(rebuiltKeys))
+	for i := range rebuilt.Nodes {
+		node := rebuilt.Nodes[i]
+		key, ok := rebuiltKeys[node.ID]
+		if !ok {
+			continue
+		}
+		node.Properties = withSnapshot(node.Properties, snapshotID)
+		previous, ok := storedByKey[key]
+		if !ok {
+			ids[node.ID] = node.ID
+			p.CreateNodes = append(p.CreateNodes, node)
+			continue
+		}
+		ids[node.ID] = previous.ID
+		matched[previous.ID] = true
+		if node.Name != previous.Name || node.Path != previous.Path ||
+			propertiesKey(node.Properties) != propertiesKey(previous.Properties) {
+			node.ID = previous.ID
+			p.UpdateNodes = append(p.UpdateNodes, node)
+		}
+	}
+	for id := range storedKeys {
+		if !matched[id] {
+			p.DeleteNodes = append(p.DeleteNodes, id)
+		}
+	}
+	sort.Slice(p.DeleteNodes, func(i, j int) bool { return p.DeleteNodes[i] < p.DeleteNodes[j] })
+	return ids, matched
+}
+
+// reconcileRelationships records the relationship writes between kept and
+// rebuilt nodes. Relationships of deleted nodes go with them.
+func (p *GraphPatch) reconcileRelationships(
+	stored, rebuilt *core.AnalysisResult,
+	ids map[core.ID]core.ID,
+	matched map[core.ID]bool,
+	snapshotID string,
+) {
+	storedRels := make(map[string][]core.ID)
+	for _, rel := range stored.Relationships {
+		if matched[rel.FromNodeID] && matched[rel.ToNodeID] {
+			key := relationshipKey(rel.Type, rel.FromNodeID, rel.ToNodeID, rel.Properties)
+			storedRels[key] = append(storedRels[key], rel.ID)
+		}
+	}
+	for _, rel := range rebuilt.Relationships {
+		from, fromOK := ids[rel.FromNodeID]
+		to, toOK := ids[rel.ToNodeID]
+		if !fromOK || !toOK {
+			continue
+		}
+		key := relationshipKey(rel.Type, from, to, rel.Properties)
+		if existing := storedRels[key]; len(existing) > 0 {
+			storedRels[key] = existing[1:]
+			continue
+		}
+		rel.FromNodeID, rel.ToNodeID = from, to
+		rel.Properties = withSnapshot(rel.Properties, snapshotID)
+		p.CreateRelationships = append(p.CreateRelationships, rel)
+	}
+	for _, remaining := range storedRels {
+		p.DeleteRelationships = append(p.DeleteRelationships, remaining...)
+	}
+	sort.Slice(p.DeleteRelationships, func(i, j int) bool {
+		return p.DeleteRelationships[i] < p.DeleteRelationships[j]
+	})
+}
+
+// storedSnapshotID returns the snapshot the stored graph is tagged with
+func storedSnapshotID(stored *core.AnalysisResult) string {
+	for _, node := range stored.Nodes {
+		if id, ok := node.Properties["snapshot_id"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// ApplyTo applies the patch to an in-memory graph
+func (p *GraphPatch) ApplyTo(result *core.AnalysisResult) {
+	deletedNodes := make(map[core.ID]bool, len(p.DeleteNodes))
+	for _, id := range p.DeleteNodes {
+		deletedNodes[id] = true
+	}
+	updated := make(map[core.ID]core.Node, len(p.UpdateNodes))
+	for _, node := range p.UpdateNodes {
+		updated[node.ID] = node
+	}
+	nodes := result.Nodes[:0]
+	for _, node := range result.Nodes {
+		if deletedNodes[node.ID] {
+			continue
+		}
+		if replacement, ok := updated[node.ID]; ok {
+			node = replacement
+		}
+		nodes = append(nodes, node)
+	}
+	result.Nodes = append(nodes, p.CreateNodes...)
+
+	deletedRels := make(map[core.ID]bool, len(p.DeleteRelationships))
+	for _, id := range p.DeleteRelationships {
+		deletedRels[id] = true
+	}
+	rels := result.Relationships[:0]
+	for _, rel := range result.Relationships {
+		if !deletedRels[rel.ID] && !deletedNodes[rel.FromNodeID] && !deletedNodes[rel.ToNodeID] {
+			rels = append(rels, rel)
+		}
+	}
+	result.Relationships = append(rels, p.CreateRelationships...)
+}
+
+// ApplyPatch writes a patch to the graph store
+func ApplyPatch(ctx context.Context, repo Repository, patch *GraphPatch) error {
+	for _, id := range patch.DeleteNodes {
+		if err := repo.DeleteNode(ctx, id); err != nil {
+			return err
+		}
+	}
+	for _, id := range patch.DeleteRelationships {
+		if err := repo.DeleteRelationship(ctx, id); err != nil {
+			return err
+		}
+	}
+	if err := repo.CreateNodes(ctx, patch.CreateNodes); err != nil {
+		return err
+	}
+	for i := range patch.UpdateNodes {
+		if err := repo.UpdateNode(ctx, &patch.UpdateNodes[i]); err != nil {
+			return err
+		}
+	}
+	return repo.CreateRelationships(ctx, patch.CreateRelationships)
+}
+
+// ownedNode is a node belonging to a patched package with its match key and
+// the position that orders nodes sharing a key
+type ownedNode struct {
+	id    core.ID
+	key   string
+	order string
+}
+
+// ownedNodes returns the key of every node belonging to the packages of the
+// given directories. Keys are unique within a graph: entities sharing a name,
+// such as init functions, are numbered in file and line order.
+func ownedNodes(result *core.AnalysisResult, dirs map[string]bool) map[core.ID]string {
+	nodes := make(map[core.ID]*core.Node, len(result.Nodes))
+	for i := range result.Nodes {
+		nodes[result.Nodes[i].ID] = &result.Nodes[i]
+	}
+	children := make(map[core.ID][]*core.Node)
+	for _, rel := range result.Relationships {
+		switch rel.Type {
+		case core.RelationContains, core.RelationDefines, core.RelationImports:
+			if child := nodes[rel.ToNodeID]; child != nil {
+				children[rel.FromNodeID] = append(children[rel.FromNodeID], child)
+			}
+		}
+	}
+
+	var owned []ownedNode
+	for i := range result.Nodes {
+		pkg := &result.Nodes[i]
+		if pkg.Type == core.NodeTypePackage && packageInDirs(children[pkg.ID], dirs) {
+			owned = append(owned, packageNodes(pkg, children)...)
+		}
+	}
+
+	sort.SliceStable(owned, func(i, j int) bool {
+		if owned[i].key != owned[j].key {
+			return owned[i].key < owned[j].key
+		}
+		return owned[i].order < owned[j].order
+	})
+	keys := make(map[core.ID]string, len(owned))
+	seen := make(map[string]int, len(owned))
+	for _, node := range owned {
+		if _, ok := keys[node.id]; ok {
+			continue
+		}
+		key := node.key
+		if n := seen[node.key]; n > 0 {
+			key = fmt.Sprintf("%s#%d", key, n)
+		}
+		seen[node.key]++
+		keys[node.id] = key
+	}
+	return keys
+}
+
+// packageInDirs reports whether one of the files of a package is in dirs
+func packageInDirs(contained []*core.Node, dirs map[string]bool) bool {
+	for _, file := range contained {
+		if file.Type == core.NodeTypeFile && dirs[filepath.Dir(stringValue(file.Properties["path"]))] {
+			return true
+		}
+	}
+	return false
+}
+
+// packageNodes returns a package with its files, their imports and the
+// entities they define
+func packageNodes(pkg *core.Node, children map[core.ID][]*core.Node) []ownedNode {
+	owned := []ownedNode{{id: pkg.ID, key: "Package:" + pkg.Path}}
+	for _, file := range children[pkg.ID] {
+		if file.Type != core.NodeTypeFile {
+			continue
+		}
+		path := stringValue(file.Properties["path"])
+		owned = append(owned, ownedNode{id: file.ID, key: "File:" + path})
+		for _, child := range children[file.ID] {
+			key := fmt.Sprintf("%s:%s.%v.%v.%s", child.Type, pkg.Path,
+				child.Properties["receiver"], child.Properties["receiver_type"], child.Name)
+			if child.Type == core.NodeTypeImport {
+				key = fmt.Sprintf("%s:%s:%s:%v", child.Type, path, child.Name, child.Properties["name"])
+			}
+			order := fmt.Sprintf("%s:%09d", path, intValue(child.Properties["line_start"]))
+			owned = append(owned, ownedNode{id: child.ID, key: key, order: order})
+		}
+	}
+	return owned
+}
+
+// relationshipKey identifies a relationship by its endpoints and content
+func relationshipKey(relType core.RelationType, from, to core.ID, props map[string]any) string {
+	return fmt.Sprintf("%s|%s|%s|%s", relType, from, to, propertiesKey(props))
+}
+
+// propertiesKey renders properties comparably, whether they were built in
+// memory or read back from the store, where nested values are JSON strings
+// and numbers are 64-bit
+func propertiesKey(props map[string]any) string {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		if !volatileProperties[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(propertyKey(props[name]))
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+func propertyKey(value any) string {
+	if s, ok := value.(string); ok && (strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{")) && json.Valid([]byte(s)) {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// withSnapshot copies properties, tagging them with the stored snapshot
+func withSnapshot(props map[string]any, snapshotID string) map[string]any {
+	copied := make(map[string]any, len(props)+1)
+	for name, value := range props {
+		copied[name] = value
+	}
+	if snapshotID != "" {
+		copied["snapshot_id"] = snapshotID
+	}
+	return copied
+}
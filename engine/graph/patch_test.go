@@ -0,0 +1,111 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchDirectories(t *testing.T) {
+	stored := func() *graphFixture {
+		return newGraphFixture().
+			file("app/a", "a/a.go").
+			file("app/b", "b/b.go").
+			function("a/a.go", "app/a", "Run", "func(x int) int").
+			function("a/a.go", "app/a", "Gone", "func()").
+			function("b/b.go", "app/b", "Helper", "func(x int) int").
+			imports("a/a.go", "fmt").
+			calls("app/a.Run", "app/a.Gone").
+			calls("app/b.Helper", "app/a.Run")
+	}
+
+	t.Run("Should produce an empty patch when nothing changed", func(t *testing.T) {
+		rebuilt := newGraphFixture().
+			file("app/a", "a/a.go").
+			function("a/a.go", "app/a", "Run", "func(x int) int").
+			function("a/a.go", "app/a", "Gone", "func()").
+			imports("a/a.go", "fmt").
+			calls("app/a.Run", "app/a.Gone")
+
+		patch := graph.PatchDirectories(stored().result, rebuilt.result, []string{"/repo/a"})
+
+		assert.True(t, patch.Empty())
+	})
+
+	t.Run("Should update matched nodes in place and keep edges from other packages", func(t *testing.T) {
+		base := stored()
+		rebuilt := newGraphFixture().
+			file("app/a", "a/a.go").
+			function("a/a.go", "app/a", "Run", "func(x, y int) int").
+			function("a/a.go", "app/a", "Added", "func()").
+			imports("a/a.go", "fmt").
+			calls("app/a.Run", "app/a.Added")
+
+		patch := graph.PatchDirectories(base.result, rebuilt.result, []string{"/repo/a"})
+
+		require.Len(t, patch.UpdateNodes, 1)
+		assert.Equal(t, base.entities["app/a.Run"], patch.UpdateNodes[0].ID)
+		assert.Equal(t, "func(x, y int) int", patch.UpdateNodes[0].Properties["signature"])
+		require.Len(t, patch.CreateNodes, 1)
+		assert.Equal(t, "Added", patch.CreateNodes[0].Name)
+		assert.Equal(t, []core.ID{base.entities["app/a.Gone"]}, patch.DeleteNodes)
+
+		require.Len(t, patch.CreateRelationships, 2)
+		for _, rel := range patch.CreateRelationships {
+			assert.Equal(t, patch.CreateNodes[0].ID, rel.ToNodeID)
+		}
+		assert.ElementsMatch(t, []core.ID{base.files["a/a.go"], base.entities["app/a.Run"]},
+			[]core.ID{patch.CreateRelationships[0].FromNodeID, patch.CreateRelationships[1].FromNodeID})
+		assert.Empty(t, patch.DeleteRelationships)
+
+		patch.ApplyTo(base.result)
+		assert.True(t, graph.PatchDirectories(base.result, rebuilt.result, []string{"/repo/a"}).Empty())
+		callers := 0
+		for _, rel := range base.result.Relationships {
+			if rel.Type == core.RelationCalls && rel.ToNodeID == base.entities["app/a.Run"] {
+				assert.Equal(t, base.entities["app/b.Helper"], rel.FromNodeID)
+				callers++
+			}
+		}
+		assert.Equal(t, 1, callers)
+	})
+
+	t.Run("Should remove packages whose directories have no Go files left", func(t *testing.T) {
+		base := stored()
+
+		patch := graph.PatchDirectories(base.result, newGraphFixture().result, []string{"/repo/a"})
+
+		assert.ElementsMatch(t, []core.ID{
+			base.packages["app/a"], base.files["a/a.go"], base.entities["app/a.Run"],
+			base.entities["app/a.Gone"], base.result.Nodes[len(base.result.Nodes)-1].ID,
+		}, patch.DeleteNodes)
+		assert.Empty(t, patch.CreateNodes)
+		patch.ApplyTo(base.result)
+		assert.Len(t, base.result.Nodes, 3)
+		assert.Len(t, base.result.Relationships, 2)
+	})
+
+	t.Run("Should compare properties read back from the store", func(t *testing.T) {
+		base := newGraphFixture().file("app/a", "a/a.go")
+		base.node(core.NodeTypeStruct, "User", map[string]any{
+			"package": "app/a", "line_start": int64(3), "fields": `[{"name":"ID","type":"int"}]`,
+			"snapshot_id": "snap-1",
+		})
+		base.rel(core.RelationDefines, base.files["a/a.go"], base.result.Nodes[len(base.result.Nodes)-1].ID)
+		rebuilt := newGraphFixture().file("app/a", "a/a.go")
+		rebuilt.node(core.NodeTypeStruct, "User", map[string]any{
+			"package": "app/a", "line_start": 3, "fields": []map[string]any{{"name": "ID", "type": "int"}},
+		})
+		rebuilt.rel(core.RelationDefines, rebuilt.files["a/a.go"], rebuilt.result.Nodes[len(rebuilt.result.Nodes)-1].ID)
+		rebuilt.function("a/a.go", "app/a", "New", "func() User")
+
+		patch := graph.PatchDirectories(base.result, rebuilt.result, []string{"/repo/a"})
+
+		assert.Empty(t, patch.UpdateNodes)
+		require.Len(t, patch.CreateNodes, 1)
+		assert.Equal(t, "snap-1", patch.CreateNodes[0].Properties["snapshot_id"])
+	})
+}
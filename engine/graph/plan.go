@@ -0,0 +1,24 @@
+package graph
+
+import "context"
+
+// QueryPlan is an operator of the plan a store runs a query with. Children
+// feed their rows to the operator.
+type QueryPlan struct {
+	Operator    string       `json:"operator"`
+	Details     string       `json:"details,omitempty"`
+	Identifiers []string     `json:"identifiers,omitempty"`
+	Rows        int64        `json:"rows"`    // Rows produced, when profiled
+	DBHits      int64        `json:"db_hits"` // Store accesses, when profiled by Neo4j
+	Children    []*QueryPlan `json:"children,omitempty"`
+}
+
+// QueryExplainer is implemented by repositories that can describe how they
+// run a query
+type QueryExplainer interface {
+	// ExplainQuery returns the plan of a query without running it
+	ExplainQuery(ctx context.Context, query string, params map[string]any) (*QueryPlan, error)
+	// ProfileQuery runs a query and returns its rows with the plan annotated
+	// with the rows each operator produced
+	ProfileQuery(ctx context.Context, query string, params map[string]any) (*QueryPlan, []map[string]any, error)
+}
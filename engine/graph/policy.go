@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/pkg/logger"
+)
+
+// Policy is a Cypher assertion that must return zero rows for a project.
+// Queries receive the project identifier as $project_id and must use it to
+// scope their matches.
+type Policy struct {
+	Name     string                 // Policy identifier
+	Query    string                 // Cypher query returning offending rows
+	Severity analyzer.SeverityLevel // Severity reported when the policy fails
+	Message  string                 // Explanation shown for failures
+	Params   map[string]any         // Parameters of the query besides $project_id
+}
+
+// PolicyResult holds the outcome of evaluating a single policy
+type PolicyResult struct {
+	Policy *Policy          // Evaluated policy
+	Rows   []map[string]any // Offending rows returned by the query
+}
+
+// Passed reports whether the policy query returned no rows
+func (r *PolicyResult) Passed() bool {
+	return len(r.Rows) == 0
+}
+
+// Validate ensures the policy can be evaluated with project scoping
+func (p *Policy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("policy name is required")
+	}
+	if strings.TrimSpace(p.Query) == "" {
+		return fmt.Errorf("policy %q has an empty query", p.Name)
+	}
+	if !strings.Contains(p.Query, "$project_id") {
+		return fmt.Errorf("policy %q must scope its query with $project_id", p.Name)
+	}
+	switch p.Severity {
+	case "":
+		p.Severity = analyzer.SeverityMedium
+	case analyzer.SeverityLow, analyzer.SeverityMedium, analyzer.SeverityHigh:
+	default:
+		return fmt.Errorf("policy %q has unknown severity %q", p.Name, p.Severity)
+	}
+	return nil
+}
+
+// EvaluatePolicies runs each policy against the project graph and collects
+// the offending rows. All policies are validated before any query is executed.
+func EvaluatePolicies(
+	ctx context.Context,
+	svc Service,
+	projectID core.ID,
+	policies []*Policy,
+) ([]*PolicyResult, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("project ID is required to evaluate policies")
+	}
+	for _, policy := range policies {
+		if err := policy.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]*PolicyResult, 0, len(policies))
+	for _, policy := range policies {
+		logger.Debug("evaluating policy", "policy", policy.Name, "project_id", projectID)
+		params := maps.Clone(policy.Params)
+		if params == nil {
+			params = make(map[string]any, 1)
+		}
+		params["project_id"] = projectID.String()
+		rows, err := svc.ExecuteQuery(ctx, policy.Query, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate policy %q: %w", policy.Name, err)
+		}
+		results = append(results, &PolicyResult{Policy: policy, Rows: rows})
+	}
+	return results, nil
+}
@@ -0,0 +1,103 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryService is a graph.Service that only answers ExecuteQuery
+type queryService struct {
+	graph.Service
+	rows    map[string][]map[string]any
+	err     error
+	queries []string
+	params  []map[string]any
+}
+
+func (s *queryService) ExecuteQuery(_ context.Context, query string, params map[string]any) ([]map[string]any, error) {
+	s.queries = append(s.queries, query)
+	s.params = append(s.params, params)
+	return s.rows[query], s.err
+}
+
+func TestEvaluatePolicies(t *testing.T) {
+	ctx := context.Background()
+	failing := "MATCH (f:Function {project_id: $project_id}) WHERE f.name = 'panic' RETURN f.name AS name"
+	passing := "MATCH (i:Interface {project_id: $project_id}) WHERE i.name = '' RETURN i"
+
+	t.Run("Should collect offending rows with project scoping", func(t *testing.T) {
+		svc := &queryService{rows: map[string][]map[string]any{
+			failing: {{"name": "panic"}},
+		}}
+		policies := []*graph.Policy{
+			{Name: "no-panic", Query: failing, Severity: analyzer.SeverityHigh, Message: "do not define panic"},
+			{Name: "named-interfaces", Query: passing},
+		}
+
+		results, err := graph.EvaluatePolicies(ctx, svc, core.ID("proj"), policies)
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.False(t, results[0].Passed())
+		assert.Equal(t, []map[string]any{{"name": "panic"}}, results[0].Rows)
+		assert.True(t, results[1].Passed())
+		assert.Equal(t, analyzer.SeverityMedium, results[1].Policy.Severity)
+		for _, params := range svc.params {
+			assert.Equal(t, "proj", params["project_id"])
+		}
+	})
+
+	t.Run("Should pass policy parameters along with the project", func(t *testing.T) {
+		svc := &queryService{}
+		params := map[string]any{"name": "panic", "project_id": "other"}
+		policies := []*graph.Policy{{Name: "no-panic", Query: failing, Params: params}}
+
+		_, err := graph.EvaluatePolicies(ctx, svc, core.ID("proj"), policies)
+
+		require.NoError(t, err)
+		require.Len(t, svc.params, 1)
+		assert.Equal(t, map[string]any{"name": "panic", "project_id": "proj"}, svc.params[0])
+		assert.Equal(t, "other", params["project_id"])
+	})
+
+	t.Run("Should reject policies that are not project scoped", func(t *testing.T) {
+		svc := &queryService{}
+		policies := []*graph.Policy{
+			{Name: "unscoped", Query: "MATCH (n) RETURN n"},
+		}
+
+		_, err := graph.EvaluatePolicies(ctx, svc, core.ID("proj"), policies)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "$project_id")
+		assert.Empty(t, svc.queries)
+	})
+
+	t.Run("Should reject unknown severities", func(t *testing.T) {
+		policies := []*graph.Policy{
+			{Name: "odd", Query: failing, Severity: "critical"},
+		}
+
+		_, err := graph.EvaluatePolicies(ctx, &queryService{}, core.ID("proj"), policies)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown severity")
+	})
+
+	t.Run("Should wrap query errors", func(t *testing.T) {
+		svc := &queryService{err: errors.New("connection refused")}
+		policies := []*graph.Policy{{Name: "no-panic", Query: failing}}
+
+		_, err := graph.EvaluatePolicies(ctx, svc, core.ID("proj"), policies)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `failed to evaluate policy "no-panic"`)
+	})
+}
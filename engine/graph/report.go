@@ -0,0 +1,333 @@
+package graph
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/core"
+	"golang.org/x/tools/cover"
+)
+
+// defaultReportTopFunctions is the number of most called functions listed
+const defaultReportTopFunctions = 20
+
+// ReportOptions configures the project report
+type ReportOptions struct {
+	Root         string             // Project root stripped from file paths
+	Coverage     map[string]float64 // Statement coverage in percent by package path
+	TopFunctions int                // Number of most called functions to list (default 20)
+}
+
+// Report is the content of the project report: package metrics and
+// dependencies, import cycles, the most called functions and every function,
+// method and type with its callers and callees
+type Report struct {
+	ProjectID    core.ID            `json:"project_id"`
+	AnalyzedAt   time.Time          `json:"analyzed_at"`
+	GeneratedAt  time.Time          `json:"generated_at"`
+	Statistics   *ProjectStatistics `json:"statistics"`
+	Packages     []*ReportPackage   `json:"packages"`
+	Dependencies []*DiffEdge        `json:"dependencies"` // Imports between packages of the project
+	Cycles       []*DiffCycle       `json:"cycles"`
+	TopFunctions []string           `json:"top_functions"` // Symbol IDs, most callers first
+	Symbols      []*ReportSymbol    `json:"symbols"`
+	HasCoverage  bool               `json:"has_coverage"`
+}
+
+// ReportPackage holds the metrics of a package. Lines are counted up to the
+// last declaration of each file, like the analyzer's line metric.
+type ReportPackage struct {
+	Path          string   `json:"path"`
+	Name          string   `json:"name"`
+	Files         int      `json:"files"`
+	Functions     int      `json:"functions"` // Functions and methods
+	Types         int      `json:"types"`
+	Lines         int      `json:"lines"`
+	Complexity    int      `json:"complexity"` // Sum of the cyclomatic complexity of its functions
+	MaxComplexity int      `json:"max_complexity"`
+	Coverage      *float64 `json:"coverage,omitempty"`
+}
+
+// ReportSymbol is a function, method, struct or interface. Related symbols
+// are referenced by ID.
+type ReportSymbol struct {
+	ID            string        `json:"id"`
+	Kind          core.NodeType `json:"kind"`
+	Name          string        `json:"name"` // Qualified name, e.g. example.com/app/user.Service.Save
+	Package       string        `json:"package"`
+	File          string        `json:"file,omitempty"`
+	Line          int           `json:"line,omitempty"`
+	Lines         int           `json:"lines,omitempty"`
+	Signature     string        `json:"signature,omitempty"`
+	Complexity    int           `json:"complexity,omitempty"`
+	Callers       []string      `json:"callers,omitempty"`
+	Callees       []string      `json:"callees,omitempty"`
+	Methods       []string      `json:"methods,omitempty"`
+	Implements    []string      `json:"implements,omitempty"`
+	ImplementedBy []string      `json:"implemented_by,omitempty"`
+}
+
+// reportBuilder indexes a project graph while the report is built
+type reportBuilder struct {
+	opts      *ReportOptions
+	report    *Report
+	nodes     map[core.ID]*core.Node
+	packages  map[string]*ReportPackage
+	symbols   map[core.ID]*ReportSymbol
+	fileLines map[core.ID]int
+	filePkg   map[core.ID]string
+}
+
+// BuildReport collects the report of a project graph. The statistics are
+// included as they are; coverage is only known for packages in the options.
+func BuildReport(result *core.AnalysisResult, stats *ProjectStatistics, opts *ReportOptions) *Report {
+	if opts == nil {
+		opts = &ReportOptions{}
+	}
+	b := &reportBuilder{
+		opts: opts,
+		report: &Report{
+			ProjectID:   result.ProjectID,
+			AnalyzedAt:  result.AnalyzedAt,
+			GeneratedAt: time.Now().UTC(),
+			Statistics:  stats,
+			HasCoverage: len(opts.Coverage) > 0,
+		},
+		nodes:     make(map[core.ID]*core.Node, len(result.Nodes)),
+		packages:  make(map[string]*ReportPackage),
+		symbols:   make(map[core.ID]*ReportSymbol),
+		fileLines: make(map[core.ID]int),
+		filePkg:   make(map[core.ID]string),
+	}
+	b.addNodes(result)
+	b.addRelationships(result)
+	b.linkMethods()
+	b.finish(indexGraph(result, ""))
+	return b.report
+}
+
+func (b *reportBuilder) addNodes(result *core.AnalysisResult) {
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		b.nodes[node.ID] = node
+		switch node.Type {
+		case projectMetadataType:
+			if analyzedAt, ok := node.Properties["analyzed_at"].(time.Time); ok && b.report.AnalyzedAt.IsZero() {
+				b.report.AnalyzedAt = analyzedAt.UTC()
+			}
+		case core.NodeTypePackage:
+			importPath := packagePath(node)
+			b.packages[importPath] = &ReportPackage{Path: importPath, Name: node.Name}
+		case core.NodeTypeFunction, core.NodeTypeMethod, core.NodeTypeStruct, core.NodeTypeInterface:
+			b.symbols[node.ID] = newReportSymbol(node)
+		}
+	}
+	for _, symbol := range b.symbols {
+		pkg := b.packages[symbol.Package]
+		if pkg == nil {
+			continue
+		}
+		if symbol.Kind == core.NodeTypeFunction || symbol.Kind == core.NodeTypeMethod {
+			pkg.Functions++
+			pkg.Complexity += symbol.Complexity
+			pkg.MaxComplexity = max(pkg.MaxComplexity, symbol.Complexity)
+		} else {
+			pkg.Types++
+		}
+	}
+}
+
+func newReportSymbol(node *core.Node) *ReportSymbol {
+	pkg, _ := node.Properties["package"].(string)
+	signature, _ := node.Properties["signature"].(string)
+	if node.Type == core.NodeTypeStruct || node.Type == core.NodeTypeInterface {
+		signature = typeShape(node)
+	}
+	symbol := &ReportSymbol{
+		ID:         node.ID.String(),
+		Kind:       node.Type,
+		Name:       qualifiedName(node),
+		Package:    pkg,
+		Line:       intValue(node.Properties["line_start"]),
+		Signature:  signature,
+		Complexity: intValue(node.Properties["complexity"]),
+	}
+	if end := intValue(node.Properties["line_end"]); symbol.Line > 0 && end >= symbol.Line {
+		symbol.Lines = end - symbol.Line + 1
+	}
+	return symbol
+}
+
+func (b *reportBuilder) addRelationships(result *core.AnalysisResult) {
+	seen := make(map[DiffEdge]bool)
+	for _, rel := range result.Relationships {
+		from, to := b.nodes[rel.FromNodeID], b.nodes[rel.ToNodeID]
+		if from == nil || to == nil {
+			continue
+		}
+		switch {
+		case rel.Type == core.RelationContains && from.Type == core.NodeTypePackage && to.Type == core.NodeTypeFile:
+			b.filePkg[to.ID] = packagePath(from)
+		case rel.Type == core.RelationDefines && from.Type == core.NodeTypeFile:
+			if symbol := b.symbols[to.ID]; symbol != nil {
+				symbol.File = relativePath(b.opts.Root, fmt.Sprint(from.Properties["path"]))
+				b.fileLines[from.ID] = max(b.fileLines[from.ID], symbol.Line+symbol.Lines-1)
+			}
+		default:
+			b.linkSymbols(rel, seen)
+		}
+	}
+	for fileID, pkgPath := range b.filePkg {
+		if pkg := b.packages[pkgPath]; pkg != nil {
+			pkg.Files++
+			pkg.Lines += b.fileLines[fileID]
+		}
+	}
+}
+
+// linkSymbols records CALLS and IMPLEMENTS edges between symbols, counting
+// repeated calls once
+func (b *reportBuilder) linkSymbols(rel core.Relationship, seen map[DiffEdge]bool) {
+	from, to := b.symbols[rel.FromNodeID], b.symbols[rel.ToNodeID]
+	if from == nil || to == nil || from == to {
+		return
+	}
+	switch rel.Type {
+	case core.RelationCalls:
+		edge := DiffEdge{From: from.ID, To: to.ID}
+		if !seen[edge] {
+			seen[edge] = true
+			from.Callees = append(from.Callees, to.ID)
+			to.Callers = append(to.Callers, from.ID)
+		}
+	case core.RelationImplements:
+		from.Implements = append(from.Implements, to.ID)
+		to.ImplementedBy = append(to.ImplementedBy, from.ID)
+	}
+}
+
+// linkMethods lists the methods of each type, matched by package and
+// receiver type name
+func (b *reportBuilder) linkMethods() {
+	types := make(map[string]*ReportSymbol)
+	for _, symbol := range b.symbols {
+		if symbol.Kind == core.NodeTypeStruct || symbol.Kind == core.NodeTypeInterface {
+			types[symbol.Name] = symbol
+		}
+	}
+	for id, symbol := range b.symbols {
+		if symbol.Kind != core.NodeTypeMethod {
+			continue
+		}
+		receiver, _ := b.nodes[id].Properties["receiver"].(string)
+		if owner := types[symbol.Package+"."+analyzer.ReceiverTypeName(receiver)]; owner != nil {
+			owner.Methods = append(owner.Methods, symbol.ID)
+		}
+	}
+}
+
+// finish sorts the collected symbols and packages and adds the package
+// dependencies, cycles and coverage
+func (b *reportBuilder) finish(index *graphIndex) {
+	report := b.report
+	report.Symbols = make([]*ReportSymbol, 0, len(b.symbols))
+	report.Packages = make([]*ReportPackage, 0, len(b.packages))
+	names := make(map[string]string, len(b.symbols))
+	for _, symbol := range b.symbols {
+		names[symbol.ID] = symbol.Name
+		report.Symbols = append(report.Symbols, symbol)
+	}
+	byName := func(ids []string) {
+		sort.Slice(ids, func(i, j int) bool { return names[ids[i]] < names[ids[j]] })
+	}
+	for _, symbol := range report.Symbols {
+		for _, ids := range [][]string{
+			symbol.Callers, symbol.Callees, symbol.Methods, symbol.Implements, symbol.ImplementedBy,
+		} {
+			byName(ids)
+		}
+	}
+	sort.Slice(report.Symbols, func(i, j int) bool {
+		if report.Symbols[i].Name != report.Symbols[j].Name {
+			return report.Symbols[i].Name < report.Symbols[j].Name
+		}
+		return report.Symbols[i].ID < report.Symbols[j].ID
+	})
+
+	for _, pkg := range b.packages {
+		if coverage, known := b.opts.Coverage[pkg.Path]; known {
+			pkg.Coverage = &coverage
+		}
+		report.Packages = append(report.Packages, pkg)
+	}
+	sort.Slice(report.Packages, func(i, j int) bool { return report.Packages[i].Path < report.Packages[j].Path })
+
+	report.Dependencies = make([]*DiffEdge, 0)
+	for edge := range index.imports {
+		if b.packages[edge.From] != nil && b.packages[edge.To] != nil && edge.From != edge.To {
+			report.Dependencies = append(report.Dependencies, &DiffEdge{From: edge.From, To: edge.To})
+		}
+	}
+	sort.Slice(report.Dependencies, func(i, j int) bool {
+		if report.Dependencies[i].From != report.Dependencies[j].From {
+			return report.Dependencies[i].From < report.Dependencies[j].From
+		}
+		return report.Dependencies[i].To < report.Dependencies[j].To
+	})
+	report.Cycles = cycleDifference(index.cycles(), nil)
+	if report.Cycles == nil {
+		report.Cycles = make([]*DiffCycle, 0)
+	}
+	report.TopFunctions = b.topFunctions()
+}
+
+// topFunctions returns the functions and methods with the most callers
+func (b *reportBuilder) topFunctions() []string {
+	limit := b.opts.TopFunctions
+	if limit <= 0 {
+		limit = defaultReportTopFunctions
+	}
+	var called []*ReportSymbol
+	for _, symbol := range b.report.Symbols {
+		if len(symbol.Callers) > 0 && (symbol.Kind == core.NodeTypeFunction || symbol.Kind == core.NodeTypeMethod) {
+			called = append(called, symbol)
+		}
+	}
+	sort.SliceStable(called, func(i, j int) bool { return len(called[i].Callers) > len(called[j].Callers) })
+	ids := make([]string, 0, limit)
+	for i := 0; i < len(called) && i < limit; i++ {
+		ids = append(ids, called[i].ID)
+	}
+	return ids
+}
+
+// PackageCoverage reads a Go coverage profile, as written by
+// 'go test -coverprofile', and returns the statement coverage of each package
+// in percent
+func PackageCoverage(profilePath string) (map[string]float64, error) {
+	profiles, err := cover.ParseProfiles(profilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage profile: %w", err)
+	}
+	total := make(map[string]int)
+	covered := make(map[string]int)
+	for _, profile := range profiles {
+		pkg := path.Dir(profile.FileName)
+		for _, block := range profile.Blocks {
+			total[pkg] += block.NumStmt
+			if block.Count > 0 {
+				covered[pkg] += block.NumStmt
+			}
+		}
+	}
+	coverage := make(map[string]float64, len(total))
+	for pkg, statements := range total {
+		if statements > 0 {
+			coverage[pkg] = float64(covered[pkg]) * 100 / float64(statements)
+		}
+	}
+	return coverage, nil
+}
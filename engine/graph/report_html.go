@@ -0,0 +1,40 @@
+package graph
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+)
+
+// reportHTML is the single page of the HTML report. The report data is
+// embedded as JSON and rendered by the page's own script, so the report needs
+// no server and no network access.
+//
+//go:embed assets/report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportHTML))
+
+// WriteHTMLReport writes the report as a static site to dir. The site is a
+// single index.html with the package dependency graph, a treemap of the
+// packages and a page for every package and symbol.
+func WriteHTMLReport(dir string, report *Report) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+	path := filepath.Join(dir, "index.html")
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create report: %w", err)
+	}
+	if err := reportTemplate.Execute(file, report); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,123 @@
+package graph_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// complexity sets the cyclomatic complexity of an entity
+func (f *graphFixture) complexity(key string, value int) *graphFixture {
+	for i := range f.result.Nodes {
+		if f.result.Nodes[i].ID == f.entities[key] {
+			f.result.Nodes[i].Properties["complexity"] = int64(value)
+		}
+	}
+	return f
+}
+
+func reportFixture() *graphFixture {
+	fixture := newGraphFixture().
+		file("app/store", "store/store.go").
+		file("app/service", "service/service.go").
+		function("store/store.go", "app/store", "Save", "func() error").
+		lines("app/store.Save", 10, 29).complexity("app/store.Save", 6).
+		function("store/store.go", "app/store", "Load", "func() error").
+		lines("app/store.Load", 31, 40).complexity("app/store.Load", 2).
+		function("service/service.go", "app/service", "Create", "func() error").
+		lines("app/service.Create", 5, 14).complexity("app/service.Create", 3).
+		function("service/service.go", "app/service", "Update", "func() error").
+		lines("app/service.Update", 16, 20).complexity("app/service.Update", 1).
+		structType("store/store.go", "app/store", "Record", `[{"name":"ID","type":"string"}]`).
+		imports("service/service.go", "app/store").
+		imports("store/store.go", "app/service").
+		calls("app/service.Create", "app/store.Save").
+		calls("app/service.Create", "app/store.Save").
+		calls("app/service.Update", "app/store.Save").
+		calls("app/service.Update", "app/store.Load")
+	fixture.method("store/store.go", "app/store", "Record", "Validate", "func() error")
+	return fixture
+}
+
+func TestBuildReport(t *testing.T) {
+	report := graph.BuildReport(reportFixture().result, &graph.ProjectStatistics{TotalNodes: 12}, &graph.ReportOptions{
+		Root:         "/repo",
+		Coverage:     map[string]float64{"app/store": 75},
+		TopFunctions: 1,
+	})
+	symbols := make(map[string]*graph.ReportSymbol)
+	for _, symbol := range report.Symbols {
+		symbols[symbol.Name] = symbol
+	}
+
+	t.Run("Should sum package metrics", func(t *testing.T) {
+		require.Len(t, report.Packages, 2)
+		service, store := report.Packages[0], report.Packages[1]
+		assert.Equal(t, "app/service", service.Path)
+		assert.Equal(t, 2, service.Functions)
+		assert.Equal(t, 20, service.Lines)
+		assert.Equal(t, 4, service.Complexity)
+		assert.Nil(t, service.Coverage)
+		assert.Equal(t, 3, store.Functions)
+		assert.Equal(t, 1, store.Types)
+		assert.Equal(t, 1, store.Files)
+		assert.Equal(t, 40, store.Lines)
+		assert.Equal(t, 6, store.MaxComplexity)
+		require.NotNil(t, store.Coverage)
+		assert.InDelta(t, 75.0, *store.Coverage, 0.001)
+		assert.True(t, report.HasCoverage)
+		assert.Equal(t, 12, report.Statistics.TotalNodes)
+	})
+
+	t.Run("Should list dependencies and cycles between packages", func(t *testing.T) {
+		assert.Equal(t, []*graph.DiffEdge{
+			{From: "app/service", To: "app/store"},
+			{From: "app/store", To: "app/service"},
+		}, report.Dependencies)
+		assert.Equal(t, []*graph.DiffCycle{{Packages: []string{"app/service", "app/store"}}}, report.Cycles)
+	})
+
+	t.Run("Should link callers, callees and methods", func(t *testing.T) {
+		save := symbols["app/store.Save"]
+		require.NotNil(t, save)
+		assert.Equal(t, "store/store.go", save.File)
+		assert.Equal(t, 20, save.Lines)
+		assert.Equal(t, []string{symbols["app/service.Create"].ID, symbols["app/service.Update"].ID}, save.Callers)
+		assert.Equal(t, []string{symbols["app/store.Load"].ID, save.ID}, symbols["app/service.Update"].Callees)
+		assert.Equal(t, []string{symbols["app/store.Record.Validate"].ID}, symbols["app/store.Record"].Methods)
+		assert.Equal(t, []string{save.ID}, report.TopFunctions)
+	})
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	report := graph.BuildReport(reportFixture().result, &graph.ProjectStatistics{}, nil)
+	dir := filepath.Join(t.TempDir(), "site")
+
+	path, err := graph.WriteHTMLReport(dir, report)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "index.html"), path)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	page := string(content)
+
+	t.Run("Should embed the report data", func(t *testing.T) {
+		assert.Contains(t, page, "<title>proj · gograph report</title>")
+		assert.Contains(t, page, `"path":"app/store"`)
+		assert.Contains(t, page, `"name":"app/store.Save"`)
+	})
+
+	t.Run("Should escape data that would end the script", func(t *testing.T) {
+		report.Symbols[0].Signature = "</script><script>alert(1)</script>"
+		path, err := graph.WriteHTMLReport(dir, report)
+		require.NoError(t, err)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, 2, strings.Count(string(content), "</script>"))
+	})
+}
@@ -212,10 +212,11 @@ func (s *service) GetNodeWithRelationships(ctx context.Context, nodeID core.ID)
 		OutgoingRelations: make([]core.Relationship, 0),
 	}
 
-	// Get outgoing relationships
+	// Get outgoing relationships. Relationships are returned as their
+	// properties and type, which every store returns as plain values.
 	outQuery := `
 		MATCH (n {id: $nodeId})-[r]->(m)
-		RETURN r, m.id as to_id
+		RETURN properties(r) as r, type(r) as type, m.id as to_id
 	`
 	outResults, err := s.repository.ExecuteQuery(ctx, outQuery, map[string]any{
 		"nodeId": nodeID.String(),
@@ -227,6 +228,9 @@ func (s *service) GetNodeWithRelationships(ctx context.Context, nodeID core.ID)
 	for _, res := range outResults {
 		if relData, ok := res["r"].(map[string]any); ok {
 			rel := s.mapToRelationship(&relData)
+			if relType, ok := res["type"].(string); ok {
+				rel.Type = core.RelationType(relType)
+			}
 			// Ensure the relationship has the correct from/to IDs
 			rel.FromNodeID = nodeID
 			if toID, ok := res["to_id"].(string); ok {
@@ -239,7 +243,7 @@ func (s *service) GetNodeWithRelationships(ctx context.Context, nodeID core.ID)
 	// Get incoming relationships
 	inQuery := `
 		MATCH (m)-[r]->(n {id: $nodeId})
-		RETURN r, m.id as from_id
+		RETURN properties(r) as r, type(r) as type, m.id as from_id
 	`
 	inResults, err := s.repository.ExecuteQuery(ctx, inQuery, map[string]any{
 		"nodeId": nodeID.String(),
@@ -251,6 +255,9 @@ func (s *service) GetNodeWithRelationships(ctx context.Context, nodeID core.ID)
 	for _, res := range inResults {
 		if relData, ok := res["r"].(map[string]any); ok {
 			rel := s.mapToRelationship(&relData)
+			if relType, ok := res["type"].(string); ok {
+				rel.Type = core.RelationType(relType)
+			}
 			// Ensure the relationship has the correct from/to IDs
 			rel.ToNodeID = nodeID
 			if fromID, ok := res["from_id"].(string); ok {
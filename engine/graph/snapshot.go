@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// RetentionPolicy decides which snapshots of a project are pruned
+type RetentionPolicy struct {
+	Keep      int           // Number of most recent snapshots to keep (0 keeps all)
+	OlderThan time.Duration // Prune snapshots older than this (0 disables)
+}
+
+// Select returns the snapshots that fall outside the policy, oldest first.
+// The latest snapshot is never selected.
+func (p *RetentionPolicy) Select(snapshots []*core.Snapshot, now time.Time) []*core.Snapshot {
+	ordered := make([]*core.Snapshot, len(snapshots))
+	copy(ordered, snapshots)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].CreatedAt.After(ordered[j].CreatedAt)
+	})
+
+	var selected []*core.Snapshot
+	for i, snapshot := range ordered {
+		if snapshot.Latest {
+			continue
+		}
+		expired := p.OlderThan > 0 && now.Sub(snapshot.CreatedAt) > p.OlderThan
+		if (p.Keep > 0 && i >= p.Keep) || expired {
+			selected = append(selected, snapshot)
+		}
+	}
+
+	// Delete the oldest snapshots first
+	for i, j := 0, len(selected)-1; i < j; i, j = i+1, j-1 {
+		selected[i], selected[j] = selected[j], selected[i]
+	}
+	return selected
+}
+
+// RelocateFiles rewrites the file paths of an analysis result from the
+// directory it was parsed in to the project root it belongs to, so that a
+// revision analyzed in a temporary worktree is stored with the paths of the
+// project checkout. Paths outside the parsed directory are left as they are.
+func RelocateFiles(result *core.AnalysisResult, from, to string) {
+	if from == to {
+		return
+	}
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		if path, ok := node.Properties["path"].(string); ok && node.Type == core.NodeTypeFile {
+			node.Properties["path"] = RelocatePath(path, from, to)
+		}
+	}
+	for i := range result.Relationships {
+		sites, _ := result.Relationships[i].Properties["call_sites"].([]map[string]any)
+		for _, site := range sites {
+			if file, ok := site["file"].(string); ok {
+				site["file"] = RelocatePath(file, from, to)
+			}
+		}
+	}
+}
+
+// RelocatePath maps a path below the from directory to the same path below to
+func RelocatePath(path, from, to string) string {
+	rel, err := filepath.Rel(from, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.Join(to, rel)
+}
@@ -0,0 +1,87 @@
+package graph_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/stretchr/testify/assert"
+)
+
+func snapshotIDs(snapshots []*core.Snapshot) []core.ID {
+	ids := make([]core.ID, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		ids = append(ids, snapshot.ID)
+	}
+	return ids
+}
+
+func TestRetentionPolicy_Select(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	snapshots := []*core.Snapshot{
+		{ID: "s2", CreatedAt: now.Add(-48 * time.Hour)},
+		{ID: "s4", CreatedAt: now, Latest: true},
+		{ID: "s1", CreatedAt: now.Add(-30 * 24 * time.Hour)},
+		{ID: "s3", CreatedAt: now.Add(-24 * time.Hour)},
+	}
+
+	t.Run("Should keep the most recent snapshots", func(t *testing.T) {
+		policy := &graph.RetentionPolicy{Keep: 2}
+
+		selected := policy.Select(snapshots, now)
+
+		assert.Equal(t, []core.ID{"s1", "s2"}, snapshotIDs(selected))
+	})
+
+	t.Run("Should prune snapshots older than the age limit", func(t *testing.T) {
+		policy := &graph.RetentionPolicy{OlderThan: 7 * 24 * time.Hour}
+
+		selected := policy.Select(snapshots, now)
+
+		assert.Equal(t, []core.ID{"s1"}, snapshotIDs(selected))
+	})
+
+	t.Run("Should never prune the latest snapshot", func(t *testing.T) {
+		policy := &graph.RetentionPolicy{Keep: 1, OlderThan: time.Nanosecond}
+		stale := []*core.Snapshot{
+			{ID: "old", CreatedAt: now.Add(-time.Hour), Latest: true},
+			{ID: "older", CreatedAt: now.Add(-2 * time.Hour)},
+		}
+
+		selected := policy.Select(stale, now)
+
+		assert.Equal(t, []core.ID{"older"}, snapshotIDs(selected))
+	})
+
+	t.Run("Should keep everything without limits", func(t *testing.T) {
+		policy := &graph.RetentionPolicy{}
+
+		assert.Empty(t, policy.Select(snapshots, now))
+	})
+}
+
+func TestRelocateFiles(t *testing.T) {
+	t.Run("Should move file paths and call sites below the parsed directory to the project root", func(t *testing.T) {
+		result := &core.AnalysisResult{
+			Nodes: []core.Node{
+				{ID: "f1", Type: core.NodeTypeFile, Properties: map[string]any{"path": "/tmp/wt/checkout/app/main.go"}},
+				{ID: "f2", Type: core.NodeTypeFile, Properties: map[string]any{"path": "/usr/lib/go/src/fmt/print.go"}},
+				{ID: "p1", Type: core.NodeTypePackage, Path: "example.com/app", Properties: map[string]any{}},
+			},
+			Relationships: []core.Relationship{
+				{ID: "r1", Type: core.RelationCalls, Properties: map[string]any{
+					"call_sites": []map[string]any{{"file": "/tmp/wt/checkout/app/main.go", "line": 3}},
+				}},
+			},
+		}
+
+		graph.RelocateFiles(result, "/tmp/wt/checkout", "/home/dev/project")
+
+		assert.Equal(t, "/home/dev/project/app/main.go", result.Nodes[0].Properties["path"])
+		assert.Equal(t, "/usr/lib/go/src/fmt/print.go", result.Nodes[1].Properties["path"])
+		assert.Equal(t, "example.com/app", result.Nodes[2].Path)
+		sites := result.Relationships[0].Properties["call_sites"].([]map[string]any)
+		assert.Equal(t, "/home/dev/project/app/main.go", sites[0]["file"])
+	})
+}
@@ -0,0 +1,11 @@
+package graph
+
+import "errors"
+
+// RowFunc receives the rows of a streamed query one at a time. Returning an
+// error stops the query; ErrStopStream stops it without failing it.
+type RowFunc func(row map[string]any) error
+
+// ErrStopStream is returned by a RowFunc to stop a query once it has read the
+// rows it needs
+var ErrStopStream = errors.New("stop streaming query rows")
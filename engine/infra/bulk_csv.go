@@ -0,0 +1,313 @@
+package infra
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// bulkArrayDelimiter separates array elements, matching the default of
+// neo4j-admin database import
+const bulkArrayDelimiter = ";"
+
+// BulkCSVFiles lists the files written by WriteBulkCSV
+type BulkCSVFiles struct {
+	Dir           string
+	Nodes         []string // One file per label
+	Relationships []string // One file per relationship type
+	Skipped       int      // Relationships with an endpoint outside the result
+}
+
+// WriteBulkCSV writes an analysis result as CSV files for an offline initial
+// load with neo4j-admin database import. Nodes and relationships get the same
+// properties as a transactional import, including project_id and the project
+// metadata node. Each file starts with a header naming the property columns
+// and their types.
+//
+// Relationships are matched by node ID during the transactional import, so
+// those whose endpoints are not part of the result are left out here as well.
+func WriteBulkCSV(dir string, result *core.AnalysisResult) (*BulkCSVFiles, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create bulk import directory: %w", err)
+	}
+	files := &BulkCSVFiles{Dir: dir}
+	projectID := result.ProjectID.String()
+
+	nodes := make(map[string][]map[string]any)
+	known := make(map[core.ID]bool, len(result.Nodes))
+	for i := range result.Nodes {
+		node := &result.Nodes[i]
+		known[node.ID] = true
+		props := bulkProperties(node.Properties, projectID, node.CreatedAt)
+		props["id"] = node.ID.String()
+		props["name"] = node.Name
+		props["path"] = node.Path
+		nodes[string(node.Type)] = append(nodes[string(node.Type)], props)
+	}
+	metadata := projectMetadataProperties(result)
+	nodes["ProjectMetadata"] = append(nodes["ProjectMetadata"], metadata)
+
+	rels := make(map[string][]map[string]any)
+	for i := range result.Relationships {
+		rel := &result.Relationships[i]
+		if !known[rel.FromNodeID] || !known[rel.ToNodeID] {
+			files.Skipped++
+			continue
+		}
+		props := bulkProperties(rel.Properties, projectID, rel.CreatedAt)
+		props["id"] = rel.ID.String()
+		props[":START_ID"] = rel.FromNodeID.String()
+		props[":END_ID"] = rel.ToNodeID.String()
+		rels[string(rel.Type)] = append(rels[string(rel.Type)], props)
+	}
+
+	for _, label := range sortedGroups(nodes) {
+		path := filepath.Join(dir, "nodes_"+label+".csv")
+		if err := writeBulkFile(path, label, nodes[label], true); err != nil {
+			return nil, err
+		}
+		files.Nodes = append(files.Nodes, path)
+	}
+	for _, relType := range sortedGroups(rels) {
+		path := filepath.Join(dir, "relationships_"+relType+".csv")
+		if err := writeBulkFile(path, relType, rels[relType], false); err != nil {
+			return nil, err
+		}
+		files.Relationships = append(files.Relationships, path)
+	}
+	return files, nil
+}
+
+// ImportCommand returns the neo4j-admin command that loads the files into a
+// new database
+func (f *BulkCSVFiles) ImportCommand(database string) string {
+	if database == "" {
+		database = "neo4j"
+	}
+	args := []string{"neo4j-admin database import full --multiline-fields=true"}
+	for _, path := range f.Nodes {
+		args = append(args, "--nodes="+path)
+	}
+	for _, path := range f.Relationships {
+		args = append(args, "--relationships="+path)
+	}
+	args = append(args, database)
+	return strings.Join(args, " \\\n  ")
+}
+
+// bulkProperties serializes properties like a transactional import and adds
+// the project ID when the builder did not set one
+func bulkProperties(properties map[string]any, projectID string, createdAt time.Time) map[string]any {
+	props := serializeProperties(properties)
+	if props == nil {
+		props = make(map[string]any)
+	}
+	props["created_at"] = createdAt.UTC()
+	if _, exists := props["project_id"]; !exists {
+		props["project_id"] = projectID
+	}
+	return props
+}
+
+// projectMetadataProperties mirrors the metadata node stored with every
+// transactional import
+func projectMetadataProperties(result *core.AnalysisResult) map[string]any {
+	return map[string]any{
+		"project_id":         result.ProjectID.String(),
+		"analyzed_at":        result.AnalyzedAt.UTC(),
+		"total_files":        int64(result.TotalFiles),
+		"total_packages":     int64(result.TotalPackages),
+		"total_functions":    int64(result.TotalFunctions),
+		"total_structs":      int64(result.TotalStructs),
+		"node_count":         int64(len(result.Nodes)),
+		"relationship_count": int64(len(result.Relationships)),
+		"updated_at":         time.Now().UnixMilli(),
+	}
+}
+
+func sortedGroups(groups map[string][]map[string]any) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeBulkFile writes the rows of one label or relationship type. Node files
+// identify rows by the id property; relationship files name their endpoints.
+func writeBulkFile(path, group string, rows []map[string]any, isNode bool) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write %s: %w", path, closeErr)
+		}
+	}()
+
+	columns := bulkColumns(rows, isNode)
+	header := make([]string, 0, len(columns)+1)
+	record := make([]string, len(columns)+1)
+	for _, column := range columns {
+		header = append(header, column.header)
+	}
+	if isNode {
+		header = append(header, ":LABEL")
+	} else {
+		header = append(header, ":TYPE")
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = bulkValue(row[column.name], column.valueType)
+		}
+		record[len(columns)] = group
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// bulkColumn is a property column with the type shared by its values
+type bulkColumn struct {
+	name      string
+	valueType string
+	header    string
+}
+
+// bulkColumns returns the identifying columns followed by the property
+// columns sorted by name. Values of mixed types are written as strings.
+func bulkColumns(rows []map[string]any, isNode bool) []bulkColumn {
+	types := make(map[string]string)
+	for _, row := range rows {
+		for name, value := range row {
+			if value == nil {
+				continue
+			}
+			valueType := bulkType(value)
+			if current, exists := types[name]; exists && current != valueType {
+				valueType = widerBulkType(current, valueType)
+			}
+			types[name] = valueType
+		}
+	}
+
+	var columns []bulkColumn
+	if _, hasID := types["id"]; isNode && hasID {
+		// The id column is stored as the id property as well. The project
+		// metadata node has no ID and is never referenced.
+		columns = append(columns, bulkColumn{name: "id", valueType: "string", header: "id:ID"})
+		delete(types, "id")
+	} else if !isNode {
+		columns = append(columns,
+			bulkColumn{name: ":START_ID", valueType: "string", header: ":START_ID"},
+			bulkColumn{name: ":END_ID", valueType: "string", header: ":END_ID"})
+		delete(types, ":START_ID")
+		delete(types, ":END_ID")
+	}
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		columns = append(columns, bulkColumn{name: name, valueType: types[name], header: name + ":" + types[name]})
+	}
+	return columns
+}
+
+// bulkType returns the neo4j-admin type of a serialized property value
+func bulkType(value any) string {
+	switch value.(type) {
+	case bool:
+		return "boolean"
+	case int64:
+		return "long"
+	case float64:
+		return "double"
+	case time.Time:
+		return "datetime"
+	case []bool:
+		return "boolean[]"
+	case []int64:
+		return "long[]"
+	case []float64:
+		return "double[]"
+	case []string:
+		return "string[]"
+	default:
+		return "string"
+	}
+}
+
+func widerBulkType(a, b string) string {
+	if (a == "long" && b == "double") || (a == "double" && b == "long") {
+		return "double"
+	}
+	return "string"
+}
+
+// bulkValue formats a property value for its column. Missing values are left
+// empty, which neo4j-admin imports as an absent property. Arrays in a column
+// of mixed types are written as JSON.
+func bulkValue(value any, valueType string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return formatBulkFloat(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	if valueType == "string" {
+		return serializeToJSON(value)
+	}
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, bulkArrayDelimiter)
+	case []bool:
+		return joinBulkArray(v, strconv.FormatBool)
+	case []int64:
+		return joinBulkArray(v, func(n int64) string { return strconv.FormatInt(n, 10) })
+	case []float64:
+		return joinBulkArray(v, formatBulkFloat)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatBulkFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func joinBulkArray[T any](values []T, format func(T) string) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = format(value)
+	}
+	return strings.Join(parts, bulkArrayDelimiter)
+}
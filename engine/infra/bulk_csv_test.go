@@ -0,0 +1,104 @@
+package infra_test
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/infra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func TestWriteBulkCSV(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	result := &core.AnalysisResult{
+		ProjectID:  "proj",
+		AnalyzedAt: createdAt,
+		Nodes: []core.Node{
+			{ID: "p1", Type: core.NodeTypePackage, Name: "app", Path: "example.com/app", CreatedAt: createdAt},
+			{ID: "f1", Type: core.NodeTypeFunction, Name: "Run", CreatedAt: createdAt, Properties: map[string]any{
+				"project_id": "proj",
+				"line_start": 10,
+				"exported":   true,
+				"params":     []string{"ctx", "name"},
+				"fields":     []map[string]any{{"name": "ID"}},
+			}},
+			{ID: "f2", Type: core.NodeTypeFunction, Name: "help", CreatedAt: createdAt, Properties: map[string]any{
+				"line_start": 20,
+				"complexity": 1.5,
+			}},
+		},
+		Relationships: []core.Relationship{
+			{ID: "r1", Type: core.RelationCalls, FromNodeID: "f1", ToNodeID: "f2", CreatedAt: createdAt},
+			{ID: "r2", Type: core.RelationCalls, FromNodeID: "f1", ToNodeID: "missing", CreatedAt: createdAt},
+		},
+	}
+	dir := t.TempDir()
+
+	files, err := infra.WriteBulkCSV(dir, result)
+	require.NoError(t, err)
+
+	t.Run("Should write one file per label and relationship type", func(t *testing.T) {
+		assert.Equal(t, []string{
+			filepath.Join(dir, "nodes_Function.csv"),
+			filepath.Join(dir, "nodes_Package.csv"),
+			filepath.Join(dir, "nodes_ProjectMetadata.csv"),
+		}, files.Nodes)
+		assert.Equal(t, []string{filepath.Join(dir, "relationships_CALLS.csv")}, files.Relationships)
+		assert.Equal(t, 1, files.Skipped)
+	})
+
+	t.Run("Should write typed headers and project IDs", func(t *testing.T) {
+		records := readCSV(t, filepath.Join(dir, "nodes_Function.csv"))
+		require.Len(t, records, 3)
+		assert.Equal(t, []string{
+			"id:ID", "complexity:double", "created_at:datetime", "exported:boolean", "fields:string",
+			"line_start:long", "name:string", "params:string[]", "path:string", "project_id:string", ":LABEL",
+		}, records[0])
+		assert.Equal(t, []string{
+			"f1", "", "2025-03-01T12:00:00Z", "true", `[{"name":"ID"}]`,
+			"10", "Run", "ctx;name", "", "proj", "Function",
+		}, records[1])
+		assert.Equal(t, "1.5", records[2][1])
+		assert.Equal(t, "proj", records[2][9])
+	})
+
+	t.Run("Should write relationships by node ID", func(t *testing.T) {
+		records := readCSV(t, filepath.Join(dir, "relationships_CALLS.csv"))
+		assert.Equal(t, [][]string{
+			{":START_ID", ":END_ID", "created_at:datetime", "id:string", "project_id:string", ":TYPE"},
+			{"f1", "f2", "2025-03-01T12:00:00Z", "r1", "proj", "CALLS"},
+		}, records)
+	})
+
+	t.Run("Should write the project metadata without an ID column", func(t *testing.T) {
+		records := readCSV(t, filepath.Join(dir, "nodes_ProjectMetadata.csv"))
+		require.Len(t, records, 2)
+		assert.NotContains(t, records[0], "id:ID")
+		assert.Contains(t, records[0], "node_count:long")
+		assert.Equal(t, "ProjectMetadata", records[1][len(records[1])-1])
+	})
+
+	t.Run("Should build the neo4j-admin import command", func(t *testing.T) {
+		command := files.ImportCommand("")
+		assert.Contains(t, command, "neo4j-admin database import full --multiline-fields=true")
+		assert.True(t, strings.HasSuffix(command, " neo4j"))
+		assert.Contains(t, command, "--nodes="+filepath.Join(dir, "nodes_Package.csv"))
+		assert.Contains(t, command, "--relationships="+filepath.Join(dir, "relationships_CALLS.csv"))
+	})
+}
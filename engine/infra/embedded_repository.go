@@ -0,0 +1,562 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/cypher"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/pkg/logger"
+	bolt "go.etcd.io/bbolt"
+)
+
+// EmbeddedURIScheme selects the embedded store in place of a Neo4j server,
+// as in "file://.gograph/graph.db"
+const EmbeddedURIScheme = "file://"
+
+// EmbeddedRepository implements graph.Repository on a single bbolt file, so
+// gograph works without a Neo4j server. The file is locked only while an
+// operation runs, which lets several gograph processes share it. Each process
+// keeps the graph in memory and reloads it when another one has written.
+type EmbeddedRepository struct {
+	path       string
+	mu         sync.RWMutex
+	graph      *memoryGraph // Nil until loaded, or after a failed write
+	generation uint64
+}
+
+// NewRepository opens the graph store named by the URI of config: the
+// embedded store for file:// URIs and Neo4j otherwise
+func NewRepository(config *Neo4jConfig) (graph.Repository, error) {
+	if config != nil && strings.HasPrefix(config.URI, EmbeddedURIScheme) {
+		return NewEmbeddedRepository(strings.TrimPrefix(config.URI, EmbeddedURIScheme))
+	}
+	return NewNeo4jRepository(config)
+}
+
+// NewEmbeddedRepository opens the embedded store at path, creating it if needed
+func NewEmbeddedRepository(path string) (graph.Repository, error) {
+	if path == "" {
+		return nil, fmt.Errorf("embedded store path is required")
+	}
+	db, err := openEmbeddedFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	if err := db.Update(initEmbeddedFile); err != nil {
+		return nil, fmt.Errorf("failed to initialize embedded store: %w", err)
+	}
+
+	logger.Debug("opened embedded store", "path", path)
+	return &EmbeddedRepository{path: path}, nil
+}
+
+// read runs fn against an up-to-date in-memory graph. The graph is refreshed
+// under the write lock and read under the read lock, so reads run
+// concurrently; a write that fails in between drops the graph, which is then
+// loaded again.
+func (r *EmbeddedRepository) read(fn func(g *memoryGraph) error) error {
+	db, err := openEmbeddedFile(r.path, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for {
+		r.mu.Lock()
+		err = db.View(func(tx *bolt.Tx) error {
+			return r.refresh(tx)
+		})
+		r.mu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		r.mu.RLock()
+		if r.graph != nil {
+			defer r.mu.RUnlock()
+			return fn(r.graph)
+		}
+		r.mu.RUnlock()
+	}
+}
+
+// write runs fn in a write transaction. A failed write drops the in-memory
+// graph, which fn may have changed, so that the next operation reloads it.
+func (r *EmbeddedRepository) write(fn func(w *embeddedWriter) error) error {
+	db, err := openEmbeddedFile(r.path, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err = db.Update(func(tx *bolt.Tx) error {
+		if err := r.refresh(tx); err != nil {
+			return err
+		}
+		if err := fn(&embeddedWriter{tx: tx, graph: r.graph}); err != nil {
+			return err
+		}
+		r.generation++
+		return tx.Bucket(bucketMeta).Put(keyGeneration, encodeUint(r.generation))
+	})
+	if err != nil {
+		r.graph = nil
+	}
+	return err
+}
+
+// refresh reloads the in-memory graph when the file has changed since it was
+// loaded. The caller holds the write lock.
+func (r *EmbeddedRepository) refresh(tx *bolt.Tx) error {
+	generation := storeGeneration(tx)
+	if r.graph != nil && generation == r.generation {
+		return nil
+	}
+	graph, err := loadMemoryGraph(tx)
+	if err != nil {
+		return fmt.Errorf("failed to load embedded store: %w", err)
+	}
+	r.graph, r.generation = graph, generation
+	return nil
+}
+
+// Connect is a no-op; the store is opened for each operation
+func (r *EmbeddedRepository) Connect(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+// Close releases the in-memory graph
+func (r *EmbeddedRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.graph = nil
+	return nil
+}
+
+// CreateNode creates a new node
+func (r *EmbeddedRepository) CreateNode(ctx context.Context, node *core.Node) error {
+	if err := r.CreateNodes(ctx, []core.Node{*node}); err != nil {
+		return fmt.Errorf("failed to create node: %w", err)
+	}
+	return nil
+}
+
+// CreateNodes creates multiple nodes in one transaction
+func (r *EmbeddedRepository) CreateNodes(_ context.Context, nodes []core.Node) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	return r.write(func(w *embeddedWriter) error {
+		return putNodes(w, nodes)
+	})
+}
+
+// GetNode retrieves a node by ID
+func (r *EmbeddedRepository) GetNode(_ context.Context, id core.ID) (*core.Node, error) {
+	var node *core.Node
+	err := r.read(func(g *memoryGraph) error {
+		stored := g.nodes[id]
+		if stored == nil {
+			return fmt.Errorf("node %s not found", id)
+		}
+		node = copyNode(stored)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	return node, nil
+}
+
+// UpdateNode replaces the name, path and properties of an existing node,
+// keeping its creation time
+func (r *EmbeddedRepository) UpdateNode(_ context.Context, node *core.Node) error {
+	err := r.write(func(w *embeddedWriter) error {
+		stored := w.graph.nodes[node.ID]
+		if stored == nil {
+			return nil
+		}
+		updated := storedNode(node)
+		updated.Type = stored.Type
+		updated.CreatedAt = stored.CreatedAt
+		updated.Properties["created_at"] = stored.Properties["created_at"]
+		return w.putNode(updated)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update node: %w", err)
+	}
+	return nil
+}
+
+// DeleteNode deletes a node and its relationships
+func (r *EmbeddedRepository) DeleteNode(_ context.Context, id core.ID) error {
+	err := r.write(func(w *embeddedWriter) error {
+		return w.detachDeleteNode(id)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete node: %w", err)
+	}
+	return nil
+}
+
+// CreateRelationship creates a new relationship between existing nodes
+func (r *EmbeddedRepository) CreateRelationship(ctx context.Context, rel *core.Relationship) error {
+	if err := r.CreateRelationships(ctx, []core.Relationship{*rel}); err != nil {
+		return fmt.Errorf("failed to create relationship: %w", err)
+	}
+	return nil
+}
+
+// CreateRelationships creates multiple relationships in one transaction.
+// Relationships whose nodes do not exist are skipped.
+func (r *EmbeddedRepository) CreateRelationships(_ context.Context, rels []core.Relationship) error {
+	if len(rels) == 0 {
+		return nil
+	}
+	return r.write(func(w *embeddedWriter) error {
+		return putRelationships(w, rels)
+	})
+}
+
+// GetRelationship retrieves a relationship by ID
+func (r *EmbeddedRepository) GetRelationship(_ context.Context, id core.ID) (*core.Relationship, error) {
+	var rel *core.Relationship
+	err := r.read(func(g *memoryGraph) error {
+		stored := g.rels[id]
+		if stored == nil {
+			return fmt.Errorf("relationship %s not found", id)
+		}
+		rel = copyRelationship(stored)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationship: %w", err)
+	}
+	return rel, nil
+}
+
+// DeleteRelationship deletes a relationship by ID
+func (r *EmbeddedRepository) DeleteRelationship(_ context.Context, id core.ID) error {
+	err := r.write(func(w *embeddedWriter) error {
+		return w.deleteRelationship(id)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete relationship: %w", err)
+	}
+	return nil
+}
+
+// ExecuteQuery runs a read-only Cypher query against the in-memory graph.
+// Queries outside the supported subset fail with cypher.ErrUnsupported.
+func (r *EmbeddedRepository) ExecuteQuery(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+) ([]map[string]any, error) {
+	parsed, err := cypher.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	var rows []map[string]any
+	err = r.read(func(g *memoryGraph) error {
+		var err error
+		rows, err = parsed.Execute(ctx, g.queryGraph(), params)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	return rows, nil
+}
+
+// StreamQuery runs a read-only Cypher query against the in-memory graph and
+// passes its rows to fn one at a time. The graph is locked for reading until
+// the query ends.
+func (r *EmbeddedRepository) StreamQuery(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+	fn graph.RowFunc,
+) error {
+	parsed, err := cypher.Parse(query)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	var fnErr error
+	err = r.read(func(g *memoryGraph) error {
+		return parsed.Stream(ctx, g.queryGraph(), params, func(row map[string]any) error {
+			fnErr = fn(row)
+			return fnErr
+		})
+	})
+	if fnErr != nil {
+		return streamError(fnErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	return nil
+}
+
+// streamError returns the error a RowFunc stopped a query with, or nil when it
+// stopped it with graph.ErrStopStream
+func streamError(err error) error {
+	if errors.Is(err, graph.ErrStopStream) {
+		return nil
+	}
+	return err
+}
+
+// ExplainQuery returns the clauses of a query as a plan, without running it
+func (r *EmbeddedRepository) ExplainQuery(
+	_ context.Context,
+	query string,
+	_ map[string]any,
+) (*graph.QueryPlan, error) {
+	parsed, err := cypher.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return embeddedQueryPlan(parsed.Explain()), nil
+}
+
+// ProfileQuery runs a query and counts the rows each clause produced
+func (r *EmbeddedRepository) ProfileQuery(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+) (*graph.QueryPlan, []map[string]any, error) {
+	parsed, err := cypher.Parse(query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to profile query: %w", err)
+	}
+	var plan *cypher.Plan
+	var rows []map[string]any
+	err = r.read(func(g *memoryGraph) error {
+		var err error
+		plan, rows, err = parsed.Profile(ctx, g.queryGraph(), params)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to profile query: %w", err)
+	}
+	return embeddedQueryPlan(plan), rows, nil
+}
+
+func embeddedQueryPlan(plan *cypher.Plan) *graph.QueryPlan {
+	converted := &graph.QueryPlan{Operator: plan.Operator, Details: plan.Details, Rows: int64(plan.Rows)}
+	for _, child := range plan.Children {
+		converted.Children = append(converted.Children, embeddedQueryPlan(child))
+	}
+	return converted
+}
+
+// ImportAnalysisResult imports an entire analysis result in one transaction
+func (r *EmbeddedRepository) ImportAnalysisResult(_ context.Context, result *core.AnalysisResult) error {
+	startTime := time.Now()
+	err := r.write(func(w *embeddedWriter) error {
+		if err := putNodes(w, result.Nodes); err != nil {
+			return fmt.Errorf("failed to create nodes: %w", err)
+		}
+		if err := putRelationships(w, result.Relationships); err != nil {
+			return fmt.Errorf("failed to create relationships: %w", err)
+		}
+		return w.putNode(projectMetadataNode(result))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import analysis result: %w", err)
+	}
+
+	logger.Info("imported analysis result",
+		"project_id", result.ProjectID,
+		"nodes", len(result.Nodes),
+		"relationships", len(result.Relationships),
+		"duration", time.Since(startTime))
+	return nil
+}
+
+// StoreAnalysis stores the complete analysis result. When the result carries
+// a snapshot, the previous analysis is archived instead of deleted.
+func (r *EmbeddedRepository) StoreAnalysis(ctx context.Context, result *core.AnalysisResult) error {
+	if result.Snapshot != nil {
+		return r.storeSnapshot(ctx, result)
+	}
+	if err := r.ClearProject(ctx, result.ProjectID); err != nil {
+		return fmt.Errorf("failed to clear existing data: %w", err)
+	}
+	return r.ImportAnalysisResult(ctx, result)
+}
+
+// ClearProject removes all nodes and relationships for a specific project,
+// including every archived snapshot
+func (r *EmbeddedRepository) ClearProject(_ context.Context, projectID core.ID) error {
+	snapshotPrefix := core.SnapshotScope(projectID, "").String()
+	err := r.write(func(w *embeddedWriter) error {
+		nodes := w.graph.sortedNodes(func(n *core.Node) bool {
+			scope, scoped := n.Properties["project_id"].(string)
+			return scoped && (scope == projectID.String() || strings.HasPrefix(scope, snapshotPrefix)) ||
+				(n.Type == nodeTypeSnapshot && hasProperty(n.Properties, "project", projectID.String()))
+		})
+		for _, node := range nodes {
+			if err := w.detachDeleteNode(node.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear project %s: %w", projectID, err)
+	}
+
+	logger.Info("successfully cleared project", "project_id", projectID)
+	return nil
+}
+
+// FindNodesByType finds all nodes of a specific type
+func (r *EmbeddedRepository) FindNodesByType(
+	_ context.Context,
+	nodeType core.NodeType,
+	projectID core.ID,
+) ([]core.Node, error) {
+	nodes, err := r.findNodes(func(n *core.Node) bool {
+		return n.Type == nodeType && hasProperty(n.Properties, "project_id", projectID.String())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nodes by type: %w", err)
+	}
+	return nodes, nil
+}
+
+// FindNodesByName finds nodes by name
+func (r *EmbeddedRepository) FindNodesByName(
+	_ context.Context,
+	name string,
+	projectID core.ID,
+) ([]core.Node, error) {
+	nodes, err := r.findNodes(func(n *core.Node) bool {
+		return n.Name == name && hasProperty(n.Properties, "project_id", projectID.String())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nodes by name: %w", err)
+	}
+	return nodes, nil
+}
+
+// FindRelationshipsByType finds all relationships of a specific type
+func (r *EmbeddedRepository) FindRelationshipsByType(
+	_ context.Context,
+	relType core.RelationType,
+	projectID core.ID,
+) ([]core.Relationship, error) {
+	var rels []core.Relationship
+	err := r.read(func(g *memoryGraph) error {
+		matches := g.sortedRelationships(func(rel *core.Relationship) bool {
+			return rel.Type == relType && hasProperty(rel.Properties, "project_id", projectID.String())
+		})
+		for _, rel := range matches {
+			rels = append(rels, *copyRelationship(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find relationships by type: %w", err)
+	}
+	return rels, nil
+}
+
+func (r *EmbeddedRepository) findNodes(keep func(*core.Node) bool) ([]core.Node, error) {
+	var nodes []core.Node
+	err := r.read(func(g *memoryGraph) error {
+		for _, node := range g.sortedNodes(keep) {
+			nodes = append(nodes, *copyNode(node))
+		}
+		return nil
+	})
+	return nodes, err
+}
+
+func putNodes(w *embeddedWriter, nodes []core.Node) error {
+	for i := range nodes {
+		if err := w.putNode(storedNode(&nodes[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func putRelationships(w *embeddedWriter, rels []core.Relationship) error {
+	for i := range rels {
+		rel := rels[i]
+		rel.Properties = storedProperties(rel.Properties)
+		rel.Properties["created_at"] = rel.CreatedAt.UTC()
+		if err := w.putRelationship(&rel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storedNode copies a node with the properties Neo4j would store for it
+func storedNode(node *core.Node) *core.Node {
+	stored := *node
+	stored.Properties = storedProperties(node.Properties)
+	stored.Properties["created_at"] = node.CreatedAt.UTC()
+	return &stored
+}
+
+// projectMetadataNode summarizes an imported analysis result
+func projectMetadataNode(result *core.AnalysisResult) *core.Node {
+	now := time.Now().UTC()
+	return &core.Node{
+		ID:        core.ID("metadata:" + result.ProjectID.String()),
+		Type:      nodeTypeProjectMetadata,
+		CreatedAt: now,
+		Properties: map[string]any{
+			"project_id":         result.ProjectID.String(),
+			"analyzed_at":        result.AnalyzedAt.UTC(),
+			"total_files":        int64(result.TotalFiles),
+			"total_packages":     int64(result.TotalPackages),
+			"total_functions":    int64(result.TotalFunctions),
+			"total_structs":      int64(result.TotalStructs),
+			"node_count":         int64(len(result.Nodes)),
+			"relationship_count": int64(len(result.Relationships)),
+			"updated_at":         now.UnixMilli(),
+		},
+	}
+}
+
+func copyNode(node *core.Node) *core.Node {
+	copied := *node
+	copied.Properties = copyProperties(node.Properties)
+	return &copied
+}
+
+func copyRelationship(rel *core.Relationship) *core.Relationship {
+	copied := *rel
+	copied.Properties = copyProperties(rel.Properties)
+	return &copied
+}
+
+func copyProperties(props map[string]any) map[string]any {
+	copied := make(map[string]any, len(props))
+	for name, value := range props {
+		copied[name] = value
+	}
+	return copied
+}
+
+// hasProperty reports whether props holds the string value under name. Like a
+// Cypher comparison, a missing property never matches.
+func hasProperty(props map[string]any, name, value string) bool {
+	s, ok := props[name].(string)
+	return ok && s == value
+}
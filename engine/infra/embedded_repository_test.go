@@ -0,0 +1,491 @@
+package infra_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/cypher"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/engine/infra"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupEmbeddedTest opens an embedded repository in a temporary directory
+func setupEmbeddedTest(t *testing.T) (graph.Repository, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "graph.db")
+	repo, err := infra.NewEmbeddedRepository(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	return repo, path
+}
+
+// embeddedResult builds a small analysis result of one package and two functions
+func embeddedResult(projectID string) *core.AnalysisResult {
+	props := func(extra map[string]any) map[string]any {
+		extra["project_id"] = projectID
+		return extra
+	}
+	pkg := core.Node{ID: core.NewID(), Type: core.NodeTypePackage, Name: "app",
+		Properties: props(map[string]any{"path": "example.com/app"})}
+	run := core.Node{ID: core.NewID(), Type: core.NodeTypeFunction, Name: "Run",
+		Properties: props(map[string]any{"package": "example.com/app", "line_start": 3, "tags": []string{"a", "b"}})}
+	helper := core.Node{ID: core.NewID(), Type: core.NodeTypeFunction, Name: "Helper",
+		Properties: props(map[string]any{"package": "example.com/app"})}
+	return &core.AnalysisResult{
+		ProjectID: core.ID(projectID),
+		Nodes:     []core.Node{pkg, run, helper},
+		Relationships: []core.Relationship{
+			{ID: core.NewID(), Type: core.RelationContains, FromNodeID: pkg.ID, ToNodeID: run.ID,
+				Properties: props(map[string]any{})},
+			{ID: core.NewID(), Type: core.RelationCalls, FromNodeID: run.ID, ToNodeID: helper.ID,
+				Properties: props(map[string]any{"line": 5})},
+		},
+		TotalPackages:  1,
+		TotalFunctions: 2,
+		AnalyzedAt:     time.Now(),
+	}
+}
+
+func TestEmbeddedRepository_Nodes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should create, read, update and delete nodes", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		node := &core.Node{ID: core.NewID(), Type: core.NodeTypeFunction, Name: "Run", Path: "/repo/a.go",
+			Properties: map[string]any{"line_start": 3, "exported": true}, CreatedAt: createdAt}
+		require.NoError(t, repo.CreateNode(ctx, node))
+
+		stored, err := repo.GetNode(ctx, node.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Run", stored.Name)
+		assert.Equal(t, "/repo/a.go", stored.Path)
+		assert.Equal(t, int64(3), stored.Properties["line_start"])
+		assert.Equal(t, true, stored.Properties["exported"])
+		assert.True(t, createdAt.Equal(stored.CreatedAt))
+
+		node.Name = "Start"
+		node.Properties = map[string]any{"line_start": 7}
+		require.NoError(t, repo.UpdateNode(ctx, node))
+		stored, err = repo.GetNode(ctx, node.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Start", stored.Name)
+		assert.Equal(t, core.NodeTypeFunction, stored.Type)
+		assert.Equal(t, int64(7), stored.Properties["line_start"])
+		assert.True(t, createdAt.Equal(stored.CreatedAt))
+
+		require.NoError(t, repo.DeleteNode(ctx, node.ID))
+		_, err = repo.GetNode(ctx, node.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("Should delete the relationships of a deleted node", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		result := embeddedResult("project-a")
+		require.NoError(t, repo.ImportAnalysisResult(ctx, result))
+
+		require.NoError(t, repo.DeleteNode(ctx, result.Nodes[1].ID))
+
+		for _, rel := range result.Relationships {
+			_, err := repo.GetRelationship(ctx, rel.ID)
+			assert.Error(t, err)
+		}
+		_, err := repo.GetNode(ctx, result.Nodes[2].ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should skip relationships whose nodes do not exist", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		rel := &core.Relationship{ID: core.NewID(), Type: core.RelationCalls, FromNodeID: core.NewID(), ToNodeID: core.NewID()}
+
+		require.NoError(t, repo.CreateRelationship(ctx, rel))
+
+		_, err := repo.GetRelationship(ctx, rel.ID)
+		assert.Error(t, err)
+	})
+}
+
+func TestEmbeddedRepository_Import(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should find imported nodes and relationships by project", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		require.NoError(t, repo.ImportAnalysisResult(ctx, embeddedResult("project-a")))
+		require.NoError(t, repo.ImportAnalysisResult(ctx, embeddedResult("project-b")))
+
+		functions, err := repo.FindNodesByType(ctx, core.NodeTypeFunction, "project-a")
+		require.NoError(t, err)
+		assert.Len(t, functions, 2)
+		named, err := repo.FindNodesByName(ctx, "Run", "project-b")
+		require.NoError(t, err)
+		require.Len(t, named, 1)
+		assert.Equal(t, []any{"a", "b"}, named[0].Properties["tags"])
+		calls, err := repo.FindRelationshipsByType(ctx, core.RelationCalls, "project-a")
+		require.NoError(t, err)
+		require.Len(t, calls, 1)
+		assert.Equal(t, int64(5), calls[0].Properties["line"])
+	})
+
+	t.Run("Should clear only the target project", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		require.NoError(t, repo.ImportAnalysisResult(ctx, embeddedResult("project-a")))
+		require.NoError(t, repo.ImportAnalysisResult(ctx, embeddedResult("project-b")))
+
+		require.NoError(t, repo.ClearProject(ctx, "project-a"))
+
+		cleared, err := repo.FindNodesByType(ctx, core.NodeTypeFunction, "project-a")
+		require.NoError(t, err)
+		assert.Empty(t, cleared)
+		kept, err := repo.FindNodesByType(ctx, core.NodeTypeFunction, "project-b")
+		require.NoError(t, err)
+		assert.Len(t, kept, 2)
+	})
+
+	t.Run("Should read the stored graph while writes fail", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		require.NoError(t, repo.ImportAnalysisResult(ctx, embeddedResult("project-a")))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					functions, err := repo.FindNodesByType(ctx, core.NodeTypeFunction, "project-a")
+					assert.NoError(t, err)
+					assert.Len(t, functions, 2)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					assert.Error(t, repo.DeleteSnapshot(ctx, "project-a", core.NewID()))
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestEmbeddedRepository_ExecuteQuery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should run Cypher queries against the stored graph", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		require.NoError(t, repo.ImportAnalysisResult(ctx, embeddedResult("project-a")))
+
+		rows, err := repo.ExecuteQuery(ctx, `
+			MATCH (caller:Function {project_id: $project_id})-[r:CALLS]->(callee)
+			RETURN caller.name AS caller, callee.name AS callee, r.line AS line, callee AS node`,
+			map[string]any{"project_id": "project-a"})
+
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "Run", rows[0]["caller"])
+		assert.Equal(t, "Helper", rows[0]["callee"])
+		assert.Equal(t, int64(5), rows[0]["line"])
+		callee, ok := rows[0]["node"].(neo4j.Node)
+		require.True(t, ok)
+		assert.Equal(t, []string{"Function"}, callee.Labels)
+		assert.Equal(t, "example.com/app", callee.Props["package"])
+	})
+
+	t.Run("Should see writes made after earlier queries", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		result := embeddedResult("project-a")
+		require.NoError(t, repo.ImportAnalysisResult(ctx, result))
+		count := func() any {
+			rows, err := repo.ExecuteQuery(ctx, "MATCH (f:Function) RETURN count(f) AS functions", nil)
+			require.NoError(t, err)
+			require.Len(t, rows, 1)
+			return rows[0]["functions"]
+		}
+		assert.Equal(t, int64(2), count())
+
+		require.NoError(t, repo.DeleteNode(ctx, result.Nodes[2].ID))
+
+		assert.Equal(t, int64(1), count())
+	})
+
+	t.Run("Should reject queries that write", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+
+		_, err := repo.ExecuteQuery(ctx, "MATCH (n) DETACH DELETE n", nil)
+
+		assert.ErrorIs(t, err, cypher.ErrUnsupported)
+	})
+}
+
+func TestEmbeddedRepository_StreamQuery(t *testing.T) {
+	ctx := context.Background()
+	query := "MATCH (f:Function {project_id: $project_id}) RETURN f.name AS name ORDER BY name"
+	params := map[string]any{"project_id": "project-a"}
+
+	t.Run("Should pass rows to the callback in order", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		require.NoError(t, repo.ImportAnalysisResult(ctx, embeddedResult("project-a")))
+
+		var names []any
+		err := repo.StreamQuery(ctx, query, params, func(row map[string]any) error {
+			names = append(names, row["name"])
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []any{"Helper", "Run"}, names)
+	})
+
+	t.Run("Should stop without error on ErrStopStream", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		require.NoError(t, repo.ImportAnalysisResult(ctx, embeddedResult("project-a")))
+
+		rows := 0
+		err := repo.StreamQuery(ctx, query, params, func(map[string]any) error {
+			rows++
+			return graph.ErrStopStream
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, rows)
+	})
+
+	t.Run("Should return the errors of the callback unwrapped", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		require.NoError(t, repo.ImportAnalysisResult(ctx, embeddedResult("project-a")))
+		failure := errors.New("disk full")
+
+		err := repo.StreamQuery(ctx, query, params, func(map[string]any) error { return failure })
+
+		assert.Equal(t, failure, err)
+	})
+}
+
+func TestEmbeddedRepository_Persistence(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should keep the graph after the store is reopened", func(t *testing.T) {
+		repo, path := setupEmbeddedTest(t)
+		result := embeddedResult("project-a")
+		require.NoError(t, repo.ImportAnalysisResult(ctx, result))
+		require.NoError(t, repo.Close())
+
+		reopened, err := infra.NewEmbeddedRepository(path)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		node, err := reopened.GetNode(ctx, result.Nodes[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Run", node.Name)
+		assert.Equal(t, int64(3), node.Properties["line_start"])
+	})
+
+	t.Run("Should see writes made through another instance", func(t *testing.T) {
+		repo, path := setupEmbeddedTest(t)
+		require.NoError(t, repo.ImportAnalysisResult(ctx, embeddedResult("project-a")))
+		functions, err := repo.FindNodesByType(ctx, core.NodeTypeFunction, "project-a")
+		require.NoError(t, err)
+		require.Len(t, functions, 2)
+
+		other, err := infra.NewEmbeddedRepository(path)
+		require.NoError(t, err)
+		defer other.Close()
+		require.NoError(t, other.ClearProject(ctx, "project-a"))
+
+		functions, err = repo.FindNodesByType(ctx, core.NodeTypeFunction, "project-a")
+		require.NoError(t, err)
+		assert.Empty(t, functions)
+	})
+
+	t.Run("Should open file URIs as embedded stores", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "graph.db")
+
+		repo, err := infra.NewRepository(&infra.Neo4jConfig{URI: infra.EmbeddedURIScheme + path})
+		require.NoError(t, err)
+		defer repo.Close()
+
+		assert.IsType(t, &infra.EmbeddedRepository{}, repo)
+		assert.FileExists(t, path)
+	})
+}
+
+func TestEmbeddedRepository_Snapshots(t *testing.T) {
+	ctx := context.Background()
+	store := func(t *testing.T, repo graph.Repository, commit string, createdAt time.Time) *core.AnalysisResult {
+		t.Helper()
+		result := embeddedResult("project-a")
+		result.Snapshot = &core.Snapshot{CommitSHA: commit, Root: "/src/" + commit, CreatedAt: createdAt}
+		require.NoError(t, repo.StoreAnalysis(ctx, result))
+		return result
+	}
+
+	t.Run("Should archive the previous analysis as a snapshot", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		first := store(t, repo, "aaa", time.Now().Add(-time.Hour))
+		second := store(t, repo, "bbb", time.Now())
+
+		snapshots, err := repo.ListSnapshots(ctx, "project-a")
+		require.NoError(t, err)
+		require.Len(t, snapshots, 2)
+		assert.Equal(t, "bbb", snapshots[0].CommitSHA)
+		assert.True(t, snapshots[0].Latest)
+		assert.Equal(t, 3, snapshots[0].NodeCount)
+		assert.Equal(t, 2, snapshots[0].RelationshipCount)
+		assert.Equal(t, "aaa", snapshots[1].CommitSHA)
+		assert.Equal(t, "/src/aaa", snapshots[1].Root)
+		assert.False(t, snapshots[1].Latest)
+
+		latest, err := repo.FindNodesByType(ctx, core.NodeTypeFunction, "project-a")
+		require.NoError(t, err)
+		assert.Len(t, latest, 2)
+		archived, err := repo.FindNodesByType(ctx, core.NodeTypeFunction, snapshots[1].Scope())
+		require.NoError(t, err)
+		assert.Len(t, archived, 2)
+		assert.Equal(t, first.Snapshot.ID.String(), archived[0].Properties["snapshot_id"])
+		assert.Equal(t, second.Snapshot.ID, snapshots[0].ID)
+	})
+
+	t.Run("Should store a backfill snapshot without changing the latest graph", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		latest := store(t, repo, "bbb", time.Now())
+		before, err := graph.LoadAnalysisResult(ctx, repo, "project-a")
+		require.NoError(t, err)
+
+		committedAt := time.Now().Add(-24 * time.Hour).UTC().Truncate(time.Second)
+		backfill := embeddedResult("project-a")
+		backfill.Nodes = backfill.Nodes[:2]
+		backfill.Relationships = backfill.Relationships[:1]
+		backfill.Snapshot = &core.Snapshot{CommitSHA: "aaa", CreatedAt: committedAt, Backfill: true}
+		require.NoError(t, repo.StoreAnalysis(ctx, backfill))
+
+		after, err := graph.LoadAnalysisResult(ctx, repo, "project-a")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, before.Nodes, after.Nodes)
+		assert.ElementsMatch(t, before.Relationships, after.Relationships)
+
+		snapshots, err := repo.ListSnapshots(ctx, "project-a")
+		require.NoError(t, err)
+		require.Len(t, snapshots, 2)
+		assert.Equal(t, latest.Snapshot.ID, snapshots[0].ID)
+		assert.True(t, snapshots[0].Latest)
+		assert.Equal(t, "aaa", snapshots[1].CommitSHA)
+		assert.False(t, snapshots[1].Latest)
+		assert.True(t, committedAt.Equal(snapshots[1].CreatedAt))
+		archived, err := repo.FindNodesByType(ctx, core.NodeTypeFunction, snapshots[1].Scope())
+		require.NoError(t, err)
+		assert.Len(t, archived, 1)
+	})
+
+	t.Run("Should delete archived snapshots but not the latest one", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		first := store(t, repo, "aaa", time.Now().Add(-time.Hour))
+		second := store(t, repo, "bbb", time.Now())
+
+		err := repo.DeleteSnapshot(ctx, "project-a", second.Snapshot.ID)
+		assert.ErrorContains(t, err, "is the latest snapshot")
+		err = repo.DeleteSnapshot(ctx, "project-a", core.NewID())
+		assert.ErrorContains(t, err, "not found")
+
+		require.NoError(t, repo.DeleteSnapshot(ctx, "project-a", first.Snapshot.ID))
+		snapshots, err := repo.ListSnapshots(ctx, "project-a")
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+		archived, err := repo.FindNodesByType(ctx, core.NodeTypeFunction,
+			core.SnapshotScope("project-a", first.Snapshot.ID))
+		require.NoError(t, err)
+		assert.Empty(t, archived)
+	})
+
+	t.Run("Should clear all snapshots of a project", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		store(t, repo, "aaa", time.Now().Add(-time.Hour))
+		store(t, repo, "bbb", time.Now())
+
+		require.NoError(t, repo.ClearProject(ctx, "project-a"))
+
+		snapshots, err := repo.ListSnapshots(ctx, "project-a")
+		require.NoError(t, err)
+		assert.Empty(t, snapshots)
+	})
+	t.Run("Should not match snapshots of other projects when clearing an empty project ID", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		store(t, repo, "aaa", time.Now())
+
+		require.NoError(t, repo.ClearProject(ctx, ""))
+
+		snapshots, err := repo.ListSnapshots(ctx, "project-a")
+		require.NoError(t, err)
+		assert.Len(t, snapshots, 1)
+	})
+}
+
+func TestEmbeddedRepository_Archive(t *testing.T) {
+	ctx := context.Background()
+	// roundTrip saves the latest graph of project-a and reads it back
+	roundTrip := func(t *testing.T, repo graph.Repository) *graph.Archive {
+		t.Helper()
+		snapshots, err := repo.ListSnapshots(ctx, "project-a")
+		require.NoError(t, err)
+		stored, err := graph.LoadAnalysisResult(ctx, repo, "project-a")
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, graph.WriteArchive(&buf, graph.NewArchive(stored, snapshots[0])))
+		archive, err := graph.ReadArchive(&buf)
+		require.NoError(t, err)
+		return archive
+	}
+	callers := func(t *testing.T, repo graph.Repository, scope string) []map[string]any {
+		t.Helper()
+		rows, err := repo.ExecuteQuery(ctx, `
+			MATCH (caller:Function {project_id: $project_id})-[:CALLS]->(callee:Function {project_id: $project_id})
+			RETURN caller.name AS caller, callee.name AS callee`,
+			map[string]any{"project_id": scope})
+		require.NoError(t, err)
+		return rows
+	}
+
+	t.Run("Should load a saved graph into a second project", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		result := embeddedResult("project-a")
+		result.Snapshot = &core.Snapshot{CommitSHA: "aaa"}
+		require.NoError(t, repo.StoreAnalysis(ctx, result))
+
+		require.NoError(t, repo.StoreAnalysis(ctx, roundTrip(t, repo).ForProject("project-b")))
+
+		for _, project := range []core.ID{"project-a", "project-b"} {
+			functions, err := repo.FindNodesByType(ctx, core.NodeTypeFunction, project)
+			require.NoError(t, err)
+			assert.Len(t, functions, 2, project)
+			assert.Len(t, callers(t, repo, project.String()), 1, project)
+		}
+	})
+
+	t.Run("Should load a saved graph into the same project as a new snapshot", func(t *testing.T) {
+		repo, _ := setupEmbeddedTest(t)
+		result := embeddedResult("project-a")
+		result.Snapshot = &core.Snapshot{CommitSHA: "aaa"}
+		require.NoError(t, repo.StoreAnalysis(ctx, result))
+
+		loaded := roundTrip(t, repo).ForProject("project-a")
+		loaded.Snapshot = &core.Snapshot{CommitSHA: "aaa"}
+		require.NoError(t, repo.StoreAnalysis(ctx, loaded))
+
+		snapshots, err := repo.ListSnapshots(ctx, "project-a")
+		require.NoError(t, err)
+		require.Len(t, snapshots, 2)
+		for _, snapshot := range snapshots {
+			functions, err := repo.FindNodesByType(ctx, core.NodeTypeFunction, snapshot.Scope())
+			require.NoError(t, err)
+			assert.Len(t, functions, 2, snapshot.Scope())
+			assert.Len(t, callers(t, repo, snapshot.Scope().String()), 1, snapshot.Scope())
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/pkg/logger"
+)
+
+const (
+	nodeTypeSnapshot        core.NodeType = "Snapshot"
+	nodeTypeProjectMetadata core.NodeType = "ProjectMetadata"
+)
+
+// storeSnapshot archives the latest snapshot of the project and imports the
+// analysis result as the new latest snapshot, in one transaction. A backfill
+// snapshot is imported as an archived one instead.
+func (r *EmbeddedRepository) storeSnapshot(_ context.Context, result *core.AnalysisResult) error {
+	snapshot := result.Snapshot
+	prepareSnapshot(result)
+
+	err := r.write(func(w *embeddedWriter) error {
+		if !snapshot.Backfill {
+			if err := archiveLatestSnapshot(w, result.ProjectID); err != nil {
+				return fmt.Errorf("failed to archive latest snapshot: %w", err)
+			}
+		}
+		if err := putNodes(w, result.Nodes); err != nil {
+			return fmt.Errorf("failed to create nodes: %w", err)
+		}
+		if err := putRelationships(w, result.Relationships); err != nil {
+			return fmt.Errorf("failed to create relationships: %w", err)
+		}
+		if !snapshot.Backfill {
+			if err := w.putNode(projectMetadataNode(result)); err != nil {
+				return err
+			}
+		}
+		return w.putNode(snapshotNode(snapshot))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store snapshot: %w", err)
+	}
+
+	logger.Info("stored analysis snapshot",
+		"project_id", result.ProjectID,
+		"snapshot_id", snapshot.ID,
+		"commit", snapshot.CommitSHA)
+	return nil
+}
+
+// prepareSnapshot completes the snapshot metadata of a result and tags every
+// node and relationship with the snapshot it belongs to. The nodes of a
+// backfill snapshot are moved to its snapshot scope.
+func prepareSnapshot(result *core.AnalysisResult) {
+	snapshot := result.Snapshot
+	snapshot.ProjectID = result.ProjectID
+	if snapshot.ID == "" {
+		snapshot.ID = core.NewID()
+	}
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = result.AnalyzedAt
+	}
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = time.Now()
+	}
+	snapshot.NodeCount = len(result.Nodes)
+	snapshot.RelationshipCount = len(result.Relationships)
+	snapshot.Latest = !snapshot.Backfill
+
+	tag := func(props map[string]any) map[string]any {
+		if props == nil {
+			props = make(map[string]any)
+		}
+		props["snapshot_id"] = snapshot.ID.String()
+		if snapshot.Backfill {
+			props["project_id"] = snapshot.Scope().String()
+		}
+		return props
+	}
+	for i := range result.Nodes {
+		result.Nodes[i].Properties = tag(result.Nodes[i].Properties)
+	}
+	for i := range result.Relationships {
+		result.Relationships[i].Properties = tag(result.Relationships[i].Properties)
+	}
+}
+
+// archiveLatestSnapshot moves the nodes of the latest snapshot to their
+// snapshot scope. Project data stored before snapshots existed is removed.
+func archiveLatestSnapshot(w *embeddedWriter, projectID core.ID) error {
+	project := projectID.String()
+	latest := w.graph.sortedNodes(func(n *core.Node) bool {
+		return n.Type == nodeTypeSnapshot &&
+			hasProperty(n.Properties, "project", project) && hasProperty(n.Properties, "scope", project)
+	})
+	inProject := func(props map[string]any) bool { return hasProperty(props, "project_id", project) }
+	nodes := w.graph.sortedNodes(func(n *core.Node) bool {
+		return n.Type != nodeTypeProjectMetadata && inProject(n.Properties)
+	})
+
+	if len(latest) == 0 {
+		for _, node := range nodes {
+			if err := w.detachDeleteNode(node.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	scope := core.SnapshotScope(projectID, latest[0].ID).String()
+	for _, rel := range w.graph.sortedRelationships(func(rel *core.Relationship) bool { return inProject(rel.Properties) }) {
+		archived := copyRelationship(rel)
+		archived.Properties["project_id"] = scope
+		if err := w.putRelationship(archived); err != nil {
+			return err
+		}
+	}
+	for _, node := range append(nodes, latest[0]) {
+		archived := copyNode(node)
+		if node.Type == nodeTypeSnapshot {
+			archived.Properties["scope"] = scope
+		} else {
+			archived.Properties["project_id"] = scope
+		}
+		if err := w.putNode(archived); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotNode records the snapshot metadata
+func snapshotNode(snapshot *core.Snapshot) *core.Node {
+	return &core.Node{
+		ID:        snapshot.ID,
+		Type:      nodeTypeSnapshot,
+		CreatedAt: snapshot.CreatedAt.UTC(),
+		Properties: map[string]any{
+			"project":            snapshot.ProjectID.String(),
+			"scope":              snapshot.Scope().String(),
+			"commit_sha":         snapshot.CommitSHA,
+			"branch":             snapshot.Branch,
+			"config_hash":        snapshot.ConfigHash,
+			"root":               snapshot.Root,
+			"created_at":         snapshot.CreatedAt.UTC(),
+			"node_count":         int64(snapshot.NodeCount),
+			"relationship_count": int64(snapshot.RelationshipCount),
+		},
+	}
+}
+
+// ListSnapshots returns the snapshots of a project, newest first
+func (r *EmbeddedRepository) ListSnapshots(_ context.Context, projectID core.ID) ([]*core.Snapshot, error) {
+	var snapshots []*core.Snapshot
+	err := r.read(func(g *memoryGraph) error {
+		nodes := g.sortedNodes(func(n *core.Node) bool {
+			return n.Type == nodeTypeSnapshot && hasProperty(n.Properties, "project", projectID.String())
+		})
+		for _, node := range nodes {
+			props := copyProperties(node.Properties)
+			props["id"] = node.ID.String()
+			snapshots = append(snapshots, recordToSnapshot(projectID, props))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// DeleteSnapshot removes an archived snapshot and all of its nodes. The latest
+// snapshot cannot be deleted; use ClearProject to remove the whole project.
+func (r *EmbeddedRepository) DeleteSnapshot(_ context.Context, projectID, snapshotID core.ID) error {
+	scope := core.SnapshotScope(projectID, snapshotID).String()
+	err := r.write(func(w *embeddedWriter) error {
+		snapshot := w.graph.nodes[snapshotID]
+		if snapshot == nil || snapshot.Type != nodeTypeSnapshot ||
+			!hasProperty(snapshot.Properties, "project", projectID.String()) {
+			return fmt.Errorf("snapshot %s not found", snapshotID)
+		}
+		if !hasProperty(snapshot.Properties, "scope", scope) {
+			return fmt.Errorf("snapshot %s is the latest snapshot", snapshotID)
+		}
+
+		nodes := w.graph.sortedNodes(func(n *core.Node) bool {
+			return hasProperty(n.Properties, "project_id", scope)
+		})
+		for _, node := range append(nodes, snapshot) {
+			if err := w.detachDeleteNode(node.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot %s: %w", snapshotID, err)
+	}
+
+	logger.Info("deleted snapshot", "project_id", projectID, "snapshot_id", snapshotID)
+	return nil
+}
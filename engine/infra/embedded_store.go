@@ -0,0 +1,437 @@
+package infra
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/cypher"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	embeddedSchemaVersion = 1
+	embeddedLockTimeout   = 30 * time.Second
+)
+
+var (
+	bucketMeta          = []byte("meta")
+	bucketNodes         = []byte("nodes")
+	bucketRelationships = []byte("relationships")
+	keyGeneration       = []byte("generation")
+	keySchemaVersion    = []byte("schema_version")
+)
+
+// memoryGraph is the in-memory copy of an embedded store. Node and
+// relationship properties hold the same values Neo4j would return, including
+// created_at.
+type memoryGraph struct {
+	nodes    map[core.ID]*core.Node
+	rels     map[core.ID]*core.Relationship
+	attached map[core.ID]map[core.ID]bool // Node ID to the IDs of its relationships
+	queryMu  sync.Mutex
+	query    *cypher.Graph // Built on the first query after a change
+}
+
+func newMemoryGraph() *memoryGraph {
+	return &memoryGraph{
+		nodes:    make(map[core.ID]*core.Node),
+		rels:     make(map[core.ID]*core.Relationship),
+		attached: make(map[core.ID]map[core.ID]bool),
+	}
+}
+
+func (g *memoryGraph) putNode(node *core.Node) {
+	g.nodes[node.ID] = node
+	g.query = nil
+}
+
+// putRelationship adds a relationship when both of its nodes exist
+func (g *memoryGraph) putRelationship(rel *core.Relationship) bool {
+	if g.nodes[rel.FromNodeID] == nil || g.nodes[rel.ToNodeID] == nil {
+		return false
+	}
+	g.rels[rel.ID] = rel
+	g.query = nil
+	for _, id := range []core.ID{rel.FromNodeID, rel.ToNodeID} {
+		if g.attached[id] == nil {
+			g.attached[id] = make(map[core.ID]bool)
+		}
+		g.attached[id][rel.ID] = true
+	}
+	return true
+}
+
+func (g *memoryGraph) deleteRelationship(id core.ID) bool {
+	rel := g.rels[id]
+	if rel == nil {
+		return false
+	}
+	delete(g.rels, id)
+	g.query = nil
+	delete(g.attached[rel.FromNodeID], id)
+	delete(g.attached[rel.ToNodeID], id)
+	return true
+}
+
+// detachDeleteNode removes a node with its relationships and returns the IDs
+// of the removed relationships
+func (g *memoryGraph) detachDeleteNode(id core.ID) []core.ID {
+	var removed []core.ID
+	for relID := range g.attached[id] {
+		if g.deleteRelationship(relID) {
+			removed = append(removed, relID)
+		}
+	}
+	delete(g.attached, id)
+	delete(g.nodes, id)
+	g.query = nil
+	return removed
+}
+
+// queryGraph returns the graph indexed for Cypher queries. Readers share the
+// index, so building it takes its own lock.
+func (g *memoryGraph) queryGraph() *cypher.Graph {
+	g.queryMu.Lock()
+	defer g.queryMu.Unlock()
+	if g.query == nil {
+		g.query = cypher.NewGraph(
+			g.sortedNodes(func(*core.Node) bool { return true }),
+			g.sortedRelationships(func(*core.Relationship) bool { return true }),
+		)
+	}
+	return g.query
+}
+
+// sortedNodes returns the nodes matching keep, ordered by ID
+func (g *memoryGraph) sortedNodes(keep func(*core.Node) bool) []*core.Node {
+	var nodes []*core.Node
+	for _, node := range g.nodes {
+		if keep(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// sortedRelationships returns the relationships matching keep, ordered by ID
+func (g *memoryGraph) sortedRelationships(keep func(*core.Relationship) bool) []*core.Relationship {
+	var rels []*core.Relationship
+	for _, rel := range g.rels {
+		if keep(rel) {
+			rels = append(rels, rel)
+		}
+	}
+	sort.Slice(rels, func(i, j int) bool { return rels[i].ID < rels[j].ID })
+	return rels
+}
+
+// embeddedWriter stages the writes of one transaction to the file and the
+// in-memory graph
+type embeddedWriter struct {
+	tx    *bolt.Tx
+	graph *memoryGraph
+}
+
+func (w *embeddedWriter) putNode(node *core.Node) error {
+	data, err := encodeEmbeddedRecord(&embeddedRecord{
+		Type: string(node.Type), Name: node.Name, Path: node.Path, Properties: node.Properties,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode node %s: %w", node.ID, err)
+	}
+	if err := w.tx.Bucket(bucketNodes).Put([]byte(node.ID), data); err != nil {
+		return err
+	}
+	w.graph.putNode(node)
+	return nil
+}
+
+func (w *embeddedWriter) putRelationship(rel *core.Relationship) error {
+	if !w.graph.putRelationship(rel) {
+		return nil
+	}
+	data, err := encodeEmbeddedRecord(&embeddedRecord{
+		Type: string(rel.Type), From: rel.FromNodeID.String(), To: rel.ToNodeID.String(), Properties: rel.Properties,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode relationship %s: %w", rel.ID, err)
+	}
+	return w.tx.Bucket(bucketRelationships).Put([]byte(rel.ID), data)
+}
+
+func (w *embeddedWriter) deleteRelationship(id core.ID) error {
+	if !w.graph.deleteRelationship(id) {
+		return nil
+	}
+	return w.tx.Bucket(bucketRelationships).Delete([]byte(id))
+}
+
+func (w *embeddedWriter) detachDeleteNode(id core.ID) error {
+	for _, relID := range w.graph.detachDeleteNode(id) {
+		if err := w.tx.Bucket(bucketRelationships).Delete([]byte(relID)); err != nil {
+			return err
+		}
+	}
+	return w.tx.Bucket(bucketNodes).Delete([]byte(id))
+}
+
+// openEmbeddedFile opens the store, creating it when writable. The file lock
+// is held until the returned database is closed.
+func openEmbeddedFile(path string, readOnly bool) (*bolt.DB, error) {
+	if !readOnly {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create store directory: %w", err)
+		}
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: embeddedLockTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded store %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// initEmbeddedFile creates the buckets of a new store and checks the schema
+// version of an existing one
+func initEmbeddedFile(tx *bolt.Tx) error {
+	meta, err := tx.CreateBucketIfNotExists(bucketMeta)
+	if err != nil {
+		return err
+	}
+	for _, name := range [][]byte{bucketNodes, bucketRelationships} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	version := meta.Get(keySchemaVersion)
+	if version == nil {
+		return meta.Put(keySchemaVersion, encodeUint(embeddedSchemaVersion))
+	}
+	if decodeUint(version) != embeddedSchemaVersion {
+		return fmt.Errorf("unsupported embedded store schema version %d (expected %d)",
+			decodeUint(version), embeddedSchemaVersion)
+	}
+	return nil
+}
+
+// storeGeneration returns the write counter of the store
+func storeGeneration(tx *bolt.Tx) uint64 {
+	return decodeUint(tx.Bucket(bucketMeta).Get(keyGeneration))
+}
+
+// loadMemoryGraph reads the whole store
+func loadMemoryGraph(tx *bolt.Tx) (*memoryGraph, error) {
+	graph := newMemoryGraph()
+	err := tx.Bucket(bucketNodes).ForEach(func(key, value []byte) error {
+		record, err := decodeEmbeddedRecord(value)
+		if err != nil {
+			return fmt.Errorf("failed to decode node %s: %w", key, err)
+		}
+		graph.putNode(&core.Node{
+			ID:         core.ID(key),
+			Type:       core.NodeType(record.Type),
+			Name:       record.Name,
+			Path:       record.Path,
+			Properties: record.Properties,
+			CreatedAt:  createdAt(record.Properties),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Bucket(bucketRelationships).ForEach(func(key, value []byte) error {
+		record, err := decodeEmbeddedRecord(value)
+		if err != nil {
+			return fmt.Errorf("failed to decode relationship %s: %w", key, err)
+		}
+		graph.putRelationship(&core.Relationship{
+			ID:         core.ID(key),
+			Type:       core.RelationType(record.Type),
+			FromNodeID: core.ID(record.From),
+			ToNodeID:   core.ID(record.To),
+			Properties: record.Properties,
+			CreatedAt:  createdAt(record.Properties),
+		})
+		return nil
+	})
+	return graph, err
+}
+
+func createdAt(props map[string]any) time.Time {
+	t, _ := props["created_at"].(time.Time)
+	return t
+}
+
+func encodeUint(v uint64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, v)
+	return data
+}
+
+func decodeUint(data []byte) uint64 {
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// embeddedRecord is the stored form of a node or relationship
+type embeddedRecord struct {
+	Type       string                   `json:"type"`
+	Name       string                   `json:"name,omitempty"`
+	Path       string                   `json:"path,omitempty"`
+	From       string                   `json:"from,omitempty"`
+	To         string                   `json:"to,omitempty"`
+	Properties map[string]any           `json:"-"`
+	Values     map[string]embeddedValue `json:"properties,omitempty"`
+}
+
+// embeddedValue keeps the type of a property, which JSON alone would lose
+// for integers and times. Lists hold embeddedValue elements.
+type embeddedValue struct {
+	Kind  string          `json:"k"`
+	Value json.RawMessage `json:"v"`
+}
+
+const (
+	kindString = "string"
+	kindInt    = "int"
+	kindFloat  = "float"
+	kindBool   = "bool"
+	kindTime   = "time"
+	kindList   = "list"
+)
+
+// storedProperties converts properties to the values Neo4j would return for
+// them: scalars, times and lists as []any
+func storedProperties(props map[string]any) map[string]any {
+	stored := make(map[string]any, len(props))
+	for name, value := range serializeProperties(props) {
+		if value == nil {
+			continue
+		}
+		switch list := value.(type) {
+		case []string:
+			value = toAnyList(list)
+		case []int64:
+			value = toAnyList(list)
+		case []float64:
+			value = toAnyList(list)
+		case []bool:
+			value = toAnyList(list)
+		}
+		stored[name] = value
+	}
+	return stored
+}
+
+func toAnyList[T any](list []T) []any {
+	values := make([]any, len(list))
+	for i, item := range list {
+		values[i] = item
+	}
+	return values
+}
+
+func encodeEmbeddedRecord(record *embeddedRecord) ([]byte, error) {
+	record.Values = make(map[string]embeddedValue, len(record.Properties))
+	for name, value := range record.Properties {
+		encoded, err := encodeEmbeddedValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", name, err)
+		}
+		record.Values[name] = encoded
+	}
+	return json.Marshal(record)
+}
+
+func encodeEmbeddedValue(value any) (embeddedValue, error) {
+	var kind string
+	switch v := value.(type) {
+	case string:
+		kind = kindString
+	case int64:
+		kind = kindInt
+	case float64:
+		kind = kindFloat
+	case bool:
+		kind = kindBool
+	case time.Time:
+		kind = kindTime
+	case []any:
+		items := make([]embeddedValue, len(v))
+		for i, item := range v {
+			encoded, err := encodeEmbeddedValue(item)
+			if err != nil {
+				return embeddedValue{}, err
+			}
+			items[i] = encoded
+		}
+		value, kind = items, kindList
+	default:
+		return embeddedValue{}, fmt.Errorf("unsupported type %T", value)
+	}
+	data, err := json.Marshal(value)
+	return embeddedValue{Kind: kind, Value: data}, err
+}
+
+func decodeEmbeddedRecord(data []byte) (*embeddedRecord, error) {
+	var record embeddedRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	record.Properties = make(map[string]any, len(record.Values))
+	for name, value := range record.Values {
+		decoded, err := decodeEmbeddedValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", name, err)
+		}
+		record.Properties[name] = decoded
+	}
+	return &record, nil
+}
+
+func decodeEmbeddedValue(value embeddedValue) (any, error) {
+	switch value.Kind {
+	case kindString:
+		return decodeAs[string](value.Value)
+	case kindInt:
+		return decodeAs[int64](value.Value)
+	case kindFloat:
+		return decodeAs[float64](value.Value)
+	case kindBool:
+		return decodeAs[bool](value.Value)
+	case kindTime:
+		return decodeAs[time.Time](value.Value)
+	case kindList:
+		var items []embeddedValue
+		if err := json.Unmarshal(value.Value, &items); err != nil {
+			return nil, err
+		}
+		list := make([]any, len(items))
+		for i, item := range items {
+			decoded, err := decodeEmbeddedValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = decoded
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("unknown property kind %q", value.Kind)
+	}
+}
+
+func decodeAs[T any](data json.RawMessage) (any, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
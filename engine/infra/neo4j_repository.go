@@ -2,9 +2,7 @@ package infra
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"reflect"
 	"sync"
 	"time"
 
@@ -64,8 +62,13 @@ func NewNeo4jRepository(config *Neo4jConfig) (graph.Repository, error) {
 	}, nil
 }
 
-// StoreAnalysis stores the complete analysis result in Neo4j
+// StoreAnalysis stores the complete analysis result in Neo4j. When the result
+// carries a snapshot, the previous analysis is archived instead of deleted.
 func (r *Neo4jRepository) StoreAnalysis(ctx context.Context, result *core.AnalysisResult) error {
+	if result.Snapshot != nil {
+		return r.storeSnapshot(ctx, result)
+	}
+
 	// Clear existing data first
 	if err := r.ClearProject(ctx, result.ProjectID); err != nil {
 		return fmt.Errorf("failed to clear existing data: %w", err)
@@ -150,7 +153,7 @@ func (r *Neo4jRepository) CreateNode(ctx context.Context, node *core.Node) error
 
 	// Serialize complex properties
 	if node.Properties != nil {
-		serializedProps := r.serializeComplexProperties(node.Properties)
+		serializedProps := serializeProperties(node.Properties)
 		for k, v := range serializedProps {
 			params[k] = v
 		}
@@ -222,7 +225,7 @@ func (r *Neo4jRepository) CreateNodes(ctx context.Context, nodes []core.Node) er
 
 					// Serialize complex properties
 					if node.Properties != nil {
-						serializedProps := r.serializeComplexProperties(node.Properties)
+						serializedProps := serializeProperties(node.Properties)
 						for k, v := range serializedProps {
 							params[k] = v
 						}
@@ -305,18 +308,32 @@ func (r *Neo4jRepository) UpdateNode(ctx context.Context, node *core.Node) error
 	})
 	defer session.Close(ctx)
 
+	// Replace the properties, keeping the creation time of the node
+	params := map[string]any{
+		"id":   node.ID.String(),
+		"name": node.Name,
+		"path": node.Path,
+	}
+	if node.Properties != nil {
+		serializedProps := serializeProperties(node.Properties)
+		for k, v := range serializedProps {
+			params[k] = v
+		}
+	}
+
 	query := `
 		MATCH (n)
 		WHERE n.id = $id
-		SET n.name = $name, n.path = $path
+		WITH n, n.created_at AS created_at
+		SET n = $props
+		SET n.created_at = created_at
 		RETURN n
 	`
 
 	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		result, err := tx.Run(ctx, query, map[string]any{
-			"id":   node.ID.String(),
-			"name": node.Name,
-			"path": node.Path,
+			"id":    node.ID.String(),
+			"props": params,
 		})
 		if err != nil {
 			return nil, err
@@ -374,7 +391,7 @@ func (r *Neo4jRepository) CreateRelationship(ctx context.Context, rel *core.Rela
 
 	// Serialize complex properties
 	if rel.Properties != nil {
-		serializedProps := r.serializeComplexProperties(rel.Properties)
+		serializedProps := serializeProperties(rel.Properties)
 		for k, v := range serializedProps {
 			relProps[k] = v
 		}
@@ -450,7 +467,7 @@ func (r *Neo4jRepository) CreateRelationships(ctx context.Context, rels []core.R
 
 					// Serialize complex properties
 					if rel.Properties != nil {
-						serializedProps := r.serializeComplexProperties(rel.Properties)
+						serializedProps := serializeProperties(rel.Properties)
 						for k, v := range serializedProps {
 							relData[k] = v
 						}
@@ -562,17 +579,30 @@ func (r *Neo4jRepository) DeleteRelationship(ctx context.Context, id core.ID) er
 	return nil
 }
 
-// ExecuteQuery runs a Cypher query and returns results
+// ExecuteQuery runs a Cypher query and returns results. Queries of a
+// graph.WithReadOnly context run in a read transaction.
 func (r *Neo4jRepository) ExecuteQuery(
 	ctx context.Context,
 	query string,
 	params map[string]any,
 ) ([]map[string]any, error) {
-	session := r.driver.NewSession(ctx, neo4j.SessionConfig{
-		DatabaseName: r.config.Database,
-	})
+	session := r.querySession(ctx)
 	defer session.Close(ctx)
 
+	if graph.IsReadOnly(ctx) {
+		records, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			result, err := tx.Run(ctx, query, params)
+			if err != nil {
+				return nil, err
+			}
+			return result.Collect(ctx)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute query: %w", err)
+		}
+		return recordMaps(records.([]*neo4j.Record)), nil
+	}
+
 	result, err := session.Run(ctx, query, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -582,21 +612,158 @@ func (r *Neo4jRepository) ExecuteQuery(
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect results: %w", err)
 	}
+	return recordMaps(records), nil
+}
+
+// StreamQuery runs a Cypher query and passes each record to fn as the driver
+// reads it. Records that are not read when fn stops the query are discarded.
+// Queries of a graph.WithReadOnly context run in a read transaction, which is
+// not retried since fn may already have seen rows.
+func (r *Neo4jRepository) StreamQuery(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+	fn graph.RowFunc,
+) error {
+	session := r.querySession(ctx)
+	defer session.Close(ctx)
+
+	var result neo4j.ResultWithContext
+	var err error
+	if graph.IsReadOnly(ctx) {
+		tx, txErr := session.BeginTransaction(ctx)
+		if txErr != nil {
+			return fmt.Errorf("failed to begin transaction: %w", txErr)
+		}
+		defer tx.Close(ctx) // Rolls back; the transaction only reads
+		result, err = tx.Run(ctx, query, params)
+	} else {
+		result, err = session.Run(ctx, query, params)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	for result.Next(ctx) {
+		if err := fn(recordMap(result.Record())); err != nil {
+			return streamError(err)
+		}
+	}
+	if err := result.Err(); err != nil {
+		return fmt.Errorf("failed to read results: %w", err)
+	}
+	return nil
+}
+
+// querySession opens a session for a query, in read access mode for the
+// queries of a graph.WithReadOnly context
+func (r *Neo4jRepository) querySession(ctx context.Context) neo4j.SessionWithContext {
+	config := neo4j.SessionConfig{DatabaseName: r.config.Database}
+	if graph.IsReadOnly(ctx) {
+		config.AccessMode = neo4j.AccessModeRead
+	}
+	return r.driver.NewSession(ctx, config)
+}
 
-	// Convert records to map
+// recordMaps converts records to maps of column names to values
+func recordMaps(records []*neo4j.Record) []map[string]any {
 	var results []map[string]any
 	for _, record := range records {
-		recordMap := make(map[string]any)
-		for _, key := range record.Keys {
-			val, ok := record.Get(key)
-			if ok {
-				recordMap[key] = val
-			}
+		results = append(results, recordMap(record))
+	}
+	return results
+}
+
+// recordMap converts a record to a map of column names to values
+func recordMap(record *neo4j.Record) map[string]any {
+	values := make(map[string]any)
+	for _, key := range record.Keys {
+		val, ok := record.Get(key)
+		if ok {
+			values[key] = val
 		}
-		results = append(results, recordMap)
 	}
+	return values
+}
+
+// ExplainQuery returns the plan Neo4j would run a query with
+func (r *Neo4jRepository) ExplainQuery(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+) (*graph.QueryPlan, error) {
+	session := r.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: r.config.Database,
+	})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, "EXPLAIN "+query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	summary, err := result.Consume(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	if summary.Plan() == nil {
+		return nil, fmt.Errorf("failed to explain query: no plan returned")
+	}
+	return neo4jQueryPlan(summary.Plan()), nil
+}
 
-	return results, nil
+// ProfileQuery runs a query with PROFILE and returns its rows and the plan
+// with the rows and database hits of each operator
+func (r *Neo4jRepository) ProfileQuery(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+) (*graph.QueryPlan, []map[string]any, error) {
+	session := r.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: r.config.Database,
+	})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, "PROFILE "+query, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to profile query: %w", err)
+	}
+	records, err := result.Collect(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to collect results: %w", err)
+	}
+	summary, err := result.Consume(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to profile query: %w", err)
+	}
+	if summary.Profile() == nil {
+		return nil, nil, fmt.Errorf("failed to profile query: no profile returned")
+	}
+	return neo4jProfiledPlan(summary.Profile()), recordMaps(records), nil
+}
+
+func neo4jQueryPlan(plan neo4j.Plan) *graph.QueryPlan {
+	converted := &graph.QueryPlan{
+		Operator:    plan.Operator(),
+		Identifiers: plan.Identifiers(),
+	}
+	converted.Details, _ = plan.Arguments()["Details"].(string)
+	for _, child := range plan.Children() {
+		converted.Children = append(converted.Children, neo4jQueryPlan(child))
+	}
+	return converted
+}
+
+func neo4jProfiledPlan(plan neo4j.ProfiledPlan) *graph.QueryPlan {
+	converted := &graph.QueryPlan{
+		Operator:    plan.Operator(),
+		Identifiers: plan.Identifiers(),
+		Rows:        plan.Records(),
+		DBHits:      plan.DbHits(),
+	}
+	converted.Details, _ = plan.Arguments()["Details"].(string)
+	for _, child := range plan.Children() {
+		converted.Children = append(converted.Children, neo4jProfiledPlan(child))
+	}
+	return converted
 }
 
 // ImportAnalysisResult imports an entire analysis result with optimized batch processing
@@ -695,7 +862,7 @@ func (r *Neo4jRepository) createNodesInTransaction(
 				}
 				// Serialize complex properties
 				if node.Properties != nil {
-					serializedProps := r.serializeComplexProperties(node.Properties)
+					serializedProps := serializeProperties(node.Properties)
 					for k, v := range serializedProps {
 						params[k] = v
 					}
@@ -767,7 +934,7 @@ func (r *Neo4jRepository) createRelationshipsInTransaction(
 
 				// Serialize complex properties
 				if rel.Properties != nil {
-					serializedProps := r.serializeComplexProperties(rel.Properties)
+					serializedProps := serializeProperties(rel.Properties)
 					for k, v := range serializedProps {
 						relData[k] = v
 					}
@@ -857,6 +1024,7 @@ func (r *Neo4jRepository) createSinglePropertyIndexes(ctx context.Context, sessi
 		{"Constant", "name"},
 		{"Import", "path"},
 		{"ProjectMetadata", "project_id"},
+		{"Snapshot", "project"},
 	}
 
 	for _, idx := range singleIndexes {
@@ -1011,7 +1179,8 @@ func (r *Neo4jRepository) createProjectMetadata(ctx context.Context, result *cor
 	return err
 }
 
-// ClearProject removes all nodes and relationships for a specific project
+// ClearProject removes all nodes and relationships for a specific project,
+// including every archived snapshot
 func (r *Neo4jRepository) ClearProject(ctx context.Context, projectID core.ID) error {
 	session := r.driver.NewSession(ctx, neo4j.SessionConfig{
 		DatabaseName: r.config.Database,
@@ -1020,13 +1189,17 @@ func (r *Neo4jRepository) ClearProject(ctx context.Context, projectID core.ID) e
 
 	// Only delete nodes and relationships for the specified project_id
 	query := `
-		MATCH (n {project_id: $project_id})
+		MATCH (n)
+		WHERE n.project_id = $project_id
+		   OR n.project_id STARTS WITH $snapshot_prefix
+		   OR (n:Snapshot AND n.project = $project_id)
 		DETACH DELETE n
 	`
 
 	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		result, err := tx.Run(ctx, query, map[string]any{
-			"project_id": projectID.String(),
+			"project_id":      projectID.String(),
+			"snapshot_prefix": core.SnapshotScope(projectID, "").String(),
 		})
 		if err != nil {
 			return nil, err
@@ -1343,86 +1516,3 @@ func (r *Neo4jRepository) recordToRelationship(record *neo4j.Record) (*core.Rela
 
 	return rel, nil
 }
-
-// serializeComplexProperties converts complex properties to JSON strings for Neo4j compatibility
-func (r *Neo4jRepository) serializeComplexProperties(properties map[string]any) map[string]any {
-	if properties == nil {
-		return nil
-	}
-	serialized := make(map[string]any)
-	for k, v := range properties {
-		serialized[k] = r.serializeValue(v)
-	}
-	return serialized
-}
-
-// serializeValue recursively serializes a single value
-func (r *Neo4jRepository) serializeValue(v any) any {
-	if v == nil {
-		return nil
-	}
-	// Convert time values to UTC
-	if t, ok := v.(time.Time); ok {
-		return t.UTC()
-	}
-	// Check if it's a supported primitive type
-	if primitiveVal := r.handlePrimitiveTypes(v); primitiveVal != nil {
-		return primitiveVal
-	}
-	// Check if it's a supported array type
-	if arrayVal := r.handleArrayTypes(v); arrayVal != nil {
-		return arrayVal
-	}
-	// Everything else needs to be serialized to JSON
-	return r.serializeToJSON(v)
-}
-
-// handlePrimitiveTypes handles Neo4j-supported primitive types
-func (r *Neo4jRepository) handlePrimitiveTypes(v any) any {
-	switch val := v.(type) {
-	case bool, int64, float64, string:
-		return v
-	case int, int8, int16, int32:
-		return reflect.ValueOf(v).Int()
-	case uint, uint8, uint16, uint32:
-		return r.handleUintConversion(reflect.ValueOf(v).Uint(), v)
-	case uint64:
-		return r.handleUintConversion(reflect.ValueOf(v).Uint(), v)
-	case float32:
-		return float64(val)
-	default:
-		return nil
-	}
-}
-
-// handleArrayTypes handles Neo4j-supported array types
-func (r *Neo4jRepository) handleArrayTypes(v any) any {
-	switch val := v.(type) {
-	case []bool, []int64, []float64, []string:
-		return v
-	case []int:
-		result := make([]int64, len(val))
-		for i, item := range val {
-			result[i] = int64(item)
-		}
-		return result
-	default:
-		return nil
-	}
-}
-
-// handleUintConversion safely converts uint values to int64, serializing large values
-func (r *Neo4jRepository) handleUintConversion(uintVal uint64, originalVal any) any {
-	if uintVal > 9223372036854775807 { // max int64
-		return r.serializeToJSON(originalVal)
-	}
-	return int64(uintVal)
-}
-
-// serializeToJSON serializes a value to JSON string
-func (r *Neo4jRepository) serializeToJSON(v any) string {
-	if jsonBytes, err := json.Marshal(v); err == nil {
-		return string(jsonBytes)
-	}
-	return fmt.Sprintf("%v", v)
-}
@@ -0,0 +1,257 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/pkg/logger"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// storeSnapshot archives the latest snapshot of the project and imports the
+// analysis result as the new latest snapshot, in one transaction, so a failed
+// import leaves the previous latest snapshot in place. A backfill snapshot is
+// imported as an archived one instead.
+func (r *Neo4jRepository) storeSnapshot(ctx context.Context, result *core.AnalysisResult) error {
+	snapshot := result.Snapshot
+	prepareSnapshot(result)
+
+	if err := r.ensureIndexes(ctx); err != nil {
+		logger.Warn("failed to create indexes, continuing anyway", "error", err)
+	}
+
+	session := r.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: r.config.Database,
+	})
+	defer session.Close(ctx)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if !snapshot.Backfill {
+			if err := r.archiveLatestSnapshot(ctx, tx, result.ProjectID); err != nil {
+				return nil, fmt.Errorf("failed to archive latest snapshot: %w", err)
+			}
+		}
+		if err := r.createNodesInTransaction(ctx, tx, result.Nodes); err != nil {
+			return nil, fmt.Errorf("failed to create nodes: %w", err)
+		}
+		if err := r.createRelationshipsInTransaction(ctx, tx, result.Relationships); err != nil {
+			return nil, fmt.Errorf("failed to create relationships: %w", err)
+		}
+		if err := r.createSnapshotNode(ctx, tx, snapshot); err != nil {
+			return nil, fmt.Errorf("failed to record snapshot: %w", err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store snapshot: %w", err)
+	}
+
+	if !snapshot.Backfill {
+		if err := r.createProjectMetadata(ctx, result); err != nil {
+			logger.Warn("failed to create project metadata", "error", err)
+		}
+	}
+
+	logger.Info("stored analysis snapshot",
+		"project_id", result.ProjectID,
+		"snapshot_id", snapshot.ID,
+		"commit", snapshot.CommitSHA)
+	return nil
+}
+
+// archiveLatestSnapshot moves the nodes of the latest snapshot to their
+// snapshot scope. Project data stored before snapshots existed is removed.
+func (r *Neo4jRepository) archiveLatestSnapshot(
+	ctx context.Context,
+	tx neo4j.ManagedTransaction,
+	projectID core.ID,
+) error {
+	params := map[string]any{"project_id": projectID.String()}
+	result, err := tx.Run(ctx, `
+		MATCH (s:Snapshot {project: $project_id, scope: $project_id})
+		RETURN s.id AS id
+	`, params)
+	if err != nil {
+		return err
+	}
+	records, err := result.Collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		_, err := tx.Run(ctx, `
+			MATCH (n {project_id: $project_id})
+			WHERE NOT n:ProjectMetadata
+			DETACH DELETE n
+		`, params)
+		return err
+	}
+
+	snapshotID, _ := records[0].Get("id")
+	params["snapshot_id"] = snapshotID
+	params["scope"] = core.SnapshotScope(projectID, core.ID(fmt.Sprint(snapshotID))).String()
+	queries := []string{
+		`MATCH ()-[r {project_id: $project_id}]->() SET r.project_id = $scope`,
+		`MATCH (n {project_id: $project_id}) WHERE NOT n:ProjectMetadata SET n.project_id = $scope`,
+		`MATCH (s:Snapshot {project: $project_id, id: $snapshot_id}) SET s.scope = $scope`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Run(ctx, query, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createSnapshotNode records the snapshot metadata
+func (r *Neo4jRepository) createSnapshotNode(
+	ctx context.Context,
+	tx neo4j.ManagedTransaction,
+	snapshot *core.Snapshot,
+) error {
+	query := `
+		CREATE (s:Snapshot {
+			id: $id,
+			project: $project_id,
+			scope: $scope,
+			commit_sha: $commit_sha,
+			branch: $branch,
+			config_hash: $config_hash,
+			root: $root,
+			created_at: $created_at,
+			node_count: $node_count,
+			relationship_count: $relationship_count
+		})
+	`
+	_, err := tx.Run(ctx, query, map[string]any{
+		"id":                 snapshot.ID.String(),
+		"project_id":         snapshot.ProjectID.String(),
+		"scope":              snapshot.Scope().String(),
+		"commit_sha":         snapshot.CommitSHA,
+		"branch":             snapshot.Branch,
+		"config_hash":        snapshot.ConfigHash,
+		"root":               snapshot.Root,
+		"created_at":         snapshot.CreatedAt.UTC(),
+		"node_count":         snapshot.NodeCount,
+		"relationship_count": snapshot.RelationshipCount,
+	})
+	return err
+}
+
+// ListSnapshots returns the snapshots of a project, newest first
+func (r *Neo4jRepository) ListSnapshots(ctx context.Context, projectID core.ID) ([]*core.Snapshot, error) {
+	session := r.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: r.config.Database,
+	})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (s:Snapshot {project: $project_id})
+		RETURN s
+		ORDER BY s.created_at DESC
+	`
+	results, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		result, err := tx.Run(ctx, query, map[string]any{
+			"project_id": projectID.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var snapshots []*core.Snapshot
+		for result.Next(ctx) {
+			value, _ := result.Record().Get("s")
+			node, ok := value.(neo4j.Node)
+			if !ok {
+				logger.Warn("unexpected snapshot record", "value", value)
+				continue
+			}
+			snapshots = append(snapshots, recordToSnapshot(projectID, node.Props))
+		}
+		return snapshots, result.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	snapshots, ok := results.([]*core.Snapshot)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type")
+	}
+	return snapshots, nil
+}
+
+// DeleteSnapshot removes an archived snapshot and all of its nodes. The latest
+// snapshot cannot be deleted; use ClearProject to remove the whole project.
+func (r *Neo4jRepository) DeleteSnapshot(ctx context.Context, projectID, snapshotID core.ID) error {
+	session := r.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: r.config.Database,
+	})
+	defer session.Close(ctx)
+
+	scope := core.SnapshotScope(projectID, snapshotID)
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		params := map[string]any{
+			"project_id":  projectID.String(),
+			"snapshot_id": snapshotID.String(),
+			"scope":       scope.String(),
+		}
+		result, err := tx.Run(ctx, `
+			MATCH (s:Snapshot {project: $project_id, id: $snapshot_id})
+			RETURN s.scope AS scope
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		record, err := result.Single(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %s not found", snapshotID)
+		}
+		if current, _ := record.Get("scope"); current != scope.String() {
+			return nil, fmt.Errorf("snapshot %s is the latest snapshot", snapshotID)
+		}
+
+		queries := []string{
+			`MATCH (n {project_id: $scope}) DETACH DELETE n`,
+			`MATCH (s:Snapshot {project: $project_id, id: $snapshot_id}) DELETE s`,
+		}
+		for _, query := range queries {
+			if _, err := tx.Run(ctx, query, params); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot %s: %w", snapshotID, err)
+	}
+
+	logger.Info("deleted snapshot", "project_id", projectID, "snapshot_id", snapshotID)
+	return nil
+}
+
+func recordToSnapshot(projectID core.ID, props map[string]any) *core.Snapshot {
+	snapshot := &core.Snapshot{ProjectID: projectID}
+	if id, ok := props["id"].(string); ok {
+		snapshot.ID = core.ID(id)
+	}
+	snapshot.CommitSHA, _ = props["commit_sha"].(string)
+	snapshot.Branch, _ = props["branch"].(string)
+	snapshot.ConfigHash, _ = props["config_hash"].(string)
+	snapshot.Root, _ = props["root"].(string)
+	if createdAt, ok := props["created_at"].(time.Time); ok {
+		snapshot.CreatedAt = createdAt
+	}
+	if count, ok := props["node_count"].(int64); ok {
+		snapshot.NodeCount = int(count)
+	}
+	if count, ok := props["relationship_count"].(int64); ok {
+		snapshot.RelationshipCount = int(count)
+	}
+	scope, _ := props["scope"].(string)
+	snapshot.Latest = scope == projectID.String()
+	return snapshot
+}
@@ -0,0 +1,92 @@
+package infra
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// serializeProperties converts complex properties to JSON strings so that every
+// store keeps only primitive values and lists of primitives
+func serializeProperties(properties map[string]any) map[string]any {
+	if properties == nil {
+		return nil
+	}
+	serialized := make(map[string]any)
+	for k, v := range properties {
+		serialized[k] = serializeValue(v)
+	}
+	return serialized
+}
+
+// serializeValue recursively serializes a single value
+func serializeValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	// Convert time values to UTC
+	if t, ok := v.(time.Time); ok {
+		return t.UTC()
+	}
+	// Check if it's a supported primitive type
+	if primitiveVal := handlePrimitiveTypes(v); primitiveVal != nil {
+		return primitiveVal
+	}
+	// Check if it's a supported array type
+	if arrayVal := handleArrayTypes(v); arrayVal != nil {
+		return arrayVal
+	}
+	// Everything else needs to be serialized to JSON
+	return serializeToJSON(v)
+}
+
+// handlePrimitiveTypes handles Neo4j-supported primitive types
+func handlePrimitiveTypes(v any) any {
+	switch val := v.(type) {
+	case bool, int64, float64, string:
+		return v
+	case int, int8, int16, int32:
+		return reflect.ValueOf(v).Int()
+	case uint, uint8, uint16, uint32:
+		return handleUintConversion(reflect.ValueOf(v).Uint(), v)
+	case uint64:
+		return handleUintConversion(reflect.ValueOf(v).Uint(), v)
+	case float32:
+		return float64(val)
+	default:
+		return nil
+	}
+}
+
+// handleArrayTypes handles Neo4j-supported array types
+func handleArrayTypes(v any) any {
+	switch val := v.(type) {
+	case []bool, []int64, []float64, []string:
+		return v
+	case []int:
+		result := make([]int64, len(val))
+		for i, item := range val {
+			result[i] = int64(item)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// handleUintConversion safely converts uint values to int64, serializing large values
+func handleUintConversion(uintVal uint64, originalVal any) any {
+	if uintVal > 9223372036854775807 { // max int64
+		return serializeToJSON(originalVal)
+	}
+	return int64(uintVal)
+}
+
+// serializeToJSON serializes a value to JSON string
+func serializeToJSON(v any) string {
+	if jsonBytes, err := json.Marshal(v); err == nil {
+		return string(jsonBytes)
+	}
+	return fmt.Sprintf("%v", v)
+}
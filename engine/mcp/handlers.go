@@ -12,10 +12,12 @@ import (
 	"time"
 
 	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/cypher"
 	"github.com/compozy/gograph/engine/graph"
 	"github.com/compozy/gograph/engine/parser"
 	"github.com/compozy/gograph/engine/query"
 	"github.com/compozy/gograph/pkg/config"
+	"github.com/compozy/gograph/pkg/git"
 	"github.com/compozy/gograph/pkg/logger"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
@@ -188,6 +190,10 @@ func (s *Server) HandleExecuteCypherInternal(ctx context.Context, input map[stri
 	if !ok {
 		return nil, fmt.Errorf("query is required")
 	}
+	ctx, scoped, err := s.guardQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get parameters if provided
 	parameters := make(map[string]any)
@@ -199,8 +205,13 @@ func (s *Server) HandleExecuteCypherInternal(ctx context.Context, input map[stri
 
 	logger.Info("executing cypher query", "project_id", projectID, "query", query)
 
+	page, err := parsePage(input, scoped, parameters)
+	if err != nil {
+		return nil, err
+	}
+
 	// Execute query
-	results, err := s.serviceAdapter.ExecuteQuery(ctx, query, parameters)
+	results, cursor, err := s.queryPage(ctx, scoped, parameters, page)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -210,13 +221,20 @@ func (s *Server) HandleExecuteCypherInternal(ctx context.Context, input map[stri
 		"parameters":   parameters,
 		"results":      results,
 		"result_count": len(results),
+		"page_size":    page.size,
+	}
+	if cursor != "" {
+		result["next_cursor"] = cursor
+	}
+	if scoped != query {
+		result["scoped_query"] = scoped
 	}
 
 	return &ToolResponse{
 		Content: []any{
 			map[string]any{
 				"type": "text",
-				"text": fmt.Sprintf("Query executed successfully, returned %d results", len(results)),
+				"text": pageText(fmt.Sprintf("Query executed successfully, returned %d results", len(results)), cursor),
 			},
 			map[string]any{
 				"type": "resource",
@@ -229,6 +247,28 @@ func (s *Server) HandleExecuteCypherInternal(ctx context.Context, input map[stri
 	}, nil
 }
 
+// guardQuery applies the query protections of the server configuration to an
+// execute_cypher query. Unless writes are allowed, writes are rejected and the
+// query runs in a read transaction, so that writes the check misses still
+// fail. Unless unscoped queries are allowed, every node pattern is limited to
+// the project.
+func (s *Server) guardQuery(ctx context.Context, query string) (context.Context, string, error) {
+	if !s.AllowsWrites() {
+		if err := cypher.CheckReadOnly(query); err != nil {
+			return nil, "", fmt.Errorf("query rejected, set security.allow_writes to run writes: %w", err)
+		}
+		ctx = graph.WithReadOnly(ctx)
+	}
+	if s.AllowsUnscopedQueries() {
+		return ctx, query, nil
+	}
+	scoped, err := cypher.ScopeToProject(query)
+	if err != nil {
+		return nil, "", fmt.Errorf("query rejected, set security.unscoped_queries to run it as written: %w", err)
+	}
+	return ctx, scoped, nil
+}
+
 // HandleGetFunctionInfoInternal gets detailed information about a function
 //
 //nolint:funlen,gocyclo // MCP tool handlers can be longer and have complex logic
@@ -261,30 +301,22 @@ func (s *Server) HandleGetFunctionInfoInternal(ctx context.Context, input map[st
 		"function", functionName,
 		"package", packageName)
 
-	// Build query to find function - try exact match first, then fuzzy
-	query := `
-		MATCH (f:Function {project_id: $project_id})
-		WHERE f.name = $function_name OR toLower(f.name) CONTAINS toLower($function_name)
-	`
-	params := map[string]any{
-		"project_id":    projectID,
-		"function_name": functionName,
-	}
-
+	// Find the function - exact name matches first, then fuzzy ones
+	functions := query.Functions().NameLike(functionName)
 	if packageName != "" {
-		query += " AND (f.package = $package OR toLower(f.package) CONTAINS toLower($package))"
-		params["package"] = packageName
+		functions.Like(query.PropertyPackage, packageName)
+	}
+	cypherQuery, params, err := query.Find(functions).As("f").
+		ReturnNode().
+		WithFile("file_path").
+		ExactNameFirst(functionName).
+		Limit(1).
+		Build(core.ID(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build function query: %w", err)
 	}
 
-	query += `
-		WITH f
-		OPTIONAL MATCH (file:File {project_id: $project_id})-[:DEFINES]->(f)
-		RETURN f, COALESCE(file.path, f.file_path) as file_path
-		ORDER BY CASE WHEN f.name = $function_name THEN 0 ELSE 1 END
-		LIMIT 1
-	`
-
-	results, err := s.serviceAdapter.ExecuteQuery(ctx, query, params)
+	results, err := s.serviceAdapter.ExecuteQuery(ctx, cypherQuery, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query function: %w", err)
 	}
@@ -366,7 +398,7 @@ func (s *Server) HandleGetFunctionInfoInternal(ctx context.Context, input map[st
 	}
 
 	// Include function calls and callers if requested
-	s.AddFunctionRelationships(ctx, result, params, includeCalls, includeCallers)
+	s.AddFunctionRelationships(ctx, result, projectID, functionName, includeCalls, includeCallers)
 
 	return &ToolResponse{
 		Content: []any{
@@ -503,26 +535,17 @@ Format the response as plain text suitable for a developer tool.`
 	return response.Query, nil
 }
 
-// AddFunctionRelationships adds calls and callers to function result
+// AddFunctionRelationships adds the functions that the functions matching
+// functionName call, and those that call them, to a function result
 func (s *Server) AddFunctionRelationships(
 	ctx context.Context,
 	result map[string]any,
-	params map[string]any,
+	projectID, functionName string,
 	includeCalls, includeCallers bool,
 ) {
 	if includeCalls {
-		callsQuery := `
-			MATCH (f:Function {project_id: $project_id})-[:CALLS]->(called:Function)
-			WHERE f.name = $function_name OR toLower(f.name) CONTAINS toLower($function_name)
-			RETURN called.name as name, 
-			       called.package as package, 
-			       called.signature as signature,
-			       called.file_path as file_path,
-			       called.line_start as line_start,
-			       called.is_exported as is_exported
-			ORDER BY called.package, called.name
-		`
-		callsResults, err := s.serviceAdapter.ExecuteQuery(ctx, callsQuery, params)
+		calls := query.Functions().CalledBy(query.Functions().NameLike(functionName))
+		callsResults, err := s.relatedFunctions(ctx, calls, projectID)
 		if err != nil {
 			logger.Warn("failed to fetch function calls", "error", err)
 		} else {
@@ -532,18 +555,8 @@ func (s *Server) AddFunctionRelationships(
 	}
 
 	if includeCallers {
-		callersQuery := `
-			MATCH (caller:Function)-[:CALLS]->(f:Function {project_id: $project_id})
-			WHERE f.name = $function_name OR toLower(f.name) CONTAINS toLower($function_name)
-			RETURN caller.name as name, 
-			       caller.package as package, 
-			       caller.signature as signature,
-			       caller.file_path as file_path,
-			       caller.line_start as line_start,
-			       caller.is_exported as is_exported
-			ORDER BY caller.package, caller.name
-		`
-		callersResults, err := s.serviceAdapter.ExecuteQuery(ctx, callersQuery, params)
+		callers := query.Functions().Calling(query.Functions().NameLike(functionName))
+		callersResults, err := s.relatedFunctions(ctx, callers, projectID)
 		if err != nil {
 			logger.Warn("failed to fetch function callers", "error", err)
 		} else {
@@ -553,6 +566,25 @@ func (s *Server) AddFunctionRelationships(
 	}
 }
 
+// relatedFunctions returns a summary of each function of a set, sorted by
+// package and name
+func (s *Server) relatedFunctions(
+	ctx context.Context,
+	functions *query.NodeSet,
+	projectID string,
+) ([]map[string]any, error) {
+	cypherQuery, params, err := query.Find(functions).
+		Return(query.PropertyName, query.PropertyPackage, query.PropertySignature).
+		WithFile("file_path").
+		Return(query.PropertyLineStart, query.PropertyExported).
+		OrderBy("package", "name").
+		Build(core.ID(projectID))
+	if err != nil {
+		return nil, err
+	}
+	return s.serviceAdapter.ExecuteQuery(ctx, cypherQuery, params)
+}
+
 // handleQueryDependencies finds dependencies for a package or function
 //
 //nolint:funlen // MCP tool handlers can be longer for comprehensive functionality
@@ -671,8 +703,89 @@ func (s *Server) HandleQueryDependenciesInternal(ctx context.Context, input map[
 	}, nil
 }
 
+// HandleAnalyzeImpactInternal maps the lines changed by a diff onto the graph
+// and lists every transitively affected function, package and entrypoint
+func (s *Server) HandleAnalyzeImpactInternal(ctx context.Context, input map[string]any) (*ToolResponse, error) {
+	projectID, err := s.getProjectID(input)
+	if err != nil {
+		return nil, err
+	}
+	diffText, _ := input["diff"].(string)
+	since, _ := input["since"].(string)
+	if (diffText == "") == (since == "") {
+		return nil, fmt.Errorf("either diff or since is required")
+	}
+	projectPath, _ := input["project_path"].(string)
+	unapplied, _ := input["unapplied"].(bool)
+	maxDepth, _ := input["max_depth"].(int)
+
+	logger.Info("analyzing change impact",
+		"project_id", projectID,
+		"since", since,
+		"unapplied", unapplied)
+
+	var files []*git.FileDiff
+	if since != "" {
+		if projectPath == "" {
+			projectPath = "."
+		}
+		if !s.IsPathAllowed(projectPath) {
+			return nil, fmt.Errorf("path %s is not allowed by security policy", projectPath)
+		}
+		files, err = git.DiffSince(ctx, projectPath, since)
+	} else {
+		files, err = git.ParseDiff(strings.NewReader(diffText))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+
+	analysis, err := graph.LoadAnalysisResult(ctx, s.serviceAdapter, core.ID(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project graph: %w", err)
+	}
+	root := ""
+	if projectPath != "" {
+		if root, err = filepath.Abs(projectPath); err != nil {
+			return nil, fmt.Errorf("failed to resolve project path: %w", err)
+		}
+	}
+	report := graph.Impact(analysis, git.ChangedLines(files, unapplied), &graph.ImpactOptions{
+		MaxDepth: maxDepth,
+		Root:     root,
+	})
+
+	return &ToolResponse{
+		Content: []any{
+			map[string]any{
+				"type": "text",
+				"text": fmt.Sprintf("%d changed, %d affected entities in %d packages, %d entrypoints",
+					len(report.Changed), len(report.Affected), len(report.Packages), len(report.Entrypoints)),
+			},
+			map[string]any{
+				"type": "resource",
+				"resource": map[string]any{
+					"uri":  fmt.Sprintf("/projects/%s/impact", projectID),
+					"data": report,
+				},
+			},
+		},
+	}, nil
+}
+
 // Helper methods
 
+// AllowsWrites reports whether execute_cypher may run queries that write to the graph
+func (s *Server) AllowsWrites() bool {
+	return s.config != nil && s.config.Security.AllowWrites
+}
+
+// AllowsUnscopedQueries reports whether execute_cypher runs queries as written
+// instead of limiting their node patterns to the project
+func (s *Server) AllowsUnscopedQueries() bool {
+	return s.config != nil && s.config.Security.UnscopedQueries
+}
+
 func (s *Server) IsPathAllowed(path string) bool {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
@@ -724,23 +837,19 @@ func (s *Server) HandleFindImplementationsInternal(ctx context.Context, input ma
 		"package", packageName)
 
 	// Query to find interface implementations
-	query := `
-		MATCH (iface:Interface {project_id: $project_id, name: $interface_name})
-		MATCH (impl:Struct)-[:IMPLEMENTS]->(iface)
-		OPTIONAL MATCH (impl_file:File)-[:DEFINES]->(impl)
-		RETURN impl, impl_file.path as file_path
-	`
-	params := map[string]any{
-		"project_id":     projectID,
-		"interface_name": interfaceName,
-	}
-
+	interfaces := query.Interfaces().Named(interfaceName)
 	if packageName != "" {
-		query = strings.Replace(query, "Interface {", "Interface {package: $package, ", 1)
-		params["package"] = packageName
+		interfaces.InPackage(packageName)
+	}
+	cypherQuery, params, err := query.Find(query.Structs().Implementing(interfaces)).As("impl").
+		ReturnNode().
+		WithFile("file_path").
+		Build(core.ID(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build implementations query: %w", err)
 	}
 
-	results, err := s.serviceAdapter.ExecuteQuery(ctx, query, params)
+	results, err := s.serviceAdapter.ExecuteQuery(ctx, cypherQuery, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find implementations: %w", err)
 	}
@@ -1045,7 +1154,7 @@ func (s *Server) HandleListPackagesInternal(ctx context.Context, input map[strin
 		OPTIONAL MATCH (p)<-[:BELONGS_TO]-(f:File)
 		OPTIONAL MATCH (p)<-[:BELONGS_TO]-(fn:Function)
 		RETURN p.name as name, p.path as path, count(DISTINCT f) as file_count, count(DISTINCT fn) as function_count
-		ORDER BY p.name
+		ORDER BY p.name, p.path
 	`
 	params := map[string]any{"project_id": projectID}
 
@@ -1056,12 +1165,16 @@ func (s *Server) HandleListPackagesInternal(ctx context.Context, input map[strin
 		OPTIONAL MATCH (p)<-[:BELONGS_TO]-(f:File)
 		OPTIONAL MATCH (p)<-[:BELONGS_TO]-(fn:Function)
 		RETURN p.name as name, p.path as path, count(DISTINCT f) as file_count, count(DISTINCT fn) as function_count
-		ORDER BY p.name
+		ORDER BY p.name, p.path
 	`
 		params["pattern"] = pattern
 	}
 
-	results, err := s.serviceAdapter.ExecuteQuery(ctx, query, params)
+	page, err := parsePage(input, query, params)
+	if err != nil {
+		return nil, err
+	}
+	results, cursor, err := s.queryPage(ctx, query, params, page)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list packages: %w", err)
 	}
@@ -1071,13 +1184,17 @@ func (s *Server) HandleListPackagesInternal(ctx context.Context, input map[strin
 		"count":            len(results),
 		"pattern":          pattern,
 		"include_external": includeExternal,
+		"page_size":        page.size,
+	}
+	if cursor != "" {
+		result["next_cursor"] = cursor
 	}
 
 	return &ToolResponse{
 		Content: []any{
 			map[string]any{
 				"type": "text",
-				"text": fmt.Sprintf("Found %d packages", len(results)),
+				"text": pageText(fmt.Sprintf("Found %d packages", len(results)), cursor),
 			},
 			map[string]any{
 				"type": "resource",
@@ -1228,41 +1345,29 @@ func (s *Server) HandleVerifyCodeExistsInternal(ctx context.Context, input map[s
 		"package", packageName)
 
 	// Build query based on element type
-	var query string
-	params := map[string]any{
-		"project_id": projectID,
-		"name":       name,
-	}
-
+	var elements *query.NodeSet
+	var variable string
 	switch strings.ToLower(elementType) {
 	case "function":
-		query = `MATCH (f:Function {project_id: $project_id, name: $name})`
-		if packageName != "" {
-			query += ` WHERE f.package = $package`
-			params["package"] = packageName
-		}
-		query += ` RETURN f, exists((f)<-[:CONTAINS]-(:File)) as has_file`
+		elements, variable = query.Functions().Named(name), "f"
 	case "struct", "type":
-		query = `MATCH (s:Struct {project_id: $project_id, name: $name})`
-		if packageName != "" {
-			query += ` WHERE s.package = $package`
-			params["package"] = packageName
-		}
-		query += ` RETURN s, exists((s)<-[:CONTAINS]-(:File)) as has_file`
+		elements, variable = query.Structs().Named(name), "s"
 	case "interface":
-		query = `MATCH (i:Interface {project_id: $project_id, name: $name})`
-		if packageName != "" {
-			query += ` WHERE i.package = $package`
-			params["package"] = packageName
-		}
-		query += ` RETURN i, exists((i)<-[:CONTAINS]-(:File)) as has_file`
+		elements, variable = query.Interfaces().Named(name), "i"
 	case "package":
-		query = `MATCH (p:Package {project_id: $project_id}) WHERE p.import_path = $name OR p.name = $name RETURN p, true as has_file`
+		elements, variable = query.Packages().WhereAny(query.Equals, name, query.PropertyName, query.PropertyImportPath), "p"
 	default:
 		return nil, fmt.Errorf("unsupported element type: %s", elementType)
 	}
+	if packageName != "" && variable != "p" {
+		elements.InPackage(packageName)
+	}
+	cypherQuery, params, err := query.Find(elements).As(variable).ReturnNode().Build(core.ID(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build verification query: %w", err)
+	}
 
-	results, err := s.serviceAdapter.ExecuteQuery(ctx, query, params)
+	results, err := s.serviceAdapter.ExecuteQuery(ctx, cypherQuery, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify code existence: %w", err)
 	}
@@ -1280,15 +1385,9 @@ func (s *Server) HandleVerifyCodeExistsInternal(ctx context.Context, input map[s
 		"package":      packageName,
 	}
 
-	if exists && elementData != nil {
-		// Add detailed information about the found element
-		for key, value := range elementData {
-			if key != "has_file" {
-				if nodeData, ok := value.(map[string]any); ok {
-					result["details"] = nodeData
-				}
-			}
-		}
+	// Add detailed information about the found element
+	if nodeData, ok := elementData[variable].(map[string]any); ok {
+		result["details"] = nodeData
 	}
 
 	message := fmt.Sprintf("Element %s not found", name)
@@ -1337,15 +1436,12 @@ func (s *Server) HandleGetCodeContextInternal(ctx context.Context, input map[str
 	}
 
 	// Get the element's location from the graph
-	query, err := s.BuildElementLocationQuery(elementType)
+	cypherQuery, params, err := s.BuildElementLocationQuery(projectID, elementType, name)
 	if err != nil {
 		return nil, err
 	}
 
-	results, err := s.serviceAdapter.ExecuteQuery(ctx, query, map[string]any{
-		"project_id": projectID,
-		"name":       name,
-	})
+	results, err := s.serviceAdapter.ExecuteQuery(ctx, cypherQuery, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query element location: %w", err)
 	}
@@ -1353,46 +1449,32 @@ func (s *Server) HandleGetCodeContextInternal(ctx context.Context, input map[str
 	return s.ExtractCodeContextFromResults(results, projectID, elementType, name, contextLines)
 }
 
-// BuildElementLocationQuery builds a query to find an element's location
-func (s *Server) BuildElementLocationQuery(elementType string) (string, error) {
+// BuildElementLocationQuery builds a query to find the location of the element
+// named name, or else of the first whose name contains it
+func (s *Server) BuildElementLocationQuery(
+	projectID, elementType, name string,
+) (string, map[string]any, error) {
+	var elements *query.NodeSet
 	switch elementType {
 	case "function":
-		return `
-			MATCH (f:Function {project_id: $project_id})
-			WHERE f.name = $name OR toLower(f.name) CONTAINS toLower($name)
-			OPTIONAL MATCH (file:File)-[:DEFINES]->(f)
-			RETURN f.line_start as line_start, f.line_end as line_end, 
-			       COALESCE(file.path, f.file_path) as file_path,
-			       f.signature as signature,
-			       f.package as package
-			ORDER BY CASE WHEN f.name = $name THEN 0 ELSE 1 END
-			LIMIT 1
-		`, nil
+		elements = query.Functions()
 	case "struct":
-		return `
-			MATCH (s:Struct {project_id: $project_id})
-			WHERE s.name = $name OR toLower(s.name) CONTAINS toLower($name)
-			OPTIONAL MATCH (file:File)-[:DEFINES]->(s)
-			RETURN s.line_start as line_start, s.line_end as line_end, 
-			       COALESCE(file.path, s.file_path) as file_path,
-			       s.package as package
-			ORDER BY CASE WHEN s.name = $name THEN 0 ELSE 1 END
-			LIMIT 1
-		`, nil
+		elements = query.Structs()
 	case "interface":
-		return `
-			MATCH (i:Interface {project_id: $project_id})
-			WHERE i.name = $name OR toLower(i.name) CONTAINS toLower($name)
-			OPTIONAL MATCH (file:File)-[:DEFINES]->(i)
-			RETURN i.line_start as line_start, i.line_end as line_end, 
-			       COALESCE(file.path, i.file_path) as file_path,
-			       i.package as package
-			ORDER BY CASE WHEN i.name = $name THEN 0 ELSE 1 END
-			LIMIT 1
-		`, nil
+		elements = query.Interfaces()
 	default:
-		return "", fmt.Errorf("unsupported element type: %s", elementType)
+		return "", nil, fmt.Errorf("unsupported element type: %s", elementType)
 	}
+	selection := query.Find(elements.NameLike(name)).
+		Return(query.PropertyLineStart, query.PropertyLineEnd).
+		WithFile("file_path")
+	if elementType == "function" {
+		selection.Return(query.PropertySignature)
+	}
+	return selection.Return(query.PropertyPackage).
+		ExactNameFirst(name).
+		Limit(1).
+		Build(core.ID(projectID))
 }
 
 // codeContextParams holds parameters for code context requests
@@ -1850,25 +1932,41 @@ func (s *Server) HandleProjectMetadataResource(ctx context.Context, params map[s
 
 // handleQueryTemplatesResource provides query templates
 func (s *Server) HandleQueryTemplatesResource(_ context.Context, _ map[string]string) ([]byte, error) {
-	// Return available query templates from the CommonTemplates map
-	templateList := make([]map[string]any, 0)
-	for name, template := range query.CommonTemplates {
-		templateList = append(templateList, map[string]any{
+	// Return the built-in and user query templates
+	templates := query.Templates()
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	templateList := make([]map[string]any, 0, len(names))
+	categories := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, name := range names {
+		template := templates[name]
+		entry := map[string]any{
 			"name":        name,
+			"title":       template.Name,
 			"description": template.Description,
 			"category":    template.Category,
+			"query":       template.Query,
 			"parameters":  template.Parameters,
-		})
+		}
+		if template.Source != "" {
+			entry["source"] = template.Source
+		}
+		templateList = append(templateList, entry)
+		if !seen[template.Category] {
+			seen[template.Category] = true
+			categories = append(categories, template.Category)
+		}
 	}
+	sort.Strings(categories)
 
 	data := map[string]any{
-		"templates": templateList,
-		"categories": []string{
-			"dependency_analysis",
-			"code_structure",
-			"quality_metrics",
-			"test_coverage",
-		},
+		"templates":  templateList,
+		"categories": categories,
 	}
 
 	return json.Marshal(data)
@@ -2198,48 +2296,21 @@ func (s *Server) AnalyzeNamingConventions(ctx context.Context, projectID, _ stri
 	conventions := make(map[string]any)
 
 	// Analyze function naming
-	functionQuery := `
-		MATCH (f:Function {project_id: $project_id})
-		WHERE f.is_exported = true
-		RETURN f.name as name
-		LIMIT 20
-	`
-
-	functionResults, err := s.serviceAdapter.ExecuteQuery(ctx, functionQuery, map[string]any{
-		"project_id": projectID,
-	})
+	functionResults, err := s.exportedNames(ctx, query.Functions(), projectID)
 	if err == nil && len(functionResults) > 0 {
 		functionPatterns := s.AnalyzeFunctionNaming(functionResults)
 		conventions["functions"] = functionPatterns
 	}
 
 	// Analyze type naming
-	typeQuery := `
-		MATCH (s:Struct {project_id: $project_id})
-		WHERE s.is_exported = true
-		RETURN s.name as name
-		LIMIT 20
-	`
-
-	typeResults, err := s.serviceAdapter.ExecuteQuery(ctx, typeQuery, map[string]any{
-		"project_id": projectID,
-	})
+	typeResults, err := s.exportedNames(ctx, query.Structs(), projectID)
 	if err == nil && len(typeResults) > 0 {
 		typePatterns := s.AnalyzeTypeNaming(typeResults)
 		conventions["types"] = typePatterns
 	}
 
 	// Analyze interface naming
-	interfaceQuery := `
-		MATCH (i:Interface {project_id: $project_id})
-		WHERE i.is_exported = true
-		RETURN i.name as name
-		LIMIT 20
-	`
-
-	interfaceResults, err := s.serviceAdapter.ExecuteQuery(ctx, interfaceQuery, map[string]any{
-		"project_id": projectID,
-	})
+	interfaceResults, err := s.exportedNames(ctx, query.Interfaces(), projectID)
 	if err == nil && len(interfaceResults) > 0 {
 		interfacePatterns := s.AnalyzeInterfaceNaming(interfaceResults)
 		conventions["interfaces"] = interfacePatterns
@@ -2248,6 +2319,22 @@ func (s *Server) AnalyzeNamingConventions(ctx context.Context, projectID, _ stri
 	return conventions
 }
 
+// exportedNames returns the names of up to 20 exported elements of a set
+func (s *Server) exportedNames(
+	ctx context.Context,
+	elements *query.NodeSet,
+	projectID string,
+) ([]map[string]any, error) {
+	cypherQuery, params, err := query.Find(elements.Exported()).
+		Return(query.PropertyName).
+		Limit(20).
+		Build(core.ID(projectID))
+	if err != nil {
+		return nil, err
+	}
+	return s.serviceAdapter.ExecuteQuery(ctx, cypherQuery, params)
+}
+
 // AnalyzeFunctionNaming analyzes function naming patterns
 func (s *Server) AnalyzeFunctionNaming(results []map[string]any) map[string]any {
 	patterns := map[string]int{
@@ -2375,27 +2462,25 @@ func (s *Server) FindTestsForElement(
 	projectID, _, name, _ string,
 ) []map[string]any {
 	// Find test functions that contain the element name
-	query := `
-		MATCH (f:Function {project_id: $project_id})
-		WHERE f.name CONTAINS 'Test' AND (
-			f.name CONTAINS $name OR
-			f.name =~ ('.*' + $name + '.*') OR
-			f.name =~ ('Test.*' + $name + '.*')
-		)
-		OPTIONAL MATCH (file:File)-[:CONTAINS]->(f)
-		RETURN f.name as test_name, f.package as test_package, file.path as file_path
-		LIMIT 10
-	`
+	tests := query.Functions().
+		Where(query.PropertyName, query.Contains, "Test").
+		Where(query.PropertyName, query.Contains, name)
+	cypherQuery, params, err := query.Find(tests).
+		ReturnFrom("n", query.PropertyName, "test_name").
+		ReturnFrom("n", query.PropertyPackage, "test_package").
+		WithFile("file_path").
+		Limit(10).
+		Build(core.ID(projectID))
+	if err != nil {
+		return []map[string]any{}
+	}
 
-	results, err := s.serviceAdapter.ExecuteQuery(ctx, query, map[string]any{
-		"project_id": projectID,
-		"name":       name,
-	})
+	results, err := s.serviceAdapter.ExecuteQuery(ctx, cypherQuery, params)
 	if err != nil {
 		return []map[string]any{}
 	}
 
-	var tests []map[string]any
+	var matches []map[string]any
 	for _, result := range results {
 		testName, ok := result["test_name"].(string)
 		if !ok {
@@ -2410,7 +2495,7 @@ func (s *Server) FindTestsForElement(
 			filePath = ""
 		}
 
-		tests = append(tests, map[string]any{
+		matches = append(matches, map[string]any{
 			"test_name":    testName,
 			"test_package": testPackage,
 			"file_path":    filePath,
@@ -2418,7 +2503,7 @@ func (s *Server) FindTestsForElement(
 		})
 	}
 
-	return tests
+	return matches
 }
 
 // TestCoverage represents test coverage information
@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -268,7 +269,7 @@ func TestHandleGetFunctionInfoInternal_EnhancedQueries(t *testing.T) {
 			expectedCallsCount:   0,
 			checkQueries: func(t *testing.T, queries []string) {
 				require.Len(t, queries, 1)
-				assert.Contains(t, queries[0], "toLower(f.name) CONTAINS toLower($function_name)")
+				assert.Contains(t, queries[0], "toLower(f.name) CONTAINS toLower($name)")
 			},
 		},
 	}
@@ -428,6 +429,25 @@ func (m *MockServiceAdapter) ExecuteQuery(
 	return args.Get(0).([]map[string]any), args.Error(1)
 }
 
+func (m *MockServiceAdapter) StreamQuery(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+	fn graph.RowFunc,
+) error {
+	args := m.Called(ctx, query, params)
+	if rows, ok := args.Get(0).([]map[string]any); ok {
+		for _, row := range rows {
+			if err := fn(row); errors.Is(err, graph.ErrStopStream) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MockServiceAdapter) ListProjects(ctx context.Context) ([]core.Project, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
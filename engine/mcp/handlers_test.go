@@ -2,10 +2,15 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/compozy/gograph/engine/cypher"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/engine/query"
+	mcpconfig "github.com/compozy/gograph/pkg/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -486,7 +491,6 @@ func TestHandleVerifyCodeExistsInternal_PackageNameAndImportPath(t *testing.T) {
 					"path":        "/engine/mcp",
 					"project_id":  "test-project",
 				},
-				"has_file": true,
 			},
 		}
 
@@ -495,7 +499,7 @@ func TestHandleVerifyCodeExistsInternal_PackageNameAndImportPath(t *testing.T) {
 			mock.MatchedBy(func(query string) bool {
 				return strings.Contains(
 					query,
-					"MATCH (p:Package {project_id: $project_id}) WHERE p.import_path = $name OR p.name = $name",
+					"MATCH (p:Package {project_id: $project_id}) WHERE (p.name = $name OR p.import_path = $name)",
 				)
 			}),
 			mock.MatchedBy(func(params map[string]any) bool {
@@ -540,7 +544,6 @@ func TestHandleVerifyCodeExistsInternal_PackageNameAndImportPath(t *testing.T) {
 					"path":        "/engine/mcp",
 					"project_id":  "test-project",
 				},
-				"has_file": true,
 			},
 		}
 
@@ -549,7 +552,7 @@ func TestHandleVerifyCodeExistsInternal_PackageNameAndImportPath(t *testing.T) {
 			mock.MatchedBy(func(query string) bool {
 				return strings.Contains(
 					query,
-					"MATCH (p:Package {project_id: $project_id}) WHERE p.import_path = $name OR p.name = $name",
+					"MATCH (p:Package {project_id: $project_id}) WHERE (p.name = $name OR p.import_path = $name)",
 				)
 			}),
 			mock.MatchedBy(func(params map[string]any) bool {
@@ -598,7 +601,6 @@ func TestHandleVerifyCodeExistsInternal_PackageNameAndImportPath(t *testing.T) {
 					"line_start":  int64(891),
 					"line_end":    int64(995),
 				},
-				"has_file": true,
 			},
 		}
 
@@ -607,7 +609,7 @@ func TestHandleVerifyCodeExistsInternal_PackageNameAndImportPath(t *testing.T) {
 			mock.MatchedBy(func(query string) bool {
 				return strings.Contains(
 					query,
-					"MATCH (f:Function {project_id: $project_id, name: $name}) WHERE f.package = $package",
+					"MATCH (f:Function {project_id: $project_id}) WHERE f.name = $name AND f.package = $package",
 				)
 			}),
 			mock.MatchedBy(func(params map[string]any) bool {
@@ -653,7 +655,7 @@ func TestHandleVerifyCodeExistsInternal_PackageNameAndImportPath(t *testing.T) {
 			mock.MatchedBy(func(query string) bool {
 				return strings.Contains(
 					query,
-					"MATCH (p:Package {project_id: $project_id}) WHERE p.import_path = $name OR p.name = $name",
+					"MATCH (p:Package {project_id: $project_id}) WHERE (p.name = $name OR p.import_path = $name)",
 				)
 			}),
 			mock.MatchedBy(func(params map[string]any) bool {
@@ -686,7 +688,7 @@ func TestHandleVerifyCodeExistsInternal_PackageNameAndImportPath(t *testing.T) {
 		mockAdapter.On("ExecuteQuery",
 			mock.Anything,
 			mock.MatchedBy(func(query string) bool {
-				return strings.Contains(query, "MATCH (f:Function {project_id: $project_id, name: $name}) RETURN f")
+				return strings.Contains(query, "MATCH (f:Function {project_id: $project_id}) WHERE f.name = $name RETURN f")
 			}),
 			mock.MatchedBy(func(params map[string]any) bool {
 				return params["project_id"] == "test-project" && params["name"] == "NonexistentFunction"
@@ -720,7 +722,7 @@ func TestHandleVerifyCodeExistsInternal_PackageNameAndImportPath(t *testing.T) {
 			mock.MatchedBy(func(query string) bool {
 				return strings.Contains(
 					query,
-					"MATCH (p:Package {project_id: $project_id}) WHERE p.import_path = $name OR p.name = $name",
+					"MATCH (p:Package {project_id: $project_id}) WHERE (p.name = $name OR p.import_path = $name)",
 				)
 			}),
 			mock.MatchedBy(func(params map[string]any) bool {
@@ -760,3 +762,166 @@ func TestHandleVerifyCodeExistsInternal_PackageNameAndImportPath(t *testing.T) {
 		assert.Nil(t, response)
 	})
 }
+
+func TestHandleAnalyzeImpactInternal(t *testing.T) {
+	mockAdapter := new(MockServiceAdapter)
+	mockAdapter.On("ExecuteQuery",
+		mock.Anything,
+		mock.MatchedBy(func(query string) bool {
+			return strings.Contains(query, "labels(n)[0] AS type")
+		}),
+		mock.Anything,
+	).Return([]map[string]any{
+		{"id": "file", "type": "File", "name": "store.go", "props": map[string]any{"path": "/repo/store/store.go"}},
+		{"id": "save", "type": "Function", "name": "Save", "props": map[string]any{
+			"package": "app/store", "line_start": int64(10), "line_end": int64(20),
+		}},
+		{"id": "create", "type": "Function", "name": "Create", "props": map[string]any{
+			"package": "app/service", "line_start": int64(3), "line_end": int64(9),
+		}},
+	}, nil)
+	mockAdapter.On("ExecuteQuery",
+		mock.Anything,
+		mock.MatchedBy(func(query string) bool {
+			return strings.Contains(query, "type(r) AS type")
+		}),
+		mock.Anything,
+	).Return([]map[string]any{
+		{"id": "r1", "type": "DEFINES", "from_id": "file", "to_id": "save"},
+		{"id": "r2", "type": "CALLS", "from_id": "create", "to_id": "save"},
+	}, nil)
+
+	server := &Server{serviceAdapter: mockAdapter}
+
+	t.Run("Should list entities affected by a diff", func(t *testing.T) {
+		response, err := server.HandleAnalyzeImpactInternal(context.Background(), map[string]any{
+			"project_id": "test-project",
+			"diff":       "--- a/store/store.go\n+++ b/store/store.go\n@@ -12 +12 @@\n-old\n+new\n",
+		})
+
+		require.NoError(t, err)
+		require.Len(t, response.Content, 2)
+		assert.Equal(t, "1 changed, 1 affected entities in 2 packages, 1 entrypoints",
+			response.Content[0].(map[string]any)["text"])
+	})
+
+	t.Run("Should require a diff or a revision", func(t *testing.T) {
+		_, err := server.HandleAnalyzeImpactInternal(context.Background(), map[string]any{
+			"project_id": "test-project",
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "either diff or since is required")
+	})
+}
+
+func TestHandleQueryTemplatesResource(t *testing.T) {
+	t.Run("Should list user templates with the built-in ones", func(t *testing.T) {
+		t.Cleanup(func() { query.RegisterTemplates(nil) })
+		query.RegisterTemplates(map[string]*query.Template{
+			"hot_functions": {
+				Name:     "Hot Functions",
+				Category: "hotspots",
+				Query:    "MATCH (f:Function) RETURN f LIMIT $limit",
+				Parameters: map[string]query.Parameter{
+					"limit": {Type: query.ParameterInteger, Default: int64(10)},
+				},
+				Source: ".gograph/templates/hot_functions.yaml",
+			},
+		})
+		server := &Server{}
+
+		data, err := server.HandleQueryTemplatesResource(context.Background(), nil)
+		require.NoError(t, err)
+		var resource struct {
+			Templates []struct {
+				Name       string                     `json:"name"`
+				Source     string                     `json:"source"`
+				Parameters map[string]query.Parameter `json:"parameters"`
+			} `json:"templates"`
+			Categories []string `json:"categories"`
+		}
+		require.NoError(t, json.Unmarshal(data, &resource))
+		assert.Len(t, resource.Templates, len(query.CommonTemplates)+1)
+		assert.Contains(t, resource.Categories, "hotspots")
+		assert.Contains(t, resource.Categories, "overview")
+		for _, template := range resource.Templates {
+			if template.Name == "hot_functions" {
+				assert.Equal(t, ".gograph/templates/hot_functions.yaml", template.Source)
+				assert.Equal(t, query.ParameterInteger, template.Parameters["limit"].Type)
+			}
+		}
+	})
+}
+
+func TestHandleExecuteCypherInternal(t *testing.T) {
+	readOnly := mock.MatchedBy(func(ctx context.Context) bool { return graph.IsReadOnly(ctx) })
+	writable := mock.MatchedBy(func(ctx context.Context) bool { return !graph.IsReadOnly(ctx) })
+
+	t.Run("Should run read queries in a read transaction", func(t *testing.T) {
+		mockAdapter := new(MockServiceAdapter)
+		mockAdapter.On("StreamQuery", readOnly, mock.Anything, mock.Anything).
+			Return([]map[string]any{{"name": "main"}}, nil)
+		server := &Server{serviceAdapter: mockAdapter}
+
+		response, err := server.HandleExecuteCypherInternal(context.Background(), map[string]any{
+			"project_id": "test-project",
+			"query":      "MATCH (f:Function {project_id: $project_id}) RETURN f.name AS name",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, response)
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Should reject writes unless the config allows them", func(t *testing.T) {
+		mockAdapter := new(MockServiceAdapter)
+		server := &Server{serviceAdapter: mockAdapter, config: mcpconfig.DefaultConfig()}
+		input := map[string]any{
+			"project_id": "test-project",
+			"query":      "MATCH (n {project_id: 'other'}) DETACH DELETE n",
+		}
+
+		_, err := server.HandleExecuteCypherInternal(context.Background(), input)
+		assert.ErrorIs(t, err, cypher.ErrWrite)
+		assert.ErrorContains(t, err, "security.allow_writes")
+		mockAdapter.AssertNotCalled(t, "StreamQuery", mock.Anything, mock.Anything, mock.Anything)
+
+		server.config.Security.AllowWrites = true
+		server.config.Security.UnscopedQueries = true // Writes are outside the subset the scope rewriter parses
+		mockAdapter.On("StreamQuery", writable, mock.Anything, mock.Anything).Return([]map[string]any{}, nil)
+		_, err = server.HandleExecuteCypherInternal(context.Background(), input)
+		require.NoError(t, err)
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Should limit node patterns to the project unless unscoped queries are allowed", func(t *testing.T) {
+		query := "MATCH (f:Function)-[:CALLS]->(g) RETURN g.name AS name"
+		scoped := "MATCH (f:Function {project_id: $project_id})-[:CALLS]->(g {project_id: $project_id}) " +
+			"RETURN g.name AS name"
+		mockAdapter := new(MockServiceAdapter)
+		mockAdapter.On("StreamQuery", readOnly, scoped, mock.Anything).Return([]map[string]any{}, nil)
+		mockAdapter.On("StreamQuery", readOnly, query, mock.Anything).Return([]map[string]any{}, nil)
+		server := &Server{serviceAdapter: mockAdapter, config: mcpconfig.DefaultConfig()}
+		input := map[string]any{"project_id": "test-project", "query": query}
+
+		response, err := server.HandleExecuteCypherInternal(context.Background(), input)
+		require.NoError(t, err)
+		data := response.Content[1].(map[string]any)["resource"].(map[string]any)["data"].(map[string]any)
+		assert.Equal(t, scoped, data["scoped_query"])
+		mockAdapter.AssertCalled(t, "StreamQuery", readOnly, scoped, mock.Anything)
+
+		_, err = server.HandleExecuteCypherInternal(context.Background(), map[string]any{
+			"project_id": "test-project",
+			"query":      "MATCH (f {project_id: 'other'}) RETURN f",
+		})
+		assert.ErrorIs(t, err, cypher.ErrUnscoped)
+		assert.ErrorContains(t, err, "security.unscoped_queries")
+
+		server.config.Security.UnscopedQueries = true
+		response, err = server.HandleExecuteCypherInternal(context.Background(), input)
+		require.NoError(t, err)
+		data = response.Content[1].(map[string]any)["resource"].(map[string]any)["data"].(map[string]any)
+		assert.NotContains(t, data, "scoped_query")
+		mockAdapter.AssertCalled(t, "StreamQuery", readOnly, query, mock.Anything)
+	})
+}
@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/compozy/gograph/engine/graph"
+)
+
+const (
+	// DefaultPageSize is the number of rows a paginated tool returns when no
+	// page_size is given
+	DefaultPageSize = 100
+	// MaxPageSize is the largest page_size a paginated tool accepts
+	MaxPageSize = 1000
+)
+
+// pageCursor is the position a continuation token resumes a query at. The
+// digest ties the token to the query and parameters it was issued for.
+type pageCursor struct {
+	Offset int    `json:"offset"`
+	Digest string `json:"digest"`
+}
+
+// page is a request for the rows of a query from an offset on
+type page struct {
+	size   int
+	offset int
+	digest string
+}
+
+// parsePage reads the page_size and cursor inputs of a paginated tool for a
+// query and its parameters
+func parsePage(input map[string]any, query string, params map[string]any) (*page, error) {
+	p := &page{size: DefaultPageSize, digest: queryDigest(query, params)}
+	switch size := input["page_size"].(type) {
+	case int:
+		p.size = size
+	case float64:
+		p.size = int(size)
+	}
+	if p.size == 0 {
+		p.size = DefaultPageSize
+	}
+	if p.size < 0 || p.size > MaxPageSize {
+		return nil, fmt.Errorf("page_size must be between 1 and %d", MaxPageSize)
+	}
+	token, ok := input["cursor"].(string)
+	if !ok || token == "" {
+		return p, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Offset < 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if cursor.Digest != p.digest {
+		return nil, fmt.Errorf("cursor was issued for another query or parameters")
+	}
+	p.offset = cursor.Offset
+	return p, nil
+}
+
+// next returns the continuation token of the page after this one
+func (p *page) next() string {
+	data, err := json.Marshal(pageCursor{Offset: p.offset + p.size, Digest: p.digest})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// pageText tells the client how to continue when more rows are available
+func pageText(text, cursor string) string {
+	if cursor == "" {
+		return text
+	}
+	return text + "; more results are available, pass next_cursor as cursor to continue"
+}
+
+// queryDigest identifies a query and its parameters
+func queryDigest(query string, params map[string]any) string {
+	hash := sha256.New()
+	hash.Write([]byte(query))
+	if data, err := json.Marshal(params); err == nil { // Map keys are sorted
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// queryPage streams the rows of a page of a query, skipping the rows of the
+// earlier pages and stopping the query once the page is full. The returned
+// token continues after the page, or is empty on the last page. Each page runs
+// the query again, so pages only line up for queries with a total ORDER BY,
+// and later pages cost more as the skipped rows are read again.
+func (s *Server) queryPage(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+	p *page,
+) ([]map[string]any, string, error) {
+	rows := make([]map[string]any, 0, min(p.size, DefaultPageSize))
+	skipped, more := 0, false
+	err := s.serviceAdapter.StreamQuery(ctx, query, params, func(row map[string]any) error {
+		if skipped < p.offset {
+			skipped++
+			return nil
+		}
+		if len(rows) == p.size {
+			more = true
+			return graph.ErrStopStream
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if !more {
+		return rows, "", nil
+	}
+	return rows, p.next(), nil
+}
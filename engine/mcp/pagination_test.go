@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagination(t *testing.T) {
+	rows := make([]map[string]any, 5)
+	for i := range rows {
+		rows[i] = map[string]any{"name": fmt.Sprintf("pkg%d", i)}
+	}
+	mockAdapter := new(MockServiceAdapter)
+	mockAdapter.On("StreamQuery", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+	server := &Server{serviceAdapter: mockAdapter}
+
+	data := func(response *ToolResponse) map[string]any {
+		return response.Content[1].(map[string]any)["resource"].(map[string]any)["data"].(map[string]any)
+	}
+	execute := func(input map[string]any) (map[string]any, error) {
+		input["project_id"] = "test-project"
+		input["query"] = "MATCH (p:Package) RETURN p.name AS name ORDER BY name"
+		response, err := server.HandleExecuteCypherInternal(context.Background(), input)
+		if err != nil {
+			return nil, err
+		}
+		return data(response), nil
+	}
+
+	t.Run("Should return pages and continue from the cursor", func(t *testing.T) {
+		var names []any
+		cursor := ""
+		for pages := 0; pages < 5; pages++ {
+			result, err := execute(map[string]any{"page_size": 2, "cursor": cursor})
+			require.NoError(t, err)
+			for _, row := range result["results"].([]map[string]any) {
+				names = append(names, row["name"])
+			}
+			next, ok := result["next_cursor"].(string)
+			if !ok {
+				break
+			}
+			cursor = next
+		}
+		assert.Equal(t, []any{"pkg0", "pkg1", "pkg2", "pkg3", "pkg4"}, names)
+	})
+
+	t.Run("Should not return a cursor when every row fits", func(t *testing.T) {
+		result, err := execute(map[string]any{"page_size": float64(5)})
+		require.NoError(t, err)
+		assert.Equal(t, 5, result["result_count"])
+		assert.NotContains(t, result, "next_cursor")
+
+		response, err := server.HandleListPackagesInternal(context.Background(), map[string]any{
+			"project_id": "test-project",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, DefaultPageSize, data(response)["page_size"])
+		assert.Equal(t, 5, data(response)["count"])
+	})
+
+	t.Run("Should reject cursors of other queries and invalid page sizes", func(t *testing.T) {
+		result, err := execute(map[string]any{"page_size": 2})
+		require.NoError(t, err)
+		_, err = server.HandleListPackagesInternal(context.Background(), map[string]any{
+			"project_id": "test-project",
+			"cursor":     result["next_cursor"],
+		})
+		assert.ErrorContains(t, err, "cursor was issued for another query")
+
+		_, err = execute(map[string]any{"cursor": "not a cursor"})
+		assert.ErrorContains(t, err, "invalid cursor")
+		_, err = execute(map[string]any{"page_size": MaxPageSize + 1})
+		assert.ErrorContains(t, err, "page_size must be between")
+	})
+}
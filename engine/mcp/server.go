@@ -131,9 +131,33 @@ func (s *Server) registerAnalysisTools() {
 		mcp.WithBoolean("recursive", mcp.Description("Include transitive dependencies")),
 	)
 	s.mcpServer.AddTool(queryDependenciesTool, s.handleQueryDependencies)
+
+	// analyze_impact tool
+	analyzeImpactTool := mcp.NewTool(
+		"analyze_impact",
+		mcp.WithDescription(
+			"List the functions, packages and entrypoints transitively affected by a change, ranked by distance. "+
+				"Use it to check the blast radius before editing.",
+		),
+		mcp.WithString(
+			"project_id",
+			mcp.Description("Project identifier (optional - will be derived from config if not provided)"),
+		),
+		mcp.WithString("diff", mcp.Description("Unified diff of the change (required unless since is set)")),
+		mcp.WithString("since", mcp.Description("Use the working tree changes since this git revision instead of a diff")),
+		mcp.WithString("project_path", mcp.Description("Path of the project, used with since and for relative file paths")),
+		mcp.WithBoolean(
+			"unapplied",
+			mcp.Description("The diff is a proposed change not yet contained in the analyzed code"),
+		),
+		mcp.WithNumber("max_depth", mcp.Description("Maximum number of edges to walk (default: no limit)")),
+	)
+	s.mcpServer.AddTool(analyzeImpactTool, s.handleAnalyzeImpact)
 }
 
 // registerNavigationTools registers code navigation tools
+//
+//nolint:funlen // Tool definitions are declarative
 func (s *Server) registerNavigationTools() {
 	// find_implementations tool
 	findImplementationsTool := mcp.NewTool(
@@ -202,6 +226,7 @@ func (s *Server) registerNavigationTools() {
 		),
 		mcp.WithString("pattern", mcp.Description("Filter packages by pattern")),
 		mcp.WithBoolean("include_external", mcp.Description("Include external dependencies")),
+		withPagination(),
 	)
 	s.mcpServer.AddTool(listPackagesTool, s.handleListPackages)
 
@@ -219,18 +244,33 @@ func (s *Server) registerNavigationTools() {
 	s.mcpServer.AddTool(getPackageStructureTool, s.handleGetPackageStructure)
 }
 
+// withPagination adds the page_size and cursor inputs of a paginated tool
+func withPagination() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithNumber("page_size", mcp.Description(
+			fmt.Sprintf("Maximum number of rows to return (default %d, max %d)", DefaultPageSize, MaxPageSize),
+		))(tool)
+		mcp.WithString("cursor", mcp.Description("Continuation token from the next_cursor of a previous call"))(tool)
+	}
+}
+
 // registerQueryTools registers query and execution tools
 func (s *Server) registerQueryTools() {
 	// execute_cypher tool
 	executeCypherTool := mcp.NewTool(
 		"execute_cypher",
-		mcp.WithDescription("Execute a Cypher query against the graph database"),
+		mcp.WithDescription(
+			"Execute a Cypher query against the graph database. Node patterns are limited to the project, "+
+				"and queries are read-only unless the server allows writes. Results are paginated; "+
+				"pages are only stable between calls when the query has an ORDER BY",
+		),
 		mcp.WithString(
 			"project_id",
 			mcp.Description("Project identifier (optional - will be derived from config if not provided)"),
 		),
 		mcp.WithString("query", mcp.Required(), mcp.Description("Cypher query to execute")),
 		mcp.WithObject("parameters", mcp.Description("Query parameters")),
+		withPagination(),
 	)
 	s.mcpServer.AddTool(executeCypherTool, s.handleExecuteCypher)
 
@@ -467,6 +507,22 @@ func (s *Server) handleQueryDependencies(ctx context.Context, req mcp.CallToolRe
 	return newToolResultFromResponse(response)
 }
 
+func (s *Server) handleAnalyzeImpact(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	response, err := s.HandleAnalyzeImpactInternal(ctx, map[string]any{
+		"project_id":   getString(req, "project_id"),
+		"diff":         getString(req, "diff"),
+		"since":        getString(req, "since"),
+		"project_path": getString(req, "project_path"),
+		"unapplied":    req.GetBool("unapplied", false),
+		"max_depth":    req.GetInt("max_depth", 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newToolResultFromResponse(response)
+}
+
 func (s *Server) handleFindImplementations(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// project_id is now optional - will be derived from config if not provided
 	projectID := getString(req, "project_id")
@@ -568,6 +624,8 @@ func (s *Server) handleListPackages(ctx context.Context, req mcp.CallToolRequest
 		"project_id":       projectID,
 		"pattern":          pattern,
 		"include_external": includeExternal,
+		"page_size":        req.GetInt("page_size", 0),
+		"cursor":           getString(req, "cursor"),
 	})
 	if err != nil {
 		return nil, err
@@ -610,6 +668,8 @@ func (s *Server) handleExecuteCypher(ctx context.Context, req mcp.CallToolReques
 		"project_id": projectID,
 		"query":      query,
 		"parameters": parameters,
+		"page_size":  req.GetInt("page_size", 0),
+		"cursor":     getString(req, "cursor"),
 	})
 	if err != nil {
 		return nil, err
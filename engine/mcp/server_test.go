@@ -2,6 +2,7 @@ package mcp_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -79,6 +80,25 @@ func (m *MockServiceAdapter) ExecuteQuery(
 	return nil, args.Error(1)
 }
 
+func (m *MockServiceAdapter) StreamQuery(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+	fn graph.RowFunc,
+) error {
+	args := m.Called(ctx, query, params)
+	if rows, ok := args.Get(0).([]map[string]any); ok {
+		for _, row := range rows {
+			if err := fn(row); errors.Is(err, graph.ErrStopStream) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MockServiceAdapter) BuildAnalysisResult(
 	ctx context.Context,
 	projectID core.ID,
@@ -109,6 +109,16 @@ func (s *serviceAdapter) ExecuteQuery(
 	return s.repository.ExecuteQuery(ctx, query, params)
 }
 
+// StreamQuery executes a custom Cypher query and passes its rows to fn as they are read
+func (s *serviceAdapter) StreamQuery(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+	fn graph.RowFunc,
+) error {
+	return s.repository.StreamQuery(ctx, query, params, fn)
+}
+
 // ListProjects lists all projects in the database
 func (s *serviceAdapter) ListProjects(ctx context.Context) ([]core.Project, error) {
 	query := `
@@ -32,6 +32,7 @@ type ServiceAdapter interface {
 	ImportAnalysisResult(ctx context.Context, result *core.AnalysisResult) (*graph.ProjectGraph, error)
 	GetProjectStatistics(ctx context.Context, projectID core.ID) (*graph.ProjectStatistics, error)
 	ExecuteQuery(ctx context.Context, query string, params map[string]any) ([]map[string]any, error)
+	StreamQuery(ctx context.Context, query string, params map[string]any, fn graph.RowFunc) error
 
 	// Project management operations
 	ListProjects(ctx context.Context) ([]core.Project, error)
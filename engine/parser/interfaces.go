@@ -74,6 +74,7 @@ type ImportInfo struct {
 	Name    string            // Local name (alias or package name)
 	Path    string            // Import path
 	Package *packages.Package // Resolved package
+	Line    int               // Line number of the import spec
 }
 
 // FunctionInfo represents a function or method with type information
@@ -86,6 +87,7 @@ type FunctionInfo struct {
 	CalledBy   []*FunctionInfo
 	LineStart  int
 	LineEnd    int
+	Complexity int // Cyclomatic complexity of the body
 	IsExported bool
 }
 
@@ -206,6 +208,7 @@ type SSAMemoryProfile struct {
 type Config struct {
 	IgnoreDirs             []string
 	IgnoreFiles            []string
+	Patterns               []string // Package patterns to load, relative to the project (defaults to ./...)
 	IncludeTests           bool
 	IncludeVendor          bool
 	BuildTags              []string
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 	"os"
 	"path/filepath"
@@ -149,9 +150,12 @@ func (s *Service) loadPackages(ctx context.Context, projectPath string, config *
 		Tests:   config.IncludeTests,
 	}
 
-	// Load all packages in the project
-	pattern := "./..."
-	pkgs, err := packages.Load(pkgConfig, pattern)
+	// Load all packages in the project unless a subset is requested
+	patterns := config.Patterns
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	pkgs, err := packages.Load(pkgConfig, patterns...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load packages: %w", err)
 	}
@@ -653,6 +657,7 @@ func (s *Service) processImports(pkg *packages.Package, file *ast.File, fileInfo
 		impPath := strings.Trim(imp.Path.Value, `"`)
 		impInfo := &ImportInfo{
 			Path: impPath,
+			Line: pkg.Fset.Position(imp.Pos()).Line,
 		}
 
 		if imp.Name != nil {
@@ -724,6 +729,7 @@ func (s *Service) processFuncDecl(pkg *packages.Package, decl *ast.FuncDecl) *Fu
 		IsExported: ast.IsExported(decl.Name.Name),
 		LineStart:  pkg.Fset.Position(decl.Pos()).Line,
 		LineEnd:    pkg.Fset.Position(decl.End()).Line,
+		Complexity: cyclomaticComplexity(decl),
 		Calls:      make([]*FunctionCall, 0),
 	}
 
@@ -773,6 +779,36 @@ func (s *Service) extractFunctionCalls(pkg *packages.Package, decl *ast.FuncDecl
 	})
 }
 
+// cyclomaticComplexity counts the independent paths through a function: one
+// plus a branch for every if, loop, case, select clause and && or || operator.
+// Function literals in the body count towards the enclosing function.
+func cyclomaticComplexity(decl *ast.FuncDecl) int {
+	complexity := 1
+	if decl.Body == nil {
+		return complexity
+	}
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if node.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if node.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if node.Op == token.LAND || node.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
 // processTypeSpec processes a type specification
 func (s *Service) processTypeSpec(pkg *packages.Package, spec *ast.TypeSpec) *TypeInfo {
 	typeInfo := &TypeInfo{
@@ -1310,3 +1310,50 @@ go 1.21
 		assert.GreaterOrEqual(t, perfStats.MemoryProfile.FinalMemoryMB, int64(0))
 	})
 }
+
+func TestService_CyclomaticComplexity(t *testing.T) {
+	testDir := t.TempDir()
+	testContent := `package main
+
+func simple() int {
+	return 1
+}
+
+func branchy(values []int, done chan bool) int {
+	total := 0
+	for _, v := range values {
+		if v > 0 && v < 10 || v == 42 {
+			total += v
+		}
+	}
+	switch total {
+	case 1, 2:
+		total++
+	default:
+		total--
+	}
+	select {
+	case <-done:
+	default:
+	}
+	return total
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "go.mod"), []byte("module testproject\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "main.go"), []byte(testContent), 0644))
+
+	t.Run("Should count branches, cases and boolean operators", func(t *testing.T) {
+		result, err := parser.NewService(nil).ParseProject(context.Background(), testDir, nil)
+		require.NoError(t, err)
+
+		complexity := make(map[string]int)
+		for _, pkg := range result.Packages {
+			for _, fn := range pkg.Functions {
+				complexity[fn.Name] = fn.Complexity
+			}
+		}
+		assert.Equal(t, 1, complexity["simple"])
+		// 1 + range + if + && + || + one case + one comm clause
+		assert.Equal(t, 7, complexity["branchy"])
+	})
+}
@@ -0,0 +1,149 @@
+package query
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// BaselineVersion is the current version of the baseline file format
+const BaselineVersion = 1
+
+// Fingerprint returns a stable identifier for the finding. It is derived from
+// the rule and the symbol, so findings survive unrelated line changes.
+func (f *Finding) Fingerprint() string {
+	key := f.Symbol
+	if key == "" {
+		key = f.Message
+	}
+	sum := sha256.Sum256([]byte(f.RuleID + "\x00" + key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Baseline records accepted findings so later runs only fail on new ones
+type Baseline struct {
+	Version  int              `json:"version"`
+	Findings []*BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry is a single accepted finding
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	RuleID      string `json:"rule_id"`
+	Symbol      string `json:"symbol,omitempty"`
+	Message     string `json:"message"`
+	Count       int    `json:"count"`
+}
+
+// BaselineComparison splits current findings against a baseline
+type BaselineComparison struct {
+	New       []*Finding       // Findings not present in the baseline
+	Unchanged []*Finding       // Findings already accepted by the baseline
+	Fixed     []*BaselineEntry // Baseline entries no longer found
+}
+
+// NewBaseline creates a baseline from the current findings. Entries are
+// sorted by rule and symbol so that the file diffs cleanly.
+func NewBaseline(findings []*Finding) *Baseline {
+	entries := make(map[string]*BaselineEntry)
+	for _, finding := range findings {
+		fingerprint := finding.Fingerprint()
+		if entry, exists := entries[fingerprint]; exists {
+			entry.Count++
+			continue
+		}
+		entries[fingerprint] = &BaselineEntry{
+			Fingerprint: fingerprint,
+			RuleID:      finding.RuleID,
+			Symbol:      finding.Symbol,
+			Message:     finding.Message,
+			Count:       1,
+		}
+	}
+
+	baseline := &Baseline{Version: BaselineVersion, Findings: make([]*BaselineEntry, 0, len(entries))}
+	for _, entry := range entries {
+		baseline.Findings = append(baseline.Findings, entry)
+	}
+	sort.Slice(baseline.Findings, func(i, j int) bool {
+		a, b := baseline.Findings[i], baseline.Findings[j]
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+	return baseline
+}
+
+// LoadBaseline reads a baseline file
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+	if baseline.Version != BaselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d (expected %d)", baseline.Version, BaselineVersion)
+	}
+	return &baseline, nil
+}
+
+// Save writes the baseline as indented JSON
+func (b *Baseline) Save(path string) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(b); err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Compare classifies findings as new or unchanged and reports baseline
+// entries that were fixed. A fingerprint accepted N times in the baseline
+// absorbs up to N current findings; any excess is reported as new.
+func (b *Baseline) Compare(findings []*Finding) *BaselineComparison {
+	remaining := make(map[string]int, len(b.Findings))
+	for _, entry := range b.Findings {
+		remaining[entry.Fingerprint] += entry.Count
+	}
+
+	comparison := &BaselineComparison{
+		New:       make([]*Finding, 0),
+		Unchanged: make([]*Finding, 0),
+		Fixed:     make([]*BaselineEntry, 0),
+	}
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		fingerprint := finding.Fingerprint()
+		seen[fingerprint] = true
+		if remaining[fingerprint] > 0 {
+			remaining[fingerprint]--
+			comparison.Unchanged = append(comparison.Unchanged, finding)
+			continue
+		}
+		comparison.New = append(comparison.New, finding)
+	}
+
+	for _, entry := range b.Findings {
+		if !seen[entry.Fingerprint] {
+			comparison.Fixed = append(comparison.Fixed, entry)
+		}
+	}
+	return comparison
+}
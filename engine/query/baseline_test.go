@@ -0,0 +1,87 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinding_Fingerprint(t *testing.T) {
+	t.Run("Should_ignore_line_and_message_changes_when_symbol_is_set", func(t *testing.T) {
+		before := &Finding{RuleID: RuleLayerViolation, Symbol: "a -> b", Message: "old", File: "a.go", Line: 3}
+		after := &Finding{RuleID: RuleLayerViolation, Symbol: "a -> b", Message: "new", File: "a.go", Line: 42}
+
+		assert.Equal(t, before.Fingerprint(), after.Fingerprint())
+	})
+
+	t.Run("Should_distinguish_rules_and_symbols", func(t *testing.T) {
+		base := &Finding{RuleID: RuleLayerViolation, Symbol: "a -> b"}
+
+		assert.NotEqual(t, base.Fingerprint(), (&Finding{RuleID: RuleDependencyDepth, Symbol: "a -> b"}).Fingerprint())
+		assert.NotEqual(t, base.Fingerprint(), (&Finding{RuleID: RuleLayerViolation, Symbol: "a -> c"}).Fingerprint())
+	})
+
+	t.Run("Should_fall_back_to_message_without_symbol", func(t *testing.T) {
+		first := &Finding{RuleID: RulePolicyPrefix + "p", Message: "one"}
+		second := &Finding{RuleID: RulePolicyPrefix + "p", Message: "two"}
+
+		assert.NotEqual(t, first.Fingerprint(), second.Fingerprint())
+	})
+}
+
+func TestBaseline(t *testing.T) {
+	layer := &Finding{RuleID: RuleLayerViolation, Symbol: "domain -> infra", Message: "domain imports infra", Line: 7}
+	cycle := &Finding{RuleID: RuleCircularDependency, Symbol: "a -> b", Message: "cycle a -> b -> a"}
+	unused := &Finding{RuleID: RuleUnusedFunction, Symbol: "util.helper", Message: "helper is unused"}
+
+	t.Run("Should_sort_and_count_entries", func(t *testing.T) {
+		baseline := NewBaseline([]*Finding{layer, unused, cycle, layer})
+
+		assert.Equal(t, BaselineVersion, baseline.Version)
+		require.Len(t, baseline.Findings, 3)
+		assert.Equal(t, RuleCircularDependency, baseline.Findings[0].RuleID)
+		assert.Equal(t, RuleLayerViolation, baseline.Findings[1].RuleID)
+		assert.Equal(t, 2, baseline.Findings[1].Count)
+		assert.Equal(t, RuleUnusedFunction, baseline.Findings[2].RuleID)
+	})
+
+	t.Run("Should_round_trip_through_file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "baseline.json")
+		baseline := NewBaseline([]*Finding{layer, cycle})
+
+		require.NoError(t, baseline.Save(path))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"symbol": "domain -> infra"`)
+
+		loaded, err := LoadBaseline(path)
+		require.NoError(t, err)
+		assert.Equal(t, baseline, loaded)
+	})
+
+	t.Run("Should_reject_unsupported_version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "baseline.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": 99, "findings": []}`), 0600))
+
+		_, err := LoadBaseline(path)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported baseline version 99")
+	})
+
+	t.Run("Should_classify_new_unchanged_and_fixed_findings", func(t *testing.T) {
+		baseline := NewBaseline([]*Finding{layer, cycle})
+		moved := &Finding{RuleID: layer.RuleID, Symbol: layer.Symbol, Message: layer.Message, Line: 12}
+		duplicate := &Finding{RuleID: layer.RuleID, Symbol: layer.Symbol, Message: layer.Message, Line: 30}
+
+		comparison := baseline.Compare([]*Finding{moved, unused, duplicate})
+
+		assert.Equal(t, []*Finding{moved}, comparison.Unchanged)
+		assert.Equal(t, []*Finding{unused, duplicate}, comparison.New)
+		require.Len(t, comparison.Fixed, 1)
+		assert.Equal(t, RuleCircularDependency, comparison.Fixed[0].RuleID)
+	})
+}
@@ -170,7 +170,22 @@ func (b *Builder) String() string {
 	return strings.TrimSpace(b.query.String())
 }
 
-// HighLevelBuilder provides high-level query building methods for common patterns
+// compiled returns a builder holding a query of the code graph DSL, compiled
+// for a project
+func compiled(q *Selection, projectID core.ID) *Builder {
+	b := NewBuilder()
+	cypherQuery, params, err := q.Build(projectID)
+	if err != nil {
+		b.errors = append(b.errors, err)
+		return b
+	}
+	b.query.WriteString(cypherQuery)
+	return b.SetParameters(params)
+}
+
+// HighLevelBuilder provides high-level query building methods for common patterns.
+// Queries the code graph DSL can express are compiled from it; those over
+// paths and computed values are written out.
 type HighLevelBuilder struct{}
 
 // NewHighLevelBuilder creates a new high-level query builder
@@ -180,12 +195,7 @@ func NewHighLevelBuilder() *HighLevelBuilder {
 
 // FindNodesByType creates a query to find nodes by type and project
 func (hlb *HighLevelBuilder) FindNodesByType(nodeType core.NodeType, projectID core.ID) *Builder {
-	return NewBuilder().
-		Match(fmt.Sprintf("(n:%s)", nodeType)).
-		Where("n.project_id = $project_id").
-		ProjectFilter(projectID).
-		Return("n").
-		OrderBy("n.name")
+	return compiled(Find(Nodes(nodeType)).ReturnNode().OrderBy(string(PropertyName)), projectID)
 }
 
 // FindRelationshipsByType creates a query to find relationships by type
@@ -199,14 +209,7 @@ func (hlb *HighLevelBuilder) FindRelationshipsByType(relType core.RelationType,
 
 // FindNodesByName creates a query to find nodes by name pattern
 func (hlb *HighLevelBuilder) FindNodesByName(namePattern string, projectID core.ID) *Builder {
-	return NewBuilder().
-		Match("(n)").
-		Where("n.project_id = $project_id").
-		And("toLower(n.name) CONTAINS toLower($name_pattern)").
-		ProjectFilter(projectID).
-		SetParameter("name_pattern", namePattern).
-		Return("n").
-		OrderBy("labels(n)[0], n.name")
+	return compiled(Find(Nodes().NameLike(namePattern)).ReturnNode().OrderBy(string(PropertyName)), projectID)
 }
 
 // FindDependencies creates a query to find dependencies for a specific node
@@ -276,24 +279,27 @@ func (hlb *HighLevelBuilder) FindComplexFunctions(projectID core.ID, minComplexi
 		Where("f.project_id = $project_id").
 		With("f, (f.line_end - f.line_start) as complexity").
 		Where("complexity >= $min_complexity").
+		OptionalMatch("(file:File)-[:DEFINES]->(f)").
 		ProjectFilter(projectID).
 		SetParameter("min_complexity", minComplexity).
-		Return("f.package as package, f.name as function, complexity, f.signature as signature").
+		Return("f.package as package, f.name as function, complexity, f.signature as signature, " +
+			"file.path as file, f.line_start as line").
 		OrderBy("complexity DESC")
 }
 
 // FindUnusedFunctions creates a query to find potentially unused functions
 func (hlb *HighLevelBuilder) FindUnusedFunctions(projectID core.ID) *Builder {
-	return NewBuilder().
-		Match("(f:Function)").
-		Where("f.project_id = $project_id").
-		And("NOT EXISTS { MATCH ()-[:CALLS]->(f) }").
-		And("f.name <> 'main'").
-		And("f.name <> 'init'").
-		And("NOT f.name STARTS WITH 'Test'").
-		ProjectFilter(projectID).
-		Return("f.package as package, f.name as function, f.signature as signature").
-		OrderBy("f.package, f.name")
+	functions := Functions().
+		NotRelated(core.RelationCalls, Incoming, nil).
+		Excluding(PropertyName, In, []string{"main", "init"}).
+		Excluding(PropertyName, StartsWith, "Test")
+	return compiled(Find(functions).As("f").
+		ReturnFrom("f", PropertyPackage, "package").
+		ReturnFrom("f", PropertyName, "function").
+		ReturnFrom("f", PropertySignature, "signature").
+		WithFile("file").
+		ReturnFrom("f", PropertyLineStart, "line").
+		OrderBy("package", "function"), projectID)
 }
 
 // FindCircularDependencies creates a query to detect circular dependencies
@@ -308,22 +314,23 @@ func (hlb *HighLevelBuilder) FindCircularDependencies(projectID core.ID) *Builde
 
 // FindInterfaceImplementations creates a query to find interface implementations
 func (hlb *HighLevelBuilder) FindInterfaceImplementations(projectID core.ID) *Builder {
-	return NewBuilder().
-		Match("(s:Struct)-[:IMPLEMENTS]->(i:Interface)").
-		Where("s.project_id = $project_id").
-		ProjectFilter(projectID).
-		Return("i.package as interface_package, i.name as interface_name, " +
-			"s.package as struct_package, s.name as struct_name").
-		OrderBy("i.name, s.name")
+	return compiled(Find(Structs()).As("s").
+		Join(core.RelationImplements, Outgoing, Interfaces(), "i").
+		ReturnFrom("i", PropertyPackage, "interface_package").
+		ReturnFrom("i", PropertyName, "interface_name").
+		ReturnFrom("s", PropertyPackage, "struct_package").
+		ReturnFrom("s", PropertyName, "struct_name").
+		OrderBy("interface_name", "struct_name"), projectID)
 }
 
 // FindMostCalledFunctions creates a query to find the most called functions
 func (hlb *HighLevelBuilder) FindMostCalledFunctions(projectID core.ID, limit int) *Builder {
-	return NewBuilder().
-		Match("(f:Function)<-[:CALLS]-()").
-		Where("f.project_id = $project_id").
-		ProjectFilter(projectID).
-		Return("f.package as package, f.name as function, count(*) as call_count, f.signature as signature").
-		OrderBy("call_count DESC").
-		Limit(limit)
+	return compiled(Find(Functions()).As("f").
+		Join(core.RelationCalls, Incoming, nil, "caller").
+		ReturnFrom("f", PropertyPackage, "package").
+		ReturnFrom("f", PropertyName, "function").
+		Count("call_count").
+		ReturnFrom("f", PropertySignature, "signature").
+		OrderByDescending("call_count").
+		Limit(limit), projectID)
 }
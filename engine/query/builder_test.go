@@ -1,9 +1,11 @@
 package query
 
 import (
+	"context"
 	"testing"
 
 	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/cypher"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -70,8 +72,8 @@ func TestHighLevelBuilder(t *testing.T) {
 
 		query, params, err := builder.Build()
 		require.NoError(t, err)
-		assert.Contains(t, query, "MATCH (n:Function)")
-		assert.Contains(t, query, "WHERE n.project_id = $project_id")
+		assert.Contains(t, query, "MATCH (n:Function {project_id: $project_id})")
+		assert.Contains(t, query, "ORDER BY n.name")
 		assert.Equal(t, string(projectID), params["project_id"])
 	})
 
@@ -98,4 +100,17 @@ func TestHighLevelBuilder(t *testing.T) {
 		assert.Contains(t, query, "RETURN labels(n)[0] as node_type, count(n) as count")
 		assert.Equal(t, string(projectID), params["project_id"])
 	})
+	t.Run("Should_compile_queries_of_the_dsl_for_the_project", func(t *testing.T) {
+		hlb := NewHighLevelBuilder()
+		query, params, err := hlb.FindMostCalledFunctions("p1", 1).Build()
+		require.NoError(t, err)
+		results, err := cypher.Execute(context.Background(), dslGraph(), query, params)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "encode", results[0]["function"])
+		assert.Equal(t, int64(2), results[0]["call_count"])
+
+		_, _, err = hlb.FindUnusedFunctions("").Build()
+		assert.Error(t, err)
+	})
 }
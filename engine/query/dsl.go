@@ -0,0 +1,645 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/compozy/gograph/engine/core"
+)
+
+// Property is a property key of the nodes an analysis stores
+type Property string
+
+const (
+	PropertyID         Property = "id"
+	PropertyName       Property = "name"
+	PropertyPath       Property = "path"
+	PropertyPackage    Property = "package"
+	PropertyImportPath Property = "import_path"
+	PropertyExported   Property = "is_exported"
+	PropertySignature  Property = "signature"
+	PropertyLineStart  Property = "line_start"
+	PropertyLineEnd    Property = "line_end"
+	PropertyComplexity Property = "complexity"
+	PropertyReceiver   Property = "receiver"
+	PropertyAlias      Property = "alias"
+)
+
+// commonProperties are the properties of every node
+var commonProperties = []Property{PropertyID, PropertyName, PropertyPath}
+
+// nodeProperties are the properties of the nodes of each label besides the
+// common ones
+var nodeProperties = map[core.NodeType][]Property{
+	core.NodeTypePackage: {PropertyImportPath},
+	core.NodeTypeFile:    {PropertyPackage},
+	core.NodeTypeFunction: {
+		PropertyPackage, PropertyExported, PropertySignature, PropertyLineStart, PropertyLineEnd, PropertyComplexity,
+	},
+	core.NodeTypeMethod: {
+		PropertyPackage, PropertyExported, PropertySignature, PropertyLineStart, PropertyLineEnd, PropertyComplexity,
+		PropertyReceiver,
+	},
+	core.NodeTypeStruct:    {PropertyPackage, PropertyExported, PropertyLineStart, PropertyLineEnd},
+	core.NodeTypeInterface: {PropertyPackage, PropertyExported, PropertyLineStart, PropertyLineEnd},
+	core.NodeTypeImport:    {PropertyAlias},
+	core.NodeTypeConstant:  {PropertyPackage, PropertyExported},
+	core.NodeTypeVariable:  {PropertyPackage, PropertyExported},
+}
+
+// Operator compares a property with a value
+type Operator string
+
+const (
+	Equals      Operator = "="
+	NotEquals   Operator = "<>"
+	LessThan    Operator = "<"
+	AtMost      Operator = "<="
+	GreaterThan Operator = ">"
+	AtLeast     Operator = ">="
+	Contains    Operator = "CONTAINS"
+	StartsWith  Operator = "STARTS WITH"
+	EndsWith    Operator = "ENDS WITH"
+	In          Operator = "IN"
+)
+
+var operators = []Operator{
+	Equals, NotEquals, LessThan, AtMost, GreaterThan, AtLeast, Contains, StartsWith, EndsWith, In,
+}
+
+// Direction is the direction of a relationship, seen from the nodes of a set
+type Direction int
+
+const (
+	Outgoing Direction = iota // (n)-[:TYPE]->(other)
+	Incoming                  // (n)<-[:TYPE]-(other)
+)
+
+// NodeSet selects nodes of an analyzed project by label, properties and
+// relationships. Each method adds a condition the nodes must meet. Labels,
+// relationship types and properties are checked against the graph schema;
+// mistakes are reported when the query is built.
+type NodeSet struct {
+	labels     []core.NodeType
+	conditions []condition
+	errs       []error
+}
+
+// condition returns a predicate on the nodes bound to a variable
+type condition func(c *compiler, variable string) string
+
+// Nodes selects the nodes of any of the labels, or of any label when none
+// is given
+func Nodes(labels ...core.NodeType) *NodeSet {
+	s := &NodeSet{labels: labels}
+	for _, label := range labels {
+		if _, known := nodeProperties[label]; !known {
+			s.errs = append(s.errs, fmt.Errorf("unknown node label %s", label))
+		}
+	}
+	return s
+}
+
+// Packages selects packages
+func Packages() *NodeSet { return Nodes(core.NodeTypePackage) }
+
+// Files selects source files
+func Files() *NodeSet { return Nodes(core.NodeTypeFile) }
+
+// Functions selects functions, which excludes methods
+func Functions() *NodeSet { return Nodes(core.NodeTypeFunction) }
+
+// Methods selects methods
+func Methods() *NodeSet { return Nodes(core.NodeTypeMethod) }
+
+// Structs selects structs
+func Structs() *NodeSet { return Nodes(core.NodeTypeStruct) }
+
+// Interfaces selects interfaces
+func Interfaces() *NodeSet { return Nodes(core.NodeTypeInterface) }
+
+// Imports selects imports
+func Imports() *NodeSet { return Nodes(core.NodeTypeImport) }
+
+// Constants selects constants
+func Constants() *NodeSet { return Nodes(core.NodeTypeConstant) }
+
+// Variables selects package variables
+func Variables() *NodeSet { return Nodes(core.NodeTypeVariable) }
+
+// Where keeps the nodes whose property compares to the value
+func (s *NodeSet) Where(property Property, op Operator, value any) *NodeSet {
+	return s.compare(property, op, value, false)
+}
+
+// Excluding drops the nodes whose property compares to the value
+func (s *NodeSet) Excluding(property Property, op Operator, value any) *NodeSet {
+	return s.compare(property, op, value, true)
+}
+
+func (s *NodeSet) compare(property Property, op Operator, value any, negate bool) *NodeSet {
+	if !slices.Contains(operators, op) {
+		s.errs = append(s.errs, fmt.Errorf("unknown operator %q", op))
+		return s
+	}
+	if !s.checkProperty(property) {
+		return s
+	}
+	return s.add(func(c *compiler, v string) string {
+		predicate := fmt.Sprintf("%s.%s %s $%s", v, property, op, c.param(string(property), value))
+		if negate {
+			return "NOT " + predicate
+		}
+		return predicate
+	})
+}
+
+// WhereAny keeps the nodes of which one of the properties compares to the
+// value
+func (s *NodeSet) WhereAny(op Operator, value any, properties ...Property) *NodeSet {
+	if len(properties) == 0 {
+		s.errs = append(s.errs, errors.New("WhereAny needs a property"))
+		return s
+	}
+	for _, property := range properties {
+		if !s.checkProperty(property) {
+			return s
+		}
+	}
+	return s.add(func(c *compiler, v string) string {
+		param := c.param(string(properties[0]), value)
+		predicates := make([]string, len(properties))
+		for i, property := range properties {
+			predicates[i] = fmt.Sprintf("%s.%s %s $%s", v, property, op, param)
+		}
+		return "(" + strings.Join(predicates, " OR ") + ")"
+	})
+}
+
+// Like keeps the nodes whose property equals the value or contains it,
+// ignoring case
+func (s *NodeSet) Like(property Property, value string) *NodeSet {
+	if !s.checkProperty(property) {
+		return s
+	}
+	return s.add(func(c *compiler, v string) string {
+		param := c.param(string(property), value)
+		return fmt.Sprintf("(%[1]s.%[2]s = $%[3]s OR toLower(%[1]s.%[2]s) CONTAINS toLower($%[3]s))", v, property, param)
+	})
+}
+
+// Named keeps the nodes of a name
+func (s *NodeSet) Named(name string) *NodeSet { return s.Where(PropertyName, Equals, name) }
+
+// NameLike keeps the nodes named name, or whose name contains it ignoring
+// case
+func (s *NodeSet) NameLike(name string) *NodeSet { return s.Like(PropertyName, name) }
+
+// WithID keeps the node of an ID
+func (s *NodeSet) WithID(id core.ID) *NodeSet { return s.Where(PropertyID, Equals, id.String()) }
+
+// InPackage keeps the nodes of a package, given by import path
+func (s *NodeSet) InPackage(pkg string) *NodeSet { return s.Where(PropertyPackage, Equals, pkg) }
+
+// Exported keeps exported nodes
+func (s *NodeSet) Exported() *NodeSet { return s.Where(PropertyExported, Equals, true) }
+
+// Unexported keeps unexported nodes
+func (s *NodeSet) Unexported() *NodeSet { return s.Where(PropertyExported, Equals, false) }
+
+// Calling keeps the nodes that call one of the other nodes
+func (s *NodeSet) Calling(other *NodeSet) *NodeSet {
+	return s.Related(core.RelationCalls, Outgoing, other)
+}
+
+// CalledBy keeps the nodes that one of the other nodes calls
+func (s *NodeSet) CalledBy(other *NodeSet) *NodeSet {
+	return s.Related(core.RelationCalls, Incoming, other)
+}
+
+// Implementing keeps the structs that implement one of the interfaces
+func (s *NodeSet) Implementing(interfaces *NodeSet) *NodeSet {
+	return s.Related(core.RelationImplements, Outgoing, interfaces)
+}
+
+// ImplementedBy keeps the interfaces that one of the structs implements
+func (s *NodeSet) ImplementedBy(structs *NodeSet) *NodeSet {
+	return s.Related(core.RelationImplements, Incoming, structs)
+}
+
+// DefinedIn keeps the nodes defined in one of the files
+func (s *NodeSet) DefinedIn(files *NodeSet) *NodeSet {
+	return s.Related(core.RelationDefines, Incoming, files)
+}
+
+// Defining keeps the files that define one of the other nodes
+func (s *NodeSet) Defining(other *NodeSet) *NodeSet {
+	return s.Related(core.RelationDefines, Outgoing, other)
+}
+
+// ContainedIn keeps the files contained in one of the packages
+func (s *NodeSet) ContainedIn(packages *NodeSet) *NodeSet {
+	return s.Related(core.RelationContains, Incoming, packages)
+}
+
+// Containing keeps the packages that contain one of the files
+func (s *NodeSet) Containing(files *NodeSet) *NodeSet {
+	return s.Related(core.RelationContains, Outgoing, files)
+}
+
+// Related keeps the nodes with a relationship of a type to one of the other
+// nodes. A nil set stands for any node of the project.
+func (s *NodeSet) Related(rel core.RelationType, direction Direction, other *NodeSet) *NodeSet {
+	return s.relate(rel, direction, other, false)
+}
+
+// NotRelated keeps the nodes without a relationship of a type to any of the
+// other nodes. A nil set stands for any node of the project.
+func (s *NodeSet) NotRelated(rel core.RelationType, direction Direction, other *NodeSet) *NodeSet {
+	return s.relate(rel, direction, other, true)
+}
+
+func (s *NodeSet) relate(rel core.RelationType, direction Direction, other *NodeSet, negate bool) *NodeSet {
+	if !schemaRelationshipTypes[string(rel)] {
+		s.errs = append(s.errs, fmt.Errorf("unknown relationship type %s", rel))
+		return s
+	}
+	if other == nil {
+		other = Nodes()
+	}
+	s.errs = append(s.errs, other.errs...)
+	return s.add(func(c *compiler, v string) string {
+		w := c.variable(other.variableBase())
+		predicate := "EXISTS { MATCH (" + v + ")" + relationship(rel, direction) + other.pattern(w)
+		if predicates := other.predicates(c, w); len(predicates) > 0 {
+			predicate += " WHERE " + strings.Join(predicates, " AND ")
+		}
+		predicate += " }"
+		if negate {
+			return "NOT " + predicate
+		}
+		return predicate
+	})
+}
+
+func (s *NodeSet) add(cond condition) *NodeSet {
+	s.conditions = append(s.conditions, cond)
+	return s
+}
+
+// checkProperty records an error unless the nodes of the set have the
+// property
+func (s *NodeSet) checkProperty(property Property) bool {
+	if !s.hasProperty(property) {
+		s.errs = append(s.errs, fmt.Errorf("nodes of %s have no property %s", s.describe(), property))
+		return false
+	}
+	return true
+}
+
+// hasProperty reports whether all labels of the set have the property, or
+// some label does for a set of any label
+func (s *NodeSet) hasProperty(property Property) bool {
+	if slices.Contains(commonProperties, property) {
+		return true
+	}
+	if len(s.labels) == 0 {
+		for _, properties := range nodeProperties {
+			if slices.Contains(properties, property) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, label := range s.labels {
+		if !slices.Contains(nodeProperties[label], property) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *NodeSet) describe() string {
+	if len(s.labels) == 0 {
+		return "any label"
+	}
+	names := make([]string, len(s.labels))
+	for i, label := range s.labels {
+		names[i] = string(label)
+	}
+	return "label " + strings.Join(names, " or ")
+}
+
+// pattern returns the node pattern of the set, scoped to the project
+func (s *NodeSet) pattern(variable string) string {
+	if len(s.labels) == 1 {
+		return fmt.Sprintf("(%s:%s {project_id: $project_id})", variable, s.labels[0])
+	}
+	return fmt.Sprintf("(%s {project_id: $project_id})", variable)
+}
+
+// predicates returns the conditions on the nodes bound to variable, starting
+// with their labels when the pattern cannot name a single one
+func (s *NodeSet) predicates(c *compiler, variable string) []string {
+	var predicates []string
+	if len(s.labels) > 1 {
+		labels := make([]string, len(s.labels))
+		for i, label := range s.labels {
+			labels[i] = variable + ":" + string(label)
+		}
+		predicates = append(predicates, "("+strings.Join(labels, " OR ")+")")
+	}
+	for _, cond := range s.conditions {
+		predicates = append(predicates, cond(c, variable))
+	}
+	return predicates
+}
+
+func (s *NodeSet) variableBase() string {
+	if len(s.labels) == 1 {
+		return strings.ToLower(string(s.labels[0]))
+	}
+	return "node"
+}
+
+func relationship(rel core.RelationType, direction Direction) string {
+	if direction == Incoming {
+		return "<-[:" + string(rel) + "]-"
+	}
+	return "-[:" + string(rel) + "]->"
+}
+
+// compiler names the variables and parameters of a query
+type compiler struct {
+	params    map[string]any
+	variables map[string]bool
+}
+
+func newCompiler(projectID core.ID) *compiler {
+	return &compiler{
+		params:    map[string]any{"project_id": projectID.String()},
+		variables: make(map[string]bool),
+	}
+}
+
+// param binds a value to a parameter named after key, numbered when the name
+// is taken, and returns the name
+func (c *compiler) param(key string, value any) string {
+	name := key
+	for i := 2; ; i++ {
+		if _, taken := c.params[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s_%d", key, i)
+	}
+	c.params[name] = value
+	return name
+}
+
+// variable returns a new variable named after base
+func (c *compiler) variable(base string) string {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s%d", base, i)
+		if !c.variables[name] {
+			c.variables[name] = true
+			return name
+		}
+	}
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// Selection is a query of the nodes of a set, optionally joined with related
+// nodes. It compiles to parameterized Cypher scoped to one project.
+type Selection struct {
+	set      *NodeSet
+	variable string
+	joins    []join
+	columns  []string
+	names    []string // Names of the columns
+	order    []func(c *compiler) string
+	file     bool
+	limit    int
+	errs     []error
+}
+
+// join is a node related to the selected ones, bound to a variable
+type join struct {
+	rel       core.RelationType
+	direction Direction
+	set       *NodeSet
+	variable  string
+}
+
+// Find starts a query of the nodes of a set, bound to the variable n
+func Find(set *NodeSet) *Selection {
+	return &Selection{set: set, variable: "n"}
+}
+
+// As binds the selected nodes to another variable
+func (q *Selection) As(variable string) *Selection {
+	q.variable = variable
+	return q
+}
+
+// Join matches the selected nodes with the related nodes of a set, bound to
+// a variable, once per related node. A nil set stands for any node.
+func (q *Selection) Join(rel core.RelationType, direction Direction, other *NodeSet, variable string) *Selection {
+	if !schemaRelationshipTypes[string(rel)] {
+		q.errs = append(q.errs, fmt.Errorf("unknown relationship type %s", rel))
+	}
+	if other == nil {
+		other = Nodes()
+	}
+	q.joins = append(q.joins, join{rel: rel, direction: direction, set: other, variable: variable})
+	return q
+}
+
+// Return adds columns of properties of the selected nodes, named after the
+// properties
+func (q *Selection) Return(properties ...Property) *Selection {
+	for _, property := range properties {
+		q.ReturnFrom(q.variable, property, string(property))
+	}
+	return q
+}
+
+// ReturnFrom adds a column of a property of the nodes bound to a variable
+func (q *Selection) ReturnFrom(variable string, property Property, name string) *Selection {
+	set := q.setOf(variable)
+	if set == nil {
+		q.errs = append(q.errs, fmt.Errorf("unknown variable %s", variable))
+		return q
+	}
+	if !set.checkProperty(property) {
+		return q
+	}
+	return q.column(fmt.Sprintf("%s.%s", variable, property), name)
+}
+
+// ReturnNode adds a column of the selected nodes, named after their variable
+func (q *Selection) ReturnNode() *Selection {
+	return q.column(q.variable, q.variable)
+}
+
+// Count adds a column counting the rows of each group of the other columns
+func (q *Selection) Count(name string) *Selection {
+	return q.column("count(*)", name)
+}
+
+// WithFile adds a column of the path of the file that defines the selected
+// nodes
+func (q *Selection) WithFile(name string) *Selection {
+	q.file = true
+	return q.column("file.path", name)
+}
+
+// OrderBy sorts the rows in ascending order by columns, or by properties of
+// the selected nodes that are not columns
+func (q *Selection) OrderBy(columns ...string) *Selection {
+	return q.sort(columns, "")
+}
+
+// OrderByDescending sorts the rows in descending order by columns, or by
+// properties of the selected nodes
+func (q *Selection) OrderByDescending(columns ...string) *Selection {
+	return q.sort(columns, " DESC")
+}
+
+func (q *Selection) sort(columns []string, suffix string) *Selection {
+	for _, column := range columns {
+		sortKey := column + suffix
+		switch {
+		case slices.Contains(q.names, column):
+		case q.set.hasProperty(Property(column)):
+			sortKey = q.variable + "." + sortKey
+		default:
+			q.errs = append(q.errs, fmt.Errorf("cannot sort by %s, which is neither a column nor a property", column))
+			continue
+		}
+		q.order = append(q.order, func(*compiler) string { return sortKey })
+	}
+	return q
+}
+
+// ExactNameFirst sorts the selected nodes named name before the others, as
+// when matching names with NameLike
+func (q *Selection) ExactNameFirst(name string) *Selection {
+	q.order = append(q.order, func(c *compiler) string {
+		return fmt.Sprintf("CASE WHEN %s.name = $%s THEN 0 ELSE 1 END", q.variable, c.param("exact_name", name))
+	})
+	return q
+}
+
+// Limit keeps the first rows
+func (q *Selection) Limit(count int) *Selection {
+	q.limit = count
+	return q
+}
+
+func (q *Selection) column(expression, name string) *Selection {
+	if slices.Contains(q.names, name) {
+		q.errs = append(q.errs, fmt.Errorf("duplicate column %s", name))
+		return q
+	}
+	if expression != name {
+		expression += " AS " + name
+	}
+	q.columns = append(q.columns, expression)
+	q.names = append(q.names, name)
+	return q
+}
+
+// setOf returns the set of the nodes bound to a variable
+func (q *Selection) setOf(variable string) *NodeSet {
+	if variable == q.variable {
+		return q.set
+	}
+	for _, j := range q.joins {
+		if j.variable == variable {
+			return j.set
+		}
+	}
+	return nil
+}
+
+// Build compiles the query to Cypher and its parameters. Every node the
+// query matches belongs to the project, bound to $project_id.
+func (q *Selection) Build(projectID core.ID) (string, map[string]any, error) {
+	if err := q.validate(projectID); err != nil {
+		return "", nil, err
+	}
+	c := newCompiler(projectID)
+	c.variables[q.variable] = true
+	for _, j := range q.joins {
+		c.variables[j.variable] = true
+	}
+	if q.file {
+		c.variables["file"] = true
+	}
+	match := "MATCH " + q.set.pattern(q.variable)
+	predicates := q.set.predicates(c, q.variable)
+	for _, j := range q.joins {
+		match += ", " + "(" + q.variable + ")" + relationship(j.rel, j.direction) + j.set.pattern(j.variable)
+		predicates = append(predicates, j.set.predicates(c, j.variable)...)
+	}
+	clauses := []string{match}
+	if len(predicates) > 0 {
+		clauses = append(clauses, "WHERE "+strings.Join(predicates, " AND "))
+	}
+	if q.file {
+		clauses = append(clauses, fmt.Sprintf(
+			"OPTIONAL MATCH (file:File {project_id: $project_id})-[:DEFINES]->(%s)", q.variable,
+		))
+	}
+	clauses = append(clauses, "RETURN "+strings.Join(q.columns, ", "))
+	if len(q.order) > 0 {
+		keys := make([]string, len(q.order))
+		for i, key := range q.order {
+			keys[i] = key(c)
+		}
+		clauses = append(clauses, "ORDER BY "+strings.Join(keys, ", "))
+	}
+	if q.limit > 0 {
+		clauses = append(clauses, "LIMIT $"+c.param("limit", q.limit))
+	}
+	return strings.Join(clauses, " "), c.params, nil
+}
+
+// validate reports the mistakes made while building the query
+func (q *Selection) validate(projectID core.ID) error {
+	errs := slices.Concat(q.errs, q.set.errs)
+	for _, j := range q.joins {
+		errs = append(errs, j.set.errs...)
+	}
+	if projectID == "" {
+		errs = append(errs, errors.New("a project ID is required"))
+	}
+	if len(q.columns) == 0 {
+		errs = append(errs, errors.New("the query returns no columns"))
+	}
+	variables := map[string]bool{}
+	for _, variable := range append([]string{q.variable}, q.joinVariables()...) {
+		if !identifier.MatchString(variable) || variable == "file" && q.file {
+			errs = append(errs, fmt.Errorf("invalid variable %q", variable))
+		} else if variables[variable] {
+			errs = append(errs, fmt.Errorf("duplicate variable %s", variable))
+		}
+		variables[variable] = true
+	}
+	if q.limit < 0 {
+		errs = append(errs, fmt.Errorf("invalid limit %d", q.limit))
+	}
+	return errors.Join(errs...)
+}
+
+func (q *Selection) joinVariables() []string {
+	variables := make([]string, len(q.joins))
+	for i, j := range q.joins {
+		variables[i] = j.variable
+	}
+	return variables
+}
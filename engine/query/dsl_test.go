@@ -0,0 +1,149 @@
+package query
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/cypher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dslGraph is a project p1 with a store package whose methods call each
+// other, plus a function of project p2 that calls into p1 by mistake
+func dslGraph() *cypher.Graph {
+	node := func(id string, nodeType core.NodeType, name string, props map[string]any) *core.Node {
+		if props["project_id"] == nil {
+			props["project_id"] = "p1"
+		}
+		return &core.Node{ID: core.ID(id), Type: nodeType, Name: name, Path: id + ".go", Properties: props}
+	}
+	rel := func(from string, relType core.RelationType, to string) *core.Relationship {
+		return &core.Relationship{
+			ID: core.ID(from + "-" + to), Type: relType, FromNodeID: core.ID(from), ToNodeID: core.ID(to),
+		}
+	}
+	nodes := []*core.Node{
+		node("file", core.NodeTypeFile, "store.go", map[string]any{"package": "store"}),
+		node("save", core.NodeTypeMethod, "Save", map[string]any{"package": "store", "is_exported": true}),
+		node("load", core.NodeTypeMethod, "Load", map[string]any{"package": "store", "is_exported": true}),
+		node("flush", core.NodeTypeFunction, "Flush", map[string]any{"package": "store", "is_exported": true}),
+		node("encode", core.NodeTypeFunction, "encode", map[string]any{"package": "store", "is_exported": false}),
+		node("main", core.NodeTypeFunction, "main", map[string]any{"package": "main", "is_exported": false}),
+		node("saver", core.NodeTypeInterface, "Saver", map[string]any{"package": "store", "is_exported": true}),
+		node("db", core.NodeTypeStruct, "DB", map[string]any{"package": "store", "is_exported": true}),
+		node("other", core.NodeTypeFunction, "Flush", map[string]any{"project_id": "p2", "package": "store"}),
+	}
+	rels := []*core.Relationship{
+		rel("file", core.RelationDefines, "save"),
+		rel("file", core.RelationDefines, "flush"),
+		rel("flush", core.RelationCalls, "save"),
+		rel("flush", core.RelationCalls, "encode"),
+		rel("main", core.RelationCalls, "flush"),
+		rel("save", core.RelationCalls, "encode"),
+		rel("db", core.RelationImplements, "saver"),
+		rel("other", core.RelationCalls, "load"),
+	}
+	return cypher.NewGraph(nodes, rels)
+}
+
+// run builds a query for project p1 and returns the values of a column
+func run(t *testing.T, q *Selection, column string) []any {
+	t.Helper()
+	cypherQuery, params, err := q.Build("p1")
+	require.NoError(t, err)
+	results, err := cypher.Execute(context.Background(), dslGraph(), cypherQuery, params)
+	require.NoError(t, err, cypherQuery)
+	values := make([]any, len(results))
+	for i, result := range results {
+		values[i] = result[column]
+	}
+	return values
+}
+
+func sortedNames(values []any) []any {
+	sort.Slice(values, func(i, j int) bool { return values[i].(string) < values[j].(string) })
+	return values
+}
+
+func TestFind(t *testing.T) {
+	t.Run("Should compile to parameterized Cypher scoped to the project", func(t *testing.T) {
+		q := Find(Functions().InPackage("store").Calling(Methods().Named("Save")).Exported()).
+			Return(PropertyName)
+		cypherQuery, params, err := q.Build("p1")
+		require.NoError(t, err)
+		assert.Equal(t, "MATCH (n:Function {project_id: $project_id}) "+
+			"WHERE n.package = $package "+
+			"AND EXISTS { MATCH (n)-[:CALLS]->(method1:Method {project_id: $project_id}) WHERE method1.name = $name } "+
+			"AND n.is_exported = $is_exported "+
+			"RETURN n.name AS name", cypherQuery)
+		assert.Equal(t, map[string]any{
+			"project_id": "p1", "package": "store", "name": "Save", "is_exported": true,
+		}, params)
+	})
+
+	t.Run("Should select nodes by relationships", func(t *testing.T) {
+		q := Find(Functions().InPackage("store").Calling(Methods().Named("Save")).Exported()).Return(PropertyName)
+		assert.Equal(t, []any{"Flush"}, run(t, q, "name"))
+
+		q = Find(Nodes(core.NodeTypeFunction, core.NodeTypeMethod).CalledBy(Functions().NameLike("flush"))).
+			Return(PropertyName)
+		assert.Equal(t, []any{"Save", "encode"}, sortedNames(run(t, q, "name")))
+
+		q = Find(Functions().NotRelated(core.RelationCalls, Incoming, nil)).Return(PropertyName)
+		assert.Equal(t, []any{"main"}, run(t, q, "name"))
+
+		q = Find(Structs().Implementing(Interfaces().Named("Saver"))).Return(PropertyName)
+		assert.Equal(t, []any{"DB"}, run(t, q, "name"))
+	})
+
+	t.Run("Should keep relationships inside the project", func(t *testing.T) {
+		q := Find(Methods().CalledBy(nil)).Return(PropertyName)
+		assert.Equal(t, []any{"Save"}, run(t, q, "name"))
+	})
+
+	t.Run("Should join, count, sort and limit", func(t *testing.T) {
+		q := Find(Nodes(core.NodeTypeFunction, core.NodeTypeMethod)).As("callee").
+			Join(core.RelationCalls, Incoming, nil, "caller").
+			Return(PropertyName).Count("callers").
+			OrderByDescending("callers").OrderBy("name").Limit(2)
+		results := run(t, q, "name")
+		assert.Equal(t, []any{"encode", "Flush"}, results)
+	})
+
+	t.Run("Should return the defining file and rank exact names first", func(t *testing.T) {
+		q := Find(Nodes(core.NodeTypeFunction, core.NodeTypeMethod).NameLike("save")).As("f").
+			ReturnNode().WithFile("file_path").ExactNameFirst("save").Limit(1)
+		assert.Equal(t, []any{"file.go"}, run(t, q, "file_path"))
+	})
+
+	t.Run("Should number parameters that share a property", func(t *testing.T) {
+		_, params, err := Find(Functions().Named("a").CalledBy(Functions().Named("b"))).Return(PropertyName).Build("p1")
+		require.NoError(t, err)
+		assert.Equal(t, "a", params["name"])
+		assert.Equal(t, "b", params["name_2"])
+	})
+
+	t.Run("Should report schema and build mistakes", func(t *testing.T) {
+		cases := map[string]*Selection{
+			"unknown node label Class":           Find(Nodes("Class")).ReturnNode(),
+			"label Package have no property":     Find(Packages().Exported()).ReturnNode(),
+			"unknown relationship type OWNS":     Find(Functions().Related("OWNS", Outgoing, nil)).ReturnNode(),
+			"returns no columns":                 Find(Functions()),
+			"cannot sort by calls":               Find(Functions()).ReturnNode().OrderBy("calls"),
+			"label Struct have no property":      Find(Functions()).Join(core.RelationCalls, Outgoing, Structs(), "s").ReturnFrom("s", PropertyReceiver, "r"),
+			"duplicate variable n":               Find(Functions()).Join(core.RelationCalls, Outgoing, nil, "n").ReturnNode(),
+			`unknown operator "LIKE"`:            Find(Functions().Where(PropertyName, "LIKE", "x")).ReturnNode(),
+			"nodes of label Import have no prop": Find(Functions().Calling(Imports().InPackage("x"))).ReturnNode(),
+		}
+		for message, q := range cases {
+			_, _, err := q.Build("p1")
+			require.Error(t, err, message)
+			assert.Contains(t, err.Error(), message)
+		}
+		_, _, err := Find(Functions()).ReturnNode().Build("")
+		assert.ErrorContains(t, err, "project ID is required")
+	})
+}
@@ -103,35 +103,26 @@ func (e *Exporter) exportEmpty(writer io.Writer) error {
 
 // exportJSON exports results as JSON
 func (e *Exporter) exportJSON(writer io.Writer, results []map[string]any) error {
-	// Process results to handle null values and format consistency
-	processedResults := make([]map[string]any, 0, len(results))
-
+	rows := e.NewRowWriter(writer)
 	for _, result := range results {
-		processed := make(map[string]any)
-		for key, value := range result {
-			processedValue := e.processValue(value)
-			if processedValue != nil || e.options.IncludeNull {
-				processed[key] = processedValue
-			}
+		if err := rows.WriteRow(result); err != nil {
+			return err
 		}
-		processedResults = append(processedResults, processed)
-	}
-
-	var data []byte
-	var err error
-
-	if e.options.Pretty {
-		data, err = json.MarshalIndent(processedResults, "", "  ")
-	} else {
-		data, err = json.Marshal(processedResults)
 	}
+	return rows.Close()
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+// processRow processes the values of a row for JSON export, leaving out null
+// values unless they are included
+func (e *Exporter) processRow(result map[string]any) map[string]any {
+	processed := make(map[string]any)
+	for key, value := range result {
+		processedValue := e.processValue(value)
+		if processedValue != nil || e.options.IncludeNull {
+			processed[key] = processedValue
+		}
 	}
-
-	_, err = writer.Write(data)
-	return err
+	return processed
 }
 
 // exportCSV exports results as CSV or TSV
@@ -242,6 +233,124 @@ func (e *Exporter) formatValueForCSV(value any) string {
 	}
 }
 
+// RowWriter writes query results one row at a time, as they arrive, in the
+// format of the exporter that created it. The output is that of Export for
+// the same rows, except that CSV and TSV columns are those of the first row.
+type RowWriter struct {
+	exporter *Exporter
+	writer   io.Writer
+	csv      *csv.Writer
+	columns  []string
+	rows     int
+}
+
+// NewRowWriter returns a writer of rows to writer. Close must be called once
+// all rows are written.
+func (e *Exporter) NewRowWriter(writer io.Writer) *RowWriter {
+	return &RowWriter{exporter: e, writer: writer}
+}
+
+// WriteRow writes a row
+func (w *RowWriter) WriteRow(row map[string]any) error {
+	var err error
+	switch w.exporter.options.Format {
+	case FormatJSON:
+		err = w.writeJSON(row)
+	case FormatCSV, FormatTSV:
+		err = w.writeCSV(row)
+	default:
+		return fmt.Errorf("unsupported export format: %s", w.exporter.options.Format)
+	}
+	if err != nil {
+		return err
+	}
+	w.rows++
+	return nil
+}
+
+// Rows returns the number of rows written
+func (w *RowWriter) Rows() int {
+	return w.rows
+}
+
+// Close ends the output and flushes what is buffered
+func (w *RowWriter) Close() error {
+	switch w.exporter.options.Format {
+	case FormatJSON:
+		end := "]"
+		switch {
+		case w.rows == 0:
+			end = "[]"
+		case w.exporter.options.Pretty:
+			end = "\n]"
+		}
+		_, err := io.WriteString(w.writer, end)
+		return err
+	case FormatCSV, FormatTSV:
+		if w.csv == nil {
+			return nil
+		}
+		w.csv.Flush()
+		return w.csv.Error()
+	default:
+		return fmt.Errorf("unsupported export format: %s", w.exporter.options.Format)
+	}
+}
+
+// writeJSON writes a row as an element of a JSON array
+func (w *RowWriter) writeJSON(row map[string]any) error {
+	processed := w.exporter.processRow(row)
+	var data []byte
+	var err error
+	separator := ","
+	if w.rows == 0 {
+		separator = "["
+	}
+	if w.exporter.options.Pretty {
+		separator += "\n  "
+		data, err = json.MarshalIndent(processed, "  ", "  ")
+	} else {
+		data, err = json.Marshal(processed)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	if _, err := io.WriteString(w.writer, separator); err != nil {
+		return err
+	}
+	_, err = w.writer.Write(data)
+	return err
+}
+
+// writeCSV writes a row as a CSV or TSV record, after the headers for the
+// first row
+func (w *RowWriter) writeCSV(row map[string]any) error {
+	if w.csv == nil {
+		w.csv = csv.NewWriter(w.writer)
+		if w.exporter.options.Delimiter != "" {
+			delimiter, _ := utf8.DecodeRuneInString(w.exporter.options.Delimiter)
+			w.csv.Comma = delimiter
+		}
+		for column := range row {
+			w.columns = append(w.columns, column)
+		}
+		sort.Strings(w.columns)
+		if w.exporter.options.Headers {
+			if err := w.csv.Write(w.columns); err != nil {
+				return fmt.Errorf("failed to write CSV headers: %w", err)
+			}
+		}
+	}
+	record := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		record[i] = w.exporter.formatValueForCSV(row[column])
+	}
+	if err := w.csv.Write(record); err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	return nil
+}
+
 // ExportResult represents the result of an export operation
 type ExportResult struct {
 	Format      ExportFormat `json:"format"`
@@ -129,6 +129,51 @@ func TestExporter_ExportEmpty(t *testing.T) {
 	})
 }
 
+func TestExporter_NewRowWriter(t *testing.T) {
+	results := []map[string]any{
+		{"name": "function1", "count": int64(5), "exported": true},
+		{"name": "function2", "count": int64(3), "exported": false},
+	}
+
+	t.Run("Should_write_rows_as_Export_writes_them", func(t *testing.T) {
+		compact := DefaultExportOptions(FormatJSON)
+		compact.Pretty = false
+		for _, options := range []*ExportOptions{
+			DefaultExportOptions(FormatJSON), compact,
+			DefaultExportOptions(FormatCSV), DefaultExportOptions(FormatTSV),
+		} {
+			exporter := NewExporter(options)
+			var exported, streamed bytes.Buffer
+			require.NoError(t, exporter.Export(&exported, results))
+
+			rows := exporter.NewRowWriter(&streamed)
+			for _, result := range results {
+				require.NoError(t, rows.WriteRow(result))
+			}
+			require.NoError(t, rows.Close())
+
+			assert.Equal(t, exported.String(), streamed.String(), options.Format)
+			assert.Equal(t, 2, rows.Rows())
+		}
+	})
+
+	t.Run("Should_write_an_empty_JSON_array_without_rows", func(t *testing.T) {
+		var buf bytes.Buffer
+		rows := NewExporter(DefaultExportOptions(FormatJSON)).NewRowWriter(&buf)
+		require.NoError(t, rows.Close())
+		assert.Equal(t, "[]", buf.String())
+	})
+
+	t.Run("Should_keep_the_columns_of_the_first_CSV_row", func(t *testing.T) {
+		var buf bytes.Buffer
+		rows := NewExporter(DefaultExportOptions(FormatCSV)).NewRowWriter(&buf)
+		require.NoError(t, rows.WriteRow(map[string]any{"name": "a"}))
+		require.NoError(t, rows.WriteRow(map[string]any{"name": "b", "extra": int64(1)}))
+		require.NoError(t, rows.Close())
+		assert.Equal(t, "name\na\nb\n", buf.String())
+	})
+}
+
 func TestResultProcessor(t *testing.T) {
 	rp := NewResultProcessor()
 	results := []map[string]any{
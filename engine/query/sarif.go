@@ -0,0 +1,516 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/parser"
+)
+
+const (
+	// SARIFVersion is the SARIF specification version produced by SARIFWriter
+	SARIFVersion = "2.1.0"
+	// SARIFSchema is the JSON schema location for SARIF 2.1.0 logs
+	SARIFSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	sarifSourceRoot     = "%SRCROOT%"
+	sarifFingerprintKey = "gographSymbol/v1"
+)
+
+// Built-in finding rule identifiers
+const (
+	RuleCircularDependency = "circular-dependency"
+	RuleLayerViolation     = "layer-violation"
+	RuleDependencyDepth    = "dependency-depth"
+	RuleUnusedFunction     = "unused-function"
+	RuleComplexFunction    = "complex-function"
+	RulePolicyPrefix       = "policy/"
+)
+
+// Rule describes a kind of finding reported by gograph
+type Rule struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Severity    analyzer.SeverityLevel `json:"severity"`
+}
+
+// Finding is a single issue with an optional source location. Symbol names
+// the code element the finding is about and stays stable when lines move.
+type Finding struct {
+	RuleID     string                 `json:"rule_id"`
+	Symbol     string                 `json:"symbol,omitempty"`
+	Message    string                 `json:"message"`
+	Severity   analyzer.SeverityLevel `json:"severity"`
+	File       string                 `json:"file,omitempty"`
+	Line       int                    `json:"line,omitempty"`
+	Properties map[string]any         `json:"properties,omitempty"`
+	// BaselineState is "new" or "unchanged" when compared against a baseline
+	BaselineState string `json:"baseline_state,omitempty"`
+}
+
+// Baseline states assigned to findings compared against a baseline
+const (
+	BaselineStateNew       = "new"
+	BaselineStateUnchanged = "unchanged"
+)
+
+// FindingRules contains metadata for the built-in finding rules
+var FindingRules = map[string]*Rule{
+	RuleCircularDependency: {
+		ID:          RuleCircularDependency,
+		Name:        "CircularDependency",
+		Description: "Packages import each other in a cycle",
+		Severity:    analyzer.SeverityHigh,
+	},
+	RuleLayerViolation: {
+		ID:          RuleLayerViolation,
+		Name:        "LayerViolation",
+		Description: "A package imports a layer it is not allowed to depend on",
+		Severity:    analyzer.SeverityHigh,
+	},
+	RuleDependencyDepth: {
+		ID:          RuleDependencyDepth,
+		Name:        "DependencyDepth",
+		Description: "A package has an import chain longer than the configured maximum",
+		Severity:    analyzer.SeverityMedium,
+	},
+	RuleUnusedFunction: {
+		ID:          RuleUnusedFunction,
+		Name:        "UnusedFunction",
+		Description: "A function is never called within the project",
+		Severity:    analyzer.SeverityLow,
+	},
+	RuleComplexFunction: {
+		ID:          RuleComplexFunction,
+		Name:        "ComplexFunction",
+		Description: "A function is longer than the configured line threshold",
+		Severity:    analyzer.SeverityMedium,
+	},
+}
+
+// SARIFLevel maps a severity to a SARIF result level
+func SARIFLevel(severity analyzer.SeverityLevel) string {
+	switch severity {
+	case analyzer.SeverityHigh:
+		return "error"
+	case analyzer.SeverityMedium:
+		return "warning"
+	case analyzer.SeverityLow:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// SARIFOptions configures the SARIF writer
+type SARIFOptions struct {
+	ToolName       string // Name of the reporting tool
+	ToolVersion    string // Version of the reporting tool
+	InformationURI string // Tool home page
+	SourceRoot     string // Absolute project root used to relativize file locations
+}
+
+// SARIFWriter writes findings as a SARIF 2.1.0 log
+type SARIFWriter struct {
+	options *SARIFOptions
+}
+
+// NewSARIFWriter creates a new SARIF writer with the specified options
+func NewSARIFWriter(options *SARIFOptions) *SARIFWriter {
+	if options == nil {
+		options = &SARIFOptions{}
+	}
+	if options.ToolName == "" {
+		options.ToolName = "gograph"
+	}
+	if options.InformationURI == "" {
+		options.InformationURI = "https://github.com/compozy/gograph"
+	}
+	return &SARIFWriter{options: options}
+}
+
+// Write writes a single-run SARIF log for the findings. Rules are looked up
+// in rules first and then in FindingRules; unknown rule IDs get generic metadata.
+func (w *SARIFWriter) Write(writer io.Writer, findings []*Finding, rules ...*Rule) error {
+	known := make(map[string]*Rule, len(FindingRules)+len(rules))
+	for id, rule := range FindingRules {
+		known[id] = rule
+	}
+	for _, rule := range rules {
+		known[rule.ID] = rule
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           w.options.ToolName,
+			Version:        w.options.ToolVersion,
+			InformationURI: w.options.InformationURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	if w.options.SourceRoot != "" {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			sarifSourceRoot: {URI: fileURI(w.options.SourceRoot) + "/"},
+		}
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, finding := range findings {
+		index, seen := ruleIndex[finding.RuleID]
+		if !seen {
+			rule, ok := known[finding.RuleID]
+			if !ok {
+				rule = &Rule{ID: finding.RuleID, Name: finding.RuleID, Severity: finding.Severity}
+			}
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[finding.RuleID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, w.convertRule(rule))
+		}
+		run.Results = append(run.Results, w.convertFinding(finding, index))
+	}
+
+	log := sarifLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs:    []sarifRun{run},
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to encode SARIF: %w", err)
+	}
+	return nil
+}
+
+// convertRule converts rule metadata into a SARIF reporting descriptor
+func (w *SARIFWriter) convertRule(rule *Rule) sarifRule {
+	descriptor := sarifRule{
+		ID:   rule.ID,
+		Name: rule.Name,
+		DefaultConfiguration: &sarifConfiguration{
+			Level: SARIFLevel(rule.Severity),
+		},
+	}
+	if rule.Description != "" {
+		descriptor.ShortDescription = &sarifMessage{Text: rule.Description}
+	}
+	return descriptor
+}
+
+// convertFinding converts a finding into a SARIF result
+func (w *SARIFWriter) convertFinding(finding *Finding, ruleIndex int) sarifResult {
+	result := sarifResult{
+		RuleID:    finding.RuleID,
+		RuleIndex: ruleIndex,
+		Level:     SARIFLevel(finding.Severity),
+		Message:   sarifMessage{Text: finding.Message},
+		PartialFingerprints: map[string]string{
+			sarifFingerprintKey: finding.Fingerprint(),
+		},
+		BaselineState: finding.BaselineState,
+		Properties:    finding.Properties,
+	}
+	if finding.File == "" {
+		return result
+	}
+
+	location := sarifPhysicalLocation{ArtifactLocation: w.artifactLocation(finding.File)}
+	if finding.Line > 0 {
+		location.Region = &sarifRegion{StartLine: finding.Line}
+	}
+	result.Locations = []sarifLocation{{PhysicalLocation: location}}
+	return result
+}
+
+// artifactLocation returns a location relative to the source root when possible
+func (w *SARIFWriter) artifactLocation(file string) sarifArtifactLocation {
+	if w.options.SourceRoot != "" && filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(w.options.SourceRoot, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return sarifArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: sarifSourceRoot}
+		}
+	}
+	if filepath.IsAbs(file) {
+		return sarifArtifactLocation{URI: fileURI(file)}
+	}
+	return sarifArtifactLocation{URI: filepath.ToSlash(file)}
+}
+
+// fileURI converts an absolute path into a file URI
+func fileURI(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(strings.TrimSuffix(path, string(filepath.Separator)))}
+	return u.String()
+}
+
+// -----
+// Finding Conversion
+// -----
+
+// FindingsFromViolations converts architecture rule violations into findings
+func FindingsFromViolations(violations []*analyzer.RuleViolation) []*Finding {
+	findings := make([]*Finding, 0, len(violations))
+	for _, violation := range violations {
+		ruleID := RuleLayerViolation
+		symbol := violation.From + " -> " + violation.To
+		properties := map[string]any{
+			"from":       violation.From,
+			"to":         violation.To,
+			"from_layer": violation.FromLayer,
+			"to_layer":   violation.ToLayer,
+		}
+		if violation.Rule == analyzer.RuleKindDependencyDepth {
+			ruleID = RuleDependencyDepth
+			symbol = violation.From
+			properties = map[string]any{"from": violation.From, "chain": violation.Chain}
+		}
+		findings = append(findings, &Finding{
+			RuleID:     ruleID,
+			Symbol:     symbol,
+			Message:    violation.Message,
+			Severity:   violation.Severity,
+			File:       violation.File,
+			Line:       violation.Line,
+			Properties: properties,
+		})
+	}
+	return findings
+}
+
+// FindingsFromCycles converts circular dependencies into findings, locating
+// each cycle at the import that closes it when the graph provides one
+func FindingsFromCycles(cycles []*analyzer.CircularDependency, graph *analyzer.DependencyGraph) []*Finding {
+	edges := make(map[string]*analyzer.DependencyEdge)
+	if graph != nil {
+		for _, edge := range graph.Edges {
+			edges[edge.From+"->"+edge.To] = edge
+		}
+	}
+
+	findings := make([]*Finding, 0, len(cycles))
+	for _, cycle := range cycles {
+		if len(cycle.Cycle) == 0 {
+			continue
+		}
+		path := append(append([]string{}, cycle.Cycle...), cycle.Cycle[0])
+		finding := &Finding{
+			RuleID:     RuleCircularDependency,
+			Symbol:     strings.Join(canonicalCycle(cycle.Cycle), " -> "),
+			Message:    fmt.Sprintf("circular dependency: %s", strings.Join(path, " -> ")),
+			Severity:   cycle.Severity,
+			Properties: map[string]any{"cycle": cycle.Cycle},
+		}
+		last := cycle.Cycle[len(cycle.Cycle)-1]
+		if edge, ok := edges[last+"->"+cycle.Cycle[0]]; ok {
+			finding.File = edge.File
+			finding.Line = edge.Line
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// canonicalCycle rotates a cycle so that it starts at its smallest element
+func canonicalCycle(cycle []string) []string {
+	start := 0
+	for i, path := range cycle {
+		if path < cycle[start] {
+			start = i
+		}
+	}
+	return append(append([]string{}, cycle[start:]...), cycle[:start]...)
+}
+
+// FindingsFromFunctionLength reports functions spanning more than maxLines lines.
+// A maxLines of zero or less disables the check.
+func FindingsFromFunctionLength(packages []*parser.PackageInfo, maxLines int) []*Finding {
+	findings := make([]*Finding, 0)
+	if maxLines <= 0 {
+		return findings
+	}
+
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			for _, fn := range file.Functions {
+				lines := fn.LineEnd - fn.LineStart + 1
+				if lines <= maxLines {
+					continue
+				}
+				name := fn.Name
+				if fn.Receiver != nil {
+					receiver := fn.Receiver.Name
+					if fn.Receiver.Type != nil {
+						receiver = types.TypeString(fn.Receiver.Type, func(*types.Package) string { return "" })
+					}
+					name = fmt.Sprintf("(%s).%s", receiver, fn.Name)
+				}
+				findings = append(findings, &Finding{
+					RuleID:   RuleComplexFunction,
+					Symbol:   pkg.Path + "." + name,
+					Message:  fmt.Sprintf("%s.%s is %d lines long (max %d)", pkg.Path, name, lines, maxLines),
+					Severity: analyzer.SeverityMedium,
+					File:     file.Path,
+					Line:     fn.LineStart,
+					Properties: map[string]any{
+						"package":  pkg.Path,
+						"function": name,
+						"lines":    lines,
+					},
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// FindingsFromRows converts query result rows into findings. Rows may carry
+// their location in "file" and "line" columns; all columns are kept as properties.
+func FindingsFromRows(
+	ruleID string,
+	severity analyzer.SeverityLevel,
+	message string,
+	rows []map[string]any,
+) []*Finding {
+	findings := make([]*Finding, 0, len(rows))
+	for _, row := range rows {
+		finding := &Finding{
+			RuleID:     ruleID,
+			Symbol:     rowSymbol(row),
+			Message:    rowMessage(message, row),
+			Severity:   severity,
+			Properties: row,
+		}
+		if file, ok := row["file"].(string); ok {
+			finding.File = file
+		}
+		switch line := row["line"].(type) {
+		case int:
+			finding.Line = line
+		case int64:
+			finding.Line = int(line)
+		case float64:
+			finding.Line = int(line)
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// rowSymbol identifies a row by its package and function columns when present,
+// otherwise by all non-location columns
+func rowSymbol(row map[string]any) string {
+	if pkg, ok := row["package"].(string); ok {
+		if fn, ok := row["function"].(string); ok {
+			return pkg + "." + fn
+		}
+	}
+	return strings.Join(rowFields(row), " ")
+}
+
+// rowFields returns the sorted key=value pairs of a row, excluding location columns
+func rowFields(row map[string]any) []string {
+	keys := make([]string, 0, len(row))
+	for key := range row {
+		if key != "file" && key != "line" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	fields := make([]string, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, fmt.Sprintf("%s=%v", key, row[key]))
+	}
+	return fields
+}
+
+// rowMessage appends the row's identifying columns to the base message
+func rowMessage(message string, row map[string]any) string {
+	fields := rowFields(row)
+	if len(fields) == 0 {
+		return message
+	}
+	if message == "" {
+		return strings.Join(fields, " ")
+	}
+	return fmt.Sprintf("%s (%s)", message, strings.Join(fields, " "))
+}
+
+// -----
+// SARIF Document Model
+// -----
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name,omitempty"`
+	ShortDescription     *sarifMessage       `json:"shortDescription,omitempty"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	BaselineState       string            `json:"baselineState,omitempty"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
@@ -0,0 +1,184 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/compozy/gograph/engine/analyzer"
+	"github.com/compozy/gograph/engine/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeSARIF(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var log map[string]any
+	require.NoError(t, json.Unmarshal(data, &log))
+	return log
+}
+
+func TestSARIFWriter_Write(t *testing.T) {
+	t.Run("Should_write_SARIF_2_1_0_log_with_rules_and_locations", func(t *testing.T) {
+		writer := NewSARIFWriter(&SARIFOptions{ToolVersion: "1.2.3", SourceRoot: "/repo"})
+		findings := []*Finding{
+			{
+				RuleID:   RuleLayerViolation,
+				Message:  "domain imports infra",
+				Severity: analyzer.SeverityHigh,
+				File:     "/repo/domain/user.go",
+				Line:     7,
+			},
+			{
+				RuleID:   RuleUnusedFunction,
+				Message:  "helper is never called",
+				Severity: analyzer.SeverityLow,
+				File:     "/repo/util/helper.go",
+				Line:     12,
+			},
+			{
+				RuleID:   RuleLayerViolation,
+				Message:  "cmd imports infra",
+				Severity: analyzer.SeverityHigh,
+				File:     "cmd/main.go",
+				Line:     3,
+			},
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, writer.Write(&buf, findings))
+
+		log := decodeSARIF(t, buf.Bytes())
+		assert.Equal(t, SARIFVersion, log["version"])
+		assert.Equal(t, SARIFSchema, log["$schema"])
+
+		run := log["runs"].([]any)[0].(map[string]any)
+		driver := run["tool"].(map[string]any)["driver"].(map[string]any)
+		assert.Equal(t, "gograph", driver["name"])
+		assert.Equal(t, "1.2.3", driver["version"])
+
+		rules := driver["rules"].([]any)
+		require.Len(t, rules, 2)
+		layerRule := rules[0].(map[string]any)
+		assert.Equal(t, RuleLayerViolation, layerRule["id"])
+		assert.Equal(t, "error", layerRule["defaultConfiguration"].(map[string]any)["level"])
+
+		results := run["results"].([]any)
+		require.Len(t, results, 3)
+
+		first := results[0].(map[string]any)
+		assert.Equal(t, "error", first["level"])
+		assert.Equal(t, float64(0), first["ruleIndex"])
+		location := first["locations"].([]any)[0].(map[string]any)["physicalLocation"].(map[string]any)
+		artifact := location["artifactLocation"].(map[string]any)
+		assert.Equal(t, "domain/user.go", artifact["uri"])
+		assert.Equal(t, "%SRCROOT%", artifact["uriBaseId"])
+		assert.Equal(t, float64(7), location["region"].(map[string]any)["startLine"])
+
+		second := results[1].(map[string]any)
+		assert.Equal(t, "note", second["level"])
+		assert.Equal(t, float64(1), second["ruleIndex"])
+
+		third := results[2].(map[string]any)
+		assert.Equal(t, float64(0), third["ruleIndex"])
+		thirdArtifact := third["locations"].([]any)[0].(map[string]any)["physicalLocation"].(map[string]any)["artifactLocation"].(map[string]any)
+		assert.Equal(t, "cmd/main.go", thirdArtifact["uri"])
+	})
+
+	t.Run("Should_write_empty_results_array_without_findings", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, NewSARIFWriter(nil).Write(&buf, nil))
+
+		log := decodeSARIF(t, buf.Bytes())
+		run := log["runs"].([]any)[0].(map[string]any)
+		assert.Empty(t, run["results"])
+	})
+
+	t.Run("Should_use_custom_rule_metadata", func(t *testing.T) {
+		rule := &Rule{ID: RulePolicyPrefix + "no-globals", Name: "no-globals", Severity: analyzer.SeverityMedium}
+		findings := []*Finding{{RuleID: rule.ID, Message: "global found", Severity: analyzer.SeverityHigh}}
+
+		var buf bytes.Buffer
+		require.NoError(t, NewSARIFWriter(nil).Write(&buf, findings, rule))
+
+		log := decodeSARIF(t, buf.Bytes())
+		run := log["runs"].([]any)[0].(map[string]any)
+		driverRule := run["tool"].(map[string]any)["driver"].(map[string]any)["rules"].([]any)[0].(map[string]any)
+		assert.Equal(t, "no-globals", driverRule["name"])
+		assert.Equal(t, "warning", driverRule["defaultConfiguration"].(map[string]any)["level"])
+		result := run["results"].([]any)[0].(map[string]any)
+		assert.Equal(t, "error", result["level"])
+		assert.Nil(t, result["locations"])
+	})
+}
+
+func TestSARIFLevel(t *testing.T) {
+	t.Run("Should_map_severity_levels", func(t *testing.T) {
+		assert.Equal(t, "error", SARIFLevel(analyzer.SeverityHigh))
+		assert.Equal(t, "warning", SARIFLevel(analyzer.SeverityMedium))
+		assert.Equal(t, "note", SARIFLevel(analyzer.SeverityLow))
+		assert.Equal(t, "warning", SARIFLevel(""))
+	})
+}
+
+func TestFindingConversion(t *testing.T) {
+	t.Run("Should_convert_rule_violations", func(t *testing.T) {
+		findings := FindingsFromViolations([]*analyzer.RuleViolation{
+			{Rule: analyzer.RuleKindLayer, From: "a", To: "b", File: "a/a.go", Line: 3, Severity: analyzer.SeverityHigh},
+			{Rule: analyzer.RuleKindDependencyDepth, From: "c", Chain: []string{"c", "d"}, Severity: analyzer.SeverityMedium},
+		})
+
+		require.Len(t, findings, 2)
+		assert.Equal(t, RuleLayerViolation, findings[0].RuleID)
+		assert.Equal(t, 3, findings[0].Line)
+		assert.Equal(t, RuleDependencyDepth, findings[1].RuleID)
+	})
+
+	t.Run("Should_locate_cycles_at_closing_import", func(t *testing.T) {
+		graph := &analyzer.DependencyGraph{Edges: []*analyzer.DependencyEdge{
+			{From: "a", To: "b", File: "a/a.go", Line: 4},
+			{From: "b", To: "a", File: "b/b.go", Line: 9},
+		}}
+		cycles := []*analyzer.CircularDependency{{Cycle: []string{"a", "b"}, Severity: analyzer.SeverityHigh}}
+
+		findings := FindingsFromCycles(cycles, graph)
+
+		require.Len(t, findings, 1)
+		assert.Equal(t, "b/b.go", findings[0].File)
+		assert.Equal(t, 9, findings[0].Line)
+		assert.Contains(t, findings[0].Message, "a -> b -> a")
+	})
+
+	t.Run("Should_report_long_functions", func(t *testing.T) {
+		packages := []*parser.PackageInfo{{
+			Path: "example.com/app",
+			Files: []*parser.FileInfo{{
+				Path: "/repo/app.go",
+				Functions: []*parser.FunctionInfo{
+					{Name: "short", LineStart: 1, LineEnd: 5},
+					{Name: "long", LineStart: 10, LineEnd: 40},
+				},
+			}},
+		}}
+
+		findings := FindingsFromFunctionLength(packages, 20)
+
+		require.Len(t, findings, 1)
+		assert.Equal(t, RuleComplexFunction, findings[0].RuleID)
+		assert.Equal(t, 10, findings[0].Line)
+		assert.Empty(t, FindingsFromFunctionLength(packages, 0))
+	})
+
+	t.Run("Should_convert_rows_with_locations", func(t *testing.T) {
+		rows := []map[string]any{
+			{"function": "helper", "package": "util", "file": "/repo/util.go", "line": int64(12)},
+		}
+
+		findings := FindingsFromRows(RuleUnusedFunction, analyzer.SeverityLow, "unused function", rows)
+
+		require.Len(t, findings, 1)
+		assert.Equal(t, "/repo/util.go", findings[0].File)
+		assert.Equal(t, 12, findings[0].Line)
+		assert.Equal(t, "unused function (function=helper package=util)", findings[0].Message)
+	})
+}
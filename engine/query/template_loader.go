@@ -0,0 +1,172 @@
+package query
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/cypher"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTemplatesDir is the directory of the user templates, relative to the
+// configuration file
+const DefaultTemplatesDir = ".gograph/templates"
+
+// schemaLabels and schemaRelationshipTypes are the node labels and
+// relationship types of an analyzed project, which template queries may use
+var (
+	schemaLabels = map[string]bool{
+		string(core.NodeTypePackage):   true,
+		string(core.NodeTypeFile):      true,
+		string(core.NodeTypeFunction):  true,
+		string(core.NodeTypeStruct):    true,
+		string(core.NodeTypeInterface): true,
+		string(core.NodeTypeMethod):    true,
+		string(core.NodeTypeImport):    true,
+		string(core.NodeTypeConstant):  true,
+		string(core.NodeTypeVariable):  true,
+	}
+	schemaRelationshipTypes = map[string]bool{
+		string(core.RelationContains):   true,
+		string(core.RelationDefines):    true,
+		string(core.RelationCalls):      true,
+		string(core.RelationImplements): true,
+		string(core.RelationEmbeds):     true,
+		string(core.RelationImports):    true,
+		string(core.RelationBelongsTo):  true,
+		string(core.RelationReferences): true,
+		string(core.RelationDependsOn):  true,
+	}
+)
+
+// LoadTemplates reads the user templates of a directory. Each *.yaml or *.yml
+// file holds one template, named after the file. A missing directory holds
+// no templates.
+func LoadTemplates(dir string) (map[string]*Template, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+	templates := make(map[string]*Template)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ext)
+		path := filepath.Join(dir, entry.Name())
+		if existing, exists := templates[name]; exists {
+			return nil, fmt.Errorf("template %s is defined by both %s and %s", name, existing.Source, path)
+		}
+		template, err := loadTemplate(path)
+		if err != nil {
+			return nil, err
+		}
+		templates[name] = template
+	}
+	return templates, nil
+}
+
+func loadTemplate(path string) (*Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	var template Template
+	if err := decoder.Decode(&template); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("file is empty")
+		}
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	template.Source = path
+	if err := template.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", path, err)
+	}
+	return &template, nil
+}
+
+// Validate checks that the template has a name and a read-only query that
+// only uses the labels and relationship types of the graph schema, and that
+// its parameters are well typed and declare every parameter of the query but
+// $project_id. Defaults and allowed values are converted to the types of
+// their parameters.
+func (t *Template) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("name is required")
+	}
+	if strings.TrimSpace(t.Query) == "" {
+		return errors.New("query is required")
+	}
+	parsed, err := cypher.Parse(t.Query)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	names := parsed.Names()
+	for _, label := range names.Labels {
+		if !schemaLabels[label] {
+			return fmt.Errorf("query uses unknown node label %s", label)
+		}
+	}
+	for _, typ := range names.RelationshipTypes {
+		if !schemaRelationshipTypes[typ] {
+			return fmt.Errorf("query uses unknown relationship type %s", typ)
+		}
+	}
+	for _, name := range names.Parameters {
+		if _, declared := t.Parameters[name]; !declared && name != "project_id" {
+			return fmt.Errorf("query parameter $%s is not declared", name)
+		}
+	}
+	for _, name := range t.ParameterNames() {
+		parameter := t.Parameters[name]
+		if err := parameter.normalize(); err != nil {
+			return fmt.Errorf("parameter %s: %w", name, err)
+		}
+		t.Parameters[name] = parameter
+	}
+	return nil
+}
+
+// normalize defaults the type to string and converts the allowed values and
+// the default to the type
+func (p *Parameter) normalize() error {
+	switch p.Type {
+	case "":
+		p.Type = ParameterString
+	case ParameterString, ParameterInteger, ParameterFloat, ParameterBoolean:
+	case ParameterList:
+		if len(p.Enum) > 0 {
+			return errors.New("list parameters cannot have allowed values")
+		}
+	default:
+		return fmt.Errorf("unknown type %q (use string, integer, float, boolean or list)", p.Type)
+	}
+	for i, value := range p.Enum {
+		converted, err := p.convert(value)
+		if err != nil {
+			return fmt.Errorf("invalid allowed value: %w", err)
+		}
+		p.Enum[i] = converted
+	}
+	if p.Default != nil {
+		converted, err := p.Convert(p.Default)
+		if err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+		p.Default = converted
+	}
+	return nil
+}
@@ -2,16 +2,44 @@ package query
 
 import (
 	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // Template represents a query template with parameters
 type Template struct {
-	Name        string            `json:"name"`
-	Description string            `json:"description"`
-	Query       string            `json:"query"`
-	Parameters  map[string]string `json:"parameters"`
-	Category    string            `json:"category"`
+	Name        string               `json:"name" yaml:"name"`
+	Description string               `json:"description" yaml:"description"`
+	Query       string               `json:"query" yaml:"query"`
+	Parameters  map[string]Parameter `json:"parameters" yaml:"parameters"`
+	Category    string               `json:"category" yaml:"category"`
+	Source      string               `json:"source,omitempty" yaml:"-"` // File of a user template
+}
+
+// ParameterType is the type of the value of a template parameter
+type ParameterType string
+
+const (
+	ParameterString  ParameterType = "string"
+	ParameterInteger ParameterType = "integer"
+	ParameterFloat   ParameterType = "float"
+	ParameterBoolean ParameterType = "boolean"
+	ParameterList    ParameterType = "list"
+)
+
+// Parameter describes a parameter of a template. A parameter that is not
+// given takes its default, or null when it is optional and has none.
+type Parameter struct {
+	Type        ParameterType `json:"type" yaml:"type"`
+	Description string        `json:"description" yaml:"description"`
+	Required    bool          `json:"required" yaml:"required"`
+	Default     any           `json:"default,omitempty" yaml:"default"`
+	Enum        []any         `json:"enum,omitempty" yaml:"enum"`
 }
 
 // CommonTemplates contains frequently used query templates
@@ -26,8 +54,8 @@ var CommonTemplates = map[string]*Template{
 		  labels(n)[0] as node_type,
 		  count(n) as count
 		ORDER BY count DESC`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	"project_files": {
@@ -37,8 +65,8 @@ var CommonTemplates = map[string]*Template{
 		Query: `MATCH (f:File) WHERE f.project_id = $project_id
 		RETURN f.path as file_path, f.name as file_name, f.package as package_name
 		ORDER BY f.package, f.name`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	"project_packages": {
@@ -48,8 +76,8 @@ var CommonTemplates = map[string]*Template{
 		Query: `MATCH (f:File) WHERE f.project_id = $project_id
 		RETURN f.package as package_name, count(f) as file_count
 		ORDER BY file_count DESC, package_name`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	// Function analysis queries
@@ -61,8 +89,8 @@ var CommonTemplates = map[string]*Template{
 		RETURN f.package as package_name, f.name as function_name, 
 		       f.signature as signature, f.is_exported as is_exported
 		ORDER BY f.package, f.name`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	"exported_functions": {
@@ -73,8 +101,8 @@ var CommonTemplates = map[string]*Template{
 		WHERE f.project_id = $project_id AND f.is_exported = true
 		RETURN f.package as package_name, f.name as function_name, f.signature as signature
 		ORDER BY f.package, f.name`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	"function_complexity": {
@@ -87,8 +115,8 @@ var CommonTemplates = map[string]*Template{
 		       complexity, f.signature as signature
 		ORDER BY complexity DESC
 		LIMIT 20`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	// Dependency analysis queries
@@ -100,8 +128,8 @@ var CommonTemplates = map[string]*Template{
 		WHERE f1.project_id = $project_id
 		RETURN DISTINCT f1.package as from_package, f2.package as to_package
 		ORDER BY from_package, to_package`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	"external_dependencies": {
@@ -112,8 +140,8 @@ var CommonTemplates = map[string]*Template{
 		AND NOT i.path STARTS WITH "."
 		RETURN DISTINCT i.path as external_package, count(*) as usage_count
 		ORDER BY usage_count DESC, external_package`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	"dependency_graph": {
@@ -123,8 +151,8 @@ var CommonTemplates = map[string]*Template{
 		Query: `MATCH (f1:File)-[r:DEPENDS_ON]->(f2:File) 
 		WHERE f1.project_id = $project_id
 		RETURN f1.path as from_file, f2.path as to_file, type(r) as relationship_type`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	// Interface and struct queries
@@ -137,8 +165,8 @@ var CommonTemplates = map[string]*Template{
 		RETURN i.package as interface_package, i.name as interface_name,
 		       s.package as struct_package, s.name as struct_name
 		ORDER BY i.name, s.name`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	"unimplemented_interfaces": {
@@ -151,21 +179,21 @@ var CommonTemplates = map[string]*Template{
 		}
 		RETURN i.package as package_name, i.name as interface_name
 		ORDER BY i.package, i.name`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	"struct_methods": {
 		Name:        "Struct Methods",
 		Description: "List all structs and their methods",
 		Category:    "types",
-		Query: `MATCH (s:Struct)-[:HAS_METHOD]->(m:Function) 
+		Query: `MATCH (m:Function)-[:BELONGS_TO]->(s:Struct)
 		WHERE s.project_id = $project_id
 		RETURN s.package as struct_package, s.name as struct_name,
 		       m.name as method_name, m.signature as method_signature
 		ORDER BY s.name, m.name`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	// Call chain analysis
@@ -178,8 +206,8 @@ var CommonTemplates = map[string]*Template{
 		RETURN f1.package as caller_package, f1.name as caller_name,
 		       f2.package as callee_package, f2.name as callee_name
 		ORDER BY caller_package, caller_name`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	"most_called_functions": {
@@ -191,8 +219,8 @@ var CommonTemplates = map[string]*Template{
 		       count(*) as call_count, f.signature as signature
 		ORDER BY call_count DESC
 		LIMIT 20`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	"unused_functions": {
@@ -206,8 +234,8 @@ var CommonTemplates = map[string]*Template{
 		AND f.name <> "main" AND f.name <> "init"
 		RETURN f.package as package_name, f.name as function_name, f.signature as signature
 		ORDER BY f.package, f.name`,
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	},
 	// Search queries
@@ -220,9 +248,9 @@ var CommonTemplates = map[string]*Template{
 		RETURN f.package as package_name, f.name as function_name, 
 		       f.signature as signature, f.is_exported as is_exported
 		ORDER BY f.package, f.name`,
-		Parameters: map[string]string{
-			"project_id":    "string - The project identifier",
-			"function_name": "string - Function name to search for",
+		Parameters: map[string]Parameter{
+			"project_id":    {Type: ParameterString, Description: "The project identifier", Required: true},
+			"function_name": {Type: ParameterString, Description: "Function name to search for", Required: true},
 		},
 	},
 	"find_struct": {
@@ -233,9 +261,9 @@ var CommonTemplates = map[string]*Template{
 		AND toLower(s.name) CONTAINS toLower($struct_name)
 		RETURN s.package as package_name, s.name as struct_name, s.is_exported as is_exported
 		ORDER BY s.package, s.name`,
-		Parameters: map[string]string{
-			"project_id":  "string - The project identifier",
-			"struct_name": "string - Struct name to search for",
+		Parameters: map[string]Parameter{
+			"project_id":  {Type: ParameterString, Description: "The project identifier", Required: true},
+			"struct_name": {Type: ParameterString, Description: "Struct name to search for", Required: true},
 		},
 	},
 	"find_interface": {
@@ -246,9 +274,9 @@ var CommonTemplates = map[string]*Template{
 		AND toLower(i.name) CONTAINS toLower($interface_name)
 		RETURN i.package as package_name, i.name as interface_name, i.is_exported as is_exported
 		ORDER BY i.package, i.name`,
-		Parameters: map[string]string{
-			"project_id":     "string - The project identifier",
-			"interface_name": "string - Interface name to search for",
+		Parameters: map[string]Parameter{
+			"project_id":     {Type: ParameterString, Description: "The project identifier", Required: true},
+			"interface_name": {Type: ParameterString, Description: "Interface name to search for", Required: true},
 		},
 	},
 	"search_code": {
@@ -259,16 +287,39 @@ var CommonTemplates = map[string]*Template{
 		AND toLower(f.signature) CONTAINS toLower($search_term)
 		RETURN f.package as package_name, f.name as function_name, f.signature as signature
 		ORDER BY f.package, f.name`,
-		Parameters: map[string]string{
-			"project_id":  "string - The project identifier",
-			"search_term": "string - Text to search for in function signatures",
+		Parameters: map[string]Parameter{
+			"project_id":  {Type: ParameterString, Description: "The project identifier", Required: true},
+			"search_term": {Type: ParameterString, Description: "Text to search for in function signatures", Required: true},
 		},
 	},
 }
 
+var (
+	userTemplatesMu sync.RWMutex
+	userTemplates   map[string]*Template
+)
+
+// RegisterTemplates sets the user templates returned along with the built-in
+// ones. A user template replaces the built-in template of the same name.
+func RegisterTemplates(templates map[string]*Template) {
+	userTemplatesMu.Lock()
+	defer userTemplatesMu.Unlock()
+	userTemplates = templates
+}
+
+// Templates returns the built-in and user templates by name
+func Templates() map[string]*Template {
+	userTemplatesMu.RLock()
+	defer userTemplatesMu.RUnlock()
+	templates := make(map[string]*Template, len(CommonTemplates)+len(userTemplates))
+	maps.Copy(templates, CommonTemplates)
+	maps.Copy(templates, userTemplates)
+	return templates
+}
+
 // GetTemplate retrieves a template by name
 func GetTemplate(name string) (*Template, error) {
-	template, exists := CommonTemplates[name]
+	template, exists := Templates()[name]
 	if !exists {
 		return nil, fmt.Errorf("template '%s' not found", name)
 	}
@@ -278,7 +329,7 @@ func GetTemplate(name string) (*Template, error) {
 // ListTemplates returns all available templates grouped by category
 func ListTemplates() map[string][]*Template {
 	categories := make(map[string][]*Template)
-	for _, template := range CommonTemplates {
+	for _, template := range Templates() {
 		categories[template.Category] = append(categories[template.Category], template)
 	}
 	return categories
@@ -287,7 +338,7 @@ func ListTemplates() map[string][]*Template {
 // GetTemplatesByCategory returns templates for a specific category
 func GetTemplatesByCategory(category string) []*Template {
 	var templates []*Template
-	for _, template := range CommonTemplates {
+	for _, template := range Templates() {
 		if template.Category == category {
 			templates = append(templates, template)
 		}
@@ -295,14 +346,49 @@ func GetTemplatesByCategory(category string) []*Template {
 	return templates
 }
 
-// ValidateParameters checks if all required parameters are provided
+// ValidateParameters checks if all required parameters are provided and that
+// the given values fit their parameters
 func (t *Template) ValidateParameters(params map[string]any) error {
-	for paramName := range t.Parameters {
-		if _, exists := params[paramName]; !exists {
-			return fmt.Errorf("missing required parameter: %s", paramName)
+	_, err := t.ResolveParameters(params)
+	return err
+}
+
+// ResolveParameters returns the parameters of a run of the template. Given
+// values are converted to the types of their parameters, so that strings of
+// the command line can be passed as they are, and missing ones take their
+// defaults. Values that are not template parameters are kept.
+func (t *Template) ResolveParameters(params map[string]any) (map[string]any, error) {
+	resolved := maps.Clone(params)
+	if resolved == nil {
+		resolved = make(map[string]any)
+	}
+	for _, name := range t.ParameterNames() {
+		parameter := t.Parameters[name]
+		value, given := params[name]
+		if !given {
+			if parameter.Required && parameter.Default == nil {
+				return nil, fmt.Errorf("missing required parameter: %s", name)
+			}
+			resolved[name] = parameter.Default
+			continue
+		}
+		converted, err := parameter.Convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of parameter %s: %w", name, err)
 		}
+		resolved[name] = converted
+	}
+	return resolved, nil
+}
+
+// ParameterNames returns the names of the template parameters in order
+func (t *Template) ParameterNames() []string {
+	names := make([]string, 0, len(t.Parameters))
+	for name := range t.Parameters {
+		names = append(names, name)
 	}
-	return nil
+	sort.Strings(names)
+	return names
 }
 
 // BuildQuery builds the final query with parameter substitution validation
@@ -320,9 +406,134 @@ func (t *Template) GetParameterHelp() string {
 		return "No parameters required"
 	}
 	var help strings.Builder
-	help.WriteString("Required parameters:\n")
-	for name, description := range t.Parameters {
-		help.WriteString(fmt.Sprintf("  %s: %s\n", name, description))
+	help.WriteString("Parameters:\n")
+	for _, name := range t.ParameterNames() {
+		help.WriteString(fmt.Sprintf("  %s: %s\n", name, t.Parameters[name]))
 	}
 	return help.String()
 }
+
+// String describes the parameter as "type - description", followed by
+// whether it is required, its default and its allowed values
+func (p Parameter) String() string {
+	description := string(p.Type)
+	if p.Description != "" {
+		description += " - " + p.Description
+	}
+	var notes []string
+	switch {
+	case p.Default != nil:
+		notes = append(notes, fmt.Sprintf("default %v", p.Default))
+	case p.Required:
+		notes = append(notes, "required")
+	}
+	if len(p.Enum) > 0 {
+		notes = append(notes, "one of "+p.enumValues())
+	}
+	if len(notes) > 0 {
+		description += " (" + strings.Join(notes, "; ") + ")"
+	}
+	return description
+}
+
+// Convert converts a value to the type of the parameter and checks it
+// against the allowed values. Strings are parsed, so "10" is a valid integer
+// and "a, b" a list of two strings.
+func (p Parameter) Convert(value any) (any, error) {
+	converted, err := p.convert(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Enum) == 0 {
+		return converted, nil
+	}
+	for _, allowed := range p.Enum {
+		if reflect.DeepEqual(converted, allowed) {
+			return converted, nil
+		}
+	}
+	return nil, fmt.Errorf("%v is not one of %s", value, p.enumValues())
+}
+
+func (p Parameter) enumValues() string {
+	values := make([]string, len(p.Enum))
+	for i, value := range p.Enum {
+		values[i] = fmt.Sprint(value)
+	}
+	return strings.Join(values, ", ")
+}
+
+func (p Parameter) convert(value any) (any, error) {
+	switch p.Type {
+	case ParameterString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case ParameterInteger:
+		return toInteger(value)
+	case ParameterFloat:
+		return toFloat(value)
+	case ParameterBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err == nil {
+				return b, nil
+			}
+		}
+	case ParameterList:
+		switch v := value.(type) {
+		case []any:
+			return v, nil
+		case string:
+			var list []any
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			return list, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown parameter type %q", p.Type)
+	}
+	return nil, fmt.Errorf("expected a %s, got %v", p.Type, value)
+}
+
+func toInteger(value any) (any, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float64: // Numbers of JSON files
+		if v == math.Trunc(v) {
+			return int64(v), nil
+		}
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err == nil {
+			return i, nil
+		}
+	}
+	return nil, fmt.Errorf("expected an integer, got %v", value)
+}
+
+func toFloat(value any) (any, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err == nil {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a float, got %v", value)
+}
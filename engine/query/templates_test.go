@@ -1,6 +1,8 @@
 package query
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -51,9 +53,9 @@ func TestGetTemplatesByCategory(t *testing.T) {
 
 func TestTemplate_ValidateParameters(t *testing.T) {
 	template := &Template{
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
-			"name":       "string - The name to search for",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
+			"name":       {Type: ParameterString, Description: "The name to search for", Required: true},
 		},
 	}
 
@@ -80,8 +82,8 @@ func TestTemplate_ValidateParameters(t *testing.T) {
 func TestTemplate_BuildQuery(t *testing.T) {
 	template := &Template{
 		Query: "MATCH (n) WHERE n.project_id = $project_id RETURN n",
-		Parameters: map[string]string{
-			"project_id": "string - The project identifier",
+		Parameters: map[string]Parameter{
+			"project_id": {Type: ParameterString, Description: "The project identifier", Required: true},
 		},
 	}
 
@@ -101,3 +103,143 @@ func TestTemplate_BuildQuery(t *testing.T) {
 		assert.Empty(t, query)
 	})
 }
+
+func TestTemplate_ResolveParameters(t *testing.T) {
+	template := &Template{
+		Parameters: map[string]Parameter{
+			"name":  {Type: ParameterString, Required: true},
+			"limit": {Type: ParameterInteger, Default: int64(10)},
+			"ratio": {Type: ParameterFloat},
+			"tests": {Type: ParameterBoolean, Default: false},
+			"tags":  {Type: ParameterList},
+			"order": {Type: ParameterString, Enum: []any{"asc", "desc"}, Default: "desc"},
+		},
+	}
+
+	t.Run("Should convert command line strings to the parameter types", func(t *testing.T) {
+		params, err := template.ResolveParameters(map[string]any{
+			"name": "main", "limit": "5", "ratio": "0.5", "tests": "true", "tags": "a, b", "project_id": "p",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"name": "main", "limit": int64(5), "ratio": 0.5, "tests": true,
+			"tags": []any{"a", "b"}, "order": "desc", "project_id": "p",
+		}, params)
+	})
+
+	t.Run("Should fill in defaults and null for optional parameters", func(t *testing.T) {
+		params, err := template.ResolveParameters(map[string]any{"name": "main", "limit": float64(3)})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), params["limit"])
+		assert.Equal(t, false, params["tests"])
+		assert.Contains(t, params, "ratio")
+		assert.Nil(t, params["ratio"])
+	})
+
+	t.Run("Should reject values of the wrong type or outside the enum", func(t *testing.T) {
+		_, err := template.ResolveParameters(map[string]any{"name": "main", "limit": "ten"})
+		assert.ErrorContains(t, err, "invalid value of parameter limit: expected an integer, got ten")
+		_, err = template.ResolveParameters(map[string]any{"name": "main", "order": "up"})
+		assert.ErrorContains(t, err, "up is not one of asc, desc")
+		_, err = template.ResolveParameters(map[string]any{"name": 3})
+		assert.ErrorContains(t, err, "expected a string")
+		_, err = template.ResolveParameters(map[string]any{})
+		assert.ErrorContains(t, err, "missing required parameter: name")
+	})
+
+	t.Run("Should describe parameters in the help", func(t *testing.T) {
+		assert.Equal(t, "string (default desc; one of asc, desc)", template.Parameters["order"].String())
+		assert.Contains(t, template.GetParameterHelp(), "  name: string (required)\n")
+	})
+}
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestLoadTemplates(t *testing.T) {
+	t.Run("Should load typed templates named after their files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplate(t, dir, "hot_functions.yaml", `
+name: Hot Functions
+description: Functions called from many packages
+category: hotspots
+query: |
+  MATCH (f:Function {project_id: $project_id})<-[:CALLS]-(c:Function)
+  WITH f, count(DISTINCT c.package) AS packages
+  WHERE packages >= $min_packages
+  RETURN f.name AS name, packages ORDER BY packages DESC LIMIT $limit
+parameters:
+  min_packages:
+    type: integer
+    description: Minimum number of calling packages
+    default: 3
+  limit:
+    type: integer
+    enum: [10, 50]
+    default: 10
+`)
+		writeTemplate(t, dir, "README.md", "not a template")
+		templates, err := LoadTemplates(dir)
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		template := templates["hot_functions"]
+		require.NotNil(t, template)
+		assert.Equal(t, "hotspots", template.Category)
+		assert.Equal(t, filepath.Join(dir, "hot_functions.yaml"), template.Source)
+		assert.Equal(t, int64(3), template.Parameters["min_packages"].Default)
+		assert.Equal(t, []any{int64(10), int64(50)}, template.Parameters["limit"].Enum)
+	})
+
+	t.Run("Should return no templates for a missing directory", func(t *testing.T) {
+		templates, err := LoadTemplates(filepath.Join(t.TempDir(), "missing"))
+		require.NoError(t, err)
+		assert.Empty(t, templates)
+	})
+
+	t.Run("Should reject templates that do not fit the schema", func(t *testing.T) {
+		tests := map[string]string{
+			"query uses unknown node label Func":        "name: x\nquery: MATCH (f:Func) RETURN f",
+			"query uses unknown relationship type CALL": "name: x\nquery: MATCH (f)-[:CALL]->(g) RETURN g",
+			"query parameter $name is not declared":     "name: x\nquery: 'MATCH (f {name: $name}) RETURN f'",
+			"invalid query":                             "name: x\nquery: MATCH (f) DELETE f",
+			"name is required":                          "query: MATCH (f) RETURN f",
+			"field cypher not found":                    "name: x\ncypher: MATCH (f) RETURN f",
+			"parameter n: invalid default: expected an integer, got many": "name: x\n" +
+				"query: MATCH (f) RETURN f LIMIT $n\nparameters:\n  n: {type: integer, default: many}",
+			`parameter n: unknown type "number"`: "name: x\n" +
+				"query: MATCH (f) RETURN f LIMIT $n\nparameters:\n  n: {type: number}",
+		}
+		for message, content := range tests {
+			dir := t.TempDir()
+			writeTemplate(t, dir, "bad.yml", content)
+			_, err := LoadTemplates(dir)
+			assert.ErrorContains(t, err, message)
+		}
+	})
+
+	t.Run("Should validate the built-in templates", func(t *testing.T) {
+		for name, template := range CommonTemplates {
+			assert.NoError(t, template.Validate(), name)
+		}
+	})
+}
+
+func TestRegisterTemplates(t *testing.T) {
+	t.Run("Should merge user templates with the built-in ones", func(t *testing.T) {
+		t.Cleanup(func() { RegisterTemplates(nil) })
+		RegisterTemplates(map[string]*Template{
+			"unused_functions": {Name: "Unused Functions", Category: "functions", Source: "unused_functions.yaml"},
+			"hot_functions":    {Name: "Hot Functions", Category: "hotspots", Source: "hot_functions.yaml"},
+		})
+
+		template, err := GetTemplate("unused_functions")
+		require.NoError(t, err)
+		assert.Equal(t, "unused_functions.yaml", template.Source)
+		assert.Len(t, Templates(), len(CommonTemplates)+1)
+		assert.Len(t, ListTemplates()["hotspots"], 1)
+		assert.Len(t, GetTemplatesByCategory("hotspots"), 1)
+		assert.Empty(t, CommonTemplates["unused_functions"].Source)
+	})
+}
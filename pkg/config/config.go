@@ -14,13 +14,21 @@ const (
 	defaultConfigType     = "yaml"
 	defaultNeo4jURI       = "bolt://localhost:7687"
 	defaultNeo4jUser      = "neo4j"
+	defaultStorageBackend = "neo4j"
+	defaultStoragePath    = ".gograph/graph.db"
+	defaultTemplatesDir   = ".gograph/templates"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Project  ProjectConfig  `mapstructure:"project"`
-	Neo4j    Neo4jConfig    `mapstructure:"neo4j"`
-	Analysis AnalysisConfig `mapstructure:"analysis"`
+	Project      ProjectConfig      `mapstructure:"project"`
+	Neo4j        Neo4jConfig        `mapstructure:"neo4j"`
+	Storage      StorageConfig      `mapstructure:"storage"`
+	Analysis     AnalysisConfig     `mapstructure:"analysis"`
+	Architecture ArchitectureConfig `mapstructure:"architecture"`
+	Policies     []PolicyConfig     `mapstructure:"policies"`
+	Snapshots    SnapshotsConfig    `mapstructure:"snapshots"`
+	Templates    TemplatesConfig    `mapstructure:"templates"`
 }
 
 // ProjectConfig represents project-specific configuration
@@ -38,6 +46,13 @@ type Neo4jConfig struct {
 	Database string `mapstructure:"database"`
 }
 
+// StorageConfig selects where the graph is stored. The "neo4j" backend uses
+// the Neo4j connection; the "embedded" backend keeps the graph in a local file.
+type StorageConfig struct {
+	Backend string `mapstructure:"backend"`
+	Path    string `mapstructure:"path"`
+}
+
 // AnalysisConfig represents analysis configuration
 type AnalysisConfig struct {
 	IgnoreDirs     []string `mapstructure:"ignore_dirs"`
@@ -47,6 +62,41 @@ type AnalysisConfig struct {
 	MaxConcurrency int      `mapstructure:"max_concurrency"`
 }
 
+// ArchitectureConfig represents the architecture rules enforced by gograph check
+type ArchitectureConfig struct {
+	MaxDependencyDepth int           `mapstructure:"max_dependency_depth"`
+	MaxFunctionLines   int           `mapstructure:"max_function_lines"`
+	UnusedFunctions    bool          `mapstructure:"unused_functions"`
+	Layers             []LayerConfig `mapstructure:"layers"`
+}
+
+// LayerConfig maps a set of package patterns to a named layer
+type LayerConfig struct {
+	Name            string   `mapstructure:"name"`
+	Packages        []string `mapstructure:"packages"`
+	MayDependOn     []string `mapstructure:"may_depend_on"`
+	MustNotDependOn []string `mapstructure:"must_not_depend_on"`
+}
+
+// PolicyConfig represents a Cypher policy that must return zero rows
+type PolicyConfig struct {
+	Name     string `mapstructure:"name"`
+	Query    string `mapstructure:"query"`
+	Severity string `mapstructure:"severity"`
+	Message  string `mapstructure:"message"`
+}
+
+// SnapshotsConfig represents how analysis snapshots are kept
+type SnapshotsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Keep    int  `mapstructure:"keep"` // Snapshots kept after each analysis (0 keeps all)
+}
+
+// TemplatesConfig represents where user query templates are read from
+type TemplatesConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -61,6 +111,10 @@ func DefaultConfig() *Config {
 			Password: "",
 			Database: "",
 		},
+		Storage: StorageConfig{
+			Backend: defaultStorageBackend,
+			Path:    defaultStoragePath,
+		},
 		Analysis: AnalysisConfig{
 			IgnoreDirs:     []string{".git", ".idea", ".vscode", "node_modules"},
 			IgnoreFiles:    []string{},
@@ -68,6 +122,18 @@ func DefaultConfig() *Config {
 			IncludeVendor:  false,
 			MaxConcurrency: 4,
 		},
+		Architecture: ArchitectureConfig{
+			MaxDependencyDepth: 0,
+			Layers:             []LayerConfig{},
+		},
+		Policies: []PolicyConfig{},
+		Snapshots: SnapshotsConfig{
+			Enabled: true,
+			Keep:    10,
+		},
+		Templates: TemplatesConfig{
+			Dir: defaultTemplatesDir,
+		},
 	}
 }
 
@@ -133,7 +199,12 @@ func Save(cfg *Config, configPath string) error {
 	// Set all values
 	viper.Set("project", cfg.Project)
 	viper.Set("neo4j", cfg.Neo4j)
+	viper.Set("storage", cfg.Storage)
 	viper.Set("analysis", cfg.Analysis)
+	viper.Set("architecture", cfg.Architecture)
+	viper.Set("policies", cfg.Policies)
+	viper.Set("snapshots", cfg.Snapshots)
+	viper.Set("templates", cfg.Templates)
 
 	// Write config file
 	if err := viper.WriteConfig(); err != nil {
@@ -24,6 +24,10 @@ func TestDefaultConfig(t *testing.T) {
 		assert.Empty(t, cfg.Neo4j.Password)
 		assert.Empty(t, cfg.Neo4j.Database)
 
+		// Storage defaults
+		assert.Equal(t, "neo4j", cfg.Storage.Backend)
+		assert.Equal(t, ".gograph/graph.db", cfg.Storage.Path)
+
 		// Analysis defaults
 		assert.Contains(t, cfg.Analysis.IgnoreDirs, ".git")
 		assert.Contains(t, cfg.Analysis.IgnoreDirs, ".idea")
@@ -33,6 +37,17 @@ func TestDefaultConfig(t *testing.T) {
 		assert.True(t, cfg.Analysis.IncludeTests)
 		assert.False(t, cfg.Analysis.IncludeVendor)
 		assert.Equal(t, 4, cfg.Analysis.MaxConcurrency)
+
+		// Architecture defaults
+		assert.Equal(t, 0, cfg.Architecture.MaxDependencyDepth)
+		assert.Empty(t, cfg.Architecture.Layers)
+
+		// Snapshot defaults
+		assert.True(t, cfg.Snapshots.Enabled)
+		assert.Equal(t, 10, cfg.Snapshots.Keep)
+
+		// Template defaults
+		assert.Equal(t, ".gograph/templates", cfg.Templates.Dir)
 	})
 }
 
@@ -92,6 +107,92 @@ analysis:
 		assert.Equal(t, 8, cfg.Analysis.MaxConcurrency)
 	})
 
+	t.Run("Should load architecture layers from YAML file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "gograph.yaml")
+
+		configContent := `
+project:
+  id: layered-project
+architecture:
+  max_dependency_depth: 6
+  max_function_lines: 120
+  unused_functions: true
+  layers:
+    - name: cmd
+      packages: ["cmd/..."]
+      may_depend_on: ["*"]
+    - name: domain
+      packages: ["engine/core", "engine/domain/..."]
+      must_not_depend_on: [infra]
+    - name: infra
+      packages: ["engine/infra/..."]
+`
+		err := os.WriteFile(configPath, []byte(configContent), 0644)
+		require.NoError(t, err)
+
+		cfg, err := config.Load(configPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, 6, cfg.Architecture.MaxDependencyDepth)
+		assert.Equal(t, 120, cfg.Architecture.MaxFunctionLines)
+		assert.True(t, cfg.Architecture.UnusedFunctions)
+		require.Len(t, cfg.Architecture.Layers, 3)
+		assert.Equal(t, "cmd", cfg.Architecture.Layers[0].Name)
+		assert.Equal(t, []string{"*"}, cfg.Architecture.Layers[0].MayDependOn)
+		assert.Equal(t, []string{"engine/core", "engine/domain/..."}, cfg.Architecture.Layers[1].Packages)
+		assert.Equal(t, []string{"infra"}, cfg.Architecture.Layers[1].MustNotDependOn)
+	})
+
+	t.Run("Should load policies from YAML file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "gograph.yaml")
+
+		configContent := `
+project:
+  id: policy-project
+policies:
+  - name: no-exported-globals
+    severity: high
+    message: Exported variables are not allowed
+    query: |
+      MATCH (v:Variable {project_id: $project_id, is_exported: true})
+      RETURN v.package AS package, v.name AS name
+`
+		err := os.WriteFile(configPath, []byte(configContent), 0644)
+		require.NoError(t, err)
+
+		cfg, err := config.Load(configPath)
+
+		require.NoError(t, err)
+		require.Len(t, cfg.Policies, 1)
+		assert.Equal(t, "no-exported-globals", cfg.Policies[0].Name)
+		assert.Equal(t, "high", cfg.Policies[0].Severity)
+		assert.Equal(t, "Exported variables are not allowed", cfg.Policies[0].Message)
+		assert.Contains(t, cfg.Policies[0].Query, "$project_id")
+	})
+
+	t.Run("Should load snapshot retention from YAML file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "gograph.yaml")
+
+		configContent := `
+project:
+  id: snapshot-project
+snapshots:
+  enabled: false
+  keep: 3
+`
+		err := os.WriteFile(configPath, []byte(configContent), 0644)
+		require.NoError(t, err)
+
+		cfg, err := config.Load(configPath)
+
+		require.NoError(t, err)
+		assert.False(t, cfg.Snapshots.Enabled)
+		assert.Equal(t, 3, cfg.Snapshots.Keep)
+	})
+
 	t.Run("Should load config from current directory when path is empty", func(t *testing.T) {
 		// Save current directory and restore it after test
 		originalDir, err := os.Getwd()
@@ -0,0 +1,194 @@
+package explore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+	"github.com/compozy/gograph/engine/infra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exploreGraph stores a small project: service.Create calls store.Save, and
+// store.Record implements service.Saver
+func exploreGraph(t *testing.T) *Navigator {
+	t.Helper()
+	dir := t.TempDir()
+	source := filepath.Join(dir, "store.go")
+	require.NoError(t, os.WriteFile(source, []byte("package store\n\n// Save stores\nfunc Save() error {\n\treturn nil\n}\n"), 0o600))
+
+	result := &core.AnalysisResult{ProjectID: "proj"}
+	node := func(id core.ID, nodeType core.NodeType, name string, props map[string]any) {
+		if props == nil {
+			props = map[string]any{}
+		}
+		props["project_id"] = "proj"
+		result.Nodes = append(result.Nodes, core.Node{ID: id, Type: nodeType, Name: name, Properties: props})
+	}
+	rel := func(relType core.RelationType, from, to core.ID) {
+		result.Relationships = append(result.Relationships, core.Relationship{
+			ID: core.NewID(), Type: relType, FromNodeID: from, ToNodeID: to,
+			Properties: map[string]any{"project_id": "proj"},
+		})
+	}
+	node("pkg-store", core.NodeTypePackage, "store", nil)
+	result.Nodes[0].Path = "app/store"
+	node("pkg-service", core.NodeTypePackage, "service", nil)
+	result.Nodes[1].Path = "app/service"
+	node("file-store", core.NodeTypeFile, "store.go", map[string]any{"path": source})
+	node("file-service", core.NodeTypeFile, "service.go", map[string]any{"path": "/missing/service.go"})
+	node("save", core.NodeTypeFunction, "Save", map[string]any{
+		"package": "app/store", "signature": "func() error", "line_start": 4, "line_end": 6,
+	})
+	node("create", core.NodeTypeFunction, "Create", map[string]any{"package": "app/service", "line_start": 3})
+	node("record", core.NodeTypeStruct, "Record", map[string]any{"package": "app/store"})
+	node("validate", core.NodeTypeMethod, "Validate", map[string]any{"package": "app/store", "receiver": "*app/store.Record"})
+	node("saver", core.NodeTypeInterface, "Saver", map[string]any{"package": "app/service"})
+	node("import-store", core.NodeTypeImport, "app/store", nil)
+	node("import-fmt", core.NodeTypeImport, "fmt", nil)
+
+	rel(core.RelationContains, "pkg-store", "file-store")
+	rel(core.RelationContains, "pkg-service", "file-service")
+	rel(core.RelationDefines, "file-store", "save")
+	rel(core.RelationDefines, "file-store", "record")
+	rel(core.RelationDefines, "file-store", "validate")
+	rel(core.RelationDefines, "file-service", "create")
+	rel(core.RelationDefines, "file-service", "saver")
+	rel(core.RelationImports, "file-service", "import-store")
+	rel(core.RelationImports, "file-service", "import-fmt")
+	rel(core.RelationCalls, "create", "save")
+	rel(core.RelationCalls, "create", "save")
+	rel(core.RelationImplements, "record", "saver")
+
+	repo, err := infra.NewEmbeddedRepository(filepath.Join(dir, "graph.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	ctx := context.Background()
+	require.NoError(t, repo.StoreAnalysis(ctx, result))
+	service := graph.NewService(nil, nil, nil, repo, graph.DefaultServiceConfig())
+	navigator, err := NewNavigator(ctx, service, repo, "proj")
+	require.NoError(t, err)
+	return navigator
+}
+
+func sectionNames(details *Details) map[string][]string {
+	sections := make(map[string][]string)
+	for _, section := range details.Sections {
+		for _, symbol := range section.Symbols {
+			sections[section.Title] = append(sections[section.Title], symbol.Name)
+		}
+	}
+	return sections
+}
+
+func TestNavigator(t *testing.T) {
+	navigator := exploreGraph(t)
+	ctx := context.Background()
+
+	t.Run("Should rank fuzzy matches by name segments", func(t *testing.T) {
+		results := navigator.Search("rv", 10)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "app/store.Record.Validate", results[0].Name)
+		assert.Equal(t, "app/service.Saver", navigator.Search("saver", 1)[0].Name)
+		assert.Equal(t, "app/store", navigator.Search("store", 1)[0].Name)
+		assert.Empty(t, navigator.Search("zzz", 10))
+	})
+
+	t.Run("Should list callers and callees once", func(t *testing.T) {
+		details, err := navigator.Details(ctx, "save")
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"Callers": {"app/service.Create"}}, sectionNames(details))
+		assert.Equal(t, 4, details.Symbol.Line)
+
+		details, err = navigator.Details(ctx, "create")
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"Callees": {"app/store.Save"}}, sectionNames(details))
+	})
+
+	t.Run("Should list methods and implementations", func(t *testing.T) {
+		details, err := navigator.Details(ctx, "record")
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"Methods":    {"app/store.Record.Validate"},
+			"Implements": {"app/service.Saver"},
+		}, sectionNames(details))
+
+		details, err = navigator.Details(ctx, "saver")
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"Implementations": {"app/store.Record"}}, sectionNames(details))
+	})
+
+	t.Run("Should list package imports and dependents", func(t *testing.T) {
+		details, err := navigator.Details(ctx, "pkg-service")
+		require.NoError(t, err)
+		sections := sectionNames(details)
+		assert.Equal(t, []string{"app/store", "fmt"}, sections["Imports"])
+		assert.Equal(t, []string{"app/service.Create", "app/service.Saver"}, sections["Symbols"])
+		assert.False(t, details.Sections[0].Symbols[1].Browsable())
+
+		details, err = navigator.Details(ctx, "pkg-store")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app/service"}, sectionNames(details)["Imported by"])
+	})
+}
+
+// press sends keys to the model and runs the commands they return, like the
+// bubbletea runtime would for loading symbols. Typing only returns the
+// cursor blink, which is skipped.
+func press(m *Model, keys ...tea.KeyMsg) {
+	for _, key := range keys {
+		_, cmd := m.Update(key)
+		if cmd == nil || key.Type == tea.KeyRunes {
+			continue
+		}
+		if msg, loaded := cmd().(detailsMsg); loaded {
+			m.Update(msg)
+		}
+	}
+}
+
+func runes(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
+
+func TestModel(t *testing.T) {
+	navigator := exploreGraph(t)
+
+	t.Run("Should open a search result and step through its relations", func(t *testing.T) {
+		m := New(context.Background(), navigator, "")
+		press(m, runes("sa"), runes("ve"))
+		require.NotEmpty(t, m.results)
+		assert.Equal(t, "app/store.Save", m.results[0].Name)
+
+		press(m, tea.KeyMsg{Type: tea.KeyEnter})
+		require.NotNil(t, m.current)
+		assert.False(t, m.searching)
+		assert.Equal(t, "app/store.Save", m.current.Symbol.Name)
+		assert.Contains(t, m.View(), "source not available") // Preview of the selected caller
+
+		press(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, "app/service.Create", m.current.Symbol.Name)
+		assert.Contains(t, m.View(), "return nil")
+
+		press(m, tea.KeyMsg{Type: tea.KeyLeft})
+		assert.Equal(t, "app/store.Save", m.current.Symbol.Name)
+		assert.Empty(t, m.history)
+	})
+
+	t.Run("Should open an exact match of the initial query", func(t *testing.T) {
+		m := New(context.Background(), navigator, "Record")
+		if msg, loaded := m.Init()().(detailsMsg); loaded {
+			m.Update(msg)
+		}
+		require.NotNil(t, m.current)
+		assert.Equal(t, "app/store.Record", m.current.Symbol.Name)
+
+		press(m, tea.KeyMsg{Type: tea.KeyTab})
+		assert.Equal(t, "app/service.Saver", m.rows[m.selected].symbol.Name)
+	})
+}
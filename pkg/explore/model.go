@@ -0,0 +1,429 @@
+package explore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/compozy/gograph/engine/core"
+)
+
+// searchLimit is the number of search results kept
+const searchLimit = 200
+
+// -----
+// Styles
+// -----
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("69"))
+	kindStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	sectionStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	borderStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+)
+
+// -----
+// Messages
+// -----
+
+// detailsMsg delivers the relations of an opened symbol
+type detailsMsg struct {
+	details *Details
+	err     error
+}
+
+// -----
+// Model
+// -----
+
+// row is a line of the browse list: a related symbol and its section
+type row struct {
+	section int
+	symbol  *Symbol
+}
+
+// visit is a previously open symbol and the relation selected in it
+type visit struct {
+	details  *Details
+	selected int
+}
+
+// Model is the explorer: a fuzzy symbol search, and a browser listing the
+// relations of the open symbol next to a preview of the selected one
+type Model struct {
+	ctx       context.Context
+	navigator *Navigator
+	input     textinput.Model
+	searching bool
+	results   []*Symbol
+	cursor    int
+	current   *Details
+	rows      []row
+	selected  int
+	history   []visit
+	loading   bool
+	err       error
+	sources   *sourceCache
+	width     int
+	height    int
+}
+
+// New creates an explorer starting with a search for query. A query naming
+// exactly one symbol opens it directly.
+func New(ctx context.Context, navigator *Navigator, query string) *Model {
+	input := textinput.New()
+	input.Prompt = "/ "
+	input.Placeholder = "search symbols"
+	input.SetValue(query)
+	input.Focus()
+	m := &Model{
+		ctx:       ctx,
+		navigator: navigator,
+		input:     input,
+		searching: true,
+		sources:   newSourceCache(),
+		width:     100,
+		height:    30,
+	}
+	m.search()
+	return m
+}
+
+// Init implements tea.Model
+func (m *Model) Init() tea.Cmd {
+	query := strings.ToLower(strings.TrimSpace(m.input.Value()))
+	if len(m.results) == 1 || (len(m.results) > 0 && query != "" && exactMatch(m.results[0], query)) {
+		return m.open(m.results[0])
+	}
+	return textinput.Blink
+}
+
+// Update implements tea.Model
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+	case detailsMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err == nil {
+			if m.current != nil {
+				m.history = append(m.history, visit{details: m.current, selected: m.selected})
+			}
+			m.show(msg.details)
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		return m.updateBrowse(msg)
+	}
+	return m, nil
+}
+
+func (m *Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		if m.current == nil {
+			return m, tea.Quit
+		}
+		m.searching = false
+		return m, nil
+	case "enter":
+		if m.cursor < len(m.results) {
+			return m, m.open(m.results[m.cursor])
+		}
+		return m, nil
+	case "up", "ctrl+p":
+		m.cursor = max(0, m.cursor-1)
+		return m, nil
+	case "down", "ctrl+n":
+		m.cursor = max(0, min(len(m.results)-1, m.cursor+1))
+		return m, nil
+	}
+	var cmd tea.Cmd
+	previous := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != previous {
+		m.search()
+	}
+	return m, cmd
+}
+
+func (m *Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "/", "esc":
+		m.searching = true
+		return m, textinput.Blink
+	case "enter", "right", "l":
+		if m.selected < len(m.rows) && m.rows[m.selected].symbol.Browsable() {
+			return m, m.open(m.rows[m.selected].symbol)
+		}
+	case "backspace", "left", "h":
+		m.back()
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup":
+		m.move(-m.listHeight())
+	case "pgdown":
+		m.move(m.listHeight())
+	case "home", "g":
+		m.move(-len(m.rows))
+	case "end", "G":
+		m.move(len(m.rows))
+	case "tab":
+		m.jumpSection(1)
+	case "shift+tab":
+		m.jumpSection(-1)
+	}
+	return m, nil
+}
+
+func (m *Model) search() {
+	m.results = m.navigator.Search(m.input.Value(), searchLimit)
+	m.cursor = 0
+}
+
+// open loads the relations of a symbol in the background
+func (m *Model) open(symbol *Symbol) tea.Cmd {
+	m.loading = true
+	navigator, ctx, id := m.navigator, m.ctx, symbol.ID
+	return func() tea.Msg {
+		details, err := navigator.Details(ctx, id)
+		return detailsMsg{details: details, err: err}
+	}
+}
+
+func (m *Model) show(details *Details) {
+	m.current = details
+	m.searching = false
+	m.selected = 0
+	m.rows = nil
+	for i, section := range details.Sections {
+		for _, symbol := range section.Symbols {
+			m.rows = append(m.rows, row{section: i, symbol: symbol})
+		}
+	}
+}
+
+// back returns to the previously open symbol
+func (m *Model) back() {
+	if len(m.history) == 0 {
+		return
+	}
+	previous := m.history[len(m.history)-1]
+	m.history = m.history[:len(m.history)-1]
+	m.show(previous.details)
+	m.selected = previous.selected
+}
+
+func (m *Model) move(delta int) {
+	m.selected = max(0, min(len(m.rows)-1, m.selected+delta))
+}
+
+// jumpSection selects the first symbol of the next or previous section
+func (m *Model) jumpSection(direction int) {
+	if len(m.rows) == 0 {
+		return
+	}
+	target := m.rows[m.selected].section + direction
+	for i, r := range m.rows {
+		if r.section == target {
+			m.selected = i
+			return
+		}
+	}
+}
+
+// -----
+// View
+// -----
+
+// View implements tea.Model
+func (m *Model) View() string {
+	leftWidth := m.width * 45 / 100
+	rightWidth := max(10, m.width-leftWidth-3)
+	var left []string
+	var previewed *Symbol
+	if m.searching {
+		left, previewed = m.searchView()
+	} else {
+		left, previewed = m.browseView()
+	}
+	right := m.previewView(previewed, rightWidth)
+
+	bodyHeight := m.bodyHeight()
+	separator := borderStyle.Render(" │ ")
+	lines := make([]string, 0, bodyHeight+2)
+	lines = append(lines, titleStyle.Render("gograph explore")+dimStyle.Render(fmt.Sprintf("  %d symbols",
+		len(m.navigator.Symbols()))))
+	for i := 0; i < bodyHeight; i++ {
+		lines = append(lines, pad(cell(left, i), leftWidth)+separator+truncate(cell(right, i), rightWidth))
+	}
+	lines = append(lines, m.statusLine())
+	return strings.Join(lines, "\n")
+}
+
+func (m *Model) bodyHeight() int {
+	return max(3, m.height-2)
+}
+
+// listHeight is the number of symbols the browse list shows at once
+func (m *Model) listHeight() int {
+	return max(1, m.bodyHeight()-3)
+}
+
+func (m *Model) searchView() ([]string, *Symbol) {
+	lines := []string{m.input.View(), ""}
+	height := m.bodyHeight() - len(lines)
+	start := max(0, m.cursor-height+1)
+	for i := start; i < len(m.results) && i < start+height; i++ {
+		symbol := m.results[i]
+		line := kindLabel(symbol.Kind) + " " + ShortName(symbol)
+		if i == m.cursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(m.results) == 0 {
+		lines = append(lines, dimStyle.Render("no matching symbols"))
+		return lines, nil
+	}
+	return lines, m.results[m.cursor]
+}
+
+func (m *Model) browseView() ([]string, *Symbol) {
+	symbol := m.current.Symbol
+	lines := []string{
+		kindLabel(symbol.Kind) + " " + titleStyle.Render(symbol.Name),
+		dimStyle.Render(location(symbol)),
+		"",
+	}
+	if len(m.rows) == 0 {
+		return append(lines, dimStyle.Render("no relations")), symbol
+	}
+
+	// Keep the selected symbol in view, with its section title above it
+	var list []string
+	selectedLine := 0
+	for i, r := range m.rows {
+		if i == 0 || m.rows[i-1].section != r.section {
+			section := m.current.Sections[r.section]
+			list = append(list, sectionStyle.Render(fmt.Sprintf("%s (%d)", section.Title, len(section.Symbols))))
+		}
+		line := "  " + kindLabel(r.symbol.Kind) + " " + ShortName(r.symbol)
+		if !r.symbol.Browsable() {
+			line = dimStyle.Render(line)
+		}
+		if i == m.selected {
+			line = selectedStyle.Render(line)
+			selectedLine = len(list)
+		}
+		list = append(list, line)
+	}
+	height := m.listHeight()
+	start := max(0, min(selectedLine-height/2, len(list)-height))
+	return append(lines, list[start:min(len(list), start+height)]...), m.rows[m.selected].symbol
+}
+
+func (m *Model) previewView(symbol *Symbol, width int) []string {
+	if symbol == nil {
+		return nil
+	}
+	lines := []string{kindStyle.Render(truncate(symbol.Signature, width))}
+	if symbol.Kind == core.NodeTypePackage || !symbol.Browsable() {
+		return append(lines, dimStyle.Render(symbol.Name))
+	}
+	preview, err := m.sources.preview(symbol, m.bodyHeight()-1)
+	if err != nil {
+		return append(lines, dimStyle.Render(err.Error()))
+	}
+	for _, line := range preview {
+		number := dimStyle.Render(fmt.Sprintf("%5d ", line.number))
+		text := line.text
+		if !line.inside {
+			text = dimStyle.Render(text)
+		}
+		lines = append(lines, number+text)
+	}
+	return lines
+}
+
+func (m *Model) statusLine() string {
+	switch {
+	case m.loading:
+		return dimStyle.Render("loading…")
+	case m.err != nil:
+		return errorStyle.Render(m.err.Error())
+	case m.searching:
+		return dimStyle.Render("type to search · ↑/↓ select · enter open · esc back · ctrl+c quit")
+	}
+	return dimStyle.Render(fmt.Sprintf("↑/↓ select · enter open · ← back (%d) · tab section · / search · q quit",
+		len(m.history)))
+}
+
+// -----
+// Helpers
+// -----
+
+func kindLabel(kind core.NodeType) string {
+	labels := map[core.NodeType]string{
+		core.NodeTypePackage:   "pkg  ",
+		core.NodeTypeFunction:  "func ",
+		core.NodeTypeMethod:    "meth ",
+		core.NodeTypeStruct:    "type ",
+		core.NodeTypeInterface: "iface",
+		core.NodeTypeImport:    "ext  ",
+	}
+	return kindStyle.Render(labels[kind])
+}
+
+// exactMatch reports whether a lowercase query is the name of a symbol with
+// or without its package, e.g. Save, Service.Save or user.Service.Save
+func exactMatch(symbol *Symbol, query string) bool {
+	name := strings.ToLower(symbol.Name)
+	return name == query || strings.HasSuffix(name, "."+query) || strings.HasSuffix(name, "/"+query)
+}
+
+func location(symbol *Symbol) string {
+	switch {
+	case symbol.File != "" && symbol.Line > 0:
+		return fmt.Sprintf("%s:%d", symbol.File, symbol.Line)
+	case symbol.File != "":
+		return symbol.File
+	}
+	return string(symbol.Kind)
+}
+
+func cell(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// truncate cuts a line, which may hold styles, to a display width
+func truncate(line string, width int) string {
+	return lipgloss.NewStyle().MaxWidth(width).Render(line)
+}
+
+// pad truncates a line and fills it with spaces to a display width
+func pad(line string, width int) string {
+	line = truncate(line, width)
+	return line + strings.Repeat(" ", max(0, width-lipgloss.Width(line)))
+}
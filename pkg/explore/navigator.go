@@ -0,0 +1,375 @@
+// Package explore implements the terminal UI of 'gograph explore', which
+// browses the callers, callees, implementations and package dependencies of
+// the symbols in a stored project graph.
+package explore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+)
+
+// symbolTypes are the node types that can be searched and browsed
+var symbolTypes = []core.NodeType{
+	core.NodeTypePackage,
+	core.NodeTypeFunction,
+	core.NodeTypeMethod,
+	core.NodeTypeStruct,
+	core.NodeTypeInterface,
+}
+
+// Symbol is a browsable node of the graph. External imports are symbols
+// without an ID, shown but not browsable.
+type Symbol struct {
+	ID        core.ID
+	Kind      core.NodeType
+	Name      string // Qualified name, or the import path of a package
+	Package   string
+	File      string
+	Line      int
+	EndLine   int
+	Signature string
+}
+
+// Browsable reports whether the symbol is a node of the project graph
+func (s *Symbol) Browsable() bool {
+	return s.ID != ""
+}
+
+// Section is a titled list of related symbols, such as the callers of a
+// function
+type Section struct {
+	Title   string
+	Symbols []*Symbol
+}
+
+// Details is a symbol with its related symbols
+type Details struct {
+	Symbol   *Symbol
+	Sections []*Section
+}
+
+// Navigator finds symbols and their relations. Symbols are loaded once for
+// searching; relations are read with graph.Service.GetNodeWithRelationships
+// each time a symbol is opened.
+type Navigator struct {
+	service  graph.Service
+	symbols  []*Symbol
+	byID     map[core.ID]*Symbol
+	packages map[string]*Symbol    // By import path
+	filePkg  map[core.ID]string    // Package path of each file
+	imports  map[string][]core.ID  // Import nodes by imported path
+	members  map[string][]*Symbol  // Symbols by package path
+	files    map[core.ID]core.Node // File nodes by ID
+}
+
+// NewNavigator loads the symbols of a project
+func NewNavigator(
+	ctx context.Context,
+	service graph.Service,
+	repo graph.Repository,
+	projectID core.ID,
+) (*Navigator, error) {
+	n := &Navigator{
+		service:  service,
+		byID:     make(map[core.ID]*Symbol),
+		packages: make(map[string]*Symbol),
+		filePkg:  make(map[core.ID]string),
+		imports:  make(map[string][]core.ID),
+		members:  make(map[string][]*Symbol),
+		files:    make(map[core.ID]core.Node),
+	}
+	for _, nodeType := range symbolTypes {
+		nodes, err := repo.FindNodesByType(ctx, nodeType, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s nodes: %w", nodeType, err)
+		}
+		for i := range nodes {
+			n.add(newSymbol(&nodes[i]))
+		}
+	}
+	if err := n.loadFiles(ctx, repo, projectID); err != nil {
+		return nil, err
+	}
+	imports, err := repo.FindNodesByType(ctx, core.NodeTypeImport, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load imports: %w", err)
+	}
+	for i := range imports {
+		n.imports[imports[i].Name] = append(n.imports[imports[i].Name], imports[i].ID)
+	}
+	sortSymbols(n.symbols)
+	return n, nil
+}
+
+func (n *Navigator) add(symbol *Symbol) {
+	n.symbols = append(n.symbols, symbol)
+	n.byID[symbol.ID] = symbol
+	if symbol.Kind == core.NodeTypePackage {
+		n.packages[symbol.Name] = symbol
+	} else {
+		n.members[symbol.Package] = append(n.members[symbol.Package], symbol)
+	}
+}
+
+// loadFiles indexes the package of each file and the file of each symbol
+func (n *Navigator) loadFiles(ctx context.Context, repo graph.Repository, projectID core.ID) error {
+	files, err := repo.FindNodesByType(ctx, core.NodeTypeFile, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to load files: %w", err)
+	}
+	for i := range files {
+		n.files[files[i].ID] = files[i]
+	}
+	contains, err := repo.FindRelationshipsByType(ctx, core.RelationContains, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to load package files: %w", err)
+	}
+	for _, rel := range contains {
+		if pkg := n.byID[rel.FromNodeID]; pkg != nil && pkg.Kind == core.NodeTypePackage {
+			n.filePkg[rel.ToNodeID] = pkg.Name
+		}
+	}
+	defines, err := repo.FindRelationshipsByType(ctx, core.RelationDefines, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to load symbol files: %w", err)
+	}
+	for _, rel := range defines {
+		file, known := n.files[rel.FromNodeID]
+		if symbol := n.byID[rel.ToNodeID]; symbol != nil && known {
+			symbol.File = filePath(&file)
+		}
+	}
+	return nil
+}
+
+// Symbols returns every symbol, sorted by name
+func (n *Navigator) Symbols() []*Symbol {
+	return n.symbols
+}
+
+// Search returns up to limit symbols matching the query, best matches first
+func (n *Navigator) Search(query string, limit int) []*Symbol {
+	return search(n.symbols, query, limit)
+}
+
+// Details reads the relations of a symbol
+func (n *Navigator) Details(ctx context.Context, id core.ID) (*Details, error) {
+	symbol := n.byID[id]
+	if symbol == nil {
+		return nil, fmt.Errorf("symbol %s not found", id)
+	}
+	node, err := n.service.GetNodeWithRelationships(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	details := &Details{Symbol: symbol}
+	switch symbol.Kind {
+	case core.NodeTypeFunction, core.NodeTypeMethod:
+		details.add("Callers", n.related(node.IncomingRelations, core.RelationCalls, true))
+		details.add("Callees", n.related(node.OutgoingRelations, core.RelationCalls, false))
+		details.add("Receiver", n.related(node.OutgoingRelations, core.RelationBelongsTo, false))
+	case core.NodeTypeStruct:
+		details.add("Methods", n.methods(symbol, node))
+		details.add("Implements", n.related(node.OutgoingRelations, core.RelationImplements, false))
+	case core.NodeTypeInterface:
+		details.add("Implementations", n.related(node.IncomingRelations, core.RelationImplements, true))
+		details.add("Implements", n.related(node.OutgoingRelations, core.RelationImplements, false))
+	case core.NodeTypePackage:
+		imports, err := n.packageImports(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		dependents, err := n.packageDependents(ctx, symbol.Name)
+		if err != nil {
+			return nil, err
+		}
+		details.add("Imports", imports)
+		details.add("Imported by", dependents)
+		details.add("Symbols", n.members[symbol.Name])
+	}
+	return details, nil
+}
+
+// add appends a section with the symbols sorted, skipping empty ones
+func (d *Details) add(title string, symbols []*Symbol) {
+	if len(symbols) == 0 {
+		return
+	}
+	sorted := append([]*Symbol(nil), symbols...)
+	sortSymbols(sorted)
+	d.Sections = append(d.Sections, &Section{Title: title, Symbols: sorted})
+}
+
+// related returns the symbols at the other end of relationships of a type,
+// counting each symbol once
+func (n *Navigator) related(rels []core.Relationship, relType core.RelationType, incoming bool) []*Symbol {
+	seen := make(map[core.ID]bool)
+	var symbols []*Symbol
+	for _, rel := range rels {
+		other := rel.ToNodeID
+		if incoming {
+			other = rel.FromNodeID
+		}
+		if rel.Type != relType || seen[other] {
+			continue
+		}
+		seen[other] = true
+		if symbol := n.byID[other]; symbol != nil {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// methods returns the methods of a struct, linked by BELONGS_TO or matched
+// by package and receiver type name
+func (n *Navigator) methods(owner *Symbol, node *graph.NodeWithRelations) []*Symbol {
+	methods := n.related(node.IncomingRelations, core.RelationBelongsTo, true)
+	seen := make(map[core.ID]bool, len(methods))
+	for _, method := range methods {
+		seen[method.ID] = true
+	}
+	for _, member := range n.members[owner.Package] {
+		if member.Kind == core.NodeTypeMethod && !seen[member.ID] &&
+			strings.HasPrefix(member.Name, owner.Name+".") {
+			methods = append(methods, member)
+		}
+	}
+	return methods
+}
+
+// packageImports follows the files of a package to the packages they import.
+// Imports of packages outside the project are listed without an ID.
+func (n *Navigator) packageImports(ctx context.Context, node *graph.NodeWithRelations) ([]*Symbol, error) {
+	seen := make(map[string]bool)
+	var imports []*Symbol
+	for _, contains := range node.OutgoingRelations {
+		if contains.Type != core.RelationContains {
+			continue
+		}
+		file, err := n.service.GetNodeWithRelationships(ctx, contains.ToNodeID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range file.OutgoingRelations {
+			if rel.Type != core.RelationImports {
+				continue
+			}
+			imported, err := n.service.GetNodeWithRelationships(ctx, rel.ToNodeID)
+			if err != nil {
+				return nil, err
+			}
+			importPath := imported.Node.Name
+			if seen[importPath] {
+				continue
+			}
+			seen[importPath] = true
+			if pkg := n.packages[importPath]; pkg != nil {
+				imports = append(imports, pkg)
+			} else {
+				imports = append(imports, &Symbol{Kind: core.NodeTypeImport, Name: importPath})
+			}
+		}
+	}
+	return imports, nil
+}
+
+// packageDependents returns the project packages with a file importing the
+// package
+func (n *Navigator) packageDependents(ctx context.Context, importPath string) ([]*Symbol, error) {
+	seen := make(map[string]bool)
+	var dependents []*Symbol
+	for _, importID := range n.imports[importPath] {
+		imported, err := n.service.GetNodeWithRelationships(ctx, importID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range imported.IncomingRelations {
+			pkgPath := n.filePkg[rel.FromNodeID]
+			if rel.Type != core.RelationImports || seen[pkgPath] || pkgPath == importPath {
+				continue
+			}
+			seen[pkgPath] = true
+			if pkg := n.packages[pkgPath]; pkg != nil {
+				dependents = append(dependents, pkg)
+			}
+		}
+	}
+	return dependents, nil
+}
+
+func newSymbol(node *core.Node) *Symbol {
+	pkg, _ := node.Properties["package"].(string)
+	signature, _ := node.Properties["signature"].(string)
+	symbol := &Symbol{
+		ID:        node.ID,
+		Kind:      node.Type,
+		Name:      pkg + "." + node.Name,
+		Package:   pkg,
+		Line:      intValue(node.Properties["line_start"]),
+		EndLine:   intValue(node.Properties["line_end"]),
+		Signature: signature,
+	}
+	switch node.Type {
+	case core.NodeTypePackage:
+		symbol.Name = node.Path
+		if importPath, ok := node.Properties["import_path"].(string); ok && symbol.Name == "" {
+			symbol.Name = importPath
+		}
+		if symbol.Name == "" {
+			symbol.Name = node.Name
+		}
+		symbol.Package = symbol.Name
+	case core.NodeTypeMethod:
+		receiver, _ := node.Properties["receiver"].(string)
+		symbol.Name = pkg + "." + receiverTypeName(receiver) + "." + node.Name
+	}
+	return symbol
+}
+
+// receiverTypeName strips the pointer, package and type parameters from a
+// receiver type such as *example.com/app.Cache[K]
+func receiverTypeName(receiver string) string {
+	name := strings.TrimPrefix(receiver, "*")
+	if index := strings.IndexByte(name, '['); index >= 0 {
+		name = name[:index]
+	}
+	if index := strings.LastIndexByte(name, '.'); index >= 0 {
+		name = name[index+1:]
+	}
+	return name
+}
+
+func filePath(file *core.Node) string {
+	if path, ok := file.Properties["path"].(string); ok && path != "" {
+		return path
+	}
+	return file.Path
+}
+
+// intValue converts a numeric property, which Neo4j returns as int64, to int
+func intValue(value any) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+func sortSymbols(symbols []*Symbol) {
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Name != symbols[j].Name {
+			return symbols[i].Name < symbols[j].Name
+		}
+		return symbols[i].ID < symbols[j].ID
+	})
+}
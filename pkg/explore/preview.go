@@ -0,0 +1,70 @@
+package explore
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// previewContext is the number of lines shown above a symbol
+const previewContext = 2
+
+// sourceCache keeps the lines of the files previewed so far
+type sourceCache struct {
+	files map[string][]string
+	errs  map[string]error
+}
+
+func newSourceCache() *sourceCache {
+	return &sourceCache{files: make(map[string][]string), errs: make(map[string]error)}
+}
+
+func (c *sourceCache) lines(path string) ([]string, error) {
+	if lines, cached := c.files[path]; cached {
+		return lines, nil
+	}
+	if err, failed := c.errs[path]; failed {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		c.errs[path] = err
+		return nil, err
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\t", "    "), "\n")
+	c.files[path] = lines
+	return lines, nil
+}
+
+// previewLine is a numbered source line; inside marks the lines of the
+// symbol itself
+type previewLine struct {
+	number int
+	text   string
+	inside bool
+}
+
+// preview returns up to height lines of source starting just above the symbol
+func (c *sourceCache) preview(symbol *Symbol, height int) ([]previewLine, error) {
+	if symbol.File == "" || symbol.Line <= 0 {
+		return nil, fmt.Errorf("no source location for %s", symbol.Name)
+	}
+	lines, err := c.lines(symbol.File)
+	if err != nil {
+		return nil, fmt.Errorf("source not available: %w", err)
+	}
+	end := symbol.EndLine
+	if end < symbol.Line {
+		end = symbol.Line
+	}
+	start := max(1, symbol.Line-previewContext)
+	var preview []previewLine
+	for number := start; number <= len(lines) && len(preview) < height; number++ {
+		preview = append(preview, previewLine{
+			number: number,
+			text:   lines[number-1],
+			inside: number >= symbol.Line && number <= end,
+		})
+	}
+	return preview, nil
+}
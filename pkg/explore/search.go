@@ -0,0 +1,144 @@
+package explore
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scores of a fuzzy match. Matches at the start of a name segment or
+// continuing the previous match make the ranking follow how names are typed.
+const (
+	scoreChar        = 1
+	scoreBoundary    = 8
+	scoreConsecutive = 4
+	scoreExactName   = 40
+	scorePrefixName  = 20
+	penaltyGap       = 1
+	maxGapPenalty    = 5 // Gaps longer than this cost the same
+	penaltyFullName  = 10
+	noMatch          = -1 << 30
+)
+
+type scoredSymbol struct {
+	symbol *Symbol
+	score  int
+}
+
+// search ranks the symbols matching the query as a case-insensitive
+// subsequence, trying the name without the package directory first. An
+// empty query returns the first symbols.
+func search(symbols []*Symbol, query string, limit int) []*Symbol {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return symbols[:min(limit, len(symbols))]
+	}
+	var matches []scoredSymbol
+	for _, symbol := range symbols {
+		score, matched := fuzzyScore(ShortName(symbol), query)
+		if !matched {
+			if score, matched = fuzzyScore(symbol.Name, query); matched {
+				score -= penaltyFullName
+			}
+		}
+		if matched {
+			matches = append(matches, scoredSymbol{symbol: symbol, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].symbol.Name) < len(matches[j].symbol.Name)
+	})
+	results := make([]*Symbol, 0, min(limit, len(matches)))
+	for i := 0; i < len(matches) && i < limit; i++ {
+		results = append(results, matches[i].symbol)
+	}
+	return results
+}
+
+// fuzzyScore matches a lowercase query against a name, choosing the
+// positions of the query characters that give the best score
+func fuzzyScore(name, query string) (int, bool) {
+	runes := []rune(name)
+	lower := []rune(strings.ToLower(name))
+	want := []rune(query)
+	if len(want) > len(lower) {
+		return 0, false
+	}
+	// best[i] is the best score of the query so far with its last character
+	// matched at position i
+	best := make([]int, len(lower))
+	for i := range best {
+		best[i] = noMatch
+		if lower[i] == want[0] {
+			best[i] = charScore(runes, i)
+		}
+	}
+	for j := 1; j < len(want); j++ {
+		next := make([]int, len(lower))
+		farBest := noMatch // Best score at least maxGapPenalty+1 positions back
+		for i := range lower {
+			next[i] = noMatch
+			if far := i - maxGapPenalty - 1; far >= 0 {
+				farBest = max(farBest, best[far])
+			}
+			if lower[i] != want[j] {
+				continue
+			}
+			prev := farBest - maxGapPenalty*penaltyGap
+			for k := max(0, i-maxGapPenalty); k < i; k++ {
+				gap := i - k - 1
+				if gap == 0 {
+					prev = max(prev, best[k]+scoreConsecutive)
+				} else {
+					prev = max(prev, best[k]-gap*penaltyGap)
+				}
+			}
+			if prev > noMatch/2 {
+				next[i] = prev + charScore(runes, i)
+			}
+		}
+		best = next
+	}
+	score := noMatch
+	for _, candidate := range best {
+		score = max(score, candidate)
+	}
+	if score <= noMatch/2 {
+		return 0, false
+	}
+	base := strings.ToLower(name[strings.LastIndexAny(name, "./")+1:])
+	switch {
+	case base == query:
+		score += scoreExactName
+	case strings.HasPrefix(base, query):
+		score += scorePrefixName
+	}
+	return score, true
+}
+
+func charScore(runes []rune, i int) int {
+	if isBoundary(runes, i) {
+		return scoreChar + scoreBoundary
+	}
+	return scoreChar
+}
+
+// isBoundary reports whether the rune at i starts a word, like the S of
+// Service in graph.Service or of userService
+func isBoundary(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := runes[i-1]
+	return prev == '.' || prev == '/' || prev == '_' || prev == '-' ||
+		(unicode.IsUpper(runes[i]) && unicode.IsLower(prev))
+}
+
+// ShortName is the name of a symbol without the directory of its package,
+// e.g. graph.Service.Save for example.com/app/graph.Service.Save
+func ShortName(symbol *Symbol) string {
+	return symbol.Name[strings.LastIndexByte(symbol.Package, '/')+1:]
+}
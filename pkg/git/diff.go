@@ -0,0 +1,157 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Hunk is a range of changed lines in a unified diff
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+}
+
+// FileDiff lists the changed hunks of one file. OldPath is empty for added
+// files and NewPath is empty for deleted files.
+type FileDiff struct {
+	OldPath string
+	NewPath string
+	Hunks   []Hunk
+}
+
+// LineRange is an inclusive range of lines in a file
+type LineRange struct {
+	Path  string
+	Start int
+	End   int
+}
+
+// hunkHeader matches "@@ -12,3 +12,4 @@"; omitted counts default to one line
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseDiff parses a unified diff as produced by git diff or diff -u
+func ParseDiff(r io.Reader) ([]*FileDiff, error) {
+	var files []*FileDiff
+	var current *FileDiff
+	// Lines of the current hunk still to be read, so that content lines
+	// starting with "---" are not mistaken for file headers
+	oldRemaining, newRemaining := 0, 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if oldRemaining > 0 || newRemaining > 0 {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				oldRemaining--
+			case strings.HasPrefix(line, "+"):
+				newRemaining--
+			case strings.HasPrefix(line, " "), line == "":
+				oldRemaining--
+				newRemaining--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			current = &FileDiff{OldPath: diffPath(line[4:])}
+			files = append(files, current)
+		case strings.HasPrefix(line, "+++ ") && current != nil:
+			current.NewPath = diffPath(line[4:])
+		case strings.HasPrefix(line, "@@ "):
+			match := hunkHeader.FindStringSubmatch(line)
+			if match == nil || current == nil {
+				return nil, fmt.Errorf("invalid hunk header %q", line)
+			}
+			hunk := Hunk{
+				OldStart: atoi(match[1], 0),
+				OldLines: atoi(match[2], 1),
+				NewStart: atoi(match[3], 0),
+				NewLines: atoi(match[4], 1),
+			}
+			current.Hunks = append(current.Hunks, hunk)
+			oldRemaining, newRemaining = hunk.OldLines, hunk.NewLines
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+	return files, nil
+}
+
+// DiffSince returns the changes of the working tree in dir relative to ref
+func DiffSince(ctx context.Context, dir, ref string) ([]*FileDiff, error) {
+	if _, err := run(ctx, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("unknown git revision %q", ref)
+	}
+	output, err := run(ctx, dir, "diff", "--no-color", "--no-ext-diff", "-U0", ref)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDiff(strings.NewReader(output))
+}
+
+// ChangedLines returns the line ranges touched by the diff. By default the
+// ranges refer to the new version of each file; with old set they refer to
+// the version before the diff was applied. Pure insertions and deletions
+// touch the line they follow on the side where they have no lines.
+func ChangedLines(files []*FileDiff, old bool) []LineRange {
+	var ranges []LineRange
+	for _, file := range files {
+		path := file.NewPath
+		if old {
+			path = file.OldPath
+		}
+		if path == "" {
+			continue
+		}
+		for _, hunk := range file.Hunks {
+			first, lines := hunk.NewStart, hunk.NewLines
+			if old {
+				first, lines = hunk.OldStart, hunk.OldLines
+			}
+			if first == 0 {
+				continue
+			}
+			last := first + lines - 1
+			if lines == 0 {
+				last = first
+			}
+			ranges = append(ranges, LineRange{Path: path, Start: first, End: last})
+		}
+	}
+	return ranges
+}
+
+// diffPath strips the a/ or b/ prefix and any trailing timestamp from a file
+// header path; /dev/null becomes empty
+func diffPath(header string) string {
+	path, _, _ := strings.Cut(header, "\t")
+	if path == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(path, "a/") || strings.HasPrefix(path, "b/") {
+		return path[2:]
+	}
+	return path
+}
+
+func atoi(value string, fallback int) int {
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
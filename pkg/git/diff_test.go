@@ -0,0 +1,102 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleDiff = `diff --git a/app/service.go b/app/service.go
+index 1111111..2222222 100644
+--- a/app/service.go
++++ b/app/service.go
+@@ -10,2 +10,3 @@ func Run() {
+-	old()
+--- not a header
++	updated()
++	more()
++	evenMore()
+@@ -40,0 +42 @@ func Stop() {
++	added()
+diff --git a/app/gone.go b/app/gone.go
+deleted file mode 100644
+--- a/app/gone.go
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package app
+-
+-func Gone() {}
+`
+
+func TestParseDiff(t *testing.T) {
+	t.Run("Should parse files and hunks", func(t *testing.T) {
+		files, err := ParseDiff(strings.NewReader(sampleDiff))
+
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		assert.Equal(t, "app/service.go", files[0].OldPath)
+		assert.Equal(t, "app/service.go", files[0].NewPath)
+		assert.Equal(t, []Hunk{
+			{OldStart: 10, OldLines: 2, NewStart: 10, NewLines: 3},
+			{OldStart: 40, OldLines: 0, NewStart: 42, NewLines: 1},
+		}, files[0].Hunks)
+		assert.Equal(t, "app/gone.go", files[1].OldPath)
+		assert.Empty(t, files[1].NewPath)
+	})
+
+	t.Run("Should reject malformed hunk headers", func(t *testing.T) {
+		_, err := ParseDiff(strings.NewReader("--- a/x.go\n+++ b/x.go\n@@ broken @@\n"))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid hunk header")
+	})
+}
+
+func TestChangedLines(t *testing.T) {
+	files, err := ParseDiff(strings.NewReader(sampleDiff))
+	require.NoError(t, err)
+
+	t.Run("Should return ranges of the new version", func(t *testing.T) {
+		assert.Equal(t, []LineRange{
+			{Path: "app/service.go", Start: 10, End: 12},
+			{Path: "app/service.go", Start: 42, End: 42},
+		}, ChangedLines(files, false))
+	})
+
+	t.Run("Should return ranges of the old version", func(t *testing.T) {
+		assert.Equal(t, []LineRange{
+			{Path: "app/service.go", Start: 10, End: 11},
+			{Path: "app/service.go", Start: 40, End: 40},
+			{Path: "app/gone.go", Start: 1, End: 3},
+		}, ChangedLines(files, true))
+	})
+}
+
+func TestDiffSince(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should diff the working tree against a revision", func(t *testing.T) {
+		dir := initRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0600))
+
+		files, err := DiffSince(ctx, dir, "HEAD")
+
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, []LineRange{{Path: "main.go", Start: 2, End: 3}}, ChangedLines(files, false))
+	})
+
+	t.Run("Should reject unknown revisions", func(t *testing.T) {
+		dir := initRepo(t)
+
+		_, err := DiffSince(ctx, dir, "does-not-exist")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown git revision "does-not-exist"`)
+	})
+}
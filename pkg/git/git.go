@@ -0,0 +1,118 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Revision identifies the commit checked out in a working tree
+type Revision struct {
+	CommitSHA   string    // Full SHA of HEAD
+	Branch      string    // Current branch, empty when HEAD is detached
+	CommittedAt time.Time // Committer date of HEAD
+}
+
+// Head returns the revision checked out in dir
+func Head(ctx context.Context, dir string) (*Revision, error) {
+	sha, err := run(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	branch, err := run(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if branch == "HEAD" {
+		branch = ""
+	}
+	date, err := run(ctx, dir, "show", "-s", "--format=%cI", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	committedAt, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected commit date %q: %w", date, err)
+	}
+	return &Revision{CommitSHA: sha, Branch: branch, CommittedAt: committedAt}, nil
+}
+
+// TopLevel returns the root directory of the repository containing dir
+func TopLevel(ctx context.Context, dir string) (string, error) {
+	return run(ctx, dir, "rev-parse", "--show-toplevel")
+}
+
+// Worktree is a temporary detached checkout of a revision
+type Worktree struct {
+	Path     string // Root directory of the checkout
+	repoRoot string // Root of the repository the worktree belongs to
+}
+
+// NewWorktree checks out ref into a temporary worktree of the repository
+// containing dir. Call Remove when done.
+func NewWorktree(ctx context.Context, dir, ref string) (*Worktree, error) {
+	root, err := TopLevel(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := run(ctx, root, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("unknown git revision %q", ref)
+	}
+
+	parent, err := os.MkdirTemp("", "gograph-worktree-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	path := filepath.Join(parent, "checkout")
+	if _, err := run(ctx, root, "worktree", "add", "--detach", "--quiet", path, ref); err != nil {
+		os.RemoveAll(parent)
+		return nil, err
+	}
+	return &Worktree{Path: path, repoRoot: root}, nil
+}
+
+// ProjectDir maps a directory of the original repository into the worktree
+func (w *Worktree) ProjectDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	rel, err := filepath.Rel(w.repoRoot, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside the repository %s", dir, w.repoRoot)
+	}
+	return filepath.Join(w.Path, rel), nil
+}
+
+// Remove deletes the worktree and its temporary directory
+func (w *Worktree) Remove(ctx context.Context) error {
+	_, err := run(ctx, w.repoRoot, "worktree", "remove", "--force", w.Path)
+	if removeErr := os.RemoveAll(filepath.Dir(w.Path)); err == nil && removeErr != nil {
+		err = fmt.Errorf("failed to remove worktree directory: %w", removeErr)
+	}
+	return err
+}
+
+// run executes a git command in dir and returns its trimmed output
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), message)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initRepo creates a git repository with a single commit
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	ctx := context.Background()
+	commands := [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+	}
+	for _, args := range commands {
+		_, err := run(ctx, dir, args...)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0600))
+	for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "initial"}} {
+		_, err := run(ctx, dir, args...)
+		require.NoError(t, err)
+	}
+	return dir
+}
+
+func TestHead(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should return commit and branch", func(t *testing.T) {
+		dir := initRepo(t)
+
+		revision, err := Head(ctx, dir)
+
+		require.NoError(t, err)
+		assert.Len(t, revision.CommitSHA, 40)
+		assert.Equal(t, "main", revision.Branch)
+		assert.WithinDuration(t, time.Now(), revision.CommittedAt, time.Minute)
+	})
+
+	t.Run("Should leave branch empty for detached HEAD", func(t *testing.T) {
+		dir := initRepo(t)
+		_, err := run(ctx, dir, "checkout", "-q", "--detach")
+		require.NoError(t, err)
+
+		revision, err := Head(ctx, dir)
+
+		require.NoError(t, err)
+		assert.Empty(t, revision.Branch)
+	})
+
+	t.Run("Should fail outside a repository", func(t *testing.T) {
+		_, err := Head(ctx, t.TempDir())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "git rev-parse HEAD failed")
+	})
+}
+
+func TestWorktree(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should check out a revision into a temporary directory", func(t *testing.T) {
+		dir := initRepo(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "new.go"), []byte("package sub\n"), 0600))
+
+		worktree, err := NewWorktree(ctx, dir, "HEAD")
+		require.NoError(t, err)
+
+		assert.FileExists(t, filepath.Join(worktree.Path, "main.go"))
+		assert.NoFileExists(t, filepath.Join(worktree.Path, "sub", "new.go"))
+		projectDir, err := worktree.ProjectDir(filepath.Join(dir, "sub"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(worktree.Path, "sub"), projectDir)
+
+		require.NoError(t, worktree.Remove(ctx))
+		assert.NoDirExists(t, worktree.Path)
+	})
+
+	t.Run("Should reject unknown revisions", func(t *testing.T) {
+		dir := initRepo(t)
+
+		_, err := NewWorktree(ctx, dir, "does-not-exist")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown git revision "does-not-exist"`)
+	})
+}
@@ -42,6 +42,11 @@ type SecurityConfig struct {
 	ForbiddenPaths []string `yaml:"forbidden_paths"`
 	RateLimit      int      `yaml:"rate_limit"`
 	MaxQueryTime   int      `yaml:"max_query_time"`
+	// AllowWrites lets execute_cypher run queries that change the graph
+	AllowWrites bool `yaml:"allow_writes"`
+	// UnscopedQueries lets execute_cypher run queries without limiting their
+	// node patterns to the project
+	UnscopedQueries bool `yaml:"unscoped_queries"`
 }
 
 // FeaturesConfig defines feature toggles
@@ -72,10 +77,12 @@ func DefaultConfig() *Config {
 			RequestTimeout:  30 * time.Second,
 		},
 		Security: SecurityConfig{
-			AllowedPaths:   []string{"."},
-			ForbiddenPaths: []string{".git", "vendor", "node_modules"},
-			RateLimit:      100,
-			MaxQueryTime:   30,
+			AllowedPaths:    []string{"."},
+			ForbiddenPaths:  []string{".git", "vendor", "node_modules"},
+			RateLimit:       100,
+			MaxQueryTime:    30,
+			AllowWrites:     false,
+			UnscopedQueries: false,
 		},
 		Features: FeaturesConfig{
 			EnableIncremental: true,
@@ -26,6 +26,8 @@ func TestDefaultConfig(t *testing.T) {
 		assert.NotEmpty(t, config.Security.ForbiddenPaths)
 		assert.Equal(t, 100, config.Security.RateLimit)
 		assert.Equal(t, 30, config.Security.MaxQueryTime)
+		assert.False(t, config.Security.AllowWrites)
+		assert.False(t, config.Security.UnscopedQueries)
 		assert.True(t, config.Features.EnableIncremental)
 		assert.True(t, config.Features.EnableValidation)
 		assert.True(t, config.Features.EnablePatterns)
@@ -0,0 +1,123 @@
+package shell
+
+import (
+	"strings"
+	"unicode"
+)
+
+// metaCommands are completed at the start of a statement
+var metaCommands = []string{
+	":exit", ":explain", ":format", ":help", ":param", ":params", ":profile", ":quit", ":schema",
+}
+
+// keywords are the Cypher keywords and functions completed outside patterns
+var keywords = []string{
+	"ALL", "AND", "AS", "ASC", "BY", "CALL", "CASE", "CONTAINS", "DESC", "DISTINCT", "ELSE", "END",
+	"ENDS", "EXISTS", "FALSE", "IN", "IS", "LIMIT", "MATCH", "NOT", "NULL", "OPTIONAL", "OR",
+	"ORDER", "RETURN", "SKIP", "STARTS", "THEN", "TRUE", "UNION", "UNWIND", "WHEN", "WHERE",
+	"WITH", "XOR",
+	"avg", "coalesce", "collect", "count", "endNode", "head", "id", "keys", "labels", "last",
+	"length", "max", "min", "nodes", "properties", "range", "relationships", "replace", "size",
+	"split", "startNode", "substring", "sum", "toInteger", "toLower", "toString", "toUpper",
+	"trim", "type",
+}
+
+// complete returns the word at the end of text and its completions: labels
+// after ':' in a node pattern or a label test, relationship types after ':'
+// or '|' in a relationship pattern, property keys after '.', parameters after
+// '$', meta-commands at the start of a statement and keywords elsewhere
+func complete(schema *Schema, params []string, text string) (string, []string) {
+	start := len(text)
+	for start > 0 && isWordByte(text[start-1]) {
+		start--
+	}
+	word := text[start:]
+	if strings.TrimLeftFunc(text, unicode.IsSpace) == ":"+word {
+		return ":" + word, matching(metaCommands, ":"+word)
+	}
+	if start > 0 {
+		switch text[start-1] {
+		case ':', '|':
+			switch openBracket(text[:start-1]) {
+			case '[':
+				return word, matching(schema.RelationshipTypes, word)
+			case '{':
+				return word, nil
+			}
+			return word, matching(schema.Labels, word)
+		case '.':
+			return word, matching(schema.PropertyKeys, word)
+		case '$':
+			return word, matching(params, word)
+		}
+	}
+	if word == "" {
+		return word, nil
+	}
+	return word, matching(keywords, word)
+}
+
+// matching returns the names starting with prefix, ignoring case
+func matching(names []string, prefix string) []string {
+	var matches []string
+	for _, name := range names {
+		if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+// openBracket returns the innermost bracket left open in text, or 0, skipping
+// the brackets in string literals
+func openBracket(text string) byte {
+	var open []byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\'', '"', '`':
+			i = closingQuote(text, i)
+		case '(', '[', '{':
+			open = append(open, c)
+		case ')', ']', '}':
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	if len(open) == 0 {
+		return 0
+	}
+	return open[len(open)-1]
+}
+
+// closingQuote returns the index of the quote ending the literal opened at
+// start, or the end of text
+func closingQuote(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case text[start]:
+			return i
+		}
+	}
+	return len(text)
+}
+
+// commonPrefix returns the longest prefix shared by names, ignoring case,
+// as written in the first name
+func commonPrefix(names []string) string {
+	prefix := names[0]
+	for _, name := range names[1:] {
+		n := 0
+		for n < len(prefix) && n < len(name) && strings.EqualFold(prefix[n:n+1], name[n:n+1]) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return prefix
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,88 @@
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistory is the number of statements kept in the history file
+const maxHistory = 1000
+
+// History is the list of statements entered in the shell. With a file it
+// persists between sessions, one statement per line.
+type History struct {
+	path    string
+	entries []string
+}
+
+// LoadHistory reads the history file at path, which is created on the first
+// statement. An empty path keeps the history in memory.
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path}
+	if path == "" {
+		return h, nil
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+		content := strings.Join(h.entries, "\n") + "\n"
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return nil, fmt.Errorf("failed to trim history: %w", err)
+		}
+	}
+	return h, nil
+}
+
+// Entries returns the statements, oldest first
+func (h *History) Entries() []string {
+	return h.entries
+}
+
+// Add appends a statement, joining its lines, unless it repeats the last one
+func (h *History) Add(statement string) error {
+	lines := strings.Split(statement, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	entry := strings.TrimSpace(strings.Join(lines, " "))
+	if entry == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return nil
+	}
+	h.entries = append(h.entries, entry)
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o750); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	if _, err := file.WriteString(entry + "\n"); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	return file.Close()
+}
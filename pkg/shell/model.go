@@ -0,0 +1,252 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	prompt         = "gograph> "
+	continuePrompt = "     ..> "
+)
+
+// -----
+// Styles
+// -----
+
+var (
+	promptStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("69"))
+	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+// -----
+// Messages
+// -----
+
+// resultMsg delivers the output of a statement
+type resultMsg struct {
+	output string
+	err    error
+}
+
+// -----
+// Model
+// -----
+
+// Model is the shell prompt. Entered lines and statement output are printed
+// above it, so the terminal keeps the scrollback of the session.
+type Model struct {
+	ctx        context.Context
+	session    *Session
+	history    *History
+	input      textinput.Model
+	lines      []string           // Entered lines of an unfinished statement
+	browsing   int                // Position in the history; past the end when not browsing
+	draft      string             // Input kept while browsing the history
+	candidates []string           // Completions shown below the prompt
+	cancel     context.CancelFunc // Cancels the running statement, nil when idle
+	width      int
+}
+
+// New creates the shell prompt for a session
+func New(ctx context.Context, session *Session, history *History) *Model {
+	input := textinput.New()
+	input.Prompt = promptStyle.Render(prompt)
+	input.Focus()
+	return &Model{
+		ctx:      ctx,
+		session:  session,
+		history:  history,
+		input:    input,
+		browsing: len(history.Entries()),
+		width:    80,
+	}
+}
+
+// Init prints the greeting and starts the cursor blink
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(
+		tea.Println(dimStyle.Render("End queries with ';'. Type :help for commands and :exit or ctrl+d to leave.")),
+		textinput.Blink,
+	)
+}
+
+// Update handles keys and statement results
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+	case resultMsg:
+		m.cancel = nil
+		return m, printResult(msg)
+	case tea.KeyMsg:
+		if m.cancel != nil {
+			if msg.Type == tea.KeyCtrlC {
+				m.cancel()
+			}
+			return m, nil
+		}
+		if cmd, handled := m.updateKey(msg); handled {
+			return m, cmd
+		}
+		m.candidates = nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// updateKey handles the keys of the shell, leaving the others to the input
+func (m *Model) updateKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.Type {
+	case tea.KeyCtrlD:
+		if m.input.Value() == "" && len(m.lines) == 0 {
+			return tea.Quit, true
+		}
+	case tea.KeyCtrlC:
+		if m.input.Value() == "" && len(m.lines) == 0 {
+			return tea.Quit, true
+		}
+		m.input.Reset()
+		m.setLines(nil)
+		return nil, true
+	case tea.KeyEnter:
+		return m.enter(), true
+	case tea.KeyTab:
+		m.complete()
+		return nil, true
+	case tea.KeyUp:
+		m.browse(-1)
+		return nil, true
+	case tea.KeyDown:
+		m.browse(1)
+		return nil, true
+	}
+	return nil, false
+}
+
+// enter prints the entered line, then runs the statement when it is complete:
+// a meta-command, or a query ending with ';'
+func (m *Model) enter() tea.Cmd {
+	line := m.input.Value()
+	echo := tea.Println(m.input.Prompt + line)
+	m.input.Reset()
+	m.candidates = nil
+	statement := strings.TrimSpace(strings.Join(append(m.lines, line), "\n"))
+	if statement == "" {
+		m.setLines(nil)
+		return echo
+	}
+	if !strings.HasPrefix(statement, ":") && !strings.HasSuffix(statement, ";") {
+		m.setLines(append(m.lines, line))
+		return echo
+	}
+	m.setLines(nil)
+
+	var saveErr tea.Cmd
+	if err := m.history.Add(statement); err != nil {
+		saveErr = tea.Println(errorStyle.Render(err.Error()))
+	}
+	m.browsing = len(m.history.Entries())
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancel = cancel
+	run := func() tea.Msg {
+		defer cancel()
+		output, err := m.session.Eval(ctx, statement)
+		return resultMsg{output: output, err: err}
+	}
+	return tea.Sequence(echo, saveErr, run)
+}
+
+func (m *Model) setLines(lines []string) {
+	m.lines = lines
+	if len(lines) == 0 {
+		m.input.Prompt = promptStyle.Render(prompt)
+	} else {
+		m.input.Prompt = promptStyle.Render(continuePrompt)
+	}
+}
+
+func printResult(msg resultMsg) tea.Cmd {
+	switch {
+	case errors.Is(msg.err, ErrQuit):
+		return tea.Quit
+	case msg.err != nil:
+		return tea.Println(errorStyle.Render("Error: " + msg.err.Error()))
+	case msg.output == "":
+		return nil
+	}
+	return tea.Println(strings.TrimRight(msg.output, "\n"))
+}
+
+// complete replaces the word before the cursor with the prefix its
+// completions share, and lists them when there are several
+func (m *Model) complete() {
+	value := []rune(m.input.Value())
+	position := m.input.Position()
+	before := string(value[:position])
+	word, candidates := m.session.Complete(strings.Join(append(m.lines, before), "\n"))
+	m.candidates = nil
+	if len(candidates) == 0 {
+		return
+	}
+	if len(candidates) > 1 {
+		m.candidates = candidates
+	}
+	insert := commonPrefix(candidates)
+	if len(insert) <= len(word) {
+		return
+	}
+	start := position - len([]rune(word))
+	m.input.SetValue(string(value[:start]) + insert + string(value[position:]))
+	m.input.SetCursor(start + len([]rune(insert)))
+}
+
+// browse replaces the input with an older or newer history entry
+func (m *Model) browse(delta int) {
+	entries := m.history.Entries()
+	next := min(max(m.browsing+delta, 0), len(entries))
+	if next == m.browsing {
+		return
+	}
+	if m.browsing == len(entries) {
+		m.draft = m.input.Value()
+	}
+	m.browsing = next
+	m.candidates = nil
+	if next == len(entries) {
+		m.input.SetValue(m.draft)
+	} else {
+		m.input.SetValue(entries[next])
+	}
+	m.input.CursorEnd()
+}
+
+// View renders the prompt and the completions
+func (m *Model) View() string {
+	if m.cancel != nil {
+		return dimStyle.Render("Running… ctrl+c cancels")
+	}
+	if len(m.candidates) == 0 {
+		return m.input.View()
+	}
+	line := ""
+	for i, candidate := range m.candidates {
+		if len(line)+len(candidate)+2 > m.width-2 {
+			line += "…"
+			break
+		}
+		if i > 0 {
+			line += "  "
+		}
+		line += candidate
+	}
+	return m.input.View() + "\n" + dimStyle.Render(line)
+}
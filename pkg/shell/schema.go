@@ -0,0 +1,82 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+)
+
+// Schema holds the names the shell completes, read from the stored graph
+type Schema struct {
+	Labels            []string
+	RelationshipTypes []string
+	PropertyKeys      []string
+}
+
+// LoadSchema reads the labels, relationship types and property keys of a
+// project, or of the whole store when the project ID is empty
+func LoadSchema(ctx context.Context, executor graph.QueryExecutor, projectID core.ID) (*Schema, error) {
+	match, params := "MATCH (n)", map[string]any{}
+	if projectID != "" {
+		match, params = "MATCH (n {project_id: $project_id})", map[string]any{"project_id": projectID.String()}
+	}
+	labels, err := names(ctx, executor, match+" UNWIND labels(n) AS name RETURN DISTINCT name", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read labels: %w", err)
+	}
+	types, err := names(ctx, executor, match+"-[r]->() RETURN DISTINCT type(r) AS name", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read relationship types: %w", err)
+	}
+	nodeKeys, err := names(ctx, executor, match+" UNWIND keys(n) AS name RETURN DISTINCT name", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read property keys: %w", err)
+	}
+	relKeys, err := names(ctx, executor, match+"-[r]->() UNWIND keys(r) AS name RETURN DISTINCT name", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read property keys: %w", err)
+	}
+	return &Schema{
+		Labels:            uniqueSorted(labels),
+		RelationshipTypes: uniqueSorted(types),
+		PropertyKeys:      uniqueSorted(append(nodeKeys, relKeys...)),
+	}, nil
+}
+
+// names returns the strings in the name column of a query
+func names(ctx context.Context, executor graph.QueryExecutor, query string, params map[string]any) ([]string, error) {
+	results, err := executor.ExecuteQuery(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, result := range results {
+		if name, ok := result["name"].(string); ok {
+			values = append(values, name)
+		}
+	}
+	return values, nil
+}
+
+// String lists the names of the schema, as shown by :schema
+func (s *Schema) String() string {
+	return fmt.Sprintf("Labels:             %s\nRelationship types: %s\nProperty keys:      %s",
+		strings.Join(s.Labels, ", "),
+		strings.Join(s.RelationshipTypes, ", "),
+		strings.Join(s.PropertyKeys, ", "))
+}
+
+func uniqueSorted(names []string) []string {
+	sort.Strings(names)
+	unique := names[:0]
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,302 @@
+// Package shell implements 'gograph shell', an interactive Cypher prompt with
+// parameters, query plans and completion of the names in the stored graph.
+package shell
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/graph"
+)
+
+// ErrQuit is returned by Session.Eval for :exit and :quit
+var ErrQuit = errors.New("quit")
+
+// Formatter writes query results in an output format
+type Formatter func(w io.Writer, results []map[string]any) error
+
+// parameterName matches the names :param accepts
+var parameterName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+const helpText = `Queries run when they end with ';' and may span several lines.
+
+  :param name => value   Set $name to the value of a Cypher expression
+  :params                List the parameters
+  :params clear          Remove every parameter
+  :format [name]         Show or set the output format
+  :schema                Reload and show the labels, types and keys
+  :explain [query]       Show the plan of a query, or of the last one
+  :profile [query]       Run a query and show its plan with row counts
+  :help                  Show this help
+  :exit, :quit           Leave the shell
+
+Tab completes labels after ':', relationship types inside [...], property
+keys after '.', parameters after '$', and keywords and functions elsewhere.`
+
+// Session evaluates the statements of a shell: Cypher queries and
+// meta-commands. It keeps the parameters, the output format and the schema
+// used for completion between statements.
+type Session struct {
+	executor   graph.QueryExecutor
+	projectID  core.ID
+	formatters map[string]Formatter
+	format     string
+	params     map[string]any
+	schema     *Schema
+	lastQuery  string
+}
+
+// NewSession creates a session writing results with the formatter of format.
+// $project_id is bound to the project ID, when there is one.
+func NewSession(
+	executor graph.QueryExecutor,
+	projectID core.ID,
+	formatters map[string]Formatter,
+	format string,
+) (*Session, error) {
+	s := &Session{
+		executor:   executor,
+		projectID:  projectID,
+		formatters: formatters,
+		params:     make(map[string]any),
+		schema:     &Schema{},
+	}
+	if _, err := s.setFormat(format); err != nil {
+		return nil, err
+	}
+	if projectID != "" {
+		s.params["project_id"] = projectID.String()
+	}
+	return s, nil
+}
+
+// LoadSchema reads the names completed by the session from the graph
+func (s *Session) LoadSchema(ctx context.Context) error {
+	schema, err := LoadSchema(ctx, s.executor, s.projectID)
+	if err != nil {
+		return err
+	}
+	s.schema = schema
+	return nil
+}
+
+// Complete returns the word typed at the end of text and the names that
+// complete it
+func (s *Session) Complete(text string) (string, []string) {
+	params := make([]string, 0, len(s.params))
+	for name := range s.params {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	return complete(s.schema, params, text)
+}
+
+// Eval runs a statement and returns its output. Statements starting with ':'
+// are meta-commands; anything else is a Cypher query, whose trailing ';' is
+// optional.
+func (s *Session) Eval(ctx context.Context, statement string) (string, error) {
+	statement = strings.TrimSpace(statement)
+	if statement == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(statement, ":") {
+		return s.query(ctx, trimQuery(statement))
+	}
+	name, args, _ := strings.Cut(statement, " ")
+	args = strings.TrimSpace(args)
+	switch name {
+	case ":help":
+		return helpText, nil
+	case ":exit", ":quit":
+		return "", ErrQuit
+	case ":param":
+		return s.setParam(ctx, args)
+	case ":params":
+		return s.listParams(args)
+	case ":format":
+		return s.setFormat(args)
+	case ":schema":
+		if err := s.LoadSchema(ctx); err != nil {
+			return "", err
+		}
+		return s.schema.String(), nil
+	case ":explain":
+		return s.explain(ctx, args, false)
+	case ":profile":
+		return s.explain(ctx, args, true)
+	}
+	return "", fmt.Errorf("unknown command %s, type :help for the list", name)
+}
+
+func (s *Session) query(ctx context.Context, query string) (string, error) {
+	start := time.Now()
+	results, err := s.executor.ExecuteQuery(ctx, query, s.params)
+	if err != nil {
+		return "", err
+	}
+	s.lastQuery = query
+	var out strings.Builder
+	if err := s.formatters[s.format](&out, results); err != nil {
+		return "", fmt.Errorf("failed to format results: %w", err)
+	}
+	fmt.Fprintf(&out, "\n%s in %v", countRows(int64(len(results))), time.Since(start).Round(time.Microsecond))
+	return out.String(), nil
+}
+
+// setParam evaluates the expression of "name => expression" and binds the
+// value to $name
+func (s *Session) setParam(ctx context.Context, args string) (string, error) {
+	name, expression, found := strings.Cut(args, "=>")
+	name = strings.Trim(strings.TrimSpace(name), "`")
+	expression = strings.TrimSpace(expression)
+	if !found || !parameterName.MatchString(name) || expression == "" {
+		return "", fmt.Errorf("usage: :param name => value")
+	}
+	results, err := s.executor.ExecuteQuery(ctx, "RETURN "+expression+" AS value", s.params)
+	if err != nil {
+		return "", err
+	}
+	if len(results) != 1 {
+		return "", fmt.Errorf("value of $%s did not evaluate to one row", name)
+	}
+	s.params[name] = results[0]["value"]
+	return formatParam(name, s.params[name]), nil
+}
+
+func (s *Session) listParams(args string) (string, error) {
+	switch args {
+	case "":
+	case "clear":
+		clear(s.params)
+		return "Parameters cleared.", nil
+	default:
+		return "", fmt.Errorf("usage: :params [clear]")
+	}
+	if len(s.params) == 0 {
+		return "No parameters set.", nil
+	}
+	names := make([]string, 0, len(s.params))
+	for name := range s.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = formatParam(name, s.params[name])
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s *Session) setFormat(format string) (string, error) {
+	if format == "" {
+		return "Output format: " + s.format, nil
+	}
+	if _, ok := s.formatters[format]; !ok {
+		names := make([]string, 0, len(s.formatters))
+		for name := range s.formatters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("invalid format: %s (must be one of %s)", format, strings.Join(names, ", "))
+	}
+	s.format = format
+	return "Output format: " + format, nil
+}
+
+// explain shows the plan of a query, or of the last query run. Profiling
+// runs the query and shows its results before the plan.
+func (s *Session) explain(ctx context.Context, query string, profile bool) (string, error) {
+	query = trimQuery(query)
+	if query == "" {
+		query = s.lastQuery
+	}
+	if query == "" {
+		return "", fmt.Errorf("no query to explain; give one or run one first")
+	}
+	explainer, ok := s.executor.(graph.QueryExplainer)
+	if !ok {
+		return "", fmt.Errorf("the graph store cannot explain queries")
+	}
+	if !profile {
+		plan, err := explainer.ExplainQuery(ctx, query, s.params)
+		if err != nil {
+			return "", err
+		}
+		return formatPlan(plan, false), nil
+	}
+	plan, results, err := explainer.ProfileQuery(ctx, query, s.params)
+	if err != nil {
+		return "", err
+	}
+	s.lastQuery = query
+	var out strings.Builder
+	if err := s.formatters[s.format](&out, results); err != nil {
+		return "", fmt.Errorf("failed to format results: %w", err)
+	}
+	out.WriteString("\n")
+	out.WriteString(formatPlan(plan, true))
+	return out.String(), nil
+}
+
+// formatPlan draws a plan as a tree, the last operator first
+func formatPlan(plan *graph.QueryPlan, profiled bool) string {
+	var out strings.Builder
+	writePlan(&out, plan, "", "", profiled)
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func writePlan(out *strings.Builder, plan *graph.QueryPlan, indent, branch string, profiled bool) {
+	out.WriteString(indent + branch + plan.Operator)
+	if plan.Details != "" {
+		out.WriteString(" " + strings.Join(strings.Fields(plan.Details), " "))
+	}
+	if profiled {
+		out.WriteString("  (" + countRows(plan.Rows))
+		if plan.DBHits > 0 {
+			fmt.Fprintf(out, ", %d db hits", plan.DBHits)
+		}
+		out.WriteString(")")
+	}
+	out.WriteString("\n")
+	switch branch {
+	case "├─ ":
+		indent += "│  "
+	case "└─ ":
+		indent += "   "
+	}
+	for i, child := range plan.Children {
+		childBranch := "├─ "
+		if i == len(plan.Children)-1 {
+			childBranch = "└─ "
+		}
+		writePlan(out, child, indent, childBranch, profiled)
+	}
+}
+
+func formatParam(name string, value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%s => %v", name, value)
+	}
+	return fmt.Sprintf("%s => %s", name, encoded)
+}
+
+func countRows(rows int64) string {
+	if rows == 1 {
+		return "1 row"
+	}
+	return fmt.Sprintf("%d rows", rows)
+}
+
+// trimQuery removes the spaces and the ';' ending a query
+func trimQuery(query string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+}
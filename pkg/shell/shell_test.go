@@ -0,0 +1,267 @@
+package shell
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/compozy/gograph/engine/core"
+	"github.com/compozy/gograph/engine/infra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shellSession stores a project where Create calls Save in an embedded repo
+func shellSession(t *testing.T) *Session {
+	t.Helper()
+	dir := t.TempDir()
+	result := &core.AnalysisResult{ProjectID: "proj"}
+	for _, name := range []string{"Create", "Save"} {
+		result.Nodes = append(result.Nodes, core.Node{
+			ID: core.ID(strings.ToLower(name)), Type: core.NodeTypeFunction, Name: name,
+			Properties: map[string]any{"project_id": "proj", "package": "app"},
+		})
+	}
+	result.Relationships = append(result.Relationships, core.Relationship{
+		ID: "calls", Type: core.RelationCalls, FromNodeID: "create", ToNodeID: "save",
+		Properties: map[string]any{"project_id": "proj", "line": 3},
+	})
+	repo, err := infra.NewEmbeddedRepository(filepath.Join(dir, "graph.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	require.NoError(t, repo.StoreAnalysis(context.Background(), result))
+
+	session, err := NewSession(repo, "proj", map[string]Formatter{
+		"names": func(w io.Writer, results []map[string]any) error {
+			for _, result := range results {
+				fmt.Fprintln(w, result["name"])
+			}
+			return nil
+		},
+		"json": func(w io.Writer, results []map[string]any) error {
+			return json.NewEncoder(w).Encode(results)
+		},
+	}, "names")
+	require.NoError(t, err)
+	require.NoError(t, session.LoadSchema(context.Background()))
+	return session
+}
+
+func TestSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should run queries with $project_id bound", func(t *testing.T) {
+		session := shellSession(t)
+		output, err := session.Eval(ctx, "MATCH (f:Function {project_id: $project_id})\nRETURN f.name AS name ORDER BY name;")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(output, "Create\nSave\n\n2 rows in "), output)
+	})
+
+	t.Run("Should set parameters from expressions", func(t *testing.T) {
+		session := shellSession(t)
+		output, err := session.Eval(ctx, ":param names => ['Save', 'Missing']")
+		require.NoError(t, err)
+		assert.Equal(t, `names => ["Save","Missing"]`, output)
+		output, err = session.Eval(ctx, "MATCH (f:Function) WHERE f.name IN $names RETURN f.name AS name")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Save\n\n1 row in ")
+
+		output, err = session.Eval(ctx, ":params")
+		require.NoError(t, err)
+		assert.Equal(t, "names => [\"Save\",\"Missing\"]\nproject_id => \"proj\"", output)
+		_, err = session.Eval(ctx, ":param 1x => 2")
+		assert.ErrorContains(t, err, "usage")
+		_, err = session.Eval(ctx, ":params clear")
+		require.NoError(t, err)
+		output, _ = session.Eval(ctx, ":params")
+		assert.Equal(t, "No parameters set.", output)
+	})
+
+	t.Run("Should switch formats", func(t *testing.T) {
+		session := shellSession(t)
+		_, err := session.Eval(ctx, ":format xml")
+		assert.ErrorContains(t, err, "must be one of json, names")
+		output, err := session.Eval(ctx, ":format json")
+		require.NoError(t, err)
+		assert.Equal(t, "Output format: json", output)
+		output, err = session.Eval(ctx, "RETURN 1 AS one;")
+		require.NoError(t, err)
+		assert.Contains(t, output, `[{"one":1}]`)
+	})
+
+	t.Run("Should explain and profile queries", func(t *testing.T) {
+		session := shellSession(t)
+		_, err := session.Eval(ctx, ":explain")
+		assert.ErrorContains(t, err, "no query to explain")
+
+		output, err := session.Eval(ctx, ":explain MATCH (f:Function) WHERE f.name = 'Save' RETURN f.name AS name;")
+		require.NoError(t, err)
+		assert.Equal(t, "RETURN f.name AS name\n└─ MATCH (f:Function) WHERE f.name = 'Save'", output)
+
+		_, err = session.Eval(ctx, "MATCH (f:Function) RETURN f.name AS name")
+		require.NoError(t, err)
+		output, err = session.Eval(ctx, ":profile")
+		require.NoError(t, err)
+		assert.Equal(t, "Create\nSave\n\nRETURN f.name AS name  (2 rows)\n└─ MATCH (f:Function)  (2 rows)", output)
+	})
+
+	t.Run("Should report unknown commands and quit", func(t *testing.T) {
+		session := shellSession(t)
+		_, err := session.Eval(ctx, ":drop")
+		assert.ErrorContains(t, err, "unknown command :drop")
+		_, err = session.Eval(ctx, ":exit")
+		assert.ErrorIs(t, err, ErrQuit)
+		output, err := session.Eval(ctx, "  ")
+		require.NoError(t, err)
+		assert.Empty(t, output)
+	})
+}
+
+func TestComplete(t *testing.T) {
+	session := shellSession(t)
+	tests := []struct {
+		text       string
+		word       string
+		candidates []string
+	}{
+		{"MATCH (f:Fu", "Fu", []string{"Function"}},
+		{"MATCH (f)-[:C", "C", []string{"CALLS"}},
+		{"MATCH (f)-[r:CALLS|", "", []string{"CALLS"}},
+		{"MATCH (f {name: 'a(b[c'})-[:", "", []string{"CALLS"}},
+		{"MATCH (f {name: ", "", nil},
+		{"MATCH (f) WHERE f:", "", []string{"Function", "ProjectMetadata"}},
+		{"RETURN f.pro", "pro", []string{"project_id"}},
+		{"RETURN f.l", "l", []string{"line"}},
+		{"MATCH (f {project_id: $p", "p", []string{"project_id"}},
+		{"  :pa", ":pa", []string{":param", ":params"}},
+		{":explain mat", "mat", []string{"MATCH"}},
+		{"MATCH (f) RETURN co", "co", []string{"CONTAINS", "coalesce", "collect", "count"}},
+		{"MATCH (f) ", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run("Should complete "+tt.text, func(t *testing.T) {
+			word, candidates := session.Complete(tt.text)
+			assert.Equal(t, tt.word, word)
+			assert.Equal(t, tt.candidates, candidates)
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	t.Run("Should persist statements on one line each", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".gograph", "shell_history")
+		history, err := LoadHistory(path)
+		require.NoError(t, err)
+		require.NoError(t, history.Add("MATCH (n)\n  RETURN n;"))
+		require.NoError(t, history.Add("MATCH (n)\n  RETURN n;"))
+		require.NoError(t, history.Add(":schema"))
+
+		history, err = LoadHistory(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"MATCH (n) RETURN n;", ":schema"}, history.Entries())
+	})
+
+	t.Run("Should keep the latest statements", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history")
+		var lines []string
+		for i := 0; i < maxHistory+5; i++ {
+			lines = append(lines, fmt.Sprintf("RETURN %d;", i))
+		}
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+		history, err := LoadHistory(path)
+		require.NoError(t, err)
+		assert.Len(t, history.Entries(), maxHistory)
+		assert.Equal(t, "RETURN 5;", history.Entries()[0])
+	})
+}
+
+// press sends keys to the model and runs the statements they start. Printed
+// lines are collected from the sequences the model returns.
+func press(m *Model, keys ...tea.KeyMsg) []string {
+	var printed []string
+	for _, key := range keys {
+		_, cmd := m.Update(key)
+		if cmd == nil || key.Type == tea.KeyRunes {
+			continue
+		}
+		printed = append(printed, run(m, cmd)...)
+	}
+	return printed
+}
+
+// run runs a command of the model, following sequences and feeding results
+// back to it
+func run(m *Model, cmd tea.Cmd) []string {
+	var printed []string
+	switch msg := cmd().(type) {
+	case resultMsg:
+		_, next := m.Update(msg)
+		if next != nil {
+			printed = append(printed, run(m, next)...)
+		}
+	default:
+		// tea.Sequence returns an unexported slice of commands
+		if value := reflect.ValueOf(msg); value.Kind() == reflect.Slice {
+			for i := 0; i < value.Len(); i++ {
+				if inner, ok := value.Index(i).Interface().(tea.Cmd); ok && inner != nil {
+					printed = append(printed, run(m, inner)...)
+				}
+			}
+			break
+		}
+		printed = append(printed, fmt.Sprintf("%v", msg))
+	}
+	return printed
+}
+
+func runes(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
+
+func TestModel(t *testing.T) {
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	t.Run("Should run a query entered on several lines", func(t *testing.T) {
+		history, err := LoadHistory("")
+		require.NoError(t, err)
+		m := New(context.Background(), shellSession(t), history)
+		press(m, runes("MATCH (f:Function)"), enter)
+		assert.Equal(t, []string{"MATCH (f:Function)"}, m.lines)
+		assert.Contains(t, m.View(), "..>")
+
+		printed := press(m, runes("RETURN f.name AS name ORDER BY name;"), enter)
+		require.NotEmpty(t, printed)
+		assert.Contains(t, strings.Join(printed, "\n"), "Create\nSave")
+		assert.Empty(t, m.lines)
+		assert.Nil(t, m.cancel)
+		assert.Equal(t, []string{"MATCH (f:Function) RETURN f.name AS name ORDER BY name;"}, history.Entries())
+
+		press(m, tea.KeyMsg{Type: tea.KeyUp})
+		assert.Equal(t, history.Entries()[0], m.input.Value())
+		press(m, tea.KeyMsg{Type: tea.KeyDown})
+		assert.Empty(t, m.input.Value())
+	})
+
+	t.Run("Should complete the word before the cursor", func(t *testing.T) {
+		history, err := LoadHistory("")
+		require.NoError(t, err)
+		m := New(context.Background(), shellSession(t), history)
+		press(m, runes("MATCH (f:Fun"), tea.KeyMsg{Type: tea.KeyTab})
+		assert.Equal(t, "MATCH (f:Function", m.input.Value())
+
+		m.input.SetValue("RETURN co)")
+		m.input.SetCursor(len("RETURN co"))
+		press(m, tea.KeyMsg{Type: tea.KeyTab})
+		assert.Contains(t, m.View(), "coalesce  collect  count")
+		press(m, runes("ll"), tea.KeyMsg{Type: tea.KeyTab})
+		assert.Equal(t, "RETURN collect)", m.input.Value())
+		assert.NotContains(t, m.View(), "count")
+	})
+}
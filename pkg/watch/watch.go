@@ -0,0 +1,176 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long a burst of changes must be quiet before it is reported
+const DefaultDebounce = 300 * time.Millisecond
+
+// Watcher reports the package directories of a project whose Go files change
+type Watcher struct {
+	root       string
+	debounce   time.Duration
+	ignoreDirs map[string]bool
+	fs         *fsnotify.Watcher
+	dirs       map[string]bool // Watched directories
+}
+
+// New watches every directory below root, skipping hidden directories,
+// vendor, testdata and the given directory names
+func New(root string, ignoreDirs []string, debounce time.Duration) (*Watcher, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve watch root: %w", err)
+	}
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	w := &Watcher{
+		root:       absRoot,
+		debounce:   debounce,
+		ignoreDirs: map[string]bool{"vendor": true, "testdata": true},
+		fs:         fs,
+		dirs:       make(map[string]bool),
+	}
+	for _, dir := range ignoreDirs {
+		w.ignoreDirs[dir] = true
+	}
+	if _, err := w.addTree(absRoot); err != nil {
+		fs.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+// Run blocks until ctx is done, calling onChange with the sorted absolute
+// directories touched by each burst of changes. Directories whose Go files
+// were all removed are reported too, so callers can drop their packages.
+func (w *Watcher) Run(ctx context.Context, onChange func(dirs []string)) error {
+	pending := make(map[string]bool)
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("file watcher failed: %w", err)
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			if w.handle(event, pending) {
+				timer.Reset(w.debounce)
+			}
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			dirs := make([]string, 0, len(pending))
+			for dir := range pending {
+				dirs = append(dirs, dir)
+			}
+			sort.Strings(dirs)
+			clear(pending)
+			onChange(dirs)
+		}
+	}
+}
+
+// handle records the directories affected by an event and reports whether
+// there were any
+func (w *Watcher) handle(event fsnotify.Event, pending map[string]bool) bool {
+	path := filepath.Clean(event.Name)
+	switch {
+	case event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename):
+		if w.dirs[path] {
+			// Everything below a removed directory is gone with it
+			found := false
+			for dir := range w.dirs {
+				if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+					delete(w.dirs, dir)
+					pending[dir] = true
+					found = true
+				}
+			}
+			return found
+		}
+	case event.Has(fsnotify.Create):
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			if w.ignored(path) {
+				return false
+			}
+			// Files written before the directory was watched produce no events
+			dirs, err := w.addTree(path)
+			if err != nil {
+				return false
+			}
+			for _, dir := range dirs {
+				pending[dir] = true
+			}
+			return len(dirs) > 0
+		}
+	case !event.Has(fsnotify.Write):
+		return false
+	}
+	if !strings.HasSuffix(path, ".go") {
+		return false
+	}
+	pending[filepath.Dir(path)] = true
+	return true
+}
+
+// addTree watches dir and its subdirectories, returning those containing Go files
+func (w *Watcher) addTree(dir string) ([]string, error) {
+	var goDirs []string
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			if strings.HasSuffix(path, ".go") && (len(goDirs) == 0 || goDirs[len(goDirs)-1] != filepath.Dir(path)) {
+				goDirs = append(goDirs, filepath.Dir(path))
+			}
+			return nil
+		}
+		if path != w.root && w.ignored(path) {
+			return filepath.SkipDir
+		}
+		if err := w.fs.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		w.dirs[path] = true
+		return nil
+	})
+	return goDirs, err
+}
+
+// ignored reports whether a directory is skipped, like the go tool skips
+// directories starting with "." or "_"
+func (w *Watcher) ignored(dir string) bool {
+	name := filepath.Base(dir)
+	return w.ignoreDirs[name] || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
@@ -0,0 +1,97 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startWatcher(t *testing.T, root string) <-chan []string {
+	t.Helper()
+	w, err := New(root, []string{"node_modules"}, 50*time.Millisecond)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	batches := make(chan []string, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, w.Run(ctx, func(dirs []string) { batches <- dirs }))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		w.Close()
+	})
+	return batches
+}
+
+func nextBatch(t *testing.T, batches <-chan []string) []string {
+	t.Helper()
+	select {
+	case dirs := <-batches:
+		return dirs
+	case <-time.After(5 * time.Second):
+		t.Fatal("no changes reported")
+		return nil
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestWatcher(t *testing.T) {
+	t.Run("Should report a burst of saves once per package directory", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, filepath.Join(root, "store", "store.go"), "package store\n")
+		batches := startWatcher(t, root)
+
+		writeFile(t, filepath.Join(root, "store", "store.go"), "package store\n\nfunc Save() {}\n")
+		writeFile(t, filepath.Join(root, "store", "load.go"), "package store\n")
+		writeFile(t, filepath.Join(root, "README.md"), "docs\n")
+
+		assert.Equal(t, []string{filepath.Join(root, "store")}, nextBatch(t, batches))
+	})
+
+	t.Run("Should watch new directories", func(t *testing.T) {
+		root := t.TempDir()
+		batches := startWatcher(t, root)
+
+		writeFile(t, filepath.Join(root, "api", "v1", "api.go"), "package v1\n")
+
+		dirs := nextBatch(t, batches)
+		assert.Contains(t, dirs, filepath.Join(root, "api", "v1"))
+		writeFile(t, filepath.Join(root, "api", "v1", "api.go"), "package v1\n\nfunc Get() {}\n")
+		assert.Equal(t, []string{filepath.Join(root, "api", "v1")}, nextBatch(t, batches))
+	})
+
+	t.Run("Should report removed directories", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, filepath.Join(root, "old", "old.go"), "package old\n")
+		batches := startWatcher(t, root)
+
+		require.NoError(t, os.RemoveAll(filepath.Join(root, "old")))
+
+		assert.Contains(t, nextBatch(t, batches), filepath.Join(root, "old"))
+	})
+
+	t.Run("Should skip ignored directories", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, filepath.Join(root, "node_modules", "x.go"), "package x\n")
+		writeFile(t, filepath.Join(root, ".cache", "y.go"), "package y\n")
+		batches := startWatcher(t, root)
+
+		writeFile(t, filepath.Join(root, "node_modules", "x.go"), "package x\n\nfunc X() {}\n")
+		writeFile(t, filepath.Join(root, ".cache", "y.go"), "package y\n\nfunc Y() {}\n")
+		writeFile(t, filepath.Join(root, "main.go"), "package main\n")
+
+		assert.Equal(t, []string{root}, nextBatch(t, batches))
+	})
+}
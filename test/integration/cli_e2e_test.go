@@ -2,6 +2,7 @@ package integration
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -182,6 +183,57 @@ func TestMCPServerCommand(t *testing.T) {
 	})
 }
 
+// TestCLIRevisionSnapshot analyzes a git revision into the embedded store and
+// compares it with the latest analysis of the same commit
+func TestCLIRevisionSnapshot(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available, skipping revision snapshot test")
+	}
+
+	gographBinary := buildCLIBinary(t, getProjectRoot())
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/revision\n\ngo 1.24\n",
+		"main.go": "package main\n\nfunc main() {\n\tgreet()\n}\n\nfunc greet() {}\n",
+		"gograph.yaml": "project:\n  id: revision-test\nstorage:\n  backend: embedded\n" +
+			"snapshots:\n  enabled: true\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	run := func(name string, args ...string) string {
+		cmd := exec.Command(name, args...)
+		cmd.Dir = dir
+		output, err := cmd.Output()
+		require.NoError(t, err, "%s %s failed", name, strings.Join(args, " "))
+		return string(output)
+	}
+	run("git", "init", "-q")
+	run("git", "add", ".")
+	run("git", "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+
+	t.Run("Should store a --ref snapshot that does not differ from the latest analysis", func(t *testing.T) {
+		run(gographBinary, "analyze", "--no-progress", ".")
+		run(gographBinary, "analyze", "--no-progress", "--ref", "HEAD", ".")
+
+		var snapshots []struct {
+			ID     string `json:"id"`
+			Latest bool   `json:"latest"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(run(gographBinary, "snapshots", "list", "--format", "json")), &snapshots))
+		var backfill string
+		for _, snapshot := range snapshots {
+			if !snapshot.Latest {
+				backfill = snapshot.ID
+			}
+		}
+		require.NotEmpty(t, backfill)
+
+		output := run(gographBinary, "diff", "--snapshots", backfill, "latest")
+		assert.Contains(t, output, "No structural changes.")
+	})
+}
+
 // buildCLIBinary builds the CLI binary for testing
 func buildCLIBinary(t *testing.T, projectRoot string) string {
 	t.Helper()
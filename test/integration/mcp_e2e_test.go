@@ -390,6 +390,15 @@ func (r *realServiceAdapter) ExecuteQuery(
 	return r.repository.ExecuteQuery(ctx, query, params)
 }
 
+func (r *realServiceAdapter) StreamQuery(
+	ctx context.Context,
+	query string,
+	params map[string]any,
+	fn graph.RowFunc,
+) error {
+	return r.repository.StreamQuery(ctx, query, params, fn)
+}
+
 func (r *realServiceAdapter) BuildAnalysisResult(
 	ctx context.Context,
 	projectID core.ID,